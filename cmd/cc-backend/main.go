@@ -13,13 +13,18 @@ import (
 	"sync"
 	"syscall"
 
+	"github.com/ClusterCockpit/cc-backend/internal/alerting"
 	"github.com/ClusterCockpit/cc-backend/internal/archiver"
 	"github.com/ClusterCockpit/cc-backend/internal/auth"
 	"github.com/ClusterCockpit/cc-backend/internal/config"
 	"github.com/ClusterCockpit/cc-backend/internal/importer"
+	"github.com/ClusterCockpit/cc-backend/internal/mailer"
 	"github.com/ClusterCockpit/cc-backend/internal/metricdata"
+	"github.com/ClusterCockpit/cc-backend/internal/notification"
 	"github.com/ClusterCockpit/cc-backend/internal/repository"
+	"github.com/ClusterCockpit/cc-backend/internal/tagger"
 	"github.com/ClusterCockpit/cc-backend/internal/taskManager"
+	"github.com/ClusterCockpit/cc-backend/internal/webhook"
 	"github.com/ClusterCockpit/cc-backend/pkg/archive"
 	"github.com/ClusterCockpit/cc-backend/pkg/log"
 	"github.com/ClusterCockpit/cc-backend/pkg/runtimeEnv"
@@ -193,6 +198,15 @@ func main() {
 		log.Abortf("Init: Failed to initialize metricdata repository.\nError %s\n", err.Error())
 	}
 
+	if err := tagger.Init(config.Keys.JobRules); err != nil {
+		log.Abortf("Init: Failed to initialize job rules.\nError %s\n", err.Error())
+	}
+
+	if err := alerting.Init(config.Keys.Alerts); err != nil {
+		log.Abortf("Init: Failed to initialize alerts.\nError %s\n", err.Error())
+	}
+	mailer.Init(config.Keys.Smtp)
+
 	if flagReinitDB {
 		if err := importer.InitDB(); err != nil {
 			log.Abortf("Init DB: Failed to re-initialize repository DB.\nError: %s\n", err.Error())
@@ -214,6 +228,8 @@ func main() {
 	}
 
 	archiver.Start(repository.GetJobRepository())
+	webhook.Start(config.Keys.Webhooks, repository.GetConnection().DB)
+	notification.Init(repository.GetConnection().DB)
 	taskManager.Start()
 	serverInit()
 
@@ -236,6 +252,7 @@ func main() {
 		serverShutdown()
 
 		taskManager.Shutdown()
+		webhook.Shutdown()
 	}()
 
 	if os.Getenv("GOGC") == "" {
@@ -0,0 +1,362 @@
+// Copyright (C) NHR@FAU, University Erlangen-Nuremberg.
+// All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+// Package alerting checks running jobs against the alerts configured in
+// `alerts`, using the statistics loaded by the footprint worker. Conditions
+// are the same as for job rules (see package tagger).
+//
+// Alerts with a duration (`for`) are evaluated on the statistics of the last
+// period of this length, loaded separately for the alerts, and only once the
+// job ran this long. Alerts without are evaluated on the statistics of the
+// whole job. An alert fires once its conditions match: it is recorded in the
+// job_alert table, its tag is attached to the job, a job.alert webhook event is
+// emitted and emails are sent to the job owner and the configured addresses.
+// An alert fires at most once per job.
+package alerting
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ClusterCockpit/cc-backend/internal/mailer"
+	"github.com/ClusterCockpit/cc-backend/internal/metricDataDispatcher"
+	"github.com/ClusterCockpit/cc-backend/internal/repository"
+	"github.com/ClusterCockpit/cc-backend/internal/tagger"
+	"github.com/ClusterCockpit/cc-backend/internal/webhook"
+	"github.com/ClusterCockpit/cc-backend/pkg/archive"
+	"github.com/ClusterCockpit/cc-backend/pkg/log"
+	"github.com/ClusterCockpit/cc-backend/pkg/schema"
+)
+
+type alert struct {
+	*schema.AlertConfig
+	duration time.Duration
+}
+
+type key struct {
+	alert string
+	job   int64
+}
+
+type state struct {
+	checked time.Time
+	fired   bool
+}
+
+var (
+	alerts  []*alert
+	mu      sync.Mutex
+	pending = make(map[key]*state)
+
+	// Replaced in tests
+	loadStats = metricDataDispatcher.LoadStats
+)
+
+// Init checks the alerts and fills in defaults. No alerts are checked if Init
+// was not called or returned an error.
+func Init(cfgs []*schema.AlertConfig) error {
+	res := make([]*alert, 0, len(cfgs))
+	for _, cfg := range cfgs {
+		if cfg.Name == "" {
+			return fmt.Errorf("ALERTING > alert without name")
+		}
+		if len(cfg.Conditions) == 0 {
+			return fmt.Errorf("ALERTING > alert '%s': no conditions", cfg.Name)
+		}
+		for _, c := range cfg.Conditions {
+			if err := tagger.CheckCondition(c); err != nil {
+				return fmt.Errorf("ALERTING > alert '%s': %w", cfg.Name, err)
+			}
+		}
+
+		switch cfg.Scope {
+		case "":
+			cfg.Scope = "job"
+		case "job", "node":
+		default:
+			return fmt.Errorf("ALERTING > alert '%s': unknown scope '%s'", cfg.Name, cfg.Scope)
+		}
+
+		a := &alert{AlertConfig: cfg}
+		if cfg.For != "" {
+			d, err := time.ParseDuration(cfg.For)
+			if err != nil {
+				return fmt.Errorf("ALERTING > alert '%s': %w", cfg.Name, err)
+			}
+			a.duration = d
+		}
+
+		if cfg.Tag == nil {
+			cfg.Tag = &schema.JobRuleTag{}
+		}
+		if cfg.Tag.Type == "" {
+			cfg.Tag.Type = "alert"
+		}
+		if cfg.Tag.Name == "" {
+			cfg.Tag.Name = cfg.Name
+		}
+		if cfg.Tag.Scope == "" {
+			cfg.Tag.Scope = "global"
+		}
+
+		res = append(res, a)
+	}
+
+	alerts = res
+	if len(alerts) > 0 {
+		log.Infof("Alerting: %d alerts configured", len(alerts))
+	}
+	return nil
+}
+
+// Enabled returns true if any alerts are configured.
+func Enabled() bool {
+	return len(alerts) > 0
+}
+
+// Check evaluates all alerts for the running job. stats are the statistics of
+// the job per metric and host, as returned by metricDataDispatcher.LoadStats.
+// Errors are only logged.
+func Check(job *schema.Job, stats map[string]map[string]schema.MetricStatistics) {
+	if len(alerts) == 0 {
+		return
+	}
+
+	var metricConfig []schema.MetricConfig
+	if sc, err := archive.GetSubCluster(job.Cluster, job.SubCluster); err == nil {
+		metricConfig = sc.MetricConfig
+	}
+
+	now := time.Now()
+	windows := make(map[time.Duration]map[string]map[string]schema.MetricStatistics)
+	for _, a := range alerts {
+		if len(a.Clusters) > 0 && !slices.Contains(a.Clusters, job.Cluster) {
+			continue
+		}
+
+		s := a.statistics(job, stats, windows, now)
+		if s == nil {
+			continue
+		}
+		if observe(key{a.Name, job.ID}, a.matches(job, s, metricConfig), now) {
+			fire(a, job, now.Add(-a.duration), now)
+		}
+	}
+}
+
+// Returns the statistics the alert is evaluated on: those of the last period
+// of its duration or of the whole job. The statistics of a period are loaded
+// once per job for all alerts with this duration. Returns nil if the job did
+// not run long enough yet or loading failed.
+func (a *alert) statistics(
+	job *schema.Job,
+	stats map[string]map[string]schema.MetricStatistics,
+	windows map[time.Duration]map[string]map[string]schema.MetricStatistics,
+	now time.Time,
+) map[string]map[string]schema.MetricStatistics {
+	if a.duration == 0 {
+		return stats
+	}
+	if now.Sub(job.StartTime) < a.duration {
+		return nil
+	}
+	if s, ok := windows[a.duration]; ok {
+		return s
+	}
+
+	metrics := make([]string, 0)
+	for _, other := range alerts {
+		if other.duration != a.duration {
+			continue
+		}
+		for _, c := range other.Conditions {
+			if c.Metric != "" && !slices.Contains(metrics, c.Metric) {
+				metrics = append(metrics, c.Metric)
+			}
+		}
+	}
+
+	// The job as if it started at the beginning of the period
+	window := *job
+	window.StartTime = now.Add(-a.duration)
+	window.StartTimeUnix = window.StartTime.Unix()
+	window.Duration = int32(a.duration.Seconds())
+
+	s := make(map[string]map[string]schema.MetricStatistics)
+	if len(metrics) > 0 {
+		var err error
+		if s, err = loadStats(&window, metrics, context.Background()); err != nil {
+			log.Errorf("Alerting: loading statistics of the last %s of job (dbid: %d) failed: %s", a.duration, job.ID, err.Error())
+			s = nil
+		}
+	}
+	windows[a.duration] = s
+	return s
+}
+
+// Prune forgets all jobs not checked since the time, jobs which are not
+// running anymore.
+func Prune(before time.Time) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	for k, s := range pending {
+		if s.checked.Before(before) {
+			delete(pending, k)
+		}
+	}
+}
+
+// Returns true if the conditions match, only once per alert and job as long
+// as they keep matching.
+func observe(k key, matched bool, now time.Time) bool {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if !matched {
+		delete(pending, k)
+		return false
+	}
+
+	s, ok := pending[k]
+	if !ok {
+		s = &state{}
+		pending[k] = s
+	}
+	s.checked = now
+
+	if s.fired {
+		return false
+	}
+	s.fired = true
+	return true
+}
+
+func (a *alert) matches(job *schema.Job, stats map[string]map[string]schema.MetricStatistics, metricConfig []schema.MetricConfig) bool {
+	if a.Scope == "node" {
+		if len(job.Resources) < 2 {
+			return false
+		}
+
+		n := 0
+		for _, res := range job.Resources {
+			hostStats := make(map[string]schema.JobStatistics, len(stats))
+			for metric, hosts := range stats {
+				if s, ok := hosts[res.Hostname]; ok {
+					hostStats[metric] = schema.JobStatistics{Avg: s.Avg, Min: s.Min, Max: s.Max}
+				}
+			}
+			if a.conditionsMatch(job, hostStats, metricConfig) {
+				n++
+			}
+		}
+		return n > 0 && n < len(job.Resources)
+	}
+
+	jobStats := make(map[string]schema.JobStatistics, len(stats))
+	for metric, hosts := range stats {
+		jobStats[metric] = tagger.Aggregate(hosts)
+	}
+	return a.conditionsMatch(job, jobStats, metricConfig)
+}
+
+func (a *alert) conditionsMatch(job *schema.Job, stats map[string]schema.JobStatistics, metricConfig []schema.MetricConfig) bool {
+	for _, c := range a.Conditions {
+		if !tagger.ConditionMatches(c, job, stats, metricConfig) {
+			return false
+		}
+	}
+	return true
+}
+
+// Human readable description of the conditions
+func (a *alert) describe() string {
+	conds := make([]string, 0, len(a.Conditions))
+	for _, c := range a.Conditions {
+		switch {
+		case c.Property != "":
+			conds = append(conds, fmt.Sprintf("%s %s %g", c.Property, c.Op, c.Value))
+		case c.Threshold != "":
+			conds = append(conds, fmt.Sprintf("%s %s %s %g%% of %s", c.Metric, c.Stat, c.Op, c.Value*100, c.Threshold))
+		default:
+			conds = append(conds, fmt.Sprintf("%s %s %s %g", c.Metric, c.Stat, c.Op, c.Value))
+		}
+	}
+
+	if a.Scope == "node" {
+		return "on some, but not all nodes: " + strings.Join(conds, " and ")
+	}
+	return strings.Join(conds, " and ")
+}
+
+func fire(a *alert, job *schema.Job, since time.Time, now time.Time) {
+	jobAlert := &schema.JobAlert{
+		Name:      a.Name,
+		Message:   a.describe(),
+		JobID:     job.ID,
+		Since:     since.Unix(),
+		CreatedAt: now.Unix(),
+	}
+
+	jobRepo := repository.GetJobRepository()
+	added, err := jobRepo.AddJobAlert(jobAlert)
+	if err != nil {
+		log.Errorf("Alerting: recording alert '%s' of job (dbid: %d) failed: %s", a.Name, job.ID, err.Error())
+		return
+	}
+	if !added {
+		return
+	}
+	log.Infof("Alerting: alert '%s' fired for job (dbid: %d)", a.Name, job.ID)
+
+	if _, err := jobRepo.AttachTag(job, a.Tag.Type, a.Tag.Name, a.Tag.Scope); err != nil {
+		log.Errorf("Alerting: attaching tag of alert '%s' to job (dbid: %d) failed: %s", a.Name, job.ID, err.Error())
+	}
+	webhook.EmitAlert(job, jobAlert)
+
+	go notify(a, job, jobAlert)
+}
+
+func notify(a *alert, job *schema.Job, jobAlert *schema.JobAlert) {
+	if !mailer.Enabled() {
+		return
+	}
+
+	to := slices.Clone(a.Emails)
+	if a.NotifyOwner {
+		user, err := repository.GetUserRepository().GetUser(job.User)
+		if err != nil || user.Email == "" {
+			log.Warnf("Alerting: no email address of user '%s' for alert '%s'", job.User, a.Name)
+		} else {
+			to = append(to, user.Email)
+		}
+	}
+	if len(to) == 0 {
+		return
+	}
+
+	subject := fmt.Sprintf("Alert '%s' for job %d on %s", a.Name, job.JobID, job.Cluster)
+	if err := mailer.Send(to, subject, message(job, jobAlert)); err != nil {
+		log.Errorf("Alerting: sending email for alert '%s' of job (dbid: %d) failed: %s", a.Name, job.ID, err.Error())
+	}
+}
+
+func message(job *schema.Job, jobAlert *schema.JobAlert) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "The alert '%s' fired for job %d on cluster %s.\n\n", jobAlert.Name, job.JobID, job.Cluster)
+	fmt.Fprintf(&b, "User:    %s\n", job.User)
+	fmt.Fprintf(&b, "Project: %s\n", job.Project)
+	fmt.Fprintf(&b, "Nodes:   %d\n", job.NumNodes)
+	fmt.Fprintf(&b, "Started: %s\n\n", job.StartTime.Format(time.RFC1123))
+	fmt.Fprintf(&b, "Conditions: %s\nMatching since %s.\n", jobAlert.Message, time.Unix(jobAlert.Since, 0).Format(time.RFC1123))
+	if url := mailer.JobUrl(job.ID); url != "" {
+		fmt.Fprintf(&b, "\n%s\n", url)
+	}
+	return b.String()
+}
@@ -0,0 +1,205 @@
+// Copyright (C) NHR@FAU, University Erlangen-Nuremberg.
+// All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+package alerting
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ClusterCockpit/cc-backend/internal/mailer"
+	"github.com/ClusterCockpit/cc-backend/internal/mailer/mailertest"
+	"github.com/ClusterCockpit/cc-backend/internal/metricDataDispatcher"
+	"github.com/ClusterCockpit/cc-backend/pkg/log"
+	"github.com/ClusterCockpit/cc-backend/pkg/schema"
+)
+
+func testAlerts(t *testing.T) (gpu *alert, imbalance *alert) {
+	err := Init([]*schema.AlertConfig{
+		{
+			Name:       "idle-gpus",
+			For:        "30m",
+			Conditions: []*schema.JobRuleCondition{{Metric: "acc_utilization", Op: "<", Value: 5}},
+			Emails:     []string{"support@example.org"},
+		},
+		{
+			Name:       "idle-node",
+			Scope:      "node",
+			Conditions: []*schema.JobRuleCondition{{Metric: "flops_any", Op: "<", Value: 0.01, Threshold: "peak"}},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { alerts = nil })
+	return alerts[0], alerts[1]
+}
+
+func TestInit(t *testing.T) {
+	gpu, imbalance := testAlerts(t)
+	if gpu.duration != 30*time.Minute || gpu.Scope != "job" || imbalance.duration != 0 {
+		t.Errorf("wrong settings: %v %s %v", gpu.duration, gpu.Scope, imbalance.duration)
+	}
+	if gpu.Tag.Type != "alert" || gpu.Tag.Name != "idle-gpus" || gpu.Tag.Scope != "global" {
+		t.Errorf("wrong default tag: %#v", gpu.Tag)
+	}
+
+	invalid := []*schema.AlertConfig{
+		{Conditions: gpu.Conditions},
+		{Name: "no conditions"},
+		{Name: "scope", Scope: "socket", Conditions: gpu.Conditions},
+		{Name: "for", For: "30 minutes", Conditions: gpu.Conditions},
+		{Name: "condition", Conditions: []*schema.JobRuleCondition{{Metric: "flops_any", Op: "!="}}},
+	}
+	for _, cfg := range invalid {
+		if err := Init([]*schema.AlertConfig{cfg}); err == nil {
+			t.Errorf("expected error for %#v", cfg)
+		}
+	}
+}
+
+func TestObserve(t *testing.T) {
+	k := key{"idle-gpus", 1}
+	start := time.Now()
+	t.Cleanup(func() { pending = make(map[key]*state) })
+
+	steps := []struct {
+		matched bool
+		due     bool
+	}{
+		{false, false},
+		{true, true},
+		{true, false}, // fires only once
+		{false, false},
+		{true, true}, // starts over
+	}
+	for i, s := range steps {
+		if due := observe(k, s.matched, start.Add(time.Duration(i)*10*time.Minute)); due != s.due {
+			t.Errorf("%d: got %v, want %v", i, due, s.due)
+		}
+	}
+
+	Prune(start.Add(30 * time.Minute))
+	if len(pending) != 1 {
+		t.Errorf("job pruned although checked")
+	}
+	Prune(start.Add(time.Hour))
+	if len(pending) != 0 {
+		t.Errorf("job not pruned")
+	}
+}
+
+func TestStatistics(t *testing.T) {
+	gpu, imbalance := testAlerts(t)
+	now := time.Now()
+
+	var loaded []*schema.Job
+	loadStats = func(job *schema.Job, metrics []string, ctx context.Context) (map[string]map[string]schema.MetricStatistics, error) {
+		loaded = append(loaded, job)
+		if len(metrics) != 1 || metrics[0] != "acc_utilization" {
+			t.Errorf("unexpected metrics: %v", metrics)
+		}
+		return map[string]map[string]schema.MetricStatistics{"acc_utilization": {"host1": {Avg: 1}}}, nil
+	}
+	t.Cleanup(func() { loadStats = metricDataDispatcher.LoadStats })
+
+	lifetime := map[string]map[string]schema.MetricStatistics{"acc_utilization": {"host1": {Avg: 50}}}
+	job := &schema.Job{ID: 1, StartTime: now.Add(-2 * time.Hour), BaseJob: schema.BaseJob{Duration: 7200}}
+	windows := make(map[time.Duration]map[string]map[string]schema.MetricStatistics)
+
+	// Alerts without duration use the statistics of the whole job
+	if s := imbalance.statistics(job, lifetime, windows, now); s["acc_utilization"]["host1"].Avg != 50 {
+		t.Errorf("unexpected statistics: %v", s)
+	}
+
+	// Otherwise the statistics of the last period are loaded once
+	for range 2 {
+		if s := gpu.statistics(job, lifetime, windows, now); s["acc_utilization"]["host1"].Avg != 1 {
+			t.Errorf("unexpected statistics: %v", s)
+		}
+	}
+	if len(loaded) != 1 {
+		t.Fatalf("expected statistics loaded once, got %d", len(loaded))
+	}
+	if !loaded[0].StartTime.Equal(now.Add(-30*time.Minute)) || loaded[0].Duration != 1800 || job.Duration != 7200 {
+		t.Errorf("unexpected period: %s, %d s", loaded[0].StartTime, loaded[0].Duration)
+	}
+
+	// Jobs which did not run long enough are skipped
+	job = &schema.Job{ID: 2, StartTime: now.Add(-10 * time.Minute)}
+	if s := gpu.statistics(job, lifetime, make(map[time.Duration]map[string]map[string]schema.MetricStatistics), now); s != nil {
+		t.Errorf("unexpected statistics: %v", s)
+	}
+}
+
+func TestMatches(t *testing.T) {
+	gpu, imbalance := testAlerts(t)
+
+	metricConfig := []schema.MetricConfig{{Name: "flops_any", Peak: 1000}}
+	job := &schema.Job{BaseJob: schema.BaseJob{
+		Cluster:   "fritz",
+		NumNodes:  3,
+		Resources: []*schema.Resource{{Hostname: "host1"}, {Hostname: "host2"}, {Hostname: "host3"}},
+	}}
+	stats := func(flops ...float64) map[string]map[string]schema.MetricStatistics {
+		res := map[string]map[string]schema.MetricStatistics{
+			"flops_any":       {},
+			"acc_utilization": {"host1": {Avg: 2}, "host2": {Avg: 4}, "host3": {Avg: 9}},
+		}
+		for i, f := range flops {
+			res["flops_any"][job.Resources[i].Hostname] = schema.MetricStatistics{Avg: f}
+		}
+		return res
+	}
+
+	// acc_utilization averages to 5
+	if gpu.matches(job, stats(), metricConfig) {
+		t.Error("idle-gpus: unexpected match")
+	}
+
+	tests := []struct {
+		flops []float64
+		want  bool
+	}{
+		{[]float64{500, 600, 2}, true},
+		{[]float64{500, 600, 700}, false},
+		{[]float64{1, 2, 3}, false},
+	}
+	for i, tt := range tests {
+		if got := imbalance.matches(job, stats(tt.flops...), metricConfig); got != tt.want {
+			t.Errorf("idle-node %d: got %v, want %v", i, got, tt.want)
+		}
+	}
+
+	if got := imbalance.describe(); got != "on some, but not all nodes: flops_any avg < 1% of peak" {
+		t.Errorf("wrong description: %s", got)
+	}
+}
+
+func TestNotify(t *testing.T) {
+	log.Init("warn", true)
+	gpu, _ := testAlerts(t)
+	sink := mailertest.NewServer(t)
+	mailer.Init(&schema.SmtpConfig{Addr: sink.Addr, From: "cc@example.org", BaseUrl: "https://monitoring.example.org"})
+
+	job := &schema.Job{ID: 42, StartTime: time.Unix(1700000000, 0), BaseJob: schema.BaseJob{
+		JobID: 123, Cluster: "fritz", User: "alice", Project: "abcd100", NumNodes: 2,
+	}}
+	notify(gpu, job, &schema.JobAlert{Name: gpu.Name, Message: gpu.describe(), JobID: job.ID, Since: 1700001000})
+
+	msgs := sink.Messages()
+	if len(msgs) != 1 {
+		t.Fatalf("expected 1 message, got %d", len(msgs))
+	}
+	if msgs[0].Subject != "Alert 'idle-gpus' for job 123 on fritz" || len(msgs[0].To) != 1 || msgs[0].To[0] != "support@example.org" {
+		t.Errorf("wrong message: %s to %v", msgs[0].Subject, msgs[0].To)
+	}
+	for _, s := range []string{"User:    alice", "Conditions: acc_utilization avg < 5", "https://monitoring.example.org/monitoring/job/42"} {
+		if !strings.Contains(msgs[0].Body, s) {
+			t.Errorf("body misses %q:\n%s", s, msgs[0].Body)
+		}
+	}
+}
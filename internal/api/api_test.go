@@ -9,6 +9,8 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"math"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
 	"os"
@@ -22,7 +24,9 @@ import (
 	"github.com/ClusterCockpit/cc-backend/internal/archiver"
 	"github.com/ClusterCockpit/cc-backend/internal/auth"
 	"github.com/ClusterCockpit/cc-backend/internal/config"
+	"github.com/ClusterCockpit/cc-backend/internal/eventBus"
 	"github.com/ClusterCockpit/cc-backend/internal/graph"
+	"github.com/ClusterCockpit/cc-backend/internal/graph/model"
 	"github.com/ClusterCockpit/cc-backend/internal/metricDataDispatcher"
 	"github.com/ClusterCockpit/cc-backend/internal/metricdata"
 	"github.com/ClusterCockpit/cc-backend/internal/repository"
@@ -238,6 +242,25 @@ func TestRestApi(t *testing.T) {
 		AuthSource: 2,
 	}
 
+	// Subscription receiving the state changes of the test job
+	subCtx, cancelSub := context.WithCancel(context.WithValue(context.Background(), contextUserKey, contextUserValue))
+	defer cancelSub()
+	stateChanges, err := graph.GetResolverInstance().Subscription().JobStateChanged(subCtx,
+		[]*model.JobFilter{{Cluster: &model.StringInput{Eq: &TestClusterName}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectStateChange := func(t *testing.T, state schema.JobState) {
+		select {
+		case job := <-stateChanges:
+			if job.JobID != TestJobId || job.State != state {
+				t.Fatalf("unexpected job in subscription: %#v", job)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("no job state change received")
+		}
+	}
+
 	if ok := t.Run("StartJob", func(t *testing.T) {
 		req := httptest.NewRequest(http.MethodPost, "/jobs/start_job/", bytes.NewBuffer([]byte(startJobBody)))
 		recorder := httptest.NewRecorder()
@@ -282,6 +305,8 @@ func TestRestApi(t *testing.T) {
 		if len(job.Tags) != 1 || job.Tags[0].Type != "testTagType" || job.Tags[0].Name != "testTagName" || job.Tags[0].Scope != "testuser" {
 			t.Fatalf("unexpected tags: %#v", job.Tags)
 		}
+
+		expectStateChange(t, schema.JobStateRunning)
 	}); !ok {
 		return
 	}
@@ -318,6 +343,8 @@ func TestRestApi(t *testing.T) {
 			t.Fatal("expected job to be completed")
 		}
 
+		expectStateChange(t, schema.JobStateCompleted)
+
 		if job.Duration != (123457789 - 123456789) {
 			t.Fatalf("unexpected job properties: %#v", job)
 		}
@@ -434,4 +461,434 @@ func TestRestApi(t *testing.T) {
 	if !ok {
 		t.Fatal("subtest failed")
 	}
+
+	ok = t.Run("ImportJob", func(t *testing.T) {
+		meta := strings.Replace(startJobBody, `"jobId":            123,`, `"jobId": 3001, "jobState": "completed", "duration": 600, "subCluster": "sc1",`, 1)
+		data, err := json.Marshal(testData)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		body := &bytes.Buffer{}
+		mw := multipart.NewWriter(body)
+		for name, content := range map[string]string{"meta": meta, "data": string(data)} {
+			fw, err := mw.CreateFormFile(name, name+".json")
+			if err != nil {
+				t.Fatal(err)
+			}
+			fw.Write([]byte(content))
+		}
+		mw.Close()
+
+		req := httptest.NewRequest(http.MethodPost, "/jobs/import/", body)
+		req.Header.Set("Content-Type", mw.FormDataContentType())
+		recorder := httptest.NewRecorder()
+
+		ctx := context.WithValue(req.Context(), contextUserKey, contextUserValue)
+
+		r.ServeHTTP(recorder, req.WithContext(ctx))
+		response := recorder.Result()
+		if response.StatusCode != http.StatusCreated {
+			t.Fatal(response.Status, recorder.Body.String())
+		}
+
+		jobid, cluster := int64(3001), "testcluster"
+		job, err := restapi.JobRepository.Find(&jobid, &cluster, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if job.State != schema.JobStateCompleted || job.Duration != 600 {
+			t.Fatalf("unexpected job properties: %#v", job)
+		}
+
+		jobData, err := metricDataDispatcher.LoadData(job, []string{"load_one"}, []schema.MetricScope{schema.MetricScopeNode}, context.Background(), 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(jobData, testData) {
+			t.Fatal("unexpected data fetched from archive")
+		}
+
+		// Importing the same job again fails
+		req = httptest.NewRequest(http.MethodPost, "/jobs/import/", bytes.NewBuffer([]byte(`{"meta": `+meta+`, "data": `+string(data)+`}`)))
+		recorder = httptest.NewRecorder()
+		r.ServeHTTP(recorder, req.WithContext(ctx))
+		if recorder.Result().StatusCode != http.StatusUnprocessableEntity {
+			t.Fatal(recorder.Result().Status, recorder.Body.String())
+		}
+	})
+	if !ok {
+		t.Fatal("subtest failed")
+	}
+
+	batchJob := func(jobId, startTime int64) string {
+		body := strings.Replace(startJobBodyFailed, `"jobId":            12345`, fmt.Sprintf(`"jobId": %d`, jobId), 1)
+		return strings.Replace(body, `"startTime": 12345678`, fmt.Sprintf(`"startTime": %d`, startTime), 1)
+	}
+
+	ok = t.Run("StartJobs", func(t *testing.T) {
+		body := "[" + strings.Join([]string{
+			batchJob(2001, 22345678),
+			batchJob(2002, 22345678),
+			strings.Replace(startJobBody, `"startTime": 123456789`, `"startTime": 123456790`, -1),
+			batchJob(2001, 22345679),
+		}, ",") + "]"
+		req := httptest.NewRequest(http.MethodPost, "/jobs/start_jobs/", bytes.NewBuffer([]byte(body)))
+		recorder := httptest.NewRecorder()
+
+		ctx := context.WithValue(req.Context(), contextUserKey, contextUserValue)
+
+		r.ServeHTTP(recorder, req.WithContext(ctx))
+		response := recorder.Result()
+		if response.StatusCode != http.StatusOK {
+			t.Fatal(response.Status, recorder.Body.String())
+		}
+
+		var res api.BatchJobApiResponse
+		if err := json.NewDecoder(recorder.Body).Decode(&res); err != nil {
+			t.Fatal(err)
+		}
+		expected := []string{"created", "created", "duplicate", "duplicate"}
+		if len(res.Results) != len(expected) {
+			t.Fatalf("unexpected results: %#v", res.Results)
+		}
+		for i, status := range expected {
+			if res.Results[i].Status != status {
+				t.Fatalf("unexpected result %d: %#v", i, res.Results[i])
+			}
+		}
+
+		jobid, cluster := int64(2002), "testcluster"
+		job, err := restapi.JobRepository.Find(&jobid, &cluster, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if job.ID != res.Results[1].Id || job.State != schema.JobStateRunning {
+			t.Fatalf("unexpected job properties: %#v", job)
+		}
+	})
+	if !ok {
+		t.Fatal("subtest failed")
+	}
+
+	ok = t.Run("StopJobs", func(t *testing.T) {
+		const body string = `[
+		{ "jobId": 2001, "cluster": "testcluster", "jobState": "completed", "stopTime": 22355678 },
+		{ "jobId": 2002, "cluster": "testcluster", "jobState": "timeout", "stopTime": 22355678 },
+		{ "jobId": 2003, "cluster": "testcluster", "jobState": "completed", "stopTime": 22355678 }
+		]`
+		req := httptest.NewRequest(http.MethodPost, "/jobs/stop_jobs/", bytes.NewBuffer([]byte(body)))
+		recorder := httptest.NewRecorder()
+
+		ctx := context.WithValue(req.Context(), contextUserKey, contextUserValue)
+
+		r.ServeHTTP(recorder, req.WithContext(ctx))
+		response := recorder.Result()
+		if response.StatusCode != http.StatusOK {
+			t.Fatal(response.Status, recorder.Body.String())
+		}
+
+		var res api.BatchJobApiResponse
+		if err := json.NewDecoder(recorder.Body).Decode(&res); err != nil {
+			t.Fatal(err)
+		}
+		expected := []string{"stopped", "stopped", "error"}
+		if len(res.Results) != len(expected) {
+			t.Fatalf("unexpected results: %#v", res.Results)
+		}
+		for i, status := range expected {
+			if res.Results[i].Status != status {
+				t.Fatalf("unexpected result %d: %#v", i, res.Results[i])
+			}
+		}
+
+		archiver.WaitForArchiving()
+		jobid, cluster := int64(2002), "testcluster"
+		job, err := restapi.JobRepository.Find(&jobid, &cluster, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if job.State != schema.JobStateTimeout || job.Duration != 10000 {
+			t.Fatalf("unexpected job properties: %#v", job)
+		}
+	})
+	if !ok {
+		t.Fatal("subtest failed")
+	}
+
+	t.Run("ExportJobs", func(t *testing.T) {
+		const body string = `{
+			"filter":  [{ "cluster": { "eq": "testcluster" } }, { "jobId": { "in": ["2001", "2002"] } }],
+			"order":   { "field": "jobId", "type": "col", "order": "ASC" },
+			"columns": ["jobId", "jobState", "duration", "nodes", "metaData.jobScript"]
+		}`
+		req := httptest.NewRequest(http.MethodPost, "/jobs/export/", bytes.NewBuffer([]byte(body)))
+		recorder := httptest.NewRecorder()
+
+		ctx := context.WithValue(req.Context(), contextUserKey, contextUserValue)
+
+		r.ServeHTTP(recorder, req.WithContext(ctx))
+		response := recorder.Result()
+		if response.StatusCode != http.StatusOK {
+			t.Fatal(response.Status, recorder.Body.String())
+		}
+		if response.Header.Get("Content-Type") != "text/csv" {
+			t.Fatalf("unexpected content type: %s", response.Header.Get("Content-Type"))
+		}
+
+		expected := "jobId,jobState,duration,nodes,metaData.jobScript\n" +
+			"2001,completed,10000,host123,\n" +
+			"2002,timeout,10000,host123,\n"
+		if recorder.Body.String() != expected {
+			t.Fatalf("unexpected export:\n%s", recorder.Body.String())
+		}
+
+		req = httptest.NewRequest(http.MethodPost, "/jobs/export/", bytes.NewBuffer([]byte(`{"columns": ["hostname"]}`)))
+		recorder = httptest.NewRecorder()
+		r.ServeHTTP(recorder, req.WithContext(ctx))
+		if recorder.Result().StatusCode != http.StatusBadRequest {
+			t.Fatalf("expected bad request for unknown column, got %s", recorder.Result().Status)
+		}
+
+		for _, order := range []string{
+			`{ "field": "jobId", "type": "col", "order": "UP" }`,
+			`{ "field": "jobId, (SELECT 1)", "type": "col", "order": "ASC" }`,
+			`{ "field": "meta_data", "type": "col", "order": "ASC" }`,
+			`{ "field": "flops_any_avg\") ASC, (SELECT 1", "type": "foot", "order": "ASC" }`,
+			`{ "field": "jobId", "type": "other", "order": "ASC" }`,
+		} {
+			req = httptest.NewRequest(http.MethodPost, "/jobs/export/", bytes.NewBuffer([]byte(`{"order": `+order+`}`)))
+			recorder = httptest.NewRecorder()
+			r.ServeHTTP(recorder, req.WithContext(ctx))
+			if recorder.Result().StatusCode != http.StatusBadRequest || recorder.Result().Header.Get("Content-Disposition") != "" {
+				t.Fatalf("expected bad request for order %s, got %s", order, recorder.Result().Status)
+			}
+		}
+	})
+
+	t.Run("ExportJobMetrics", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/jobs/metrics/%d/export?metric=load_one", stoppedJob.ID), nil)
+		recorder := httptest.NewRecorder()
+
+		ctx := context.WithValue(req.Context(), contextUserKey, contextUserValue)
+
+		r.ServeHTTP(recorder, req.WithContext(ctx))
+		response := recorder.Result()
+		if response.StatusCode != http.StatusOK {
+			t.Fatal(response.Status, recorder.Body.String())
+		}
+
+		lines := strings.Split(strings.TrimSpace(recorder.Body.String()), "\n")
+		if len(lines) != 10 || lines[0] != "metric,scope,hostname,id,timestamp,value" ||
+			lines[1] != "load_one,node,host123,,123456789,0.1" || lines[9] != "load_one,node,host123,,123457269,0.3" {
+			t.Fatalf("unexpected export:\n%s", recorder.Body.String())
+		}
+
+		req = httptest.NewRequest(http.MethodGet, fmt.Sprintf("/jobs/metrics/%d/export?format=xlsx", stoppedJob.ID), nil)
+		recorder = httptest.NewRecorder()
+		r.ServeHTTP(recorder, req.WithContext(ctx))
+		if recorder.Result().StatusCode != http.StatusBadRequest {
+			t.Fatalf("expected bad request for unknown format, got %s", recorder.Result().Status)
+		}
+	})
+
+	t.Run("JobAlerts", func(t *testing.T) {
+		alert := &schema.JobAlert{Name: "idle", Message: "load_one avg < 0.5", JobID: stoppedJob.ID, Since: 123456789, CreatedAt: 123457389}
+		for i, want := range []bool{true, false} {
+			added, err := restapi.JobRepository.AddJobAlert(alert)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if added != want {
+				t.Fatalf("%d: alert added: %v, want %v", i, added, want)
+			}
+		}
+
+		req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/jobs/alerts/%d", stoppedJob.ID), nil)
+		recorder := httptest.NewRecorder()
+
+		ctx := context.WithValue(req.Context(), contextUserKey, contextUserValue)
+
+		r.ServeHTTP(recorder, req.WithContext(ctx))
+		response := recorder.Result()
+		if response.StatusCode != http.StatusOK {
+			t.Fatal(response.Status, recorder.Body.String())
+		}
+
+		var alerts []*schema.JobAlert
+		if err := json.NewDecoder(response.Body).Decode(&alerts); err != nil {
+			t.Fatal(err)
+		}
+		if len(alerts) != 1 || *alerts[0] != *alert {
+			t.Fatalf("unexpected alerts: %s", recorder.Body.String())
+		}
+	})
+
+	t.Run("ProjectBudgets", func(t *testing.T) {
+		configRouter := mux.NewRouter()
+		restapi.MountConfigApiRoutes(configRouter)
+		admin := &schema.User{Username: "admin", Roles: []string{"admin"}, AuthType: schema.AuthSession}
+		manager := &schema.User{Username: "manager", Roles: []string{"manager"}, Projects: []string{"testproj"}, AuthType: schema.AuthSession}
+
+		serve := func(router *mux.Router, user *schema.User, method string, url string, body string) *httptest.ResponseRecorder {
+			req := httptest.NewRequest(method, url, strings.NewReader(body))
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, req.WithContext(context.WithValue(req.Context(), contextUserKey, user)))
+			return recorder
+		}
+
+		// The test job and the imported job ran for 1000s and 600s on 8 hardware threads
+		recorder := serve(configRouter, admin, http.MethodPost, "/budgets/",
+			`{"project": "testproj", "cluster": "testcluster", "unit": "coreHours", "amount": 10, "startTime": 123456789, "endTime": 123460389}`)
+		if recorder.Code != http.StatusCreated {
+			t.Fatal(recorder.Code, recorder.Body.String())
+		}
+		var budget schema.ProjectBudget
+		if err := json.NewDecoder(recorder.Body).Decode(&budget); err != nil {
+			t.Fatal(err)
+		}
+
+		if recorder := serve(configRouter, manager, http.MethodPost, "/budgets/", `{}`); recorder.Code != http.StatusForbidden {
+			t.Fatal("manager created budget:", recorder.Code)
+		}
+		if recorder := serve(r, contextUserValue, http.MethodGet, "/budgets/", ""); recorder.Code != http.StatusForbidden {
+			t.Fatal("user listed budgets:", recorder.Code)
+		}
+
+		recorder = serve(r, manager, http.MethodGet, "/budgets/?project=testproj", "")
+		if recorder.Code != http.StatusOK {
+			t.Fatal(recorder.Code, recorder.Body.String())
+		}
+		var status []*schema.BudgetStatus
+		if err := json.Unmarshal(recorder.Body.Bytes(), &status); err != nil {
+			t.Fatal(err)
+		}
+		if len(status) != 1 || status[0].Budget.ID != budget.ID || math.Abs(status[0].Used-1600.0*8/3600) > 1e-9 {
+			t.Fatalf("unexpected budgets: %s", recorder.Body.String())
+		}
+
+		recorder = serve(configRouter, admin, http.MethodDelete, fmt.Sprintf("/budgets/%d", budget.ID), "")
+		if recorder.Code != http.StatusOK {
+			t.Fatal(recorder.Code, recorder.Body.String())
+		}
+	})
+
+	t.Run("Subscriptions", func(t *testing.T) {
+		subscription := graph.GetResolverInstance().Subscription()
+		admin := &schema.User{Username: "admin", Roles: []string{"admin"}, AuthType: schema.AuthSession}
+		otherUser := &schema.User{Username: "otheruser", Roles: []string{"user"}, AuthType: schema.AuthSession}
+		subCtx, cancelSub := context.WithCancel(context.Background())
+		defer cancelSub()
+		userCtx := func(user *schema.User) context.Context {
+			return context.WithValue(subCtx, contextUserKey, user)
+		}
+
+		serve := func(t *testing.T, url string, body string, status int) {
+			req := httptest.NewRequest(http.MethodPost, url, bytes.NewBuffer([]byte(body)))
+			recorder := httptest.NewRecorder()
+			r.ServeHTTP(recorder, req.WithContext(context.WithValue(req.Context(), contextUserKey, contextUserValue)))
+			if recorder.Code != status {
+				t.Fatal(recorder.Code, recorder.Body.String())
+			}
+		}
+		startJob := func(t *testing.T, jobId int64, user string, project string) *schema.Job {
+			body := strings.NewReplacer(`"testuser"`, `"`+user+`"`, `"testproj"`, `"`+project+`"`).Replace(batchJob(jobId, 32345678))
+			serve(t, "/jobs/start_job/", body, http.StatusCreated)
+			cluster := "testcluster"
+			job, err := restapi.JobRepository.Find(&jobId, &cluster, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			return job
+		}
+		stopJob := func(t *testing.T, jobId int64) {
+			serve(t, "/jobs/stop_job/", fmt.Sprintf(`{"jobId": %d, "cluster": "testcluster", "startTime": 32345678, "jobState": "completed", "stopTime": 32355678}`, jobId), http.StatusOK)
+			archiver.WaitForArchiving()
+		}
+
+		// State changes are only sent for jobs the user may see and which match the filter
+		otherProject := "otherproj"
+		otherJobs, err := subscription.JobStateChanged(userCtx(otherUser), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		projectJobs, err := subscription.JobStateChanged(userCtx(admin), []*model.JobFilter{{Project: &model.StringInput{Eq: &otherProject}}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		ownJob := startJob(t, 4001, "testuser", "testproj")
+		otherJob := startJob(t, 4002, "otheruser", otherProject)
+		for _, ch := range []<-chan *schema.Job{otherJobs, projectJobs} {
+			select {
+			case job := <-ch:
+				if job.ID != otherJob.ID {
+					t.Fatalf("unexpected job %d in subscription", job.JobID)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatal("no job state change received")
+			}
+		}
+
+		// Only the metrics of jobs the user may see can be subscribed
+		if _, err := subscription.JobMetricsUpdated(userCtx(otherUser), fmt.Sprint(ownJob.ID),
+			[]string{"load_one"}, []schema.MetricScope{schema.MetricScopeNode}, nil); err == nil {
+			t.Fatal("expected error for job of another user")
+		}
+		metrics, err := subscription.JobMetricsUpdated(userCtx(admin), fmt.Sprint(otherJob.ID),
+			[]string{"load_one"}, []schema.MetricScope{schema.MetricScopeNode}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expectMetrics := func(t *testing.T) {
+			select {
+			case data, ok := <-metrics:
+				if !ok || len(data) != 1 || data[0].Name != "load_one" {
+					t.Fatalf("unexpected metrics in subscription: %v", data)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatal("no metrics received")
+			}
+		}
+		expectMetrics(t)
+		eventBus.JobMetricsUpdated.Publish(ownJob)
+		eventBus.JobMetricsUpdated.Publish(otherJob)
+		expectMetrics(t)
+
+		// The count is only sent if it changed
+		counts, err := subscription.RunningJobsCount(userCtx(admin), "testcluster")
+		if err != nil {
+			t.Fatal(err)
+		}
+		expectCount := func(t *testing.T) int {
+			select {
+			case count := <-counts:
+				return count
+			case <-time.After(5 * time.Second):
+				t.Fatal("no count received")
+			}
+			return 0
+		}
+		count := expectCount(t)
+		eventBus.JobStateChanged.Publish(ownJob)
+		select {
+		case c := <-counts:
+			t.Fatalf("unchanged count %d sent again", c)
+		case <-time.After(100 * time.Millisecond):
+		}
+		stopJob(t, ownJob.JobID)
+		if c := expectCount(t); c != count-1 {
+			t.Fatalf("unexpected count %d, was %d", c, count)
+		}
+
+		// The metrics subscription ends when the job stops
+		stopJob(t, otherJob.JobID)
+		select {
+		case data, ok := <-metrics:
+			if ok {
+				t.Fatalf("unexpected metrics after job stopped: %v", data)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("metrics subscription not closed")
+		}
+	})
 }
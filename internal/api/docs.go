@@ -23,6 +23,185 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
+        "/budgets/": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Returns the budgets with the core-hours, accelerator-hours or energy consumed so far, including running jobs.\nAdmins, support staff and API users see all budgets, managers only the budgets of their projects.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Budget"
+                ],
+                "summary": "Lists project budgets and their consumption",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Only budgets of this project",
+                        "name": "project",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only budgets of this cluster",
+                        "name": "cluster",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Budgets and their consumption",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/schema.BudgetStatus"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Allocates core-hours, accelerator-hours or energy (kWh) to a project for a period.\nAn empty cluster applies the budget to jobs on all clusters. The id in the request body is ignored.\nOnly accessible from IPs registered with apiAllowedIPs configuration option.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Budget"
+                ],
+                "summary": "Adds a project budget",
+                "parameters": [
+                    {
+                        "description": "Budget to add",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/schema.ProjectBudget"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Budget added, with its id",
+                        "schema": {
+                            "$ref": "#/definitions/schema.ProjectBudget"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity: invalid budget",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/budgets/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Only accessible from IPs registered with apiAllowedIPs configuration option.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Budget"
+                ],
+                "summary": "Removes a project budget",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Database ID of the budget",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Success message",
+                        "schema": {
+                            "$ref": "#/definitions/api.DefaultJobApiResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity: no budget with id",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
         "/clusters/": {
             "get": {
                 "security": [
@@ -175,6 +354,73 @@ const docTemplate = `{
                 }
             }
         },
+        "/jobs/alerts/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Returns all alerts which fired for the job, oldest first.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Job query"
+                ],
+                "summary": "Lists the alerts of a job",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Database ID of Job",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Alerts of the job",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/schema.JobAlert"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity: finding job failed: sql: no rows in result set",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
         "/jobs/delete_job/": {
             "delete": {
                 "security": [
@@ -460,40 +706,42 @@ const docTemplate = `{
                 }
             }
         },
-        "/jobs/start_job/": {
+        "/jobs/export/": {
             "post": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Job specified in request body will be saved to database as \"running\" with new DB ID.\nJob specifications follow the 'JobMeta' scheme, API will fail to execute if requirements are not met.",
+                "description": "Streams all jobs matching the filters as CSV, Parquet or Arrow IPC stream. Only jobs visible to the user are exported.\nThe fixed columns are id, jobId, user, project, cluster, subCluster, partition, arrayJobId, numNodes, numHwthreads, numAcc,\nexclusive, smt, monitoringStatus, jobState, startTime, duration, walltime, energy and nodes (not exported by default).",
                 "consumes": [
                     "application/json"
                 ],
                 "produces": [
-                    "application/json"
+                    "text/csv",
+                    "application/vnd.apache.parquet",
+                    "application/vnd.apache.arrow.stream"
                 ],
                 "tags": [
-                    "Job add and modify"
+                    "Job query"
                 ],
-                "summary": "Adds a new job as \"running\"",
+                "summary": "Exports a job list",
                 "parameters": [
                     {
-                        "description": "Job to add",
+                        "description": "Filters, format and columns of the export",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/schema.JobMeta"
+                            "$ref": "#/definitions/api.ExportJobsApiRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Job added successfully",
+                    "200": {
+                        "description": "Job list",
                         "schema": {
-                            "$ref": "#/definitions/api.DefaultJobApiResponse"
+                            "type": "file"
                         }
                     },
                     "400": {
@@ -513,9 +761,377 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/api.ErrorResponse"
                         }
-                    },
-                    "422": {
-                        "description": "Unprocessable Entity: The combination of jobId, clusterId and startTime does already exist",
+                    }
+                }
+            }
+        },
+        "/jobs/import/": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Job meta and metric data are validated, written to the job archive and the job is added to the database.\nThe job is sent either as JSON object with the fields 'meta' and 'data' or as multipart form with the files 'meta' and 'data'.",
+                "consumes": [
+                    "application/json",
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Job add and modify"
+                ],
+                "summary": "Imports a finished job",
+                "parameters": [
+                    {
+                        "description": "Job meta and metric data",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.ImportJobApiRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Job imported successfully",
+                        "schema": {
+                            "$ref": "#/definitions/api.ImportJobApiResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity: The combination of jobId, clusterId and startTime does already exist",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/jobs/metrics/{id}/export": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Streams the metric data of a running or archived job in long format, one row per data point with the columns\nmetric, scope, hostname, id, timestamp (unix epoch in seconds) and value. Missing values are empty or null.",
+                "produces": [
+                    "text/csv",
+                    "application/vnd.apache.parquet",
+                    "application/vnd.apache.arrow.stream"
+                ],
+                "tags": [
+                    "Job query"
+                ],
+                "summary": "Exports the metric data of a job",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Database ID of Job",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Metric to export, can be repeated (Default: all metrics)",
+                        "name": "metric",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "node",
+                            "socket",
+                            "memoryDomain",
+                            "core",
+                            "hwthread",
+                            "accelerator"
+                        ],
+                        "type": "string",
+                        "description": "Scope to export, can be repeated (Default: node)",
+                        "name": "scope",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Timestep in seconds the series are resampled to, a multiple of the metric timestep (Default: no resampling)",
+                        "name": "resolution",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "csv",
+                            "parquet",
+                            "arrow"
+                        ],
+                        "type": "string",
+                        "description": "Format of the export (Default: csv)",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Metric data",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity: finding job failed: sql: no rows in result set",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/jobs/start_job/": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Job specified in request body will be saved to database as \"running\" with new DB ID.\nJob specifications follow the 'JobMeta' scheme, API will fail to execute if requirements are not met.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Job add and modify"
+                ],
+                "summary": "Adds a new job as \"running\"",
+                "parameters": [
+                    {
+                        "description": "Job to add",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/schema.JobMeta"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Job added successfully",
+                        "schema": {
+                            "$ref": "#/definitions/api.DefaultJobApiResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity: The combination of jobId, clusterId and startTime does already exist",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/jobs/start_jobs/": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Jobs specified in request body will be saved to database as \"running\" with new DB IDs in a single transaction.\nJob specifications follow the 'JobMeta' scheme. The result of each job is returned in the order of the request:\n'created', 'duplicate' if the combination of jobId, clusterId and startTime does already exist, or 'error'.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Job add and modify"
+                ],
+                "summary": "Adds new jobs as \"running\"",
+                "parameters": [
+                    {
+                        "description": "Jobs to add",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/schema.JobMeta"
+                            }
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Result of each job",
+                        "schema": {
+                            "$ref": "#/definitions/api.BatchJobApiResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/jobs/stop_job/": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Job to stop is specified by request body. All fields are required in this case.\nReturns full job resource information according to 'JobMeta' scheme.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Job add and modify"
+                ],
+                "summary": "Marks job as completed and triggers archiving",
+                "parameters": [
+                    {
+                        "description": "All fields required",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.StopJobApiRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Success message",
+                        "schema": {
+                            "$ref": "#/definitions/schema.JobMeta"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Resource not found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity: job has already been stopped",
                         "schema": {
                             "$ref": "#/definitions/api.ErrorResponse"
                         }
@@ -529,21 +1145,24 @@ const docTemplate = `{
                 }
             }
         },
-        "/jobs/stop_job/": {
+        "/jobs/stop_jobs/": {
             "post": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Job to stop is specified by request body. All fields are required in this case.\nReturns full job resource information according to 'JobMeta' scheme.",
+                "description": "Jobs to stop are specified by request body, all jobs are updated in a single transaction.\nThe result of each job is returned in the order of the request: 'stopped' or 'error'.",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "Job add and modify"
                 ],
-                "summary": "Marks job as completed and triggers archiving",
+                "summary": "Marks jobs as completed and triggers archiving",
                 "parameters": [
                     {
                         "description": "All fields required",
@@ -551,15 +1170,18 @@ const docTemplate = `{
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/api.StopJobApiRequest"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/api.StopJobApiRequest"
+                            }
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Success message",
+                        "description": "Result of each job",
                         "schema": {
-                            "$ref": "#/definitions/schema.JobMeta"
+                            "$ref": "#/definitions/api.BatchJobApiResponse"
                         }
                     },
                     "400": {
@@ -580,18 +1202,6 @@ const docTemplate = `{
                             "$ref": "#/definitions/api.ErrorResponse"
                         }
                     },
-                    "404": {
-                        "description": "Resource not found",
-                        "schema": {
-                            "$ref": "#/definitions/api.ErrorResponse"
-                        }
-                    },
-                    "422": {
-                        "description": "Unprocessable Entity: job has already been stopped",
-                        "schema": {
-                            "$ref": "#/definitions/api.ErrorResponse"
-                        }
-                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -833,6 +1443,63 @@ const docTemplate = `{
                 }
             }
         },
+        "/metricdata/health/": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get the circuit breaker state, last success, error rate and latency of the metric data repositories.\nRepositories of a specific cluster can be requested using query parameter.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Cluster query"
+                ],
+                "summary": "Health of the metric data repositories",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Job Cluster",
+                        "name": "cluster",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Array of repository health states",
+                        "schema": {
+                            "$ref": "#/definitions/api.GetMetricDataHealthApiResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
         "/notice/": {
             "post": {
                 "security": [
@@ -1279,6 +1946,58 @@ const docTemplate = `{
                 }
             }
         },
+        "api.BatchJobApiResponse": {
+            "type": "object",
+            "properties": {
+                "results": {
+                    "description": "Result of each job in the order of the request",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.BatchJobApiResult"
+                    }
+                }
+            }
+        },
+        "api.BatchJobApiResult": {
+            "type": "object",
+            "properties": {
+                "cluster": {
+                    "description": "Cluster of job",
+                    "type": "string",
+                    "example": "fritz"
+                },
+                "error": {
+                    "description": "Error Message",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "Database ID of the created, duplicate or stopped job",
+                    "type": "integer",
+                    "example": 1234
+                },
+                "jobId": {
+                    "description": "Cluster Job ID of job",
+                    "type": "integer",
+                    "example": 123000
+                },
+                "startTime": {
+                    "description": "Start Time of job as epoch",
+                    "type": "integer",
+                    "example": 1649723812
+                },
+                "status": {
+                    "description": "Result of the request",
+                    "type": "string",
+                    "enum": [
+                        "created",
+                        "duplicate",
+                        "stopped",
+                        "error"
+                    ],
+                    "example": "created"
+                }
+            }
+        },
         "api.DefaultJobApiResponse": {
             "type": "object",
             "properties": {
@@ -1336,6 +2055,45 @@ const docTemplate = `{
                 }
             }
         },
+        "api.ExportJobsApiRequest": {
+            "type": "object",
+            "properties": {
+                "columns": {
+                    "description": "Exported columns, keys of footprint, energyFootprint and metaData are selected as 'footprint.\u003ckey\u003e' etc. (Default: all fixed job columns)",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "jobId",
+                        "user",
+                        "duration",
+                        "footprint.flops_any_avg"
+                    ]
+                },
+                "filter": {
+                    "description": "Job filters as used by the 'jobs' GraphQL query",
+                    "type": "array",
+                    "items": {
+                        "type": "object"
+                    }
+                },
+                "format": {
+                    "description": "Format of the export (Default: csv)",
+                    "type": "string",
+                    "enum": [
+                        "csv",
+                        "parquet",
+                        "arrow"
+                    ],
+                    "example": "csv"
+                },
+                "order": {
+                    "description": "Sort order as used by the 'jobs' GraphQL query (Default: descending startTime)",
+                    "type": "object"
+                }
+            }
+        },
         "api.GetClustersApiResponse": {
             "type": "object",
             "properties": {
@@ -1382,6 +2140,44 @@ const docTemplate = `{
                 }
             }
         },
+        "api.GetMetricDataHealthApiResponse": {
+            "type": "object",
+            "properties": {
+                "repositories": {
+                    "description": "Health of the metric data repositories",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/metricdata.RepositoryHealth"
+                    }
+                }
+            }
+        },
+        "api.ImportJobApiRequest": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "description": "Job metric data following the 'JobData' scheme",
+                    "type": "object"
+                },
+                "meta": {
+                    "description": "Job meta data following the 'JobMeta' scheme",
+                    "type": "object"
+                }
+            }
+        },
+        "api.ImportJobApiResponse": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "description": "Database ID of the imported job",
+                    "type": "integer",
+                    "example": 1234
+                },
+                "msg": {
+                    "type": "string"
+                }
+            }
+        },
         "api.JobMetricWithName": {
             "type": "object",
             "properties": {
@@ -1429,6 +2225,38 @@ const docTemplate = `{
                 }
             }
         },
+        "metricdata.RepositoryHealth": {
+            "type": "object",
+            "properties": {
+                "cluster": {
+                    "type": "string"
+                },
+                "errorRate": {
+                    "type": "number"
+                },
+                "healthy": {
+                    "type": "boolean"
+                },
+                "kind": {
+                    "type": "string"
+                },
+                "lastError": {
+                    "type": "string"
+                },
+                "lastErrorTime": {
+                    "type": "string"
+                },
+                "lastSuccess": {
+                    "type": "string"
+                },
+                "latency": {
+                    "type": "number"
+                },
+                "state": {
+                    "type": "string"
+                }
+            }
+        },
         "schema.Accelerator": {
             "type": "object",
             "properties": {
@@ -1443,6 +2271,48 @@ const docTemplate = `{
                 }
             }
         },
+        "schema.BudgetStatus": {
+            "description": "Consumption of a project budget, including running jobs.",
+            "type": "object",
+            "properties": {
+                "budget": {
+                    "$ref": "#/definitions/schema.ProjectBudget"
+                },
+                "burnRate": {
+                    "description": "Average consumption per day since the start of the period",
+                    "type": "number",
+                    "example": 2500
+                },
+                "projected": {
+                    "description": "Consumption at the end of the period at the current burn rate",
+                    "type": "number",
+                    "example": 912500
+                },
+                "remaining": {
+                    "description": "Negative if the budget is overdrawn",
+                    "type": "number",
+                    "example": 750000
+                },
+                "used": {
+                    "description": "Consumed so far, in the unit of the budget",
+                    "type": "number",
+                    "example": 250000
+                }
+            }
+        },
+        "schema.BudgetUnit": {
+            "type": "string",
+            "enum": [
+                "coreHours",
+                "accHours",
+                "energy"
+            ],
+            "x-enum-varnames": [
+                "BudgetUnitCoreHours",
+                "BudgetUnitAccHours",
+                "BudgetUnitEnergy"
+            ]
+        },
         "schema.Cluster": {
             "type": "object",
             "properties": {
@@ -1596,6 +2466,37 @@ const docTemplate = `{
                 }
             }
         },
+        "schema.JobAlert": {
+            "description": "An alert which fired for a running job.",
+            "type": "object",
+            "properties": {
+                "createdAt": {
+                    "description": "Time the alert fired (unix epoch seconds)",
+                    "type": "integer",
+                    "example": 1649725612
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "jobId": {
+                    "description": "The unique DB identifier of the job",
+                    "type": "integer",
+                    "example": 123000
+                },
+                "message": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "idle-gpus"
+                },
+                "since": {
+                    "description": "Start of the period the conditions matched (unix epoch seconds)",
+                    "type": "integer",
+                    "example": 1649723812
+                }
+            }
+        },
         "schema.JobLink": {
             "type": "object",
             "properties": {
@@ -1844,6 +2745,9 @@ const docTemplate = `{
                 "caution": {
                     "type": "number"
                 },
+                "derived": {
+                    "type": "string"
+                },
                 "energy": {
                     "type": "string"
                 },
@@ -1925,6 +2829,52 @@ const docTemplate = `{
                 }
             }
         },
+        "schema.ProjectBudget": {
+            "description": "Allocation of a project for a period, on one or all clusters.",
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "description": "Allocated core-hours, accelerator-hours or kWh",
+                    "type": "number",
+                    "example": 1000000
+                },
+                "cluster": {
+                    "description": "Empty for all clusters",
+                    "type": "string",
+                    "example": "fritz"
+                },
+                "endTime": {
+                    "description": "End of the period (unix epoch seconds)",
+                    "type": "integer",
+                    "example": 1735689600
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "project": {
+                    "type": "string",
+                    "example": "abcd100"
+                },
+                "startTime": {
+                    "description": "Start of the period (unix epoch seconds)",
+                    "type": "integer",
+                    "example": 1704067200
+                },
+                "unit": {
+                    "enum": [
+                        "coreHours",
+                        "accHours",
+                        "energy"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/schema.BudgetUnit"
+                        }
+                    ],
+                    "example": "coreHours"
+                }
+            }
+        },
         "schema.Resource": {
             "description": "A resource used by a job",
             "type": "object",
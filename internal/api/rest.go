@@ -6,7 +6,7 @@ package api
 
 import (
 	"bufio"
-	"database/sql"
+	"bytes"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -14,6 +14,7 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
 	"sync"
@@ -22,12 +23,16 @@ import (
 	"github.com/ClusterCockpit/cc-backend/internal/archiver"
 	"github.com/ClusterCockpit/cc-backend/internal/auth"
 	"github.com/ClusterCockpit/cc-backend/internal/config"
+	"github.com/ClusterCockpit/cc-backend/internal/eventBus"
+	"github.com/ClusterCockpit/cc-backend/internal/export"
 	"github.com/ClusterCockpit/cc-backend/internal/graph"
 	"github.com/ClusterCockpit/cc-backend/internal/graph/model"
 	"github.com/ClusterCockpit/cc-backend/internal/importer"
 	"github.com/ClusterCockpit/cc-backend/internal/metricDataDispatcher"
+	"github.com/ClusterCockpit/cc-backend/internal/metricdata"
 	"github.com/ClusterCockpit/cc-backend/internal/repository"
 	"github.com/ClusterCockpit/cc-backend/internal/util"
+	"github.com/ClusterCockpit/cc-backend/internal/webhook"
 	"github.com/ClusterCockpit/cc-backend/pkg/archive"
 	"github.com/ClusterCockpit/cc-backend/pkg/log"
 	"github.com/ClusterCockpit/cc-backend/pkg/schema"
@@ -56,7 +61,6 @@ type RestApi struct {
 	JobRepository   *repository.JobRepository
 	Authentication  *auth.Authentication
 	MachineStateDir string
-	RepositoryMutex sync.Mutex
 }
 
 func New() *RestApi {
@@ -72,19 +76,26 @@ func (api *RestApi) MountApiRoutes(r *mux.Router) {
 
 	r.HandleFunc("/jobs/start_job/", api.startJob).Methods(http.MethodPost, http.MethodPut)
 	r.HandleFunc("/jobs/stop_job/", api.stopJobByRequest).Methods(http.MethodPost, http.MethodPut)
-	// r.HandleFunc("/jobs/import/", api.importJob).Methods(http.MethodPost, http.MethodPut)
+	r.HandleFunc("/jobs/start_jobs/", api.startJobs).Methods(http.MethodPost, http.MethodPut)
+	r.HandleFunc("/jobs/stop_jobs/", api.stopJobs).Methods(http.MethodPost, http.MethodPut)
+	r.HandleFunc("/jobs/import/", api.importJob).Methods(http.MethodPost, http.MethodPut)
 
 	r.HandleFunc("/jobs/", api.getJobs).Methods(http.MethodGet)
+	r.HandleFunc("/jobs/export/", api.exportJobs).Methods(http.MethodPost)
 	r.HandleFunc("/jobs/{id}", api.getJobById).Methods(http.MethodPost)
 	r.HandleFunc("/jobs/{id}", api.getCompleteJobById).Methods(http.MethodGet)
 	r.HandleFunc("/jobs/tag_job/{id}", api.tagJob).Methods(http.MethodPost, http.MethodPatch)
 	r.HandleFunc("/jobs/edit_meta/{id}", api.editMeta).Methods(http.MethodPost, http.MethodPatch)
 	r.HandleFunc("/jobs/metrics/{id}", api.getJobMetrics).Methods(http.MethodGet)
+	r.HandleFunc("/jobs/metrics/{id}/export", api.exportJobMetrics).Methods(http.MethodGet)
+	r.HandleFunc("/jobs/alerts/{id}", api.getJobAlerts).Methods(http.MethodGet)
 	r.HandleFunc("/jobs/delete_job/", api.deleteJobByRequest).Methods(http.MethodDelete)
 	r.HandleFunc("/jobs/delete_job/{id}", api.deleteJobById).Methods(http.MethodDelete)
 	r.HandleFunc("/jobs/delete_job_before/{ts}", api.deleteJobBefore).Methods(http.MethodDelete)
 
 	r.HandleFunc("/clusters/", api.getClusters).Methods(http.MethodGet)
+	r.HandleFunc("/budgets/", api.getProjectBudgets).Methods(http.MethodGet)
+	r.HandleFunc("/metricdata/health/", api.getMetricDataHealth).Methods(http.MethodGet)
 
 	if api.MachineStateDir != "" {
 		r.HandleFunc("/machine_state/{cluster}/{host}", api.getMachineState).Methods(http.MethodGet)
@@ -96,9 +107,13 @@ func (api *RestApi) MountUserApiRoutes(r *mux.Router) {
 	r.StrictSlash(true)
 
 	r.HandleFunc("/jobs/", api.getJobs).Methods(http.MethodGet)
+	r.HandleFunc("/jobs/export/", api.exportJobs).Methods(http.MethodPost)
 	r.HandleFunc("/jobs/{id}", api.getJobById).Methods(http.MethodPost)
 	r.HandleFunc("/jobs/{id}", api.getCompleteJobById).Methods(http.MethodGet)
 	r.HandleFunc("/jobs/metrics/{id}", api.getJobMetrics).Methods(http.MethodGet)
+	r.HandleFunc("/jobs/metrics/{id}/export", api.exportJobMetrics).Methods(http.MethodGet)
+	r.HandleFunc("/jobs/alerts/{id}", api.getJobAlerts).Methods(http.MethodGet)
+	r.HandleFunc("/budgets/", api.getProjectBudgets).Methods(http.MethodGet)
 }
 
 func (api *RestApi) MountConfigApiRoutes(r *mux.Router) {
@@ -111,6 +126,8 @@ func (api *RestApi) MountConfigApiRoutes(r *mux.Router) {
 		r.HandleFunc("/users/", api.deleteUser).Methods(http.MethodDelete)
 		r.HandleFunc("/user/{id}", api.updateUser).Methods(http.MethodPost)
 		r.HandleFunc("/notice/", api.editNotice).Methods(http.MethodPost)
+		r.HandleFunc("/budgets/", api.createProjectBudget).Methods(http.MethodPost)
+		r.HandleFunc("/budgets/{id}", api.deleteProjectBudget).Methods(http.MethodDelete)
 	}
 }
 
@@ -137,6 +154,41 @@ type StopJobApiRequest struct {
 	StopTime  int64           `json:"stopTime" validate:"required" example:"1649763839"`
 }
 
+// BatchJobApiResult model
+type BatchJobApiResult struct {
+	JobId     int64  `json:"jobId" example:"123000"`                                           // Cluster Job ID of job
+	Cluster   string `json:"cluster" example:"fritz"`                                          // Cluster of job
+	StartTime int64  `json:"startTime" example:"1649723812"`                                   // Start Time of job as epoch
+	Status    string `json:"status" enums:"created,duplicate,stopped,error" example:"created"` // Result of the request
+	Id        int64  `json:"id,omitempty" example:"1234"`                                      // Database ID of the created, duplicate or stopped job
+	Error     string `json:"error,omitempty"`                                                  // Error Message
+}
+
+// BatchJobApiResponse model
+type BatchJobApiResponse struct {
+	Results []BatchJobApiResult `json:"results"` // Result of each job in the order of the request
+}
+
+// ImportJobApiRequest model
+type ImportJobApiRequest struct {
+	Meta json.RawMessage `json:"meta" swaggertype:"object"` // Job meta data following the 'JobMeta' scheme
+	Data json.RawMessage `json:"data" swaggertype:"object"` // Job metric data following the 'JobData' scheme
+}
+
+// ExportJobsApiRequest model
+type ExportJobsApiRequest struct {
+	Filter  []*model.JobFilter  `json:"filter" swaggertype:"array,object"`                                   // Job filters as used by the 'jobs' GraphQL query
+	Order   *model.OrderByInput `json:"order" swaggertype:"object"`                                          // Sort order as used by the 'jobs' GraphQL query (Default: descending startTime)
+	Format  export.Format       `json:"format" swaggertype:"string" enums:"csv,parquet,arrow" example:"csv"` // Format of the export (Default: csv)
+	Columns []string            `json:"columns" example:"jobId,user,duration,footprint.flops_any_avg"`       // Exported columns, keys of footprint, energyFootprint and metaData are selected as 'footprint.<key>' etc. (Default: all fixed job columns)
+}
+
+// ImportJobApiResponse model
+type ImportJobApiResponse struct {
+	Id      int64  `json:"id" example:"1234"` // Database ID of the imported job
+	Message string `json:"msg"`
+}
+
 // DeleteJobApiRequest model
 type DeleteJobApiRequest struct {
 	JobId     *int64  `json:"jobId" validate:"required" example:"123000"` // Cluster Job ID of job
@@ -156,6 +208,11 @@ type GetClustersApiResponse struct {
 	Clusters []*schema.Cluster `json:"clusters"` // Array of clusters
 }
 
+// GetMetricDataHealthApiResponse model
+type GetMetricDataHealthApiResponse struct {
+	Repositories []metricdata.RepositoryHealth `json:"repositories"` // Health of the metric data repositories
+}
+
 // ErrorResponse model
 type ErrorResponse struct {
 	// Statustext of Errorcode
@@ -308,6 +365,44 @@ func (api *RestApi) getClusters(rw http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// getMetricDataHealth godoc
+// @summary     Health of the metric data repositories
+// @tags Cluster query
+// @description Get the circuit breaker state, last success, error rate and latency of the metric data repositories.
+// @description Repositories of a specific cluster can be requested using query parameter.
+// @produce     json
+// @param       cluster        query    string            false "Job Cluster"
+// @success     200            {object} api.GetMetricDataHealthApiResponse  "Array of repository health states"
+// @failure     400            {object} api.ErrorResponse       "Bad Request"
+// @failure     401            {object} api.ErrorResponse       "Unauthorized"
+// @failure     403            {object} api.ErrorResponse       "Forbidden"
+// @failure     500            {object} api.ErrorResponse       "Internal Server Error"
+// @security    ApiKeyAuth
+// @router      /metricdata/health/ [get]
+func (api *RestApi) getMetricDataHealth(rw http.ResponseWriter, r *http.Request) {
+	if user := repository.GetUserFromContext(r.Context()); user != nil &&
+		!user.HasRole(schema.RoleApi) {
+
+		handleError(fmt.Errorf("missing role: %v", schema.GetRoleString(schema.RoleApi)), http.StatusForbidden, rw)
+		return
+	}
+
+	cluster := r.URL.Query().Get("cluster")
+	if cluster != "" && archive.GetCluster(cluster) == nil {
+		handleError(fmt.Errorf("unknown cluster: %s", cluster), http.StatusBadRequest, rw)
+		return
+	}
+
+	rw.Header().Add("Content-Type", "application/json")
+	payload := GetMetricDataHealthApiResponse{
+		Repositories: metricdata.GetHealth(cluster),
+	}
+	if err := json.NewEncoder(rw).Encode(payload); err != nil {
+		handleError(err, http.StatusInternalServerError, rw)
+		return
+	}
+}
+
 // getJobs godoc
 // @summary     Lists all jobs
 // @tags Job query
@@ -536,6 +631,64 @@ func (api *RestApi) getCompleteJobById(rw http.ResponseWriter, r *http.Request)
 	}
 }
 
+// exportJobs godoc
+// @summary     Exports a job list
+// @tags Job query
+// @description Streams all jobs matching the filters as CSV, Parquet or Arrow IPC stream. Only jobs visible to the user are exported.
+// @description The fixed columns are id, jobId, user, project, cluster, subCluster, partition, arrayJobId, numNodes, numHwthreads, numAcc,
+// @description exclusive, smt, monitoringStatus, jobState, startTime, duration, walltime, energy and nodes (not exported by default).
+// @accept      json
+// @produce     text/csv,application/vnd.apache.parquet,application/vnd.apache.arrow.stream
+// @param       request body     api.ExportJobsApiRequest true "Filters, format and columns of the export"
+// @success     200     {file}   file                     "Job list"
+// @failure     400     {object} api.ErrorResponse        "Bad Request"
+// @failure     401     {object} api.ErrorResponse        "Unauthorized"
+// @failure     403     {object} api.ErrorResponse        "Forbidden"
+// @security    ApiKeyAuth
+// @router      /jobs/export/ [post]
+func (api *RestApi) exportJobs(rw http.ResponseWriter, r *http.Request) {
+	req := ExportJobsApiRequest{
+		Format: export.FormatCSV,
+		Order:  &model.OrderByInput{Field: "startTime", Type: "col", Order: model.SortDirectionEnumDesc},
+	}
+	if err := decode(r.Body, &req); err != nil {
+		handleError(fmt.Errorf("parsing request body failed: %w", err), http.StatusBadRequest, rw)
+		return
+	}
+	if req.Order != nil {
+		if err := repository.ValidateOrder(req.Order); err != nil {
+			handleError(err, http.StatusBadRequest, rw)
+			return
+		}
+	}
+
+	rw.Header().Set("Content-Type", req.Format.ContentType())
+	rw.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"jobs.%s\"", req.Format))
+	jw, err := export.NewJobWriter(rw, req.Format, req.Columns)
+	if err != nil {
+		rw.Header().Del("Content-Type")
+		rw.Header().Del("Content-Disposition")
+		handleError(err, http.StatusBadRequest, rw)
+		return
+	}
+
+	// Errors cannot be reported to the client anymore, the response is
+	// already on its way
+	count := 0
+	err = api.JobRepository.IterateJobs(r.Context(), req.Filter, req.Order, func(job *schema.Job) error {
+		count++
+		return jw.Write(job)
+	})
+	if err == nil {
+		err = jw.Close()
+	}
+	if err != nil {
+		log.Warnf("REST > exporting jobs failed after %d jobs: %s", count, err.Error())
+		return
+	}
+	log.Debugf("/api/jobs/export: %d jobs exported", count)
+}
+
 // getJobById godoc
 // @summary   Get job meta and configurable metric data
 // @tags Job query
@@ -781,21 +934,16 @@ func (api *RestApi) startJob(rw http.ResponseWriter, r *http.Request) {
 
 	// aquire lock to avoid race condition between API calls
 	var unlockOnce sync.Once
-	api.RepositoryMutex.Lock()
-	defer unlockOnce.Do(api.RepositoryMutex.Unlock)
+	api.JobRepository.Mutex.Lock()
+	defer unlockOnce.Do(api.JobRepository.Mutex.Unlock)
 
 	// Check if combination of (job_id, cluster_id, start_time) already exists:
-	jobs, err := api.JobRepository.FindAll(&req.JobID, &req.Cluster, nil)
-	if err != nil && err != sql.ErrNoRows {
+	if job, err := api.JobRepository.FindDuplicate(req.JobID, req.Cluster, req.StartTime); err != nil {
 		handleError(fmt.Errorf("checking for duplicate failed: %w", err), http.StatusInternalServerError, rw)
 		return
-	} else if err == nil {
-		for _, job := range jobs {
-			if (req.StartTime - job.StartTimeUnix) < 86400 {
-				handleError(fmt.Errorf("a job with that jobId, cluster and startTime already exists: dbid: %d, jobid: %d", job.ID, job.JobID), http.StatusUnprocessableEntity, rw)
-				return
-			}
-		}
+	} else if job != nil {
+		handleError(fmt.Errorf("a job with that jobId, cluster and startTime already exists: dbid: %d, jobid: %d", job.ID, job.JobID), http.StatusUnprocessableEntity, rw)
+		return
 	}
 
 	id, err := api.JobRepository.Start(&req)
@@ -804,7 +952,9 @@ func (api *RestApi) startJob(rw http.ResponseWriter, r *http.Request) {
 		return
 	}
 	// unlock here, adding Tags can be async
-	unlockOnce.Do(api.RepositoryMutex.Unlock)
+	unlockOnce.Do(api.JobRepository.Mutex.Unlock)
+	webhook.EmitJobMeta(webhook.JobStarted, id, &req)
+	eventBus.PublishJobStarted(id, &req)
 
 	for _, tag := range req.Tags {
 		if _, err := api.JobRepository.AddTagOrCreate(repository.GetUserFromContext(r.Context()), id, tag.Type, tag.Name, tag.Scope); err != nil {
@@ -822,6 +972,213 @@ func (api *RestApi) startJob(rw http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// startJobs godoc
+// @summary     Adds new jobs as "running"
+// @tags Job add and modify
+// @description Jobs specified in request body will be saved to database as "running" with new DB IDs in a single transaction.
+// @description Job specifications follow the 'JobMeta' scheme. The result of each job is returned in the order of the request:
+// @description 'created', 'duplicate' if the combination of jobId, clusterId and startTime does already exist, or 'error'.
+// @accept      json
+// @produce     json
+// @param       request body     []schema.JobMeta         true "Jobs to add"
+// @success     200     {object} api.BatchJobApiResponse  "Result of each job"
+// @failure     400     {object} api.ErrorResponse        "Bad Request"
+// @failure     401     {object} api.ErrorResponse        "Unauthorized"
+// @failure     403     {object} api.ErrorResponse        "Forbidden"
+// @failure     500     {object} api.ErrorResponse        "Internal Server Error"
+// @security    ApiKeyAuth
+// @router      /jobs/start_jobs/ [post]
+func (api *RestApi) startJobs(rw http.ResponseWriter, r *http.Request) {
+	var reqs []json.RawMessage
+	if err := decode(r.Body, &reqs); err != nil {
+		handleError(fmt.Errorf("parsing request body failed: %w", err), http.StatusBadRequest, rw)
+		return
+	}
+	if len(reqs) == 0 {
+		handleError(errors.New("no jobs in request"), http.StatusBadRequest, rw)
+		return
+	}
+
+	results := make([]BatchJobApiResult, len(reqs))
+	jobs := make([]*schema.JobMeta, len(reqs))
+	for i, raw := range reqs {
+		req := schema.JobMeta{BaseJob: schema.JobDefaults}
+		if err := decode(bytes.NewReader(raw), &req); err != nil {
+			results[i] = BatchJobApiResult{Status: "error", Error: fmt.Sprintf("parsing job failed: %s", err.Error())}
+			continue
+		}
+		results[i] = BatchJobApiResult{JobId: req.JobID, Cluster: req.Cluster, StartTime: req.StartTime}
+
+		req.State = schema.JobStateRunning
+		if err := importer.SanityChecks(&req.BaseJob); err != nil {
+			results[i].Status, results[i].Error = "error", err.Error()
+			continue
+		}
+		jobs[i] = &req
+	}
+
+	// aquire lock to avoid race condition between API calls
+	var unlockOnce sync.Once
+	api.JobRepository.Mutex.Lock()
+	defer unlockOnce.Do(api.JobRepository.Mutex.Unlock)
+
+	for i, req := range jobs {
+		if req == nil {
+			continue
+		}
+
+		job, err := api.JobRepository.FindDuplicate(req.JobID, req.Cluster, req.StartTime)
+		if err != nil {
+			results[i].Status, results[i].Error = "error", fmt.Sprintf("checking for duplicate failed: %s", err.Error())
+			jobs[i] = nil
+			continue
+		}
+		if job != nil {
+			results[i].Status, results[i].Id = "duplicate", job.ID
+			jobs[i] = nil
+			continue
+		}
+
+		// Jobs of the same request are checked like jobs in the database
+		for j := 0; j < i; j++ {
+			if jobs[j] != nil && jobs[j].JobID == req.JobID && jobs[j].Cluster == req.Cluster &&
+				(req.StartTime-jobs[j].StartTime) < 86400 {
+				results[i].Status = "duplicate"
+				jobs[i] = nil
+				break
+			}
+		}
+	}
+
+	t, err := api.JobRepository.TransactionInit()
+	if err != nil {
+		handleError(fmt.Errorf("starting transaction failed: %w", err), http.StatusInternalServerError, rw)
+		return
+	}
+	for i, req := range jobs {
+		if req == nil {
+			continue
+		}
+		id, err := api.JobRepository.TransactionStart(t, req)
+		if err != nil {
+			results[i].Status, results[i].Error = "error", fmt.Sprintf("insert into database failed: %s", err.Error())
+			jobs[i] = nil
+			continue
+		}
+		results[i].Status, results[i].Id = "created", id
+	}
+	if err := api.JobRepository.TransactionEnd(t); err != nil {
+		handleError(fmt.Errorf("insert into database failed: %w", err), http.StatusInternalServerError, rw)
+		return
+	}
+	// unlock here, adding Tags can be async
+	unlockOnce.Do(api.JobRepository.Mutex.Unlock)
+
+	created := 0
+	for i, req := range jobs {
+		if req == nil {
+			continue
+		}
+		created++
+		webhook.EmitJobMeta(webhook.JobStarted, results[i].Id, req)
+		eventBus.PublishJobStarted(results[i].Id, req)
+		for _, tag := range req.Tags {
+			if _, err := api.JobRepository.AddTagOrCreate(repository.GetUserFromContext(r.Context()), results[i].Id, tag.Type, tag.Name, tag.Scope); err != nil {
+				log.Warnf("adding tag to new job %d failed: %s", results[i].Id, err.Error())
+				results[i].Error = fmt.Sprintf("adding tag failed: %s", err.Error())
+			}
+		}
+	}
+
+	log.Printf("%d of %d new jobs started", created, len(reqs))
+	rw.Header().Add("Content-Type", "application/json")
+	rw.WriteHeader(http.StatusOK)
+	json.NewEncoder(rw).Encode(BatchJobApiResponse{
+		Results: results,
+	})
+}
+
+// importJob godoc
+// @summary     Imports a finished job
+// @tags Job add and modify
+// @description Job meta and metric data are validated, written to the job archive and the job is added to the database.
+// @description The job is sent either as JSON object with the fields 'meta' and 'data' or as multipart form with the files 'meta' and 'data'.
+// @accept      json,mpfd
+// @produce     json
+// @param       request body     api.ImportJobApiRequest  true "Job meta and metric data"
+// @success     201     {object} api.ImportJobApiResponse "Job imported successfully"
+// @failure     400     {object} api.ErrorResponse        "Bad Request"
+// @failure     401     {object} api.ErrorResponse        "Unauthorized"
+// @failure     403     {object} api.ErrorResponse        "Forbidden"
+// @failure     422     {object} api.ErrorResponse        "Unprocessable Entity: The combination of jobId, clusterId and startTime does already exist"
+// @failure     500     {object} api.ErrorResponse        "Internal Server Error"
+// @security    ApiKeyAuth
+// @router      /jobs/import/ [post]
+func (api *RestApi) importJob(rw http.ResponseWriter, r *http.Request) {
+	var rawMeta, rawData []byte
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		if err := r.ParseMultipartForm(32 << 20); err != nil {
+			handleError(fmt.Errorf("parsing request body failed: %w", err), http.StatusBadRequest, rw)
+			return
+		}
+		readFile := func(name string) ([]byte, error) {
+			f, _, err := r.FormFile(name)
+			if err == http.ErrMissingFile {
+				return nil, nil
+			} else if err != nil {
+				return nil, err
+			}
+			defer f.Close()
+			return io.ReadAll(f)
+		}
+
+		var err error
+		if rawMeta, err = readFile("meta"); err != nil {
+			handleError(fmt.Errorf("reading job meta failed: %w", err), http.StatusBadRequest, rw)
+			return
+		}
+		if rawData, err = readFile("data"); err != nil {
+			handleError(fmt.Errorf("reading job data failed: %w", err), http.StatusBadRequest, rw)
+			return
+		}
+	} else {
+		req := ImportJobApiRequest{}
+		if err := decode(r.Body, &req); err != nil {
+			handleError(fmt.Errorf("parsing request body failed: %w", err), http.StatusBadRequest, rw)
+			return
+		}
+		rawMeta, rawData = req.Meta, req.Data
+	}
+
+	if len(rawMeta) == 0 || len(rawData) == 0 {
+		handleError(errors.New("the fields 'meta' and 'data' are required"), http.StatusBadRequest, rw)
+		return
+	}
+
+	// aquire lock to avoid race condition between API calls
+	api.JobRepository.Mutex.Lock()
+	job, id, err := importer.ImportJob(rawMeta, rawData)
+	api.JobRepository.Mutex.Unlock()
+	if errors.Is(err, importer.ErrJobExists) {
+		handleError(err, http.StatusUnprocessableEntity, rw)
+		return
+	} else if errors.Is(err, importer.ErrInvalidJob) {
+		handleError(err, http.StatusBadRequest, rw)
+		return
+	} else if err != nil {
+		handleError(fmt.Errorf("importing job failed: %w", err), http.StatusInternalServerError, rw)
+		return
+	}
+
+	log.Printf("imported job (dbid: %d): cluster=%s, jobId=%d, user=%s, startTime=%d", id, job.Cluster, job.JobID, job.User, job.StartTime)
+	rw.Header().Add("Content-Type", "application/json")
+	rw.WriteHeader(http.StatusCreated)
+	json.NewEncoder(rw).Encode(ImportJobApiResponse{
+		Id:      id,
+		Message: "success",
+	})
+}
+
 // stopJobByRequest godoc
 // @summary     Marks job as completed and triggers archiving
 // @tags Job add and modify
@@ -864,6 +1221,112 @@ func (api *RestApi) stopJobByRequest(rw http.ResponseWriter, r *http.Request) {
 	api.checkAndHandleStopJob(rw, job, req)
 }
 
+// stopJobs godoc
+// @summary     Marks jobs as completed and triggers archiving
+// @tags Job add and modify
+// @description Jobs to stop are specified by request body, all jobs are updated in a single transaction.
+// @description The result of each job is returned in the order of the request: 'stopped' or 'error'.
+// @accept      json
+// @produce     json
+// @param       request body     []api.StopJobApiRequest true "All fields required"
+// @success     200     {object} api.BatchJobApiResponse   "Result of each job"
+// @failure     400     {object} api.ErrorResponse         "Bad Request"
+// @failure     401     {object} api.ErrorResponse         "Unauthorized"
+// @failure     403     {object} api.ErrorResponse         "Forbidden"
+// @failure     500     {object} api.ErrorResponse         "Internal Server Error"
+// @security    ApiKeyAuth
+// @router      /jobs/stop_jobs/ [post]
+func (api *RestApi) stopJobs(rw http.ResponseWriter, r *http.Request) {
+	var reqs []StopJobApiRequest
+	if err := decode(r.Body, &reqs); err != nil {
+		handleError(fmt.Errorf("parsing request body failed: %w", err), http.StatusBadRequest, rw)
+		return
+	}
+	if len(reqs) == 0 {
+		handleError(errors.New("no jobs in request"), http.StatusBadRequest, rw)
+		return
+	}
+
+	results := make([]BatchJobApiResult, len(reqs))
+	jobs := make([]*schema.Job, len(reqs))
+	for i := range reqs {
+		req := &reqs[i]
+		if req.JobId == nil {
+			results[i] = BatchJobApiResult{Status: "error", Error: "the field 'jobId' is required"}
+			continue
+		}
+		results[i].JobId = *req.JobId
+		if req.Cluster != nil {
+			results[i].Cluster = *req.Cluster
+		}
+		if req.StartTime != nil {
+			results[i].StartTime = *req.StartTime
+		}
+
+		job, err := api.JobRepository.Find(req.JobId, req.Cluster, req.StartTime)
+		if err != nil {
+			results[i].Status, results[i].Error = "error", fmt.Sprintf("finding job failed: %s", err.Error())
+			continue
+		}
+		results[i].Cluster, results[i].StartTime, results[i].Id = job.Cluster, job.StartTime.Unix(), job.ID
+
+		if slices.ContainsFunc(jobs[:i], func(j *schema.Job) bool { return j != nil && j.ID == job.ID }) {
+			results[i].Status, results[i].Error = "error", fmt.Sprintf("jobId %d (id %d) on %s : job is stopped twice in this request", job.JobID, job.ID, job.Cluster)
+			continue
+		}
+
+		if _, err := checkStopJob(job, req); err != nil {
+			results[i].Status, results[i].Error = "error", err.Error()
+			continue
+		}
+		jobs[i] = job
+	}
+
+	t, err := api.JobRepository.TransactionInit()
+	if err != nil {
+		handleError(fmt.Errorf("starting transaction failed: %w", err), http.StatusInternalServerError, rw)
+		return
+	}
+	for i, job := range jobs {
+		if job == nil {
+			continue
+		}
+		if err := api.JobRepository.TransactionStop(t, job.ID, job.Duration, job.State, job.MonitoringStatus); err != nil {
+			results[i].Status, results[i].Error = "error", fmt.Sprintf("marking job as '%s' (duration: %d) in DB failed: %s", job.State, job.Duration, err.Error())
+			jobs[i] = nil
+			continue
+		}
+		results[i].Status = "stopped"
+	}
+	if err := api.JobRepository.TransactionEnd(t); err != nil {
+		handleError(fmt.Errorf("updating database failed: %w", err), http.StatusInternalServerError, rw)
+		return
+	}
+
+	rw.Header().Add("Content-Type", "application/json")
+	rw.WriteHeader(http.StatusOK)
+	json.NewEncoder(rw).Encode(BatchJobApiResponse{
+		Results: results,
+	})
+
+	stopped := 0
+	for _, job := range jobs {
+		if job == nil {
+			continue
+		}
+		stopped++
+		webhook.Emit(webhook.JobStopped, job, nil)
+		eventBus.JobStateChanged.Publish(job)
+		// Monitoring is disabled...
+		if job.MonitoringStatus == schema.MonitoringStatusDisabled {
+			continue
+		}
+		// Trigger async archiving
+		archiver.TriggerArchiving(job)
+	}
+	log.Printf("%d of %d jobs stopped", stopped, len(reqs))
+}
+
 // deleteJobById godoc
 // @summary     Remove a job from the sql database
 // @tags Job remove
@@ -1001,34 +1464,46 @@ func (api *RestApi) deleteJobBefore(rw http.ResponseWriter, r *http.Request) {
 	})
 }
 
-func (api *RestApi) checkAndHandleStopJob(rw http.ResponseWriter, job *schema.Job, req StopJobApiRequest) {
+// Checks whether job can be stopped as requested and sets its duration and
+// state. Returns the HTTP status code for errors.
+func checkStopJob(job *schema.Job, req *StopJobApiRequest) (int, error) {
 	// Sanity checks
 	if job.State != schema.JobStateRunning {
-		handleError(fmt.Errorf("jobId %d (id %d) on %s : job has already been stopped (state is: %s)", job.JobID, job.ID, job.Cluster, job.State), http.StatusUnprocessableEntity, rw)
-		return
+		return http.StatusUnprocessableEntity, fmt.Errorf("jobId %d (id %d) on %s : job has already been stopped (state is: %s)", job.JobID, job.ID, job.Cluster, job.State)
 	}
 
 	if job == nil || job.StartTime.Unix() > req.StopTime {
-		handleError(fmt.Errorf("jobId %d (id %d) on %s : stopTime %d must be larger/equal than startTime %d", job.JobID, job.ID, job.Cluster, req.StopTime, job.StartTime.Unix()), http.StatusBadRequest, rw)
-		return
+		return http.StatusBadRequest, fmt.Errorf("jobId %d (id %d) on %s : stopTime %d must be larger/equal than startTime %d", job.JobID, job.ID, job.Cluster, req.StopTime, job.StartTime.Unix())
 	}
 
 	if req.State != "" && !req.State.Valid() {
-		handleError(fmt.Errorf("jobId %d (id %d) on %s : invalid requested job state: %#v", job.JobID, job.ID, job.Cluster, req.State), http.StatusBadRequest, rw)
-		return
+		return http.StatusBadRequest, fmt.Errorf("jobId %d (id %d) on %s : invalid requested job state: %#v", job.JobID, job.ID, job.Cluster, req.State)
 	} else if req.State == "" {
 		req.State = schema.JobStateCompleted
 	}
 
-	// Mark job as stopped in the database (update state and duration)
+	// Mark job as stopped (update state and duration)
 	job.Duration = int32(req.StopTime - job.StartTime.Unix())
 	job.State = req.State
+
+	return http.StatusOK, nil
+}
+
+func (api *RestApi) checkAndHandleStopJob(rw http.ResponseWriter, job *schema.Job, req StopJobApiRequest) {
+	if status, err := checkStopJob(job, &req); err != nil {
+		handleError(err, status, rw)
+		return
+	}
+
+	// Mark job as stopped in the database (update state and duration)
 	if err := api.JobRepository.Stop(job.ID, job.Duration, job.State, job.MonitoringStatus); err != nil {
 		handleError(fmt.Errorf("jobId %d (id %d) on %s : marking job as '%s' (duration: %d) in DB failed: %w", job.JobID, job.ID, job.Cluster, job.State, job.Duration, err), http.StatusInternalServerError, rw)
 		return
 	}
 
 	log.Printf("archiving job... (dbid: %d): cluster=%s, jobId=%d, user=%s, startTime=%s, duration=%d, state=%s", job.ID, job.Cluster, job.JobID, job.User, job.StartTime, job.Duration, job.State)
+	webhook.Emit(webhook.JobStopped, job, nil)
+	eventBus.JobStateChanged.Publish(job)
 
 	// Send a response (with status OK). This means that erros that happen from here on forward
 	// can *NOT* be communicated to the client. If reading from a MetricDataRepository or
@@ -1089,6 +1564,251 @@ func (api *RestApi) getJobMetrics(rw http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// exportJobMetrics godoc
+// @summary     Exports the metric data of a job
+// @tags Job query
+// @description Streams the metric data of a running or archived job in long format, one row per data point with the columns
+// @description metric, scope, hostname, id, timestamp (unix epoch in seconds) and value. Missing values are empty or null.
+// @produce     text/csv,application/vnd.apache.parquet,application/vnd.apache.arrow.stream
+// @param       id         path     int    true  "Database ID of Job"
+// @param       metric     query    string false "Metric to export, can be repeated (Default: all metrics)"
+// @param       scope      query    string false "Scope to export, can be repeated (Default: node)" Enums(node, socket, memoryDomain, core, hwthread, accelerator)
+// @param       resolution query    int    false "Timestep in seconds the series are resampled to, a multiple of the metric timestep (Default: no resampling)"
+// @param       format     query    string false "Format of the export (Default: csv)" Enums(csv, parquet, arrow)
+// @success     200        {file}   file          "Metric data"
+// @failure     400        {object} api.ErrorResponse "Bad Request"
+// @failure     401        {object} api.ErrorResponse "Unauthorized"
+// @failure     403        {object} api.ErrorResponse "Forbidden"
+// @failure     422        {object} api.ErrorResponse "Unprocessable Entity: finding job failed: sql: no rows in result set"
+// @failure     500        {object} api.ErrorResponse "Internal Server Error"
+// @security    ApiKeyAuth
+// @router      /jobs/metrics/{id}/export [get]
+func (api *RestApi) exportJobMetrics(rw http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		handleError(fmt.Errorf("integer expected in path for id: %w", err), http.StatusBadRequest, rw)
+		return
+	}
+
+	query := r.URL.Query()
+	metrics := query["metric"]
+	scopes := []schema.MetricScope{}
+	for _, scope := range query["scope"] {
+		var s schema.MetricScope
+		if err := s.UnmarshalGQL(scope); err != nil {
+			handleError(err, http.StatusBadRequest, rw)
+			return
+		}
+		scopes = append(scopes, s)
+	}
+	if len(scopes) == 0 {
+		scopes = append(scopes, schema.MetricScopeNode)
+	}
+
+	resolution := 0
+	if res := query.Get("resolution"); res != "" {
+		if resolution, err = strconv.Atoi(res); err != nil || resolution < 0 {
+			handleError(fmt.Errorf("invalid query parameter value: resolution"), http.StatusBadRequest, rw)
+			return
+		}
+	}
+
+	format := export.FormatCSV
+	if f := query.Get("format"); f != "" {
+		format = export.Format(f)
+	}
+	if !format.Valid() {
+		handleError(fmt.Errorf("invalid query parameter value: format"), http.StatusBadRequest, rw)
+		return
+	}
+
+	job, err := api.JobRepository.FindById(r.Context(), id)
+	if err != nil {
+		handleError(fmt.Errorf("finding job with db id %d failed: %w", id, err), http.StatusUnprocessableEntity, rw)
+		return
+	}
+
+	data, err := metricDataDispatcher.LoadData(job, metrics, scopes, r.Context(), resolution)
+	if err != nil {
+		handleError(fmt.Errorf("loading metric data of job %d failed: %w", id, err), http.StatusInternalServerError, rw)
+		return
+	}
+
+	// Metric data repositories without support for resampling return the
+	// data with the original timestep
+	data, err = export.ResampleJobData(data, resolution)
+	if err != nil {
+		handleError(err, http.StatusBadRequest, rw)
+		return
+	}
+
+	rw.Header().Set("Content-Type", format.ContentType())
+	rw.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"job-%d.%s\"", job.ID, format))
+	if err := export.WriteJobData(rw, format, job, data); err != nil {
+		log.Warnf("REST > exporting metric data of job %d failed: %s", job.ID, err.Error())
+	}
+}
+
+// getJobAlerts godoc
+// @summary     Lists the alerts of a job
+// @tags Job query
+// @description Returns all alerts which fired for the job, oldest first.
+// @produce     json
+// @param       id  path     int    true  "Database ID of Job"
+// @success     200 {array}  schema.JobAlert        "Alerts of the job"
+// @failure     400 {object} api.ErrorResponse "Bad Request"
+// @failure     401 {object} api.ErrorResponse "Unauthorized"
+// @failure     403 {object} api.ErrorResponse "Forbidden"
+// @failure     422 {object} api.ErrorResponse "Unprocessable Entity: finding job failed: sql: no rows in result set"
+// @failure     500 {object} api.ErrorResponse "Internal Server Error"
+// @security    ApiKeyAuth
+// @router      /jobs/alerts/{id} [get]
+func (api *RestApi) getJobAlerts(rw http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		handleError(fmt.Errorf("integer expected in path for id: %w", err), http.StatusBadRequest, rw)
+		return
+	}
+
+	// Checks the permissions of the user for the job
+	if _, err := api.JobRepository.FindById(r.Context(), id); err != nil {
+		handleError(fmt.Errorf("finding job with db id %d failed: %w", id, err), http.StatusUnprocessableEntity, rw)
+		return
+	}
+
+	alerts, err := api.JobRepository.FindJobAlerts(id)
+	if err != nil {
+		handleError(err, http.StatusInternalServerError, rw)
+		return
+	}
+
+	rw.Header().Add("Content-Type", "application/json")
+	rw.WriteHeader(http.StatusOK)
+	json.NewEncoder(rw).Encode(alerts)
+}
+
+// getProjectBudgets godoc
+// @summary     Lists project budgets and their consumption
+// @tags Budget
+// @description Returns the budgets with the core-hours, accelerator-hours or energy consumed so far, including running jobs.
+// @description Admins, support staff and API users see all budgets, managers only the budgets of their projects.
+// @produce     json
+// @param       project query    string false "Only budgets of this project"
+// @param       cluster query    string false "Only budgets of this cluster"
+// @success     200     {array}  schema.BudgetStatus "Budgets and their consumption"
+// @failure     401     {object} api.ErrorResponse   "Unauthorized"
+// @failure     403     {object} api.ErrorResponse   "Forbidden"
+// @failure     500     {object} api.ErrorResponse   "Internal Server Error"
+// @security    ApiKeyAuth
+// @router      /budgets/ [get]
+func (api *RestApi) getProjectBudgets(rw http.ResponseWriter, r *http.Request) {
+	var project, cluster *string
+	if values, ok := r.URL.Query()["project"]; ok {
+		project = &values[0]
+	}
+	if values, ok := r.URL.Query()["cluster"]; ok {
+		cluster = &values[0]
+	}
+
+	budgets, err := api.JobRepository.ProjectBudgetStatus(repository.GetUserFromContext(r.Context()), project, cluster)
+	if errors.Is(err, repository.ErrForbidden) {
+		handleError(err, http.StatusForbidden, rw)
+		return
+	} else if err != nil {
+		handleError(err, http.StatusInternalServerError, rw)
+		return
+	}
+
+	rw.Header().Add("Content-Type", "application/json")
+	rw.WriteHeader(http.StatusOK)
+	json.NewEncoder(rw).Encode(budgets)
+}
+
+// createProjectBudget godoc
+// @summary     Adds a project budget
+// @tags Budget
+// @description Allocates core-hours, accelerator-hours or energy (kWh) to a project for a period.
+// @description An empty cluster applies the budget to jobs on all clusters. The id in the request body is ignored.
+// @description Only accessible from IPs registered with apiAllowedIPs configuration option.
+// @accept      json
+// @produce     json
+// @param       request body     schema.ProjectBudget true "Budget to add"
+// @success     201     {object} schema.ProjectBudget      "Budget added, with its id"
+// @failure     400     {object} api.ErrorResponse         "Bad Request"
+// @failure     401     {object} api.ErrorResponse         "Unauthorized"
+// @failure     403     {object} api.ErrorResponse         "Forbidden"
+// @failure     422     {object} api.ErrorResponse         "Unprocessable Entity: invalid budget"
+// @security    ApiKeyAuth
+// @router      /budgets/ [post]
+func (api *RestApi) createProjectBudget(rw http.ResponseWriter, r *http.Request) {
+	if err := securedCheck(r); err != nil {
+		handleError(err, http.StatusForbidden, rw)
+		return
+	}
+
+	if user := repository.GetUserFromContext(r.Context()); !user.HasRole(schema.RoleAdmin) {
+		handleError(errors.New("only admins are allowed to add budgets"), http.StatusForbidden, rw)
+		return
+	}
+
+	var budget schema.ProjectBudget
+	if err := decode(r.Body, &budget); err != nil {
+		handleError(fmt.Errorf("parsing request body failed: %w", err), http.StatusBadRequest, rw)
+		return
+	}
+
+	if err := api.JobRepository.AddProjectBudget(&budget); err != nil {
+		handleError(err, http.StatusUnprocessableEntity, rw)
+		return
+	}
+
+	rw.Header().Add("Content-Type", "application/json")
+	rw.WriteHeader(http.StatusCreated)
+	json.NewEncoder(rw).Encode(budget)
+}
+
+// deleteProjectBudget godoc
+// @summary     Removes a project budget
+// @tags Budget
+// @description Only accessible from IPs registered with apiAllowedIPs configuration option.
+// @produce     json
+// @param       id  path     int                     true "Database ID of the budget"
+// @success     200 {object} api.DefaultJobApiResponse     "Success message"
+// @failure     400 {object} api.ErrorResponse             "Bad Request"
+// @failure     401 {object} api.ErrorResponse             "Unauthorized"
+// @failure     403 {object} api.ErrorResponse             "Forbidden"
+// @failure     422 {object} api.ErrorResponse             "Unprocessable Entity: no budget with id"
+// @security    ApiKeyAuth
+// @router      /budgets/{id} [delete]
+func (api *RestApi) deleteProjectBudget(rw http.ResponseWriter, r *http.Request) {
+	if err := securedCheck(r); err != nil {
+		handleError(err, http.StatusForbidden, rw)
+		return
+	}
+
+	if user := repository.GetUserFromContext(r.Context()); !user.HasRole(schema.RoleAdmin) {
+		handleError(errors.New("only admins are allowed to delete budgets"), http.StatusForbidden, rw)
+		return
+	}
+
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		handleError(fmt.Errorf("integer expected in path for id: %w", err), http.StatusBadRequest, rw)
+		return
+	}
+
+	if err := api.JobRepository.DeleteProjectBudget(id); err != nil {
+		handleError(err, http.StatusUnprocessableEntity, rw)
+		return
+	}
+
+	rw.Header().Add("Content-Type", "application/json")
+	rw.WriteHeader(http.StatusOK)
+	json.NewEncoder(rw).Encode(DefaultJobApiResponse{
+		Message: fmt.Sprintf("Deleted budget %d", id),
+	})
+}
+
 // createUser godoc
 // @summary     Adds a new user
 // @tags User
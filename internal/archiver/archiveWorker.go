@@ -9,7 +9,10 @@ import (
 	"sync"
 	"time"
 
+	"github.com/ClusterCockpit/cc-backend/internal/notification"
 	"github.com/ClusterCockpit/cc-backend/internal/repository"
+	"github.com/ClusterCockpit/cc-backend/internal/tagger"
+	"github.com/ClusterCockpit/cc-backend/internal/webhook"
 	"github.com/ClusterCockpit/cc-backend/pkg/log"
 	"github.com/ClusterCockpit/cc-backend/pkg/schema"
 	sq "github.com/Masterminds/squirrel"
@@ -41,16 +44,16 @@ func archivingWorker() {
 			// will fail if job meta not in repository
 			if _, err := jobRepo.FetchMetadata(job); err != nil {
 				log.Errorf("archiving job (dbid: %d) failed at check metadata step: %s", job.ID, err.Error())
-				jobRepo.UpdateMonitoringStatus(job.ID, schema.MonitoringStatusArchivingFailed)
+				archivingFailed(job)
 				continue
 			}
 
 			// ArchiveJob will fetch all the data from a MetricDataRepository and push into configured archive backend
-			// TODO: Maybe use context with cancel/timeout here
+			// Timeouts are applied per request by the MetricDataRepository (see metricdata.ResilientMetricDataRepository)
 			jobMeta, err := ArchiveJob(job, context.Background())
 			if err != nil {
 				log.Errorf("archiving job (dbid: %d) failed at archiving job step: %s", job.ID, err.Error())
-				jobRepo.UpdateMonitoringStatus(job.ID, schema.MonitoringStatusArchivingFailed)
+				archivingFailed(job)
 				continue
 			}
 
@@ -72,11 +75,21 @@ func archivingWorker() {
 			}
 			log.Debugf("archiving job %d took %s", job.JobID, time.Since(start))
 			log.Printf("archiving job (dbid: %d) successful", job.ID)
+			job.MonitoringStatus = schema.MonitoringStatusArchivingSuccessful
+			webhook.Emit(webhook.JobArchived, job, nil)
+			tagger.TagJob(job, jobMeta.Statistics)
+			notification.JobArchived(job, jobMeta)
 			archivePending.Done()
 		}
 	}
 }
 
+func archivingFailed(job *schema.Job) {
+	jobRepo.UpdateMonitoringStatus(job.ID, schema.MonitoringStatusArchivingFailed)
+	job.MonitoringStatus = schema.MonitoringStatusArchivingFailed
+	webhook.Emit(webhook.JobArchivingFailed, job, nil)
+}
+
 // Trigger async archiving
 func TriggerArchiving(job *schema.Job) {
 	if archiveChannel == nil {
@@ -46,6 +46,7 @@ var Keys schema.ProgramConfig = schema.ProgramConfig{
 		"analysis_view_selectedTopCategory":      "totalWalltime",
 		"status_view_selectedTopUserCategory":    "totalJobs",
 		"status_view_selectedTopProjectCategory": "totalJobs",
+		"notification_jobSummary":                "off",
 	},
 }
 
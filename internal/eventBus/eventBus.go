@@ -0,0 +1,77 @@
+// Copyright (C) NHR@FAU, University Erlangen-Nuremberg.
+// All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+// Package eventBus passes job events from the REST API and the background
+// workers to the GraphQL subscriptions. Events are only kept in memory,
+// subscribers which do not keep up miss events.
+package eventBus
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/ClusterCockpit/cc-backend/pkg/schema"
+)
+
+var (
+	// Jobs which were started or stopped
+	JobStateChanged Bus[*schema.Job]
+	// Running jobs whose footprint was updated by the footprint worker
+	JobMetricsUpdated Bus[*schema.Job]
+)
+
+// PublishJobStarted publishes a job which was just added to the database
+// with the id to JobStateChanged.
+func PublishJobStarted(id int64, job *schema.JobMeta) {
+	JobStateChanged.Publish(&schema.Job{
+		ID:            id,
+		BaseJob:       job.BaseJob,
+		StartTime:     time.Unix(job.StartTime, 0),
+		StartTimeUnix: job.StartTime,
+	})
+}
+
+type Bus[T any] struct {
+	mu          sync.RWMutex
+	subscribers map[chan T]struct{}
+}
+
+// Publish passes v to all subscribers. It never blocks, the event is dropped
+// for subscribers whose buffer is full.
+func (b *Bus[T]) Publish(v T) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for ch := range b.subscribers {
+		select {
+		case ch <- v:
+		default:
+		}
+	}
+}
+
+// Subscribe returns a channel receiving all events published until ctx is
+// done. The channel is closed afterwards.
+func (b *Bus[T]) Subscribe(ctx context.Context, buffer int) <-chan T {
+	ch := make(chan T, buffer)
+
+	b.mu.Lock()
+	if b.subscribers == nil {
+		b.subscribers = make(map[chan T]struct{})
+	}
+	b.subscribers[ch] = struct{}{}
+	b.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		b.mu.Lock()
+		delete(b.subscribers, ch)
+		close(ch)
+		b.mu.Unlock()
+	}()
+
+	return ch
+}
@@ -0,0 +1,51 @@
+// Copyright (C) NHR@FAU, University Erlangen-Nuremberg.
+// All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+package eventBus
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestBus(t *testing.T) {
+	var b Bus[int]
+	b.Publish(0) // no subscribers
+
+	ctx1, cancel1 := context.WithCancel(context.Background())
+	ctx2, cancel2 := context.WithCancel(context.Background())
+	defer cancel2()
+	ch1, ch2 := b.Subscribe(ctx1, 1), b.Subscribe(ctx2, 2)
+
+	b.Publish(1)
+	b.Publish(2) // dropped for ch1
+
+	if v := <-ch1; v != 1 {
+		t.Errorf("ch1: got %d, want 1", v)
+	}
+	select {
+	case v := <-ch1:
+		t.Errorf("ch1: unexpected event %d", v)
+	default:
+	}
+	if v1, v2 := <-ch2, <-ch2; v1 != 1 || v2 != 2 {
+		t.Errorf("ch2: got %d, %d, want 1, 2", v1, v2)
+	}
+
+	cancel1()
+	select {
+	case _, ok := <-ch1:
+		if ok {
+			t.Error("ch1: expected channel to be closed")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("ch1: channel not closed after cancel")
+	}
+
+	b.Publish(3)
+	if v := <-ch2; v != 3 {
+		t.Errorf("ch2: got %d, want 3", v)
+	}
+}
@@ -0,0 +1,90 @@
+// Copyright (C) NHR@FAU, University Erlangen-Nuremberg.
+// All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+package export
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/apache/arrow/go/arrow"
+	"github.com/apache/arrow/go/arrow/array"
+	"github.com/apache/arrow/go/arrow/ipc"
+	"github.com/apache/arrow/go/arrow/memory"
+)
+
+// Rows are sent in record batches of this size
+const arrowBatchSize = 64 * 1024
+
+type arrowWriter struct {
+	w    *ipc.Writer
+	b    *array.RecordBuilder
+	rows int
+}
+
+// Writes an Arrow IPC stream, all fields are nullable
+func newArrowWriter(w io.Writer, fields []field) (*arrowWriter, error) {
+	afields := make([]arrow.Field, 0, len(fields))
+	for _, f := range fields {
+		af := arrow.Field{Name: f.name, Nullable: true}
+		switch f.typ {
+		case typeInt:
+			af.Type = arrow.PrimitiveTypes.Int64
+		case typeFloat:
+			af.Type = arrow.PrimitiveTypes.Float64
+		case typeString:
+			af.Type = arrow.BinaryTypes.String
+		}
+		afields = append(afields, af)
+	}
+
+	schema := arrow.NewSchema(afields, nil)
+	return &arrowWriter{
+		w: ipc.NewWriter(w, ipc.WithSchema(schema)),
+		b: array.NewRecordBuilder(memory.DefaultAllocator, schema),
+	}, nil
+}
+
+func (aw *arrowWriter) write(row []any) error {
+	for i, v := range row {
+		if v == nil {
+			aw.b.Field(i).AppendNull()
+			continue
+		}
+
+		switch b := aw.b.Field(i).(type) {
+		case *array.Int64Builder:
+			b.Append(v.(int64))
+		case *array.Float64Builder:
+			b.Append(v.(float64))
+		case *array.StringBuilder:
+			b.Append(v.(string))
+		default:
+			return fmt.Errorf("EXPORT > unexpected arrow builder %T", b)
+		}
+	}
+
+	aw.rows++
+	if aw.rows >= arrowBatchSize {
+		return aw.flush()
+	}
+	return nil
+}
+
+func (aw *arrowWriter) flush() error {
+	rec := aw.b.NewRecord()
+	defer rec.Release()
+	aw.rows = 0
+	return aw.w.Write(rec)
+}
+
+func (aw *arrowWriter) close() error {
+	defer aw.b.Release()
+	if aw.rows > 0 {
+		if err := aw.flush(); err != nil {
+			return err
+		}
+	}
+	return aw.w.Close()
+}
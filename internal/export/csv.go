@@ -0,0 +1,52 @@
+// Copyright (C) NHR@FAU, University Erlangen-Nuremberg.
+// All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+package export
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+type csvWriter struct {
+	w      *csv.Writer
+	record []string
+}
+
+// Writes the header with the column names, missing values are left empty
+func newCsvWriter(w io.Writer, fields []field) (*csvWriter, error) {
+	cw := &csvWriter{w: csv.NewWriter(w), record: make([]string, len(fields))}
+	for i, f := range fields {
+		cw.record[i] = f.name
+	}
+	if err := cw.w.Write(cw.record); err != nil {
+		return nil, err
+	}
+	return cw, nil
+}
+
+func (cw *csvWriter) write(row []any) error {
+	for i, v := range row {
+		switch v := v.(type) {
+		case nil:
+			cw.record[i] = ""
+		case int64:
+			cw.record[i] = strconv.FormatInt(v, 10)
+		case float64:
+			cw.record[i] = strconv.FormatFloat(v, 'f', -1, 64)
+		case string:
+			cw.record[i] = v
+		default:
+			return fmt.Errorf("EXPORT > unexpected value type %T", v)
+		}
+	}
+	return cw.w.Write(cw.record)
+}
+
+func (cw *csvWriter) close() error {
+	cw.w.Flush()
+	return cw.w.Error()
+}
@@ -0,0 +1,75 @@
+// Copyright (C) NHR@FAU, University Erlangen-Nuremberg.
+// All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+// Package export writes job lists and job metric data as CSV, Parquet or
+// Arrow IPC stream. Rows are written one by one, so exports of any size can be
+// streamed to the client.
+package export
+
+import (
+	"fmt"
+	"io"
+)
+
+type Format string
+
+const (
+	FormatCSV     Format = "csv"
+	FormatParquet Format = "parquet"
+	FormatArrow   Format = "arrow"
+)
+
+func (f Format) Valid() bool {
+	switch f {
+	case FormatCSV, FormatParquet, FormatArrow:
+		return true
+	default:
+		return false
+	}
+}
+
+func (f Format) ContentType() string {
+	switch f {
+	case FormatParquet:
+		return "application/vnd.apache.parquet"
+	case FormatArrow:
+		return "application/vnd.apache.arrow.stream"
+	default:
+		return "text/csv"
+	}
+}
+
+type columnType int
+
+const (
+	typeInt columnType = iota
+	typeFloat
+	typeString
+)
+
+// Column of the written rows. Values are int64, float64 or string depending
+// on the type, nil for missing values.
+type field struct {
+	name string
+	typ  columnType
+}
+
+type rowWriter interface {
+	write(row []any) error
+	close() error
+}
+
+func newRowWriter(w io.Writer, format Format, fields []field) (rowWriter, error) {
+	switch format {
+	case FormatCSV:
+		return newCsvWriter(w, fields)
+	case FormatParquet:
+		return newParquetWriter(w, fields)
+	case FormatArrow:
+		return newArrowWriter(w, fields)
+	default:
+		return nil, fmt.Errorf("EXPORT > unknown format '%s'", format)
+	}
+}
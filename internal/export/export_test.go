@@ -0,0 +1,131 @@
+// Copyright (C) NHR@FAU, University Erlangen-Nuremberg.
+// All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+package export
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/ClusterCockpit/cc-backend/pkg/schema"
+	"github.com/xitongsys/parquet-go-source/buffer"
+	"github.com/xitongsys/parquet-go/reader"
+)
+
+var testJobs = []*schema.Job{
+	{
+		ID:            1,
+		StartTimeUnix: 1700000000,
+		BaseJob: schema.BaseJob{
+			JobID: 1001, User: "abcd100h", Cluster: "fritz", State: schema.JobStateCompleted, Duration: 600,
+			Resources: []*schema.Resource{{Hostname: "f0101"}, {Hostname: "f0102"}},
+			Footprint: map[string]float64{"flops_any_avg": 12.5},
+			MetaData:  map[string]string{"jobName": "lammps, run 1"},
+		},
+	},
+	{
+		ID:            2,
+		StartTimeUnix: 1700000100,
+		BaseJob: schema.BaseJob{
+			JobID: 1002, User: "abcd101h", Cluster: "fritz", State: schema.JobStateRunning,
+			EnergyFootprint: map[string]float64{"acc_power": 0.75},
+		},
+	},
+}
+
+var testColumns = []string{"jobId", "user", "startTime", "nodes", "footprint.flops_any_avg", "energyFootprint.acc_power", "metaData.jobName"}
+
+func write(t *testing.T, format Format, columns []string) []byte {
+	var b bytes.Buffer
+	jw, err := NewJobWriter(&b, format, columns)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, job := range testJobs {
+		if err := jw.Write(job); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := jw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return b.Bytes()
+}
+
+func TestCsv(t *testing.T) {
+	got := string(write(t, FormatCSV, testColumns))
+	want := `jobId,user,startTime,nodes,footprint.flops_any_avg,energyFootprint.acc_power,metaData.jobName
+1001,abcd100h,1700000000,"f0101,f0102",12.5,,"lammps, run 1"
+1002,abcd101h,1700000100,,,0.75,
+`
+	if got != want {
+		t.Errorf("unexpected csv:\n%s\nwant:\n%s", got, want)
+	}
+
+	header, _, _ := bytes.Cut(write(t, FormatCSV, nil), []byte("\n"))
+	if string(header) != "id,jobId,user,project,cluster,subCluster,partition,arrayJobId,numNodes,numHwthreads,numAcc,exclusive,smt,monitoringStatus,jobState,startTime,duration,walltime,energy" {
+		t.Errorf("unexpected default columns: %s", header)
+	}
+}
+
+func TestParquet(t *testing.T) {
+	b := write(t, FormatParquet, testColumns)
+
+	// The reader refers to the columns by internal names, the original names
+	// are only kept in the file
+	for _, name := range testColumns {
+		if !bytes.Contains(b, []byte(name)) {
+			t.Errorf("column %s missing in file", name)
+		}
+	}
+
+	file, err := buffer.NewBufferFile(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pr, err := reader.NewParquetReader(file, nil, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pr.ReadStop()
+
+	if pr.GetNumRows() != 2 {
+		t.Fatalf("expected 2 rows, got %d", pr.GetNumRows())
+	}
+
+	want := [][]any{
+		{int64(1001), int64(1002)},
+		{"abcd100h", "abcd101h"},
+		{int64(1700000000), int64(1700000100)},
+		{"f0101,f0102", ""},
+		{12.5, nil},
+		{nil, 0.75},
+		{"lammps, run 1", nil},
+	}
+	for i, path := range pr.SchemaHandler.ValueColumns {
+		values, _, _, err := pr.ReadColumnByPath(path, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(values, want[i]) {
+			t.Errorf("column %s: got %v, want %v", testColumns[i], values, want[i])
+		}
+	}
+}
+
+func TestInvalidColumns(t *testing.T) {
+	var b bytes.Buffer
+	for _, columns := range [][]string{{"jobId", "hostname"}, {"footprint."}, {"statistics.flops_any"}} {
+		if _, err := NewJobWriter(&b, FormatCSV, columns); err == nil {
+			t.Errorf("expected error for columns %v", columns)
+		}
+	}
+	if _, err := NewJobWriter(&b, FormatParquet, []string{"metaData.a,b"}); err == nil {
+		t.Error("expected error for parquet column with comma")
+	}
+	if _, err := NewJobWriter(&b, Format("xlsx"), nil); err == nil {
+		t.Error("expected error for unknown format")
+	}
+}
@@ -0,0 +1,154 @@
+// Copyright (C) NHR@FAU, University Erlangen-Nuremberg.
+// All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+package export
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/ClusterCockpit/cc-backend/pkg/schema"
+)
+
+type column struct {
+	field
+	value func(job *schema.Job) any
+}
+
+var jobColumns = []column{
+	{field{"id", typeInt}, func(job *schema.Job) any { return job.ID }},
+	{field{"jobId", typeInt}, func(job *schema.Job) any { return job.JobID }},
+	{field{"user", typeString}, func(job *schema.Job) any { return job.User }},
+	{field{"project", typeString}, func(job *schema.Job) any { return job.Project }},
+	{field{"cluster", typeString}, func(job *schema.Job) any { return job.Cluster }},
+	{field{"subCluster", typeString}, func(job *schema.Job) any { return job.SubCluster }},
+	{field{"partition", typeString}, func(job *schema.Job) any { return job.Partition }},
+	{field{"arrayJobId", typeInt}, func(job *schema.Job) any { return job.ArrayJobId }},
+	{field{"numNodes", typeInt}, func(job *schema.Job) any { return int64(job.NumNodes) }},
+	{field{"numHwthreads", typeInt}, func(job *schema.Job) any { return int64(job.NumHWThreads) }},
+	{field{"numAcc", typeInt}, func(job *schema.Job) any { return int64(job.NumAcc) }},
+	{field{"exclusive", typeInt}, func(job *schema.Job) any { return int64(job.Exclusive) }},
+	{field{"smt", typeInt}, func(job *schema.Job) any { return int64(job.SMT) }},
+	{field{"monitoringStatus", typeInt}, func(job *schema.Job) any { return int64(job.MonitoringStatus) }},
+	{field{"jobState", typeString}, func(job *schema.Job) any { return string(job.State) }},
+	{field{"startTime", typeInt}, func(job *schema.Job) any { return job.StartTimeUnix }},
+	{field{"duration", typeInt}, func(job *schema.Job) any { return int64(job.Duration) }},
+	{field{"walltime", typeInt}, func(job *schema.Job) any { return job.Walltime }},
+	{field{"energy", typeFloat}, func(job *schema.Job) any { return job.Energy }},
+}
+
+// Columns exported if none are selected
+var DefaultColumns []string
+
+// Comma separated list of the hostnames, only exported if selected
+var nodesColumn = column{field{"nodes", typeString}, func(job *schema.Job) any {
+	hosts := make([]string, 0, len(job.Resources))
+	for _, r := range job.Resources {
+		hosts = append(hosts, r.Hostname)
+	}
+	return strings.Join(hosts, ",")
+}}
+
+func init() {
+	for _, c := range jobColumns {
+		DefaultColumns = append(DefaultColumns, c.name)
+	}
+}
+
+// Resolves the column names. Besides the fixed columns, keys of the footprint,
+// energy footprint and meta data can be selected as "footprint.<key>",
+// "energyFootprint.<key>" and "metaData.<key>".
+func parseColumns(names []string) ([]column, error) {
+	if len(names) == 0 {
+		names = DefaultColumns
+	}
+
+	columns := make([]column, 0, len(names))
+NAMES:
+	for _, name := range names {
+		if name == nodesColumn.name {
+			columns = append(columns, nodesColumn)
+			continue
+		}
+		for _, c := range jobColumns {
+			if c.name == name {
+				columns = append(columns, c)
+				continue NAMES
+			}
+		}
+
+		prefix, key, ok := strings.Cut(name, ".")
+		if !ok || key == "" {
+			return nil, fmt.Errorf("EXPORT > unknown column '%s'", name)
+		}
+		switch prefix {
+		case "footprint":
+			columns = append(columns, column{field{name, typeFloat}, func(job *schema.Job) any {
+				if v, ok := job.Footprint[key]; ok {
+					return v
+				}
+				return nil
+			}})
+		case "energyFootprint":
+			columns = append(columns, column{field{name, typeFloat}, func(job *schema.Job) any {
+				if v, ok := job.EnergyFootprint[key]; ok {
+					return v
+				}
+				return nil
+			}})
+		case "metaData":
+			columns = append(columns, column{field{name, typeString}, func(job *schema.Job) any {
+				if v, ok := job.MetaData[key]; ok {
+					return v
+				}
+				return nil
+			}})
+		default:
+			return nil, fmt.Errorf("EXPORT > unknown column '%s'", name)
+		}
+	}
+
+	return columns, nil
+}
+
+type JobWriter struct {
+	columns []column
+	row     []any
+	w       rowWriter
+}
+
+// NewJobWriter returns a writer for jobs in format with the selected columns,
+// DefaultColumns if columns is empty. Close has to be called after the last
+// job was written.
+func NewJobWriter(w io.Writer, format Format, columns []string) (*JobWriter, error) {
+	cols, err := parseColumns(columns)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := make([]field, 0, len(cols))
+	for _, c := range cols {
+		fields = append(fields, c.field)
+	}
+
+	jw := &JobWriter{columns: cols, row: make([]any, len(cols))}
+	jw.w, err = newRowWriter(w, format, fields)
+	if err != nil {
+		return nil, err
+	}
+
+	return jw, nil
+}
+
+func (jw *JobWriter) Write(job *schema.Job) error {
+	for i, c := range jw.columns {
+		jw.row[i] = c.value(job)
+	}
+	return jw.w.write(jw.row)
+}
+
+func (jw *JobWriter) Close() error {
+	return jw.w.close()
+}
@@ -0,0 +1,109 @@
+// Copyright (C) NHR@FAU, University Erlangen-Nuremberg.
+// All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+package export
+
+import (
+	"io"
+	"math"
+	"sort"
+
+	"github.com/ClusterCockpit/cc-backend/pkg/resampler"
+	"github.com/ClusterCockpit/cc-backend/pkg/schema"
+)
+
+// Long format of the metric data, one row per data point. The timestamp is
+// the unix epoch in seconds, the id is the id of the hwthread, socket,
+// accelerator etc. and empty for the node scope.
+var metricFields = []field{
+	{"metric", typeString},
+	{"scope", typeString},
+	{"hostname", typeString},
+	{"id", typeString},
+	{"timestamp", typeInt},
+	{"value", typeFloat},
+}
+
+// ResampleJobData returns data with all series resampled to the timestep
+// resolution (in seconds) if they have a smaller timestep. The series in data
+// are not modified.
+func ResampleJobData(data schema.JobData, resolution int) (schema.JobData, error) {
+	if resolution <= 0 {
+		return data, nil
+	}
+
+	res := make(schema.JobData, len(data))
+	for metric, scopes := range data {
+		res[metric] = make(map[schema.MetricScope]*schema.JobMetric, len(scopes))
+		for scope, jm := range scopes {
+			if jm.Timestep >= resolution {
+				res[metric][scope] = jm
+				continue
+			}
+
+			resampled := *jm
+			resampled.StatisticsSeries = nil
+			resampled.Series = make([]schema.Series, len(jm.Series))
+			for i, s := range jm.Series {
+				var err error
+				resampled.Series[i] = s
+				resampled.Series[i].Data, resampled.Timestep, err = resampler.LargestTriangleThreeBucket(s.Data, jm.Timestep, resolution)
+				if err != nil {
+					return nil, err
+				}
+			}
+			res[metric][scope] = &resampled
+		}
+	}
+
+	return res, nil
+}
+
+// WriteJobData writes the series of data in long format. Missing values (NaN)
+// are written as null. Statistics series are not written.
+func WriteJobData(w io.Writer, format Format, job *schema.Job, data schema.JobData) error {
+	rw, err := newRowWriter(w, format, metricFields)
+	if err != nil {
+		return err
+	}
+
+	metrics := make([]string, 0, len(data))
+	for metric := range data {
+		metrics = append(metrics, metric)
+	}
+	sort.Strings(metrics)
+
+	row := make([]any, len(metricFields))
+	for _, metric := range metrics {
+		scopes := make([]schema.MetricScope, 0, len(data[metric]))
+		for scope := range data[metric] {
+			scopes = append(scopes, scope)
+		}
+		sort.Slice(scopes, func(i, j int) bool { return scopes[i] < scopes[j] })
+
+		for _, scope := range scopes {
+			jm := data[metric][scope]
+			for _, s := range jm.Series {
+				row[0], row[1], row[2], row[3] = metric, string(scope), s.Hostname, nil
+				if s.Id != nil {
+					row[3] = *s.Id
+				}
+
+				for i, v := range s.Data {
+					row[4] = job.StartTimeUnix + int64(i*jm.Timestep)
+					if math.IsNaN(float64(v)) {
+						row[5] = nil
+					} else {
+						row[5] = float64(v)
+					}
+					if err := rw.write(row); err != nil {
+						return err
+					}
+				}
+			}
+		}
+	}
+
+	return rw.close()
+}
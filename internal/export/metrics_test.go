@@ -0,0 +1,107 @@
+// Copyright (C) NHR@FAU, University Erlangen-Nuremberg.
+// All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+package export
+
+import (
+	"bytes"
+	"math"
+	"testing"
+
+	"github.com/ClusterCockpit/cc-backend/pkg/schema"
+	"github.com/apache/arrow/go/arrow/array"
+	"github.com/apache/arrow/go/arrow/ipc"
+)
+
+func testJobData() schema.JobData {
+	id0, id1 := "0", "1"
+	nan := schema.Float(math.NaN())
+	return schema.JobData{
+		"mem_bw": {
+			schema.MetricScopeNode: {Timestep: 60, Series: []schema.Series{
+				{Hostname: "f0101", Data: []schema.Float{10, nan, 30}},
+			}},
+		},
+		"flops_any": {
+			schema.MetricScopeSocket: {Timestep: 30, Series: []schema.Series{
+				{Hostname: "f0101", Id: &id0, Data: []schema.Float{1.5}},
+				{Hostname: "f0101", Id: &id1, Data: []schema.Float{2.5}},
+			}},
+		},
+	}
+}
+
+func TestWriteJobData(t *testing.T) {
+	job := &schema.Job{StartTimeUnix: 1700000000}
+
+	var b bytes.Buffer
+	if err := WriteJobData(&b, FormatCSV, job, testJobData()); err != nil {
+		t.Fatal(err)
+	}
+	want := `metric,scope,hostname,id,timestamp,value
+flops_any,socket,f0101,0,1700000000,1.5
+flops_any,socket,f0101,1,1700000000,2.5
+mem_bw,node,f0101,,1700000000,10
+mem_bw,node,f0101,,1700000060,
+mem_bw,node,f0101,,1700000120,30
+`
+	if b.String() != want {
+		t.Errorf("unexpected csv:\n%s\nwant:\n%s", b.String(), want)
+	}
+
+	b.Reset()
+	if err := WriteJobData(&b, FormatArrow, job, testJobData()); err != nil {
+		t.Fatal(err)
+	}
+	r, err := ipc.NewReader(&b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Release()
+
+	if names := []string{r.Schema().Field(0).Name, r.Schema().Field(5).Name}; names[0] != "metric" || names[1] != "value" {
+		t.Fatalf("unexpected schema: %v", r.Schema())
+	}
+	rows := 0
+	for r.Next() {
+		rec := r.Record()
+		metrics := rec.Column(0).(*array.String)
+		timestamps := rec.Column(4).(*array.Int64)
+		values := rec.Column(5).(*array.Float64)
+		for i := 0; i < int(rec.NumRows()); i++ {
+			if rows+i == 3 {
+				if metrics.Value(i) != "mem_bw" || timestamps.Value(i) != 1700000060 || !values.IsNull(i) {
+					t.Errorf("unexpected row %d: %s %d %v", rows+i, metrics.Value(i), timestamps.Value(i), values.Value(i))
+				}
+			}
+		}
+		rows += int(rec.NumRows())
+	}
+	if rows != 5 {
+		t.Errorf("expected 5 rows, got %d", rows)
+	}
+}
+
+func TestResampleJobData(t *testing.T) {
+	data := make([]schema.Float, 200)
+	for i := range data {
+		data[i] = schema.Float(i)
+	}
+	jd := schema.JobData{"cpu_load": {schema.MetricScopeNode: {Timestep: 60, Series: []schema.Series{{Hostname: "f0101", Data: data}}}}}
+
+	res, err := ResampleJobData(jd, 600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if jm := res["cpu_load"][schema.MetricScopeNode]; jm.Timestep != 600 || len(jm.Series[0].Data) != 20 {
+		t.Errorf("unexpected resampled metric: timestep %d, %d points", jm.Timestep, len(jm.Series[0].Data))
+	}
+	if jm := jd["cpu_load"][schema.MetricScopeNode]; jm.Timestep != 60 || len(jm.Series[0].Data) != 200 {
+		t.Error("original data was modified")
+	}
+
+	if _, err := ResampleJobData(jd, 90); err == nil {
+		t.Error("expected error for resolution which is no multiple of the timestep")
+	}
+}
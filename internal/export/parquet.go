@@ -0,0 +1,55 @@
+// Copyright (C) NHR@FAU, University Erlangen-Nuremberg.
+// All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+package export
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/xitongsys/parquet-go/writer"
+)
+
+// Rows are buffered until a row group of this size is complete
+const parquetRowGroupSize = 8 * 1024 * 1024
+
+type parquetWriter struct {
+	w *writer.CSVWriter
+}
+
+// All columns are optional, missing values are null
+func newParquetWriter(w io.Writer, fields []field) (*parquetWriter, error) {
+	md := make([]string, 0, len(fields))
+	for _, c := range fields {
+		if strings.ContainsAny(c.name, ",=") {
+			return nil, fmt.Errorf("EXPORT > column '%s' cannot be used in parquet files", c.name)
+		}
+
+		switch c.typ {
+		case typeInt:
+			md = append(md, fmt.Sprintf("name=%s, type=INT64, repetitiontype=OPTIONAL", c.name))
+		case typeFloat:
+			md = append(md, fmt.Sprintf("name=%s, type=DOUBLE, repetitiontype=OPTIONAL", c.name))
+		case typeString:
+			md = append(md, fmt.Sprintf("name=%s, type=BYTE_ARRAY, convertedtype=UTF8, repetitiontype=OPTIONAL", c.name))
+		}
+	}
+
+	pw, err := writer.NewCSVWriterFromWriter(md, w, 1)
+	if err != nil {
+		return nil, fmt.Errorf("EXPORT > %w", err)
+	}
+	pw.RowGroupSize = parquetRowGroupSize
+	return &parquetWriter{w: pw}, nil
+}
+
+func (pw *parquetWriter) write(row []any) error {
+	// The parquet writer keeps the row until the row group is flushed
+	return pw.w.Write(append([]any(nil), row...))
+}
+
+func (pw *parquetWriter) close() error {
+	return pw.w.WriteStop()
+}
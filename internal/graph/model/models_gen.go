@@ -194,6 +194,9 @@ type StringInput struct {
 	In         []string `json:"in,omitempty"`
 }
 
+type Subscription struct {
+}
+
 type TimeRangeOutput struct {
 	Range *string   `json:"range,omitempty"`
 	From  time.Time `json:"from"`
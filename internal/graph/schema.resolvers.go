@@ -15,9 +15,11 @@ import (
 	"time"
 
 	"github.com/ClusterCockpit/cc-backend/internal/config"
+	"github.com/ClusterCockpit/cc-backend/internal/eventBus"
 	"github.com/ClusterCockpit/cc-backend/internal/graph/generated"
 	"github.com/ClusterCockpit/cc-backend/internal/graph/model"
 	"github.com/ClusterCockpit/cc-backend/internal/metricDataDispatcher"
+	"github.com/ClusterCockpit/cc-backend/internal/metricdata"
 	"github.com/ClusterCockpit/cc-backend/internal/repository"
 	"github.com/ClusterCockpit/cc-backend/pkg/archive"
 	"github.com/ClusterCockpit/cc-backend/pkg/log"
@@ -191,6 +193,53 @@ func (r *mutationResolver) RemoveTagsFromJob(ctx context.Context, job string, ta
 	return tags, nil
 }
 
+// CreateProjectBudget is the resolver for the createProjectBudget field.
+func (r *mutationResolver) CreateProjectBudget(ctx context.Context, project string, cluster *string, unit schema.BudgetUnit, amount float64, startTime int, endTime int) (*schema.ProjectBudget, error) {
+	user := repository.GetUserFromContext(ctx)
+	if user != nil && !user.HasRole(schema.RoleAdmin) {
+		return nil, errors.New("you need to be administrator to create budgets")
+	}
+
+	budget := &schema.ProjectBudget{
+		Project:   project,
+		Unit:      unit,
+		Amount:    amount,
+		StartTime: int64(startTime),
+		EndTime:   int64(endTime),
+	}
+	if cluster != nil {
+		budget.Cluster = *cluster
+	}
+
+	if err := r.Repo.AddProjectBudget(budget); err != nil {
+		log.Warn("Error while creating budget")
+		return nil, err
+	}
+
+	return budget, nil
+}
+
+// DeleteProjectBudget is the resolver for the deleteProjectBudget field.
+func (r *mutationResolver) DeleteProjectBudget(ctx context.Context, id string) (string, error) {
+	user := repository.GetUserFromContext(ctx)
+	if user != nil && !user.HasRole(schema.RoleAdmin) {
+		return "", errors.New("you need to be administrator to delete budgets")
+	}
+
+	bid, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		log.Warn("Error while parsing budget id")
+		return "", err
+	}
+
+	if err := r.Repo.DeleteProjectBudget(bid); err != nil {
+		log.Warn("Error while deleting budget")
+		return "", err
+	}
+
+	return id, nil
+}
+
 // UpdateConfiguration is the resolver for the updateConfiguration field.
 func (r *mutationResolver) UpdateConfiguration(ctx context.Context, name string, value string) (*string, error) {
 	if err := repository.GetUserCfgRepo().UpdateConfig(name, value, repository.GetUserFromContext(ctx)); err != nil {
@@ -240,6 +289,26 @@ func (r *queryResolver) AllocatedNodes(ctx context.Context, cluster string) ([]*
 	return counts, nil
 }
 
+// MetricDataHealth is the resolver for the metricDataHealth field.
+func (r *queryResolver) MetricDataHealth(ctx context.Context, cluster *string) ([]*metricdata.RepositoryHealth, error) {
+	name := ""
+	if cluster != nil {
+		name = *cluster
+	}
+
+	health := metricdata.GetHealth(name)
+	res := make([]*metricdata.RepositoryHealth, 0, len(health))
+	for i := range health {
+		res = append(res, &health[i])
+	}
+	return res, nil
+}
+
+// ProjectBudgets is the resolver for the projectBudgets field.
+func (r *queryResolver) ProjectBudgets(ctx context.Context, project *string, cluster *string) ([]*schema.BudgetStatus, error) {
+	return r.Repo.ProjectBudgetStatus(repository.GetUserFromContext(ctx), project, cluster)
+}
+
 // Job is the resolver for the job field.
 func (r *queryResolver) Job(ctx context.Context, id string) (*schema.Job, error) {
 	numericId, err := strconv.ParseInt(id, 10, 64)
@@ -613,6 +682,152 @@ func (r *subClusterResolver) NumberOfNodes(ctx context.Context, obj *schema.SubC
 	return nodeList.NodeCount(), nil
 }
 
+// JobStateChanged is the resolver for the jobStateChanged field.
+func (r *subscriptionResolver) JobStateChanged(ctx context.Context, filter []*model.JobFilter) (<-chan *schema.Job, error) {
+	events := eventBus.JobStateChanged.Subscribe(ctx, 64)
+	ch := make(chan *schema.Job)
+
+	go func() {
+		defer close(ch)
+		for event := range events {
+			ok, err := r.Repo.JobMatches(ctx, event.ID, filter)
+			if err != nil {
+				log.Warnf("Error while matching job %d against subscription filter: %s", event.ID, err.Error())
+				continue
+			} else if !ok {
+				continue
+			}
+
+			job, err := r.Repo.FindByIdDirect(event.ID)
+			if err != nil {
+				log.Warnf("Error while loading job %d for subscription: %s", event.ID, err.Error())
+				continue
+			}
+
+			select {
+			case ch <- job:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return ch, nil
+}
+
+// JobMetricsUpdated is the resolver for the jobMetricsUpdated field.
+func (r *subscriptionResolver) JobMetricsUpdated(ctx context.Context, id string, metrics []string, scopes []schema.MetricScope, resolution *int) (<-chan []*model.JobMetricWithName, error) {
+	job, err := r.Query().Job(ctx, id)
+	if err != nil {
+		log.Warn("Error while querying job for metrics subscription")
+		return nil, err
+	}
+
+	// Subscribe before loading the initial data to not miss updates in between
+	subCtx, cancel := context.WithCancel(ctx)
+	updates := eventBus.JobMetricsUpdated.Subscribe(subCtx, 4)
+	stateChanges := eventBus.JobStateChanged.Subscribe(subCtx, 64)
+
+	data, err := r.Query().JobMetrics(ctx, id, metrics, scopes, resolution)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	ch := make(chan []*model.JobMetricWithName, 1)
+	ch <- data
+
+	// The data of finished jobs does not change anymore
+	if job.State != schema.JobStateRunning {
+		cancel()
+		close(ch)
+		return ch, nil
+	}
+
+	go func() {
+		defer close(ch)
+		defer cancel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event, ok := <-stateChanges:
+				if !ok {
+					return
+				}
+				if event.ID == job.ID && event.State != schema.JobStateRunning {
+					return
+				}
+			case event, ok := <-updates:
+				if !ok {
+					return
+				}
+				if event.ID != job.ID {
+					continue
+				}
+
+				data, err := r.Query().JobMetrics(ctx, id, metrics, scopes, resolution)
+				if err != nil {
+					log.Warnf("Error while loading updated metrics of job %d: %s", job.ID, err.Error())
+					continue
+				}
+
+				select {
+				case ch <- data:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return ch, nil
+}
+
+// RunningJobsCount is the resolver for the runningJobsCount field.
+func (r *subscriptionResolver) RunningJobsCount(ctx context.Context, cluster string) (<-chan int, error) {
+	filter := []*model.JobFilter{{
+		Cluster: &model.StringInput{Eq: &cluster},
+		State:   []schema.JobState{schema.JobStateRunning},
+	}}
+
+	events := eventBus.JobStateChanged.Subscribe(ctx, 64)
+	count, err := r.Repo.CountJobs(ctx, filter)
+	if err != nil {
+		log.Warn("Error while counting running jobs")
+		return nil, err
+	}
+
+	ch := make(chan int, 1)
+	ch <- count
+
+	go func() {
+		defer close(ch)
+		for event := range events {
+			if event.Cluster != cluster {
+				continue
+			}
+
+			c, err := r.Repo.CountJobs(ctx, filter)
+			if err != nil {
+				log.Warnf("Error while counting running jobs of cluster %s: %s", cluster, err.Error())
+				continue
+			} else if c == count {
+				continue
+			}
+			count = c
+
+			select {
+			case ch <- count:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return ch, nil
+}
+
 // Cluster returns generated.ClusterResolver implementation.
 func (r *Resolver) Cluster() generated.ClusterResolver { return &clusterResolver{r} }
 
@@ -631,9 +846,13 @@ func (r *Resolver) Query() generated.QueryResolver { return &queryResolver{r} }
 // SubCluster returns generated.SubClusterResolver implementation.
 func (r *Resolver) SubCluster() generated.SubClusterResolver { return &subClusterResolver{r} }
 
+// Subscription returns generated.SubscriptionResolver implementation.
+func (r *Resolver) Subscription() generated.SubscriptionResolver { return &subscriptionResolver{r} }
+
 type clusterResolver struct{ *Resolver }
 type jobResolver struct{ *Resolver }
 type metricValueResolver struct{ *Resolver }
 type mutationResolver struct{ *Resolver }
 type queryResolver struct{ *Resolver }
 type subClusterResolver struct{ *Resolver }
+type subscriptionResolver struct{ *Resolver }
@@ -7,10 +7,12 @@ package importer
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"math"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/ClusterCockpit/cc-backend/internal/config"
 	"github.com/ClusterCockpit/cc-backend/internal/repository"
@@ -19,6 +21,14 @@ import (
 	"github.com/ClusterCockpit/cc-backend/pkg/schema"
 )
 
+var (
+	// The job meta or data is invalid
+	ErrInvalidJob = errors.New("invalid job")
+	// A job with the same jobId and cluster started less than a day before the
+	// imported job
+	ErrJobExists = errors.New("job already exists")
+)
+
 // Import all jobs specified as `<path-to-meta.json>:<path-to-data.json>,...`
 func HandleImportFlag(flag string) error {
 	r := repository.GetJobRepository()
@@ -29,142 +39,205 @@ func HandleImportFlag(flag string) error {
 			return fmt.Errorf("REPOSITORY/INIT > invalid import flag format")
 		}
 
-		raw, err := os.ReadFile(files[0])
+		rawMeta, err := os.ReadFile(files[0])
 		if err != nil {
 			log.Warn("Error while reading metadata file for import")
 			return err
 		}
 
-		if config.Keys.Validate {
-			if err = schema.Validate(schema.Meta, bytes.NewReader(raw)); err != nil {
-				return fmt.Errorf("REPOSITORY/INIT > validate job meta: %v", err)
-			}
-		}
-		dec := json.NewDecoder(bytes.NewReader(raw))
-		dec.DisallowUnknownFields()
-		job := schema.JobMeta{BaseJob: schema.JobDefaults}
-		if err = dec.Decode(&job); err != nil {
-			log.Warn("Error while decoding raw json metadata for import")
+		rawData, err := os.ReadFile(files[1])
+		if err != nil {
+			log.Warn("Error while reading jobdata file for import")
 			return err
 		}
 
-		raw, err = os.ReadFile(files[1])
+		job, id, err := importJob(r, rawMeta, rawData)
 		if err != nil {
-			log.Warn("Error while reading jobdata file for import")
 			return err
 		}
 
-		if config.Keys.Validate {
-			if err = schema.Validate(schema.Data, bytes.NewReader(raw)); err != nil {
-				return fmt.Errorf("REPOSITORY/INIT > validate job data: %v", err)
-			}
-		}
-		dec = json.NewDecoder(bytes.NewReader(raw))
-		dec.DisallowUnknownFields()
-		jobData := schema.JobData{}
-		if err = dec.Decode(&jobData); err != nil {
-			log.Warn("Error while decoding raw json jobdata for import")
-			return err
+		log.Infof("successfully imported a new job (jobId: %d, cluster: %s, dbid: %d)", job.JobID, job.Cluster, id)
+	}
+	return nil
+}
+
+// ImportJob decodes the JSON encoded job meta and data, writes them to the
+// job archive and inserts the job into the database. Both are validated
+// against the JSON schema if enabled in the configuration. Returns the job
+// and its database id. Errors caused by the job itself wrap ErrInvalidJob or
+// ErrJobExists.
+func ImportJob(rawMeta, rawData []byte) (*schema.JobMeta, int64, error) {
+	return importJob(repository.GetJobRepository(), rawMeta, rawData)
+}
+
+func importJob(r *repository.JobRepository, rawMeta, rawData []byte) (*schema.JobMeta, int64, error) {
+	if config.Keys.Validate {
+		if err := schema.Validate(schema.Meta, bytes.NewReader(rawMeta)); err != nil {
+			return nil, 0, fmt.Errorf("REPOSITORY/INIT > validate job meta: %w: %v", ErrInvalidJob, err)
 		}
+	}
+	dec := json.NewDecoder(bytes.NewReader(rawMeta))
+	dec.DisallowUnknownFields()
+	job := schema.JobMeta{BaseJob: schema.JobDefaults}
+	if err := dec.Decode(&job); err != nil {
+		log.Warn("Error while decoding raw json metadata for import")
+		return nil, 0, fmt.Errorf("REPOSITORY/INIT > decode job meta: %w: %v", ErrInvalidJob, err)
+	}
 
-		job.MonitoringStatus = schema.MonitoringStatusArchivingSuccessful
+	if job.State == schema.JobStateRunning {
+		return nil, 0, fmt.Errorf("REPOSITORY/INIT > %w: job is still running", ErrInvalidJob)
+	}
 
-		sc, err := archive.GetSubCluster(job.Cluster, job.SubCluster)
-		if err != nil {
-			log.Errorf("cannot get subcluster: %s", err.Error())
-			return err
+	if config.Keys.Validate {
+		if err := schema.Validate(schema.Data, bytes.NewReader(rawData)); err != nil {
+			return nil, 0, fmt.Errorf("REPOSITORY/INIT > validate job data: %w: %v", ErrInvalidJob, err)
 		}
+	}
+	dec = json.NewDecoder(bytes.NewReader(rawData))
+	dec.DisallowUnknownFields()
+	jobData := schema.JobData{}
+	if err := dec.Decode(&jobData); err != nil {
+		log.Warn("Error while decoding raw json jobdata for import")
+		return nil, 0, fmt.Errorf("REPOSITORY/INIT > decode job data: %w: %v", ErrInvalidJob, err)
+	}
 
-		job.Footprint = make(map[string]float64)
+	if err := prepareJob(&job); err != nil {
+		return nil, 0, fmt.Errorf("REPOSITORY/INIT > %w: %v", ErrInvalidJob, err)
+	}
 
-		for _, fp := range sc.Footprint {
-			statType := "avg"
+	if existing, err := r.FindDuplicate(job.JobID, job.Cluster, job.StartTime); err != nil {
+		return nil, 0, err
+	} else if existing != nil {
+		return nil, 0, fmt.Errorf("REPOSITORY/INIT > %w: dbid: %d, jobid: %d", ErrJobExists, existing.ID, existing.JobID)
+	}
 
-			if i, err := archive.MetricIndex(sc.MetricConfig, fp); err != nil {
-				statType = sc.MetricConfig[i].Footprint
-			}
+	ar := archive.GetHandle()
+	if err := ar.ImportJob(&job, &jobData); err != nil {
+		log.Error("Error while importing job")
+		return nil, 0, err
+	}
 
-			name := fmt.Sprintf("%s_%s", fp, statType)
+	id, err := insertJob(r, &job)
+	if err != nil {
+		// Do not leave a job in the archive which is not in the database
+		ar.CleanUp([]*schema.Job{{BaseJob: job.BaseJob, StartTime: time.Unix(job.StartTime, 0)}})
+		return nil, 0, err
+	}
 
-			job.Footprint[name] = repository.LoadJobStat(&job, fp, statType)
-		}
+	return &job, id, nil
+}
 
-		job.RawFootprint, err = json.Marshal(job.Footprint)
-		if err != nil {
-			log.Warn("Error while marshaling job footprint")
-			return err
-		}
+// ImportArchivedJob inserts a job that already exists in the job archive
+// into the database. Footprints are computed from the archived statistics.
+func ImportArchivedJob(job *schema.JobMeta) (int64, error) {
+	if err := prepareJob(job); err != nil {
+		return 0, err
+	}
 
-		job.EnergyFootprint = make(map[string]float64)
-
-		// Total Job Energy Outside Loop
-		totalEnergy := 0.0
-		for _, fp := range sc.EnergyFootprint {
-			// Always Init Metric Energy Inside Loop
-			metricEnergy := 0.0
-			if i, err := archive.MetricIndex(sc.MetricConfig, fp); err == nil {
-				// Note: For DB data, calculate and save as kWh
-				if sc.MetricConfig[i].Energy == "energy" { // this metric has energy as unit (Joules)
-					log.Warnf("Update EnergyFootprint for Job %d and Metric %s on cluster %s: Set to 'energy' in cluster.json: Not implemented, will return 0.0", job.JobID, job.Cluster, fp)
-					// FIXME: Needs sum as stats type
-				} else if sc.MetricConfig[i].Energy == "power" { // this metric has power as unit (Watt)
-					// Energy: Power (in Watts) * Time (in Seconds)
-					// Unit: (W * (s / 3600)) / 1000 = kWh
-					// Round 2 Digits: round(Energy * 100) / 100
-					// Here: (All-Node Metric Average * Number of Nodes) * (Job Duration in Seconds / 3600) / 1000
-					// Note: Shared Jobs handled correctly since "Node Average" is based on partial resources, while "numNodes" factor is 1
-					rawEnergy := ((repository.LoadJobStat(&job, fp, "avg") * float64(job.NumNodes)) * (float64(job.Duration) / 3600.0)) / 1000.0
-					metricEnergy = math.Round(rawEnergy*100.0) / 100.0
-				}
-			} else {
-				log.Warnf("Error while collecting energy metric %s for job, DB ID '%v', return '0.0'", fp, job.ID)
-			}
+	return insertJob(repository.GetJobRepository(), job)
+}
 
-			job.EnergyFootprint[fp] = metricEnergy
-			totalEnergy += metricEnergy
-		}
+// Sets the monitoring status, computes footprint and energy from the job
+// statistics and checks the job for consistency.
+func prepareJob(job *schema.JobMeta) error {
+	job.MonitoringStatus = schema.MonitoringStatusArchivingSuccessful
 
-		job.Energy = (math.Round(totalEnergy*100.0) / 100.0)
-		if job.RawEnergyFootprint, err = json.Marshal(job.EnergyFootprint); err != nil {
-			log.Warnf("Error while marshaling energy footprint for job INTO BYTES, DB ID '%v'", job.ID)
-			return err
-		}
+	sc, err := archive.GetSubCluster(job.Cluster, job.SubCluster)
+	if err != nil {
+		log.Errorf("cannot get subcluster: %s", err.Error())
+		return err
+	}
 
-		job.RawResources, err = json.Marshal(job.Resources)
-		if err != nil {
-			log.Warn("Error while marshaling job resources")
-			return err
-		}
-		job.RawMetaData, err = json.Marshal(job.MetaData)
-		if err != nil {
-			log.Warn("Error while marshaling job metadata")
-			return err
-		}
+	job.Footprint = make(map[string]float64)
 
-		if err = SanityChecks(&job.BaseJob); err != nil {
-			log.Warn("BaseJob SanityChecks failed")
-			return err
-		}
+	for _, fp := range sc.Footprint {
+		statType := "avg"
 
-		if err = archive.GetHandle().ImportJob(&job, &jobData); err != nil {
-			log.Error("Error while importing job")
-			return err
+		if i, err := archive.MetricIndex(sc.MetricConfig, fp); err != nil {
+			statType = sc.MetricConfig[i].Footprint
 		}
 
-		id, err := r.InsertJob(&job)
-		if err != nil {
-			log.Warn("Error while job db insert")
-			return err
-		}
+		name := fmt.Sprintf("%s_%s", fp, statType)
+
+		job.Footprint[name] = repository.LoadJobStat(job, fp, statType)
+	}
 
-		for _, tag := range job.Tags {
-			if err := r.ImportTag(id, tag.Type, tag.Name, tag.Scope); err != nil {
-				log.Error("Error while adding or creating tag on import")
-				return err
+	job.RawFootprint, err = json.Marshal(job.Footprint)
+	if err != nil {
+		log.Warn("Error while marshaling job footprint")
+		return err
+	}
+
+	job.EnergyFootprint = make(map[string]float64)
+
+	// Total Job Energy Outside Loop
+	totalEnergy := 0.0
+	for _, fp := range sc.EnergyFootprint {
+		// Always Init Metric Energy Inside Loop
+		metricEnergy := 0.0
+		if i, err := archive.MetricIndex(sc.MetricConfig, fp); err == nil {
+			// Note: For DB data, calculate and save as kWh
+			if sc.MetricConfig[i].Energy == "energy" { // this metric has energy as unit (Joules)
+				log.Warnf("Update EnergyFootprint for Job %d and Metric %s on cluster %s: Set to 'energy' in cluster.json: Not implemented, will return 0.0", job.JobID, job.Cluster, fp)
+				// FIXME: Needs sum as stats type
+			} else if sc.MetricConfig[i].Energy == "power" { // this metric has power as unit (Watt)
+				// Energy: Power (in Watts) * Time (in Seconds)
+				// Unit: (W * (s / 3600)) / 1000 = kWh
+				// Round 2 Digits: round(Energy * 100) / 100
+				// Here: (All-Node Metric Average * Number of Nodes) * (Job Duration in Seconds / 3600) / 1000
+				// Note: Shared Jobs handled correctly since "Node Average" is based on partial resources, while "numNodes" factor is 1
+				rawEnergy := ((repository.LoadJobStat(job, fp, "avg") * float64(job.NumNodes)) * (float64(job.Duration) / 3600.0)) / 1000.0
+				metricEnergy = math.Round(rawEnergy*100.0) / 100.0
 			}
+		} else {
+			log.Warnf("Error while collecting energy metric %s for job, DB ID '%v', return '0.0'", fp, job.ID)
 		}
 
-		log.Infof("successfully imported a new job (jobId: %d, cluster: %s, dbid: %d)", job.JobID, job.Cluster, id)
+		job.EnergyFootprint[fp] = metricEnergy
+		totalEnergy += metricEnergy
+	}
+
+	job.Energy = (math.Round(totalEnergy*100.0) / 100.0)
+	if job.RawEnergyFootprint, err = json.Marshal(job.EnergyFootprint); err != nil {
+		log.Warnf("Error while marshaling energy footprint for job INTO BYTES, DB ID '%v'", job.ID)
+		return err
+	}
+
+	job.RawResources, err = json.Marshal(job.Resources)
+	if err != nil {
+		log.Warn("Error while marshaling job resources")
+		return err
+	}
+	job.RawMetaData, err = json.Marshal(job.MetaData)
+	if err != nil {
+		log.Warn("Error while marshaling job metadata")
+		return err
+	}
+
+	if err = SanityChecks(&job.BaseJob); err != nil {
+		log.Warn("BaseJob SanityChecks failed")
+		return err
 	}
+
 	return nil
 }
+
+func insertJob(r *repository.JobRepository, job *schema.JobMeta) (int64, error) {
+	id, err := r.InsertJob(job)
+	if err != nil {
+		log.Warn("Error while job db insert")
+		return 0, err
+	}
+
+	for _, tag := range job.Tags {
+		if err := r.ImportTag(id, tag.Type, tag.Name, tag.Scope); err != nil {
+			log.Error("Error while adding or creating tag on import")
+			if err := r.DeleteJobById(id); err != nil {
+				log.Errorf("Error while removing job %d after failed import: %v", id, err)
+			}
+			return 0, err
+		}
+	}
+
+	return id, nil
+}
@@ -5,7 +5,9 @@
 package importer_test
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -17,6 +19,7 @@ import (
 	"github.com/ClusterCockpit/cc-backend/internal/repository"
 	"github.com/ClusterCockpit/cc-backend/pkg/archive"
 	"github.com/ClusterCockpit/cc-backend/pkg/log"
+	"github.com/ClusterCockpit/cc-backend/pkg/schema"
 )
 
 func copyFile(s string, d string) error {
@@ -173,3 +176,57 @@ func TestHandleImportFlag(t *testing.T) {
 		})
 	}
 }
+
+func TestImportJob(t *testing.T) {
+	r := setup(t)
+
+	rawMeta, err := os.ReadFile(filepath.Join("testdata", "meta-fritzMinimal.input"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rawData, err := os.ReadFile(filepath.Join("testdata", "data-fritzMinimal.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rawMeta = bytes.Replace(rawMeta, []byte(`"jobId":398764`), []byte(`"jobId":398765`), 1)
+
+	job, id, err := importer.ImportJob(rawMeta, rawData)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dbJob, err := r.Find(&job.JobID, &job.Cluster, &job.StartTime)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dbJob.ID != id || dbJob.JobID != 398765 || dbJob.MonitoringStatus != schema.MonitoringStatusArchivingSuccessful {
+		t.Errorf("unexpected job properties: %#v", dbJob)
+	}
+
+	if _, _, err := importer.ImportJob(rawMeta, rawData); !errors.Is(err, importer.ErrJobExists) {
+		t.Errorf("expected ErrJobExists, got: %v", err)
+	}
+
+	// Jobs with the same jobId starting within a day are duplicates, as for start_job
+	later := bytes.Replace(rawMeta, []byte(`"startTime":1675954353`), []byte(`"startTime":1675957953`), 1)
+	if _, _, err := importer.ImportJob(later, rawData); !errors.Is(err, importer.ErrJobExists) {
+		t.Errorf("expected ErrJobExists, got: %v", err)
+	}
+
+	running := bytes.Replace(rawMeta, []byte(`"jobState":"completed"`), []byte(`"jobState":"running"`), 1)
+	if _, _, err := importer.ImportJob(running, rawData); !errors.Is(err, importer.ErrInvalidJob) {
+		t.Errorf("expected ErrInvalidJob, got: %v", err)
+	}
+
+	if _, _, err := importer.ImportJob([]byte(`{"jobId": 1}`), rawData); !errors.Is(err, importer.ErrInvalidJob) {
+		t.Errorf("expected ErrInvalidJob, got: %v", err)
+	}
+
+	// The test data lacks metrics required by the job data schema
+	config.Keys.Validate = true
+	defer func() { config.Keys.Validate = false }()
+	other := bytes.Replace(rawMeta, []byte(`"jobId":398765`), []byte(`"jobId":398766`), 1)
+	if _, _, err := importer.ImportJob(other, rawData); !errors.Is(err, importer.ErrInvalidJob) {
+		t.Errorf("expected ErrInvalidJob, got: %v", err)
+	}
+}
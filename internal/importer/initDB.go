@@ -49,7 +49,7 @@ func InitDB() error {
 	i := 0
 	errorOccured := 0
 
-	for jobContainer := range ar.Iter(false) {
+	for jobContainer := range ar.Iter(false, nil) {
 
 		jobMeta := jobContainer.Meta
 
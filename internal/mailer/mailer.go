@@ -0,0 +1,145 @@
+// Copyright (C) NHR@FAU, University Erlangen-Nuremberg.
+// All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+// Package mailer sends plain text emails using the mail server configured in
+// `smtp`.
+package mailer
+
+import (
+	"bytes"
+	"crypto/tls"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/smtp"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ClusterCockpit/cc-backend/pkg/log"
+	"github.com/ClusterCockpit/cc-backend/pkg/schema"
+)
+
+// Sending an email is aborted after this timeout
+const timeout = 30 * time.Second
+
+var (
+	config   *schema.SmtpConfig
+	password string
+)
+
+// Init sets the mail server. Emails are dropped if no mail server is
+// configured.
+func Init(cfg *schema.SmtpConfig) {
+	if cfg == nil || cfg.Addr == "" {
+		return
+	}
+
+	config = cfg
+	password = os.Getenv("SMTP_PASSWORD")
+	log.Infof("Mailer: sending emails via %s", cfg.Addr)
+}
+
+// Enabled returns true if a mail server is configured.
+func Enabled() bool {
+	return config != nil
+}
+
+// JobUrl returns the URL of the job view of the job with the database id in
+// the web interface, or an empty string if no base URL is configured.
+func JobUrl(id int64) string {
+	if config == nil || config.BaseUrl == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s/monitoring/job/%d", strings.TrimSuffix(config.BaseUrl, "/"), id)
+}
+
+// Send sends an email to all recipients.
+func Send(to []string, subject string, body string) error {
+	if config == nil {
+		return fmt.Errorf("MAILER > no mail server configured")
+	}
+	if len(to) == 0 {
+		return nil
+	}
+
+	host, _, err := net.SplitHostPort(config.Addr)
+	if err != nil {
+		return fmt.Errorf("MAILER > invalid address '%s': %w", config.Addr, err)
+	}
+
+	msg, err := message(config.From, to, subject, body)
+	if err != nil {
+		return err
+	}
+
+	conn, err := net.DialTimeout("tcp", config.Addr, timeout)
+	if err != nil {
+		return fmt.Errorf("MAILER > connecting to %s failed: %w", config.Addr, err)
+	}
+	conn.SetDeadline(time.Now().Add(timeout))
+
+	c, err := smtp.NewClient(conn, host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("MAILER > %w", err)
+	}
+	defer c.Close()
+
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		if err := c.StartTLS(&tls.Config{ServerName: host}); err != nil {
+			return fmt.Errorf("MAILER > STARTTLS failed: %w", err)
+		}
+	}
+	if config.Username != "" {
+		if err := c.Auth(smtp.PlainAuth("", config.Username, password, host)); err != nil {
+			return fmt.Errorf("MAILER > authentication failed: %w", err)
+		}
+	}
+
+	if err := c.Mail(config.From); err != nil {
+		return fmt.Errorf("MAILER > %w", err)
+	}
+	for _, rcpt := range to {
+		if err := c.Rcpt(rcpt); err != nil {
+			return fmt.Errorf("MAILER > recipient '%s': %w", rcpt, err)
+		}
+	}
+
+	w, err := c.Data()
+	if err != nil {
+		return fmt.Errorf("MAILER > %w", err)
+	}
+	if _, err := w.Write(msg); err != nil {
+		return fmt.Errorf("MAILER > %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("MAILER > %w", err)
+	}
+
+	return c.Quit()
+}
+
+func message(from string, to []string, subject string, body string) ([]byte, error) {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", from)
+	fmt.Fprintf(&buf, "To: %s\r\n", strings.Join(to, ", "))
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	buf.WriteString("Content-Transfer-Encoding: quoted-printable\r\n\r\n")
+
+	w := quotedprintable.NewWriter(&buf)
+	if _, err := w.Write([]byte(body)); err != nil {
+		return nil, fmt.Errorf("MAILER > encoding message failed: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return nil, fmt.Errorf("MAILER > encoding message failed: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,50 @@
+// Copyright (C) NHR@FAU, University Erlangen-Nuremberg.
+// All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+package mailer
+
+import (
+	"testing"
+
+	"github.com/ClusterCockpit/cc-backend/internal/mailer/mailertest"
+	"github.com/ClusterCockpit/cc-backend/pkg/log"
+	"github.com/ClusterCockpit/cc-backend/pkg/schema"
+)
+
+func TestSend(t *testing.T) {
+	log.Init("warn", true)
+	sink := mailertest.NewServer(t)
+
+	if err := Send([]string{"alice@example.org"}, "test", "test"); err == nil {
+		t.Error("expected error without mail server")
+	}
+
+	Init(&schema.SmtpConfig{Addr: sink.Addr, From: "cc@example.org", BaseUrl: "https://monitoring.example.org/"})
+	defer func() { config = nil }()
+
+	body := "Job 123 used 0.5 % of the peak performance.\n\nA very long line " +
+		"which has to be wrapped by the quoted-printable encoding, since lines of an email must not be longer than 78 characters.\n"
+	if err := Send([]string{"alice@example.org", "support@example.org"}, "Job 123 – efficiency", body); err != nil {
+		t.Fatal(err)
+	}
+
+	msgs := sink.Messages()
+	if len(msgs) != 1 {
+		t.Fatalf("expected 1 message, got %d", len(msgs))
+	}
+	msg := msgs[0]
+	if msg.From != "cc@example.org" || len(msg.To) != 2 || msg.To[1] != "support@example.org" {
+		t.Errorf("wrong envelope: %s -> %v", msg.From, msg.To)
+	}
+	if msg.Subject != "Job 123 – efficiency" {
+		t.Errorf("wrong subject: %q", msg.Subject)
+	}
+	if msg.Body != body {
+		t.Errorf("wrong body:\n%q\nwant:\n%q", msg.Body, body)
+	}
+
+	if url := JobUrl(42); url != "https://monitoring.example.org/monitoring/job/42" {
+		t.Errorf("wrong job url: %s", url)
+	}
+}
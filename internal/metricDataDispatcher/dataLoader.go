@@ -65,7 +65,7 @@ func LoadData(job *schema.Job,
 				}
 			}
 
-			jd, err = repo.LoadData(job, metrics, scopes, ctx, resolution)
+			jd, err = loadJobData(repo, job, metrics, scopes, ctx, resolution)
 			if err != nil {
 				if len(jd) != 0 {
 					log.Warnf("partial error: %s", err.Error())
@@ -185,6 +185,21 @@ func LoadData(job *schema.Job,
 	return data.(schema.JobData), nil
 }
 
+// Returns the node scope statistics of a job by metric and host. Derived
+// metrics are computed from the metric data.
+func LoadStats(
+	job *schema.Job,
+	metrics []string,
+	ctx context.Context,
+) (map[string]map[string]schema.MetricStatistics, error) {
+	repo, err := metricdata.GetMetricDataRepo(job.Cluster)
+	if err != nil {
+		return nil, fmt.Errorf("METRICDATA/METRICDATA > no metric data repository configured for '%s'", job.Cluster)
+	}
+
+	return loadStats(repo, job, metrics, ctx)
+}
+
 // Used for the jobsFootprint GraphQL-Query. TODO: Rename/Generalize.
 func LoadAverages(
 	job *schema.Job,
@@ -201,7 +216,7 @@ func LoadAverages(
 		return fmt.Errorf("METRICDATA/METRICDATA > no metric data repository configured for '%s'", job.Cluster)
 	}
 
-	stats, err := repo.LoadStats(job, metrics, ctx) // #166 how to handle stats for acc normalizazion?
+	stats, err := loadStats(repo, job, metrics, ctx) // #166 how to handle stats for acc normalizazion?
 	if err != nil {
 		log.Errorf("Error while loading statistics for job %v (User %v, Project %v)", job.JobID, job.User, job.Project)
 		return err
@@ -241,7 +256,7 @@ func LoadScopedJobStats(
 		return nil, fmt.Errorf("job %d: no metric data repository configured for '%s'", job.JobID, job.Cluster)
 	}
 
-	scopedStats, err := repo.LoadScopedStats(job, metrics, scopes, ctx)
+	scopedStats, err := loadScopedStats(repo, job, metrics, scopes, ctx)
 	if err != nil {
 		log.Errorf("error while loading scoped statistics for job %d (User %s, Project %s)", job.JobID, job.User, job.Project)
 		return nil, err
@@ -266,7 +281,7 @@ func LoadJobStats(
 		return data, fmt.Errorf("job %d: no metric data repository configured for '%s'", job.JobID, job.Cluster)
 	}
 
-	stats, err := repo.LoadStats(job, metrics, ctx)
+	stats, err := loadStats(repo, job, metrics, ctx)
 	if err != nil {
 		log.Errorf("error while loading statistics for job %d (User %s, Project %s)", job.JobID, job.User, job.Project)
 		return data, err
@@ -315,7 +330,7 @@ func LoadNodeData(
 		}
 	}
 
-	data, err := repo.LoadNodeData(cluster, metrics, nodes, scopes, from, to, ctx)
+	data, err := loadNodeData(repo, cluster, metrics, nodes, scopes, from, to, ctx)
 	if err != nil {
 		if len(data) != 0 {
 			log.Warnf("partial error: %s", err.Error())
@@ -352,7 +367,7 @@ func LoadNodeListData(
 		}
 	}
 
-	data, totalNodes, hasNextPage, err := repo.LoadNodeListData(cluster, subCluster, nodeFilter, metrics, scopes, resolution, from, to, page, ctx)
+	data, totalNodes, hasNextPage, err := loadNodeListData(repo, cluster, subCluster, nodeFilter, metrics, scopes, resolution, from, to, page, ctx)
 	if err != nil {
 		if len(data) != 0 {
 			log.Warnf("partial error: %s", err.Error())
@@ -0,0 +1,870 @@
+// Copyright (C) NHR@FAU, University Erlangen-Nuremberg.
+// All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+package metricDataDispatcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/ClusterCockpit/cc-backend/internal/graph/model"
+	"github.com/ClusterCockpit/cc-backend/internal/metricdata"
+	"github.com/ClusterCockpit/cc-backend/pkg/archive"
+	"github.com/ClusterCockpit/cc-backend/pkg/log"
+	"github.com/ClusterCockpit/cc-backend/pkg/schema"
+)
+
+// Derived metrics are declared in the metricConfig of a cluster with an
+// expression over other metrics, e.g. "flops_dp*2 + flops_sp". They are
+// computed per series and scope from the metrics they depend on. The
+// functions sum, avg, min and max aggregate all series of a node at the
+// native scope of their argument into one node scope series.
+
+const (
+	exprNumber   = 'n'
+	exprMetric   = 'm'
+	exprFunction = 'f'
+	exprNegate   = '~'
+)
+
+type derivedExpr struct {
+	op    byte // exprNumber, exprMetric, exprFunction, exprNegate or + - * /
+	value float64
+	name  string
+	args  []*derivedExpr
+}
+
+var derivedFunctions = map[string]bool{"sum": true, "avg": true, "min": true, "max": true}
+
+type exprParser struct {
+	input []rune
+	pos   int
+}
+
+func parseDerived(input string) (*derivedExpr, error) {
+	p := &exprParser{input: []rune(input)}
+	e, err := p.parseSum()
+	if err != nil {
+		return nil, err
+	}
+	if p.skipSpace(); p.pos < len(p.input) {
+		return nil, fmt.Errorf("unexpected '%c' at position %d", p.input[p.pos], p.pos)
+	}
+	return e, nil
+}
+
+func (p *exprParser) skipSpace() {
+	for p.pos < len(p.input) && unicode.IsSpace(p.input[p.pos]) {
+		p.pos++
+	}
+}
+
+func (p *exprParser) peek() rune {
+	if p.skipSpace(); p.pos < len(p.input) {
+		return p.input[p.pos]
+	}
+	return 0
+}
+
+func (p *exprParser) parseSum() (*derivedExpr, error) {
+	lhs, err := p.parseProduct()
+	if err != nil {
+		return nil, err
+	}
+	for c := p.peek(); c == '+' || c == '-'; c = p.peek() {
+		p.pos++
+		rhs, err := p.parseProduct()
+		if err != nil {
+			return nil, err
+		}
+		lhs = &derivedExpr{op: byte(c), args: []*derivedExpr{lhs, rhs}}
+	}
+	return lhs, nil
+}
+
+func (p *exprParser) parseProduct() (*derivedExpr, error) {
+	lhs, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for c := p.peek(); c == '*' || c == '/'; c = p.peek() {
+		p.pos++
+		rhs, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		lhs = &derivedExpr{op: byte(c), args: []*derivedExpr{lhs, rhs}}
+	}
+	return lhs, nil
+}
+
+func (p *exprParser) parseUnary() (*derivedExpr, error) {
+	if p.peek() == '-' {
+		p.pos++
+		e, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &derivedExpr{op: exprNegate, args: []*derivedExpr{e}}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *exprParser) parsePrimary() (*derivedExpr, error) {
+	c := p.peek()
+	switch {
+	case c == '(':
+		p.pos++
+		e, err := p.parseSum()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek() != ')' {
+			return nil, fmt.Errorf("missing ')' at position %d", p.pos)
+		}
+		p.pos++
+		return e, nil
+	case unicode.IsDigit(c) || c == '.':
+		start := p.pos
+		for p.pos < len(p.input) && (unicode.IsDigit(p.input[p.pos]) || p.input[p.pos] == '.') {
+			p.pos++
+		}
+		if p.pos < len(p.input) && (p.input[p.pos] == 'e' || p.input[p.pos] == 'E') {
+			p.pos++
+			if p.pos < len(p.input) && (p.input[p.pos] == '+' || p.input[p.pos] == '-') {
+				p.pos++
+			}
+			for p.pos < len(p.input) && unicode.IsDigit(p.input[p.pos]) {
+				p.pos++
+			}
+		}
+		v, err := strconv.ParseFloat(string(p.input[start:p.pos]), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number at position %d", start)
+		}
+		return &derivedExpr{op: exprNumber, value: v}, nil
+	case unicode.IsLetter(c) || c == '_':
+		start := p.pos
+		for p.pos < len(p.input) && (unicode.IsLetter(p.input[p.pos]) || unicode.IsDigit(p.input[p.pos]) || p.input[p.pos] == '_') {
+			p.pos++
+		}
+		name := string(p.input[start:p.pos])
+		if p.peek() != '(' {
+			return &derivedExpr{op: exprMetric, name: name}, nil
+		}
+		if !derivedFunctions[name] {
+			return nil, fmt.Errorf("unknown function '%s'", name)
+		}
+		p.pos++
+		arg, err := p.parseSum()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek() != ')' {
+			return nil, fmt.Errorf("missing ')' at position %d", p.pos)
+		}
+		p.pos++
+		return &derivedExpr{op: exprFunction, name: name, args: []*derivedExpr{arg}}, nil
+	case c == 0:
+		return nil, errors.New("unexpected end of expression")
+	default:
+		return nil, fmt.Errorf("unexpected '%c' at position %d", c, p.pos)
+	}
+}
+
+// Calls fn for every metric referenced in the expression, inFunction is
+// set for metrics in the argument of a function.
+func (e *derivedExpr) walk(inFunction bool, fn func(metric string, inFunction bool)) {
+	if e.op == exprMetric {
+		fn(e.name, inFunction)
+	}
+	for _, arg := range e.args {
+		arg.walk(inFunction || e.op == exprFunction, fn)
+	}
+}
+
+type derivedMetric struct {
+	mc   *schema.MetricConfig
+	expr *derivedExpr
+}
+
+var (
+	derivedLock  sync.Mutex
+	derivedCache = map[string]*derivedMetric{}
+)
+
+// Returns nil if the metric is not derived or its expression is invalid
+func getDerived(cluster, metric string) *derivedMetric {
+	mc := archive.GetMetricConfig(cluster, metric)
+	if mc == nil || mc.Derived == "" {
+		return nil
+	}
+
+	derivedLock.Lock()
+	defer derivedLock.Unlock()
+
+	key := cluster + "/" + metric
+	if dm, ok := derivedCache[key]; ok {
+		return dm
+	}
+
+	expr, err := parseDerived(mc.Derived)
+	if err != nil {
+		log.Errorf("Invalid expression '%s' of derived metric %s on cluster %s: %s", mc.Derived, metric, cluster, err.Error())
+		derivedCache[key] = nil
+		return nil
+	}
+	derivedCache[key] = &derivedMetric{mc: mc, expr: expr}
+	return derivedCache[key]
+}
+
+// Splits metrics in metrics loaded from the repository and derived metrics
+func splitDerived(cluster string, metrics []string) (stored []string, derived []string) {
+	for _, metric := range metrics {
+		if getDerived(cluster, metric) != nil {
+			derived = append(derived, metric)
+		} else {
+			stored = append(stored, metric)
+		}
+	}
+	return stored, derived
+}
+
+// Metrics required to compute a list of metrics
+type derivedPlan struct {
+	// Metrics to load at the requested scopes
+	stored []string
+	// Metrics to load at their native scope as they are aggregated by a
+	// function
+	native map[schema.MetricScope][]string
+	// Derived metrics in order of evaluation
+	derived []*derivedMetric
+	// Derived metrics that are aggregated by a function
+	derivedNative map[string]bool
+}
+
+func planDerived(cluster string, metrics []string) *derivedPlan {
+	plan := &derivedPlan{
+		native:        make(map[schema.MetricScope][]string),
+		derivedNative: make(map[string]bool),
+	}
+	seen := make(map[string]bool)
+	seenNative := make(map[string]bool)
+	done := make(map[string]bool)
+	visiting := make(map[string]bool)
+
+	var visit func(metric string, inFunction bool)
+	visit = func(metric string, inFunction bool) {
+		dm := getDerived(cluster, metric)
+		if dm == nil {
+			if inFunction {
+				if mc := archive.GetMetricConfig(cluster, metric); mc != nil && !seenNative[metric] {
+					seenNative[metric] = true
+					plan.native[mc.Scope] = append(plan.native[mc.Scope], metric)
+				}
+			} else if !seen[metric] {
+				seen[metric] = true
+				plan.stored = append(plan.stored, metric)
+			}
+			return
+		}
+
+		if inFunction {
+			plan.derivedNative[metric] = true
+		}
+		if visiting[metric] {
+			log.Errorf("Derived metric %s on cluster %s depends on itself", metric, cluster)
+			return
+		}
+		visiting[metric] = true
+		dm.expr.walk(inFunction, visit)
+		visiting[metric] = false
+		if !done[metric] {
+			done[metric] = true
+			plan.derived = append(plan.derived, dm)
+		}
+	}
+
+	for _, metric := range metrics {
+		visit(metric, false)
+	}
+
+	return plan
+}
+
+var errUnavailable = errors.New("operand not available")
+
+type derivedValue struct {
+	constant bool
+	value    float64
+	timestep int
+	series   map[string]schema.Series
+}
+
+func seriesKey(s *schema.Series) string {
+	if s.Id == nil {
+		return s.Hostname
+	}
+	return s.Hostname + "/" + *s.Id
+}
+
+func applyOp(op byte, a, b schema.Float) schema.Float {
+	switch op {
+	case '+':
+		return a + b
+	case '-':
+		return a - b
+	case '*':
+		return a * b
+	case '/':
+		if b == 0 {
+			return schema.NaN
+		}
+		return a / b
+	}
+	return schema.NaN
+}
+
+func aggregateValues(fn string, values []schema.Float) schema.Float {
+	res, n := 0.0, 0
+	for _, v := range values {
+		if v.IsNaN() {
+			continue
+		}
+		switch {
+		case n == 0:
+			res = float64(v)
+		case fn == "min":
+			res = math.Min(res, float64(v))
+		case fn == "max":
+			res = math.Max(res, float64(v))
+		default:
+			res += float64(v)
+		}
+		n++
+	}
+	if n == 0 {
+		return schema.NaN
+	}
+	if fn == "avg" {
+		res /= float64(n)
+	}
+	return schema.Float(res)
+}
+
+type derivedEvaluator struct {
+	cluster string
+	// Metrics at the requested scopes, derived metrics are added
+	data schema.JobData
+	// Metrics at their native scope
+	native schema.JobData
+}
+
+func (ev *derivedEvaluator) lookup(metric string, scope schema.MetricScope) *schema.JobMetric {
+	if jm, ok := ev.data[metric][scope]; ok {
+		return jm
+	}
+	return ev.native[metric][scope]
+}
+
+func (ev *derivedEvaluator) eval(e *derivedExpr, scope schema.MetricScope) (*derivedValue, error) {
+	switch e.op {
+	case exprNumber:
+		return &derivedValue{constant: true, value: e.value}, nil
+	case exprMetric:
+		jm := ev.lookup(e.name, scope)
+		if jm == nil {
+			return nil, errUnavailable
+		}
+		v := &derivedValue{timestep: jm.Timestep, series: make(map[string]schema.Series, len(jm.Series))}
+		for _, s := range jm.Series {
+			v.series[seriesKey(&s)] = s
+		}
+		return v, nil
+	case exprNegate:
+		v, err := ev.eval(e.args[0], scope)
+		if err != nil {
+			return nil, err
+		}
+		return ev.combine('*', &derivedValue{constant: true, value: -1}, v)
+	case exprFunction:
+		return ev.aggregate(e, scope)
+	default:
+		lhs, err := ev.eval(e.args[0], scope)
+		if err != nil {
+			return nil, err
+		}
+		rhs, err := ev.eval(e.args[1], scope)
+		if err != nil {
+			return nil, err
+		}
+		return ev.combine(e.op, lhs, rhs)
+	}
+}
+
+func (ev *derivedEvaluator) combine(op byte, lhs, rhs *derivedValue) (*derivedValue, error) {
+	if lhs.constant && rhs.constant {
+		return &derivedValue{constant: true, value: float64(applyOp(op, schema.Float(lhs.value), schema.Float(rhs.value)))}, nil
+	}
+	if !lhs.constant && !rhs.constant && lhs.timestep != rhs.timestep {
+		return nil, errUnavailable
+	}
+
+	res := &derivedValue{timestep: lhs.timestep, series: make(map[string]schema.Series)}
+	if lhs.constant {
+		res.timestep = rhs.timestep
+	}
+	keys := lhs.series
+	if lhs.constant {
+		keys = rhs.series
+	}
+	for key, s := range keys {
+		a, b := lhs.series[key], rhs.series[key]
+		if (!lhs.constant && a.Data == nil) || (!rhs.constant && b.Data == nil) {
+			continue
+		}
+
+		n := max(len(a.Data), len(b.Data))
+		if !lhs.constant && !rhs.constant {
+			n = min(len(a.Data), len(b.Data))
+		}
+		data := make([]schema.Float, n)
+		for i := range data {
+			x, y := schema.Float(lhs.value), schema.Float(rhs.value)
+			if !lhs.constant {
+				x = a.Data[i]
+			}
+			if !rhs.constant {
+				y = b.Data[i]
+			}
+			data[i] = applyOp(op, x, y)
+		}
+		res.series[key] = schema.Series{Hostname: s.Hostname, Id: s.Id, Data: data}
+	}
+	return res, nil
+}
+
+// Aggregates all series of each node at the native scope of the argument
+func (ev *derivedEvaluator) aggregate(e *derivedExpr, scope schema.MetricScope) (*derivedValue, error) {
+	if scope != schema.MetricScopeNode {
+		return nil, errUnavailable
+	}
+
+	var nativeScope schema.MetricScope
+	e.args[0].walk(true, func(metric string, _ bool) {
+		if mc := archive.GetMetricConfig(ev.cluster, metric); mc != nil && nativeScope == "" {
+			nativeScope = mc.Scope
+		}
+	})
+	if nativeScope == "" {
+		return nil, errUnavailable
+	}
+
+	v, err := ev.eval(e.args[0], nativeScope)
+	if err != nil {
+		return nil, err
+	}
+	if v.constant {
+		return v, nil
+	}
+
+	hosts := make(map[string][]schema.Series)
+	for _, s := range v.series {
+		hosts[s.Hostname] = append(hosts[s.Hostname], s)
+	}
+
+	res := &derivedValue{timestep: v.timestep, series: make(map[string]schema.Series, len(hosts))}
+	for host, series := range hosts {
+		n := 0
+		for _, s := range series {
+			n = max(n, len(s.Data))
+		}
+		data := make([]schema.Float, n)
+		values := make([]schema.Float, 0, len(series))
+		for i := range data {
+			values = values[:0]
+			for _, s := range series {
+				if i < len(s.Data) {
+					values = append(values, s.Data[i])
+				}
+			}
+			data[i] = aggregateValues(e.name, values)
+		}
+		res.series[host] = schema.Series{Hostname: host, Data: data}
+	}
+	return res, nil
+}
+
+func (ev *derivedEvaluator) evaluate(dm *derivedMetric, scope schema.MetricScope) *schema.JobMetric {
+	v, err := ev.eval(dm.expr, scope)
+	if err != nil || v.constant || len(v.series) == 0 {
+		return nil
+	}
+
+	jm := &schema.JobMetric{
+		Unit:     dm.mc.Unit,
+		Timestep: v.timestep,
+		Series:   make([]schema.Series, 0, len(v.series)),
+	}
+	for _, s := range v.series {
+		min, max, avg := metricdata.MinMaxMean(s.Data)
+		if !math.IsNaN(avg) {
+			s.Statistics = schema.MetricStatistics{Min: min, Max: max, Avg: avg}
+		}
+		jm.Series = append(jm.Series, s)
+	}
+	sort.Slice(jm.Series, func(i, j int) bool {
+		return seriesKey(&jm.Series[i]) < seriesKey(&jm.Series[j])
+	})
+
+	return jm
+}
+
+// Computes all derived metrics of the plan at the requested scopes and adds
+// them to data. The result only contains the requested metrics.
+func evaluateDerived(
+	cluster string,
+	plan *derivedPlan,
+	metrics []string,
+	scopes []schema.MetricScope,
+	data, native schema.JobData,
+) schema.JobData {
+	ev := &derivedEvaluator{cluster: cluster, data: data, native: native}
+	if ev.data == nil {
+		ev.data = make(schema.JobData)
+	}
+	if ev.native == nil {
+		ev.native = make(schema.JobData)
+	}
+
+	for _, dm := range plan.derived {
+		name := dm.mc.Name
+		for _, scope := range scopes {
+			if jm := ev.evaluate(dm, scope); jm != nil {
+				if ev.data[name] == nil {
+					ev.data[name] = make(map[schema.MetricScope]*schema.JobMetric)
+				}
+				ev.data[name][scope] = jm
+			}
+		}
+		if plan.derivedNative[name] {
+			if jm := ev.evaluate(dm, dm.mc.Scope); jm != nil {
+				if ev.native[name] == nil {
+					ev.native[name] = make(map[schema.MetricScope]*schema.JobMetric)
+				}
+				ev.native[name][dm.mc.Scope] = jm
+			}
+		}
+	}
+
+	res := make(schema.JobData, len(metrics))
+	for _, metric := range metrics {
+		if scopes, ok := ev.data[metric]; ok {
+			res[metric] = scopes
+		}
+	}
+	return res
+}
+
+func mergeJobData(dst, src schema.JobData) {
+	for metric, scopes := range src {
+		if dst[metric] == nil {
+			dst[metric] = make(map[schema.MetricScope]*schema.JobMetric, len(scopes))
+		}
+		for scope, jm := range scopes {
+			dst[metric][scope] = jm
+		}
+	}
+}
+
+// Loads the requested metrics of a job from the repository and computes the
+// derived metrics among them.
+func loadJobData(
+	repo metricdata.MetricDataRepository,
+	job *schema.Job,
+	metrics []string,
+	scopes []schema.MetricScope,
+	ctx context.Context,
+	resolution int,
+) (schema.JobData, error) {
+	plan := planDerived(job.Cluster, metrics)
+	if len(plan.derived) == 0 {
+		return repo.LoadData(job, metrics, scopes, ctx, resolution)
+	}
+
+	var data schema.JobData
+	var err error
+	if len(plan.stored) > 0 {
+		data, err = repo.LoadData(job, plan.stored, scopes, ctx, resolution)
+		if err != nil && len(data) == 0 {
+			return nil, err
+		}
+	}
+
+	native := make(schema.JobData)
+	for scope, nativeMetrics := range plan.native {
+		nd, nerr := repo.LoadData(job, nativeMetrics, []schema.MetricScope{scope}, ctx, resolution)
+		if nerr != nil {
+			log.Warnf("Error while loading operands of derived metrics for job %d: %s", job.JobID, nerr.Error())
+		}
+		mergeJobData(native, nd)
+	}
+
+	return evaluateDerived(job.Cluster, plan, metrics, scopes, data, native), err
+}
+
+// Returns the statistics of the node scope series of data by metric and host
+func nodeStatistics(data schema.JobData, stats map[string]map[string]schema.MetricStatistics) {
+	for metric, scopes := range data {
+		jm, ok := scopes[schema.MetricScopeNode]
+		if !ok {
+			continue
+		}
+		stats[metric] = make(map[string]schema.MetricStatistics, len(jm.Series))
+		for _, s := range jm.Series {
+			stats[metric][s.Hostname] = s.Statistics
+		}
+	}
+}
+
+// Returns the scope of the node data series jms loaded for scopes. Series of
+// unknown scope are skipped.
+func nodeDataScope(jm *schema.JobMetric, scopes []schema.MetricScope) (schema.MetricScope, bool) {
+	if len(scopes) == 1 {
+		return scopes[0], true
+	}
+	if len(jm.Series) > 0 && jm.Series[0].Id == nil {
+		return schema.MetricScopeNode, true
+	}
+	return "", false
+}
+
+func nodeDataToJobData(
+	data map[string]map[string][]*schema.JobMetric,
+	scopes []schema.MetricScope,
+) map[string]schema.JobData {
+	res := make(map[string]schema.JobData, len(data))
+	for host, metrics := range data {
+		res[host] = make(schema.JobData, len(metrics))
+		for metric, jms := range metrics {
+			for _, jm := range jms {
+				if scope, ok := nodeDataScope(jm, scopes); ok {
+					if res[host][metric] == nil {
+						res[host][metric] = make(map[schema.MetricScope]*schema.JobMetric)
+					}
+					res[host][metric][scope] = jm
+				}
+			}
+		}
+	}
+	return res
+}
+
+func loadStats(
+	repo metricdata.MetricDataRepository,
+	job *schema.Job,
+	metrics []string,
+	ctx context.Context,
+) (map[string]map[string]schema.MetricStatistics, error) {
+	stored, derived := splitDerived(job.Cluster, metrics)
+	if len(derived) == 0 {
+		return repo.LoadStats(job, metrics, ctx)
+	}
+
+	stats := make(map[string]map[string]schema.MetricStatistics, len(metrics))
+	if len(stored) > 0 {
+		var err error
+		if stats, err = repo.LoadStats(job, stored, ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	data, err := loadJobData(repo, job, derived, []schema.MetricScope{schema.MetricScopeNode}, ctx, 0)
+	if err != nil && len(data) == 0 {
+		return nil, err
+	}
+	nodeStatistics(data, stats)
+
+	return stats, nil
+}
+
+func loadScopedStats(
+	repo metricdata.MetricDataRepository,
+	job *schema.Job,
+	metrics []string,
+	scopes []schema.MetricScope,
+	ctx context.Context,
+) (schema.ScopedJobStats, error) {
+	stored, derived := splitDerived(job.Cluster, metrics)
+	if len(derived) == 0 {
+		return repo.LoadScopedStats(job, metrics, scopes, ctx)
+	}
+
+	scopedStats := make(schema.ScopedJobStats, len(metrics))
+	if len(stored) > 0 {
+		var err error
+		if scopedStats, err = repo.LoadScopedStats(job, stored, scopes, ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	data, err := loadJobData(repo, job, derived, scopes, ctx, 0)
+	if err != nil && len(data) == 0 {
+		return nil, err
+	}
+	for metric, scopes := range data {
+		scopedStats[metric] = make(map[schema.MetricScope][]*schema.ScopedStats, len(scopes))
+		for scope, jm := range scopes {
+			for i := range jm.Series {
+				scopedStats[metric][scope] = append(scopedStats[metric][scope], &schema.ScopedStats{
+					Hostname: jm.Series[i].Hostname,
+					Id:       jm.Series[i].Id,
+					Data:     &jm.Series[i].Statistics,
+				})
+			}
+		}
+	}
+
+	return scopedStats, nil
+}
+
+func loadNodeData(
+	repo metricdata.MetricDataRepository,
+	cluster string,
+	metrics, nodes []string,
+	scopes []schema.MetricScope,
+	from, to time.Time,
+	ctx context.Context,
+) (map[string]map[string][]*schema.JobMetric, error) {
+	plan := planDerived(cluster, metrics)
+	if len(plan.derived) == 0 {
+		return repo.LoadNodeData(cluster, metrics, nodes, scopes, from, to, ctx)
+	}
+
+	var data map[string]map[string][]*schema.JobMetric
+	var err error
+	if len(plan.stored) > 0 {
+		data, err = repo.LoadNodeData(cluster, plan.stored, nodes, scopes, from, to, ctx)
+		if err != nil && len(data) == 0 {
+			return nil, err
+		}
+	}
+
+	native := make(map[string]schema.JobData)
+	for scope, nativeMetrics := range plan.native {
+		nativeScopes := []schema.MetricScope{scope}
+		nd, nerr := repo.LoadNodeData(cluster, nativeMetrics, nodes, nativeScopes, from, to, ctx)
+		if nerr != nil {
+			log.Warnf("Error while loading operands of derived metrics for cluster %s: %s", cluster, nerr.Error())
+		}
+		for host, jd := range nodeDataToJobData(nd, nativeScopes) {
+			if native[host] == nil {
+				native[host] = make(schema.JobData)
+			}
+			mergeJobData(native[host], jd)
+		}
+	}
+
+	if len(scopes) == 0 {
+		scopes = []schema.MetricScope{schema.MetricScopeNode}
+	}
+	hostData := nodeDataToJobData(data, scopes)
+	for host := range native {
+		if hostData[host] == nil {
+			hostData[host] = make(schema.JobData)
+		}
+	}
+
+	res := make(map[string]map[string][]*schema.JobMetric, len(hostData))
+	for host, jd := range hostData {
+		res[host] = make(map[string][]*schema.JobMetric)
+		for metric, jms := range data[host] {
+			if slices.Contains(metrics, metric) {
+				res[host][metric] = jms
+			}
+		}
+		for metric, scopes := range evaluateDerived(cluster, plan, metrics, scopes, jd, native[host]) {
+			if getDerived(cluster, metric) == nil {
+				continue
+			}
+			for _, jm := range scopes {
+				res[host][metric] = append(res[host][metric], jm)
+			}
+		}
+	}
+
+	return res, err
+}
+
+func loadNodeListData(
+	repo metricdata.MetricDataRepository,
+	cluster, subCluster, nodeFilter string,
+	metrics []string,
+	scopes []schema.MetricScope,
+	resolution int,
+	from, to time.Time,
+	page *model.PageRequest,
+	ctx context.Context,
+) (map[string]schema.JobData, int, bool, error) {
+	plan := planDerived(cluster, metrics)
+	if len(plan.derived) == 0 {
+		return repo.LoadNodeListData(cluster, subCluster, nodeFilter, metrics, scopes, resolution, from, to, page, ctx)
+	}
+
+	var data map[string]schema.JobData
+	var totalNodes int
+	var hasNextPage bool
+	var err error
+	if len(plan.stored) > 0 {
+		data, totalNodes, hasNextPage, err = repo.LoadNodeListData(cluster, subCluster, nodeFilter,
+			plan.stored, scopes, resolution, from, to, page, ctx)
+		if err != nil && len(data) == 0 {
+			return nil, totalNodes, hasNextPage, err
+		}
+	}
+	if data == nil {
+		data = make(map[string]schema.JobData)
+	}
+
+	native := make(map[string]schema.JobData)
+	for scope, nativeMetrics := range plan.native {
+		nd, nTotalNodes, nHasNextPage, nerr := repo.LoadNodeListData(cluster, subCluster, nodeFilter,
+			nativeMetrics, []schema.MetricScope{scope}, resolution, from, to, page, ctx)
+		if nerr != nil {
+			log.Warnf("Error while loading operands of derived metrics for cluster %s: %s", cluster, nerr.Error())
+		}
+		// Without stored metrics, the page is the one of the operands
+		if len(plan.stored) == 0 {
+			totalNodes, hasNextPage = max(totalNodes, nTotalNodes), hasNextPage || nHasNextPage
+		}
+		for host, jd := range nd {
+			if native[host] == nil {
+				native[host] = make(schema.JobData)
+			}
+			mergeJobData(native[host], jd)
+		}
+	}
+
+	for host := range native {
+		if data[host] == nil {
+			data[host] = make(schema.JobData)
+		}
+	}
+	if len(scopes) == 0 {
+		scopes = []schema.MetricScope{schema.MetricScopeNode}
+	}
+	for host, jd := range data {
+		data[host] = evaluateDerived(cluster, plan, metrics, scopes, jd, native[host])
+	}
+
+	return data, totalNodes, hasNextPage, err
+}
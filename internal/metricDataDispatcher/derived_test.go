@@ -0,0 +1,303 @@
+// Copyright (C) NHR@FAU, University Erlangen-Nuremberg.
+// All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+package metricDataDispatcher
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ClusterCockpit/cc-backend/internal/graph/model"
+	"github.com/ClusterCockpit/cc-backend/pkg/archive"
+	"github.com/ClusterCockpit/cc-backend/pkg/log"
+	"github.com/ClusterCockpit/cc-backend/pkg/schema"
+)
+
+// Prefix notation of the expression
+func (e *derivedExpr) String() string {
+	switch e.op {
+	case exprNumber:
+		return fmt.Sprint(e.value)
+	case exprMetric:
+		return e.name
+	case exprFunction:
+		return fmt.Sprintf("(%s %s)", e.name, e.args[0])
+	default:
+		args := make([]string, 0, len(e.args))
+		for _, arg := range e.args {
+			args = append(args, arg.String())
+		}
+		return fmt.Sprintf("(%c %s)", e.op, strings.Join(args, " "))
+	}
+}
+
+func TestParseDerived(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"flops_dp*2 + flops_sp", "(+ (* flops_dp 2) flops_sp)"},
+		{"1 + 2 * 3", "(+ 1 (* 2 3))"},
+		{"(1 + 2) * 3", "(* (+ 1 2) 3)"},
+		{"8 / 2 / 2", "(/ (/ 8 2) 2)"},
+		{"1 - 2 - 3", "(- (- 1 2) 3)"},
+		{"-a * b", "(* (~ a) b)"},
+		{"a - -b", "(- a (~ b))"},
+		{"--a", "(~ (~ a))"},
+		{"sum(mem_bw) / 2", "(/ (sum mem_bw) 2)"},
+		{"avg(a + b)", "(avg (+ a b))"},
+		{"1e3 + 2.5E-1 + .5", "(+ (+ 1000 0.25) 0.5)"},
+	}
+	for _, tt := range tests {
+		e, err := parseDerived(tt.input)
+		if err != nil {
+			t.Errorf("%q: %s", tt.input, err.Error())
+			continue
+		}
+		if got := e.String(); got != tt.want {
+			t.Errorf("%q: got %s, want %s", tt.input, got, tt.want)
+		}
+	}
+
+	invalid := []string{"", "a +", "(a", "a b", "a)", "foo(a)", "sum(a", "sum()", "1..2", "a # b", "*a"}
+	for _, input := range invalid {
+		if _, err := parseDerived(input); err == nil {
+			t.Errorf("%q: expected error", input)
+		}
+	}
+}
+
+func TestEvalConstant(t *testing.T) {
+	tests := []struct {
+		input string
+		want  float64
+	}{
+		{"1 + 2 * 3", 7},
+		{"(1 + 2) * 3", 9},
+		{"8 / 2 / 2", 2},
+		{"1 - 2 - 3", -4},
+		{"-2 * -3", 6},
+		{"2 - -2", 4},
+	}
+	ev := &derivedEvaluator{}
+	for _, tt := range tests {
+		e, err := parseDerived(tt.input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		v, err := ev.eval(e, schema.MetricScopeNode)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !v.constant || v.value != tt.want {
+			t.Errorf("%q: got %v, want %v", tt.input, v.value, tt.want)
+		}
+	}
+
+	if !applyOp('/', 1, 0).IsNaN() {
+		t.Error("division by zero is not NaN")
+	}
+}
+
+// Repository returning fixed data for the requested metrics and scopes
+type testRepository struct {
+	data  schema.JobData
+	calls [][]string
+}
+
+func (r *testRepository) Init(rawConfig json.RawMessage) error {
+	return nil
+}
+
+func (r *testRepository) LoadData(job *schema.Job, metrics []string, scopes []schema.MetricScope, ctx context.Context, resolution int) (schema.JobData, error) {
+	r.calls = append(r.calls, metrics)
+	if len(metrics) == 0 {
+		return nil, fmt.Errorf("no metrics requested")
+	}
+
+	res := make(schema.JobData)
+	for _, metric := range metrics {
+		for _, scope := range scopes {
+			if jm, ok := r.data[metric][scope]; ok {
+				if res[metric] == nil {
+					res[metric] = make(map[schema.MetricScope]*schema.JobMetric)
+				}
+				res[metric][scope] = jm
+			}
+		}
+	}
+	return res, nil
+}
+
+func (r *testRepository) LoadStats(job *schema.Job, metrics []string, ctx context.Context) (map[string]map[string]schema.MetricStatistics, error) {
+	return nil, nil
+}
+
+func (r *testRepository) LoadScopedStats(job *schema.Job, metrics []string, scopes []schema.MetricScope, ctx context.Context) (schema.ScopedJobStats, error) {
+	return nil, nil
+}
+
+func (r *testRepository) LoadNodeData(cluster string, metrics, nodes []string, scopes []schema.MetricScope, from, to time.Time, ctx context.Context) (map[string]map[string][]*schema.JobMetric, error) {
+	return nil, nil
+}
+
+func (r *testRepository) LoadNodeListData(cluster, subCluster, nodeFilter string, metrics []string, scopes []schema.MetricScope, resolution int, from, to time.Time, page *model.PageRequest, ctx context.Context) (map[string]schema.JobData, int, bool, error) {
+	data, err := r.LoadData(nil, metrics, scopes, ctx, resolution)
+	if err != nil {
+		return nil, 0, false, err
+	}
+	return map[string]schema.JobData{"host1": data}, 1, false, nil
+}
+
+func setup(t *testing.T) *testRepository {
+	log.Init("warn", true)
+
+	clusters := archive.Clusters
+	archive.Clusters = []*schema.Cluster{{
+		Name: "derivedcluster",
+		MetricConfig: []*schema.MetricConfig{
+			{Name: "flops_dp", Scope: schema.MetricScopeCore},
+			{Name: "flops_sp", Scope: schema.MetricScopeCore},
+			{Name: "flops_any", Scope: schema.MetricScopeCore, Derived: "flops_dp*2 + flops_sp"},
+			{Name: "neg_dp", Scope: schema.MetricScopeCore, Derived: "-flops_dp"},
+			{Name: "node_dp", Scope: schema.MetricScopeNode, Derived: "sum(flops_dp)"},
+			{Name: "node_avg", Scope: schema.MetricScopeNode, Derived: "avg(flops_any)"},
+			{Name: "ghost", Scope: schema.MetricScopeNode, Derived: "unknown + 1"},
+			{Name: "cycle_a", Scope: schema.MetricScopeNode, Derived: "cycle_b + 1"},
+			{Name: "cycle_b", Scope: schema.MetricScopeNode, Derived: "cycle_a * 2"},
+			{Name: "broken", Scope: schema.MetricScopeNode, Derived: "flops_dp +"},
+		},
+	}}
+	t.Cleanup(func() {
+		archive.Clusters = clusters
+		derivedCache = map[string]*derivedMetric{}
+	})
+
+	cpu0, cpu1 := "0", "1"
+	nan := schema.NaN
+	return &testRepository{data: schema.JobData{
+		"flops_dp": {schema.MetricScopeCore: {Timestep: 60, Series: []schema.Series{
+			{Hostname: "host1", Id: &cpu0, Data: []schema.Float{1, 2, nan}},
+			{Hostname: "host1", Id: &cpu1, Data: []schema.Float{3, 4, 5}},
+		}}},
+		"flops_sp": {schema.MetricScopeCore: {Timestep: 60, Series: []schema.Series{
+			{Hostname: "host1", Id: &cpu0, Data: []schema.Float{10, 10, 10}},
+			{Hostname: "host1", Id: &cpu1, Data: []schema.Float{1, 1, 1}},
+		}}},
+	}}
+}
+
+func seriesData(t *testing.T, data schema.JobData, metric string, scope schema.MetricScope) map[string]string {
+	t.Helper()
+	jm, ok := data[metric][scope]
+	if !ok {
+		t.Fatalf("%s at scope %s missing", metric, scope)
+	}
+
+	res := make(map[string]string, len(jm.Series))
+	for _, s := range jm.Series {
+		res[seriesKey(&s)] = fmt.Sprint(s.Data)
+	}
+	return res
+}
+
+func TestLoadJobData(t *testing.T) {
+	repo := setup(t)
+	job := &schema.Job{BaseJob: schema.BaseJob{Cluster: "derivedcluster"}}
+	scopes := []schema.MetricScope{schema.MetricScopeCore, schema.MetricScopeNode}
+
+	data, err := loadJobData(repo, job, []string{"flops_any", "neg_dp", "node_dp", "node_avg"}, scopes, context.Background(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		metric string
+		scope  schema.MetricScope
+		want   map[string]string
+	}{
+		// NaN operands result in NaN
+		{"flops_any", schema.MetricScopeCore, map[string]string{"host1/0": "[12 14 NaN]", "host1/1": "[7 9 11]"}},
+		{"neg_dp", schema.MetricScopeCore, map[string]string{"host1/0": "[-1 -2 NaN]", "host1/1": "[-3 -4 -5]"}},
+		// Aggregation skips NaN values
+		{"node_dp", schema.MetricScopeNode, map[string]string{"host1": "[4 6 5]"}},
+		{"node_avg", schema.MetricScopeNode, map[string]string{"host1": "[9.5 11.5 11]"}},
+	}
+	for _, tt := range tests {
+		got := seriesData(t, data, tt.metric, tt.scope)
+		if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.metric, got, tt.want)
+		}
+	}
+
+	// The operands are not available at node scope, and functions only
+	// aggregate to node scope
+	if _, ok := data["flops_any"][schema.MetricScopeNode]; ok {
+		t.Error("flops_any at node scope without operands")
+	}
+	if _, ok := data["node_dp"][schema.MetricScopeCore]; ok {
+		t.Error("node_dp at core scope")
+	}
+	if len(data) != 4 {
+		t.Errorf("unexpected metrics: %v", data)
+	}
+}
+
+func TestLoadJobDataInvalid(t *testing.T) {
+	repo := setup(t)
+	job := &schema.Job{BaseJob: schema.BaseJob{Cluster: "derivedcluster"}}
+
+	// Unknown and cyclic references and invalid expressions result in no data
+	done := make(chan struct{})
+	var data schema.JobData
+	var err error
+	go func() {
+		data, err = loadJobData(repo, job, []string{"ghost", "cycle_a", "broken", "flops_dp"},
+			[]schema.MetricScope{schema.MetricScopeCore}, context.Background(), 0)
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("cyclic reference not detected")
+	}
+
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := data["flops_dp"]; !ok || len(data) != 1 {
+		t.Errorf("unexpected metrics: %v", data)
+	}
+
+	plan := planDerived("derivedcluster", []string{"cycle_a"})
+	if len(plan.derived) != 2 {
+		t.Errorf("unexpected plan: %#v", plan)
+	}
+}
+
+func TestLoadNodeListData(t *testing.T) {
+	repo := setup(t)
+
+	// Only derived metrics: no stored metrics are loaded
+	data, totalNodes, hasNextPage, err := loadNodeListData(repo, "derivedcluster", "", "",
+		[]string{"node_dp"}, []schema.MetricScope{schema.MetricScopeNode}, 0, time.Now(), time.Now(), nil, context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if totalNodes != 1 || hasNextPage {
+		t.Errorf("wrong page: %d nodes, next page %v", totalNodes, hasNextPage)
+	}
+	if got := fmt.Sprint(seriesData(t, data["host1"], "node_dp", schema.MetricScopeNode)); got != "map[host1:[4 6 5]]" {
+		t.Errorf("wrong data: %s", got)
+	}
+	for _, metrics := range repo.calls {
+		if len(metrics) == 0 {
+			t.Error("repository called without metrics")
+		}
+	}
+}
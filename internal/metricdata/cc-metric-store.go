@@ -79,6 +79,21 @@ type ApiMetricData struct {
 	Max        schema.Float   `json:"max"`
 }
 
+// Returns the data padded with NaN so that it starts at from. The
+// cc-metric-store only returns the part of the requested range it holds.
+func (d *ApiMetricData) dataFrom(from int64) []schema.Float {
+	if d.Resolution <= 0 || d.From-from < int64(d.Resolution) {
+		return d.Data
+	}
+
+	n := int((d.From - from) / int64(d.Resolution))
+	data := make([]schema.Float, n, n+len(d.Data))
+	for i := range data {
+		data[i] = schema.NaN
+	}
+	return append(data, d.Data...)
+}
+
 func (ccms *CCMetricStore) Init(rawConfig json.RawMessage) error {
 	var config CCMetricStoreConfig
 	if err := json.Unmarshal(rawConfig, &config); err != nil {
@@ -250,7 +265,7 @@ func (ccms *CCMetricStore) LoadData(
 					Min: float64(res.Min),
 					Max: float64(res.Max),
 				},
-				Data: res.Data,
+				Data: res.dataFrom(req.From),
 			})
 		}
 
@@ -773,7 +788,7 @@ func (ccms *CCMetricStore) LoadNodeData(
 			Series: []schema.Series{
 				{
 					Hostname: query.Hostname,
-					Data:     qdata.Data,
+					Data:     qdata.dataFrom(req.From),
 					Statistics: schema.MetricStatistics{
 						Avg: float64(qdata.Avg),
 						Min: float64(qdata.Min),
@@ -940,7 +955,7 @@ func (ccms *CCMetricStore) LoadNodeListData(
 					Min: float64(res.Min),
 					Max: float64(res.Max),
 				},
-				Data: res.Data,
+				Data: res.dataFrom(req.From),
 			})
 		}
 	}
@@ -0,0 +1,434 @@
+// Copyright (C) NHR@FAU, University Erlangen-Nuremberg.
+// All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+package metricdata
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ClusterCockpit/cc-backend/internal/graph/model"
+	"github.com/ClusterCockpit/cc-backend/pkg/log"
+	"github.com/ClusterCockpit/cc-backend/pkg/schema"
+)
+
+// Routing options of a repository in a chain. All other options are passed
+// to the repository itself.
+type ChainMemberConfig struct {
+	Kind string `json:"kind"`
+	// Only query for data after since and before until (RFC3339)
+	Since string `json:"since,omitempty"`
+	Until string `json:"until,omitempty"`
+	// Only query for data younger than max-age, e.g. "336h"
+	MaxAge string `json:"max-age,omitempty"`
+	// Only query for these metrics, all if empty
+	Metrics []string `json:"metrics,omitempty"`
+}
+
+type chainMember struct {
+	kind    string
+	repo    MetricDataRepository
+	since   time.Time
+	until   time.Time
+	maxAge  time.Duration
+	metrics map[string]bool
+}
+
+// Combines an ordered list of repositories, e.g. cc-metric-store for the
+// last two weeks and InfluxDB for older data. Every repository whose time
+// window overlaps a request is queried for the metrics routed to it. The
+// results are merged, data of earlier repositories takes precedence where
+// both have values. A failing repository is skipped as long as another one
+// returned data.
+type ChainedMetricDataRepository struct {
+	cluster string
+	members []*chainMember
+}
+
+func (cmdr *ChainedMetricDataRepository) Init(rawConfig json.RawMessage) error {
+	var rawMembers []json.RawMessage
+	if err := json.Unmarshal(rawConfig, &rawMembers); err != nil {
+		log.Warn("Error while unmarshaling raw json config")
+		return err
+	}
+	if len(rawMembers) == 0 {
+		return errors.New("METRICDATA/CHAIN > empty list of metric data repositories")
+	}
+
+	for i, raw := range rawMembers {
+		var config ChainMemberConfig
+		if err := json.Unmarshal(raw, &config); err != nil {
+			log.Warn("Error while unmarshaling raw json config")
+			return err
+		}
+
+		m := &chainMember{kind: config.Kind}
+		var err error
+		if config.Since != "" {
+			if m.since, err = time.Parse(time.RFC3339, config.Since); err != nil {
+				return fmt.Errorf("METRICDATA/CHAIN > repository %d: invalid since: %w", i, err)
+			}
+		}
+		if config.Until != "" {
+			if m.until, err = time.Parse(time.RFC3339, config.Until); err != nil {
+				return fmt.Errorf("METRICDATA/CHAIN > repository %d: invalid until: %w", i, err)
+			}
+		}
+		if config.MaxAge != "" {
+			if m.maxAge, err = time.ParseDuration(config.MaxAge); err != nil {
+				return fmt.Errorf("METRICDATA/CHAIN > repository %d: invalid max-age: %w", i, err)
+			}
+		}
+		if len(config.Metrics) > 0 {
+			m.metrics = make(map[string]bool, len(config.Metrics))
+			for _, metric := range config.Metrics {
+				m.metrics[metric] = true
+			}
+		}
+
+		if m.repo, err = initMetricDataRepository(cmdr.cluster, raw); err != nil {
+			return err
+		}
+		cmdr.members = append(cmdr.members, m)
+	}
+
+	return nil
+}
+
+// Returns whether the time window of the repository overlaps from to to
+func (m *chainMember) covers(from, to time.Time) bool {
+	start := m.since
+	if m.maxAge > 0 {
+		if t := time.Now().Add(-m.maxAge); t.After(start) {
+			start = t
+		}
+	}
+
+	return !to.Before(start) && (m.until.IsZero() || !from.After(m.until))
+}
+
+// Returns the metrics routed to the repository
+func (m *chainMember) filter(metrics []string) []string {
+	if m.metrics == nil {
+		return metrics
+	}
+
+	res := make([]string, 0, len(metrics))
+	for _, metric := range metrics {
+		if m.metrics[metric] {
+			res = append(res, metric)
+		}
+	}
+	return res
+}
+
+// Returns the repositories to query for the time range with the metrics
+// routed to each of them
+func (cmdr *ChainedMetricDataRepository) route(metrics []string, from, to time.Time) ([]*chainMember, [][]string) {
+	members := make([]*chainMember, 0, len(cmdr.members))
+	memberMetrics := make([][]string, 0, len(cmdr.members))
+	for _, m := range cmdr.members {
+		if !m.covers(from, to) {
+			continue
+		}
+		if ms := m.filter(metrics); len(ms) > 0 {
+			members = append(members, m)
+			memberMetrics = append(memberMetrics, ms)
+		}
+	}
+
+	return members, memberMetrics
+}
+
+// Returns whether any metric would be loaded from more than one repository
+func multiSourced(memberMetrics [][]string) bool {
+	seen := make(map[string]bool)
+	for _, metrics := range memberMetrics {
+		for _, metric := range metrics {
+			if seen[metric] {
+				return true
+			}
+			seen[metric] = true
+		}
+	}
+	return false
+}
+
+func seriesKey(s *schema.Series) string {
+	if s.Id == nil {
+		return s.Hostname
+	}
+	return s.Hostname + "/" + *s.Id
+}
+
+// Fills gaps in the series of dst with the series of src. Series only in src
+// are added. Metrics with a different timestep are not merged. The series of
+// all repositories start at the start of the requested range, values with
+// the same index belong to the same time.
+func mergeJobMetric(dst, src *schema.JobMetric) {
+	if dst.Timestep != src.Timestep {
+		return
+	}
+
+	index := make(map[string]int, len(dst.Series))
+	for i := range dst.Series {
+		index[seriesKey(&dst.Series[i])] = i
+	}
+
+	for _, s := range src.Series {
+		i, ok := index[seriesKey(&s)]
+		if !ok {
+			dst.Series = append(dst.Series, s)
+			continue
+		}
+
+		d := &dst.Series[i]
+		merged := make([]schema.Float, max(len(d.Data), len(s.Data)))
+		for j := range merged {
+			merged[j] = schema.NaN
+			if j < len(d.Data) {
+				merged[j] = d.Data[j]
+			}
+			if merged[j].IsNaN() && j < len(s.Data) {
+				merged[j] = s.Data[j]
+			}
+		}
+		d.Data = merged
+		d.Statistics = seriesStatistics(merged)
+	}
+}
+
+func mergeJobData(dst, src schema.JobData) {
+	for metric, scopes := range src {
+		if dst[metric] == nil {
+			dst[metric] = make(map[schema.MetricScope]*schema.JobMetric, len(scopes))
+		}
+		for scope, jm := range scopes {
+			if d, ok := dst[metric][scope]; ok {
+				mergeJobMetric(d, jm)
+			} else {
+				dst[metric][scope] = jm
+			}
+		}
+	}
+}
+
+// Returns the error of the first failed repository if none succeeded
+func chainError(errs []error, succeeded int) error {
+	if succeeded > 0 || len(errs) == 0 {
+		return nil
+	}
+	return errs[0]
+}
+
+func (cmdr *ChainedMetricDataRepository) LoadData(
+	job *schema.Job,
+	metrics []string,
+	scopes []schema.MetricScope,
+	ctx context.Context,
+	resolution int,
+) (schema.JobData, error) {
+	from, to := jobRange(job)
+	members, memberMetrics := cmdr.route(metrics, from, to)
+
+	jobData := make(schema.JobData)
+	errs := make([]error, 0)
+	succeeded := 0
+	for i, m := range members {
+		jd, err := m.repo.LoadData(job, memberMetrics[i], scopes, ctx, resolution)
+		if err != nil {
+			log.Warnf("MetricDataRepository %s in chain failed for job %d: %s", m.kind, job.JobID, err.Error())
+			errs = append(errs, err)
+			if len(jd) == 0 {
+				continue
+			}
+		}
+		mergeJobData(jobData, jd)
+		succeeded++
+	}
+
+	return jobData, chainError(errs, succeeded)
+}
+
+// Computes the statistics from the merged data if any metric is loaded from
+// more than one repository, otherwise every repository computes its own.
+func (cmdr *ChainedMetricDataRepository) LoadStats(
+	job *schema.Job,
+	metrics []string,
+	ctx context.Context,
+) (map[string]map[string]schema.MetricStatistics, error) {
+	from, to := jobRange(job)
+	members, memberMetrics := cmdr.route(metrics, from, to)
+
+	stats := make(map[string]map[string]schema.MetricStatistics, len(metrics))
+	if multiSourced(memberMetrics) {
+		jobData, err := cmdr.LoadData(job, metrics, []schema.MetricScope{schema.MetricScopeNode}, ctx, 0)
+		if err != nil {
+			return nil, err
+		}
+		for metric, scopes := range jobData {
+			jm, ok := scopes[schema.MetricScopeNode]
+			if !ok {
+				continue
+			}
+			stats[metric] = make(map[string]schema.MetricStatistics, len(jm.Series))
+			for _, s := range jm.Series {
+				stats[metric][s.Hostname] = s.Statistics
+			}
+		}
+		return stats, nil
+	}
+
+	errs := make([]error, 0)
+	succeeded := 0
+	for i, m := range members {
+		s, err := m.repo.LoadStats(job, memberMetrics[i], ctx)
+		if err != nil {
+			log.Warnf("MetricDataRepository %s in chain failed for job %d: %s", m.kind, job.JobID, err.Error())
+			errs = append(errs, err)
+			continue
+		}
+		for metric, hosts := range s {
+			stats[metric] = hosts
+		}
+		succeeded++
+	}
+
+	return stats, chainError(errs, succeeded)
+}
+
+func (cmdr *ChainedMetricDataRepository) LoadScopedStats(
+	job *schema.Job,
+	metrics []string,
+	scopes []schema.MetricScope,
+	ctx context.Context,
+) (schema.ScopedJobStats, error) {
+	from, to := jobRange(job)
+	members, memberMetrics := cmdr.route(metrics, from, to)
+
+	scopedJobStats := make(schema.ScopedJobStats)
+	if multiSourced(memberMetrics) {
+		jobData, err := cmdr.LoadData(job, metrics, scopes, ctx, 0)
+		if err != nil {
+			return nil, err
+		}
+		for metric, scopes := range jobData {
+			scopedJobStats[metric] = make(map[schema.MetricScope][]*schema.ScopedStats, len(scopes))
+			for scope, jm := range scopes {
+				for i := range jm.Series {
+					s := &jm.Series[i]
+					scopedJobStats[metric][scope] = append(scopedJobStats[metric][scope], &schema.ScopedStats{
+						Hostname: s.Hostname,
+						Id:       s.Id,
+						Data:     &s.Statistics,
+					})
+				}
+			}
+		}
+		return scopedJobStats, nil
+	}
+
+	errs := make([]error, 0)
+	succeeded := 0
+	for i, m := range members {
+		s, err := m.repo.LoadScopedStats(job, memberMetrics[i], scopes, ctx)
+		if err != nil {
+			log.Warnf("MetricDataRepository %s in chain failed for job %d: %s", m.kind, job.JobID, err.Error())
+			errs = append(errs, err)
+			continue
+		}
+		for metric, stats := range s {
+			scopedJobStats[metric] = stats
+		}
+		succeeded++
+	}
+
+	return scopedJobStats, chainError(errs, succeeded)
+}
+
+func (cmdr *ChainedMetricDataRepository) LoadNodeData(
+	cluster string,
+	metrics, nodes []string,
+	scopes []schema.MetricScope,
+	from, to time.Time,
+	ctx context.Context,
+) (map[string]map[string][]*schema.JobMetric, error) {
+	members, memberMetrics := cmdr.route(metrics, from, to)
+
+	data := make(map[string]map[string][]*schema.JobMetric)
+	errs := make([]error, 0)
+	succeeded := 0
+	for i, m := range members {
+		nodeData, err := m.repo.LoadNodeData(cluster, memberMetrics[i], nodes, scopes, from, to, ctx)
+		if err != nil {
+			log.Warnf("MetricDataRepository %s in chain failed for cluster %s: %s", m.kind, cluster, err.Error())
+			errs = append(errs, err)
+			continue
+		}
+		succeeded++
+
+		for host, hostData := range nodeData {
+			if data[host] == nil {
+				data[host] = hostData
+				continue
+			}
+			for metric, jms := range hostData {
+				dst := data[host][metric]
+				if len(dst) != len(jms) {
+					if len(dst) == 0 {
+						data[host][metric] = jms
+					}
+					continue
+				}
+				for j := range jms {
+					mergeJobMetric(dst[j], jms[j])
+				}
+			}
+		}
+	}
+
+	return data, chainError(errs, succeeded)
+}
+
+func (cmdr *ChainedMetricDataRepository) LoadNodeListData(
+	cluster, subCluster, nodeFilter string,
+	metrics []string,
+	scopes []schema.MetricScope,
+	resolution int,
+	from, to time.Time,
+	page *model.PageRequest,
+	ctx context.Context,
+) (map[string]schema.JobData, int, bool, error) {
+	members, memberMetrics := cmdr.route(metrics, from, to)
+
+	data := make(map[string]schema.JobData)
+	totalNodes, hasNextPage := 0, false
+	errs := make([]error, 0)
+	succeeded := 0
+	for i, m := range members {
+		nodeData, total, hasNext, err := m.repo.LoadNodeListData(cluster, subCluster, nodeFilter,
+			memberMetrics[i], scopes, resolution, from, to, page, ctx)
+		if err != nil {
+			log.Warnf("MetricDataRepository %s in chain failed for cluster %s: %s", m.kind, cluster, err.Error())
+			errs = append(errs, err)
+			continue
+		}
+		if succeeded == 0 {
+			totalNodes, hasNextPage = total, hasNext
+		}
+		succeeded++
+
+		for host, jobData := range nodeData {
+			if data[host] == nil {
+				data[host] = make(schema.JobData)
+			}
+			mergeJobData(data[host], jobData)
+		}
+	}
+
+	return data, totalNodes, hasNextPage, chainError(errs, succeeded)
+}
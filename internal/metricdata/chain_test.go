@@ -0,0 +1,275 @@
+// Copyright (C) NHR@FAU, University Erlangen-Nuremberg.
+// All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+package metricdata
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ClusterCockpit/cc-backend/pkg/schema"
+)
+
+func TestChainInit(t *testing.T) {
+	tests := []struct {
+		config string
+		valid  bool
+	}{
+		{`[{"kind": "test", "max-age": "336h"}, {"kind": "test", "until": "2024-01-01T00:00:00Z", "metrics": ["cpu_load"]}]`, true},
+		{`[]`, false},
+		{`{"kind": "test"}`, false},
+		{`[{"kind": "test", "since": "yesterday"}]`, false},
+		{`[{"kind": "test", "until": "2024-01-01"}]`, false},
+		{`[{"kind": "test", "max-age": "2w"}]`, false},
+		{`[{"kind": "unknown"}]`, false},
+	}
+	for _, tt := range tests {
+		chain := &ChainedMetricDataRepository{cluster: "testcluster"}
+		if err := chain.Init(json.RawMessage(tt.config)); (err == nil) != tt.valid {
+			t.Errorf("%s: unexpected error %v", tt.config, err)
+		}
+	}
+
+	chain := &ChainedMetricDataRepository{cluster: "testcluster"}
+	if err := chain.Init(json.RawMessage(tests[0].config)); err != nil {
+		t.Fatal(err)
+	}
+	if len(chain.members) != 2 || chain.members[0].maxAge != 336*time.Hour ||
+		chain.members[1].until.Unix() != 1704067200 || !reflect.DeepEqual(chain.members[1].metrics, map[string]bool{"cpu_load": true}) {
+		t.Errorf("unexpected members: %+v %+v", chain.members[0], chain.members[1])
+	}
+}
+
+func TestChainCovers(t *testing.T) {
+	now := time.Now()
+	from, to := now.Add(-2*time.Hour), now.Add(-time.Hour)
+
+	tests := []struct {
+		name   string
+		member chainMember
+		want   bool
+	}{
+		{"unlimited", chainMember{}, true},
+		{"since before", chainMember{since: from.Add(-time.Hour)}, true},
+		{"since within", chainMember{since: from.Add(time.Minute)}, true},
+		{"since after", chainMember{since: to.Add(time.Minute)}, false},
+		{"until after", chainMember{until: to.Add(time.Hour)}, true},
+		{"until within", chainMember{until: from.Add(time.Minute)}, true},
+		{"until before", chainMember{until: from.Add(-time.Minute)}, false},
+		{"max-age covering", chainMember{maxAge: 3 * time.Hour}, true},
+		{"max-age partially", chainMember{maxAge: 90 * time.Minute}, true},
+		{"max-age too short", chainMember{maxAge: 30 * time.Minute}, false},
+		{"since later than max-age", chainMember{since: to.Add(time.Minute), maxAge: 3 * time.Hour}, false},
+	}
+	for _, tt := range tests {
+		if got := tt.member.covers(from, to); got != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestMergeJobMetric(t *testing.T) {
+	nan := schema.NaN
+	tests := []struct {
+		name     string
+		dst, src *schema.JobMetric
+		want     map[string][]schema.Float
+	}{
+		{
+			"gaps filled",
+			nodeMetric(60, map[string][]schema.Float{"node01": {1, nan, 3, nan}}),
+			nodeMetric(60, map[string][]schema.Float{"node01": {9, 2, 9, nan}}),
+			map[string][]schema.Float{"node01": {1, 2, 3, nan}},
+		},
+		{
+			"longer source",
+			nodeMetric(60, map[string][]schema.Float{"node01": {1}}),
+			nodeMetric(60, map[string][]schema.Float{"node01": {9, 2}}),
+			map[string][]schema.Float{"node01": {1, 2}},
+		},
+		{
+			"series added",
+			nodeMetric(60, map[string][]schema.Float{"node01": {1, 1}}),
+			nodeMetric(60, map[string][]schema.Float{"node02": {2, 2}}),
+			map[string][]schema.Float{"node01": {1, 1}, "node02": {2, 2}},
+		},
+		{
+			"different timestep",
+			nodeMetric(60, map[string][]schema.Float{"node01": {1, nan}}),
+			nodeMetric(30, map[string][]schema.Float{"node01": {2, 2, 2, 2}}),
+			map[string][]schema.Float{"node01": {1, nan}},
+		},
+	}
+	for _, tt := range tests {
+		mergeJobMetric(tt.dst, tt.src)
+		got := make(map[string][]schema.Float, len(tt.dst.Series))
+		for _, s := range tt.dst.Series {
+			got[s.Hostname] = s.Data
+		}
+		if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	// Statistics are computed from the merged data
+	dst := nodeMetric(60, map[string][]schema.Float{"node01": {nan, 4}})
+	mergeJobMetric(dst, nodeMetric(60, map[string][]schema.Float{"node01": {2, nan}}))
+	if s := dst.Series[0].Statistics; s.Min != 2 || s.Max != 4 || s.Avg != 3 {
+		t.Errorf("unexpected statistics: %+v", s)
+	}
+}
+
+// Returns a chain of a recent repository for all metrics, an older one for
+// cpu_load only and one which ended before the test job.
+func testChain() (*ChainedMetricDataRepository, []*fakeRepository) {
+	nan := schema.NaN
+	repos := []*fakeRepository{
+		{data: schema.JobData{
+			"cpu_load":  {schema.MetricScopeNode: nodeMetric(60, map[string][]schema.Float{"node01": {1, nan, 3, nan}})},
+			"flops_any": {schema.MetricScopeNode: nodeMetric(60, map[string][]schema.Float{"node01": {5, 5, 5, 5}})},
+		}},
+		{data: schema.JobData{
+			"cpu_load": {schema.MetricScopeNode: nodeMetric(60, map[string][]schema.Float{"node01": {9, 2, 9, nan}})},
+		}},
+		{},
+	}
+	chain := &ChainedMetricDataRepository{cluster: "testcluster", members: []*chainMember{
+		{kind: "recent", repo: repos[0]},
+		{kind: "old", repo: repos[1], metrics: map[string]bool{"cpu_load": true}},
+		{kind: "ended", repo: repos[2], until: time.Unix(testStart-3600, 0)},
+	}}
+	return chain, repos
+}
+
+func TestChainLoadData(t *testing.T) {
+	chain, repos := testChain()
+	job := testJob()
+
+	data, err := chain.LoadData(job, []string{"cpu_load", "flops_any"}, nil, context.Background(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := seriesData(t, data, "cpu_load", schema.MetricScopeNode); got["node01"] != "[1 2 3 NaN]" {
+		t.Errorf("unexpected cpu_load: %v", got)
+	}
+	if got := seriesData(t, data, "flops_any", schema.MetricScopeNode); got["node01"] != "[5 5 5 5]" {
+		t.Errorf("unexpected flops_any: %v", got)
+	}
+
+	// Metrics and time windows are routed to the repositories
+	if !reflect.DeepEqual(repos[0].metrics, [][]string{{"cpu_load", "flops_any"}}) ||
+		!reflect.DeepEqual(repos[1].metrics, [][]string{{"cpu_load"}}) || repos[2].calls() != 0 {
+		t.Errorf("unexpected routing: %v %v %v", repos[0].metrics, repos[1].metrics, repos[2].metrics)
+	}
+
+	// A failing repository is skipped
+	repos[1].err = errors.New("unreachable")
+	data, err = chain.LoadData(job, []string{"cpu_load"}, nil, context.Background(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := seriesData(t, data, "cpu_load", schema.MetricScopeNode); got["node01"] != "[1 NaN 3 NaN]" {
+		t.Errorf("unexpected cpu_load: %v", got)
+	}
+
+	// The error of the first repository is returned if all fail
+	repos[0].err = errors.New("timeout")
+	if _, err := chain.LoadData(job, []string{"cpu_load"}, nil, context.Background(), 0); err == nil || err.Error() != "timeout" {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+// The cc-metric-store only holds the last two timesteps of the test job
+func TestChainLoadDataPartial(t *testing.T) {
+	setupCluster(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		var req ApiQueryRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Error(err)
+		}
+		res := ApiQueryResponse{}
+		for range req.Queries {
+			res.Results = append(res.Results, []ApiMetricData{{
+				Data: []schema.Float{7, 8}, From: req.From + 120, To: req.To, Resolution: 60, Avg: 7.5, Min: 7, Max: 8,
+			}})
+		}
+		json.NewEncoder(rw).Encode(&res)
+	}))
+	defer srv.Close()
+
+	ccms := &CCMetricStore{}
+	if err := ccms.Init(json.RawMessage(fmt.Sprintf(`{"kind": "cc-metric-store", "url": "%s"}`, srv.URL))); err != nil {
+		t.Fatal(err)
+	}
+	old := &fakeRepository{data: schema.JobData{
+		"cpu_load": {schema.MetricScopeNode: nodeMetric(60, map[string][]schema.Float{"node01": {1, 2, 3, 4}})},
+	}}
+	chain := &ChainedMetricDataRepository{cluster: "testcluster", members: []*chainMember{
+		{kind: "cc-metric-store", repo: ccms, maxAge: time.Since(time.Unix(testStart+120, 0))},
+		{kind: "old", repo: old},
+	}}
+
+	job := testJob()
+	job.SubCluster = "main"
+	data, err := chain.LoadData(job, []string{"cpu_load"}, []schema.MetricScope{schema.MetricScopeNode}, context.Background(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := seriesData(t, data, "cpu_load", schema.MetricScopeNode); got["node01"] != "[1 2 7 8]" {
+		t.Errorf("unexpected cpu_load: %v", got)
+	}
+}
+
+func TestChainLoadStats(t *testing.T) {
+	chain, repos := testChain()
+	job := testJob()
+
+	// cpu_load is loaded from two repositories, the statistics are computed
+	// from the merged data
+	stats, err := chain.LoadStats(job, []string{"cpu_load", "flops_any"}, context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := stats["cpu_load"]["node01"]; s.Min != 1 || s.Max != 3 || s.Avg != 2 {
+		t.Errorf("unexpected cpu_load statistics: %+v", s)
+	}
+	if s := stats["flops_any"]["node01"]; s.Avg != 5 {
+		t.Errorf("unexpected flops_any statistics: %+v", s)
+	}
+
+	// Otherwise the statistics of the repository are used
+	repos[0].metrics, repos[1].metrics = nil, nil
+	stats, err = chain.LoadStats(job, []string{"flops_any"}, context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := stats["flops_any"]["node01"]; s.Avg != 5 || repos[0].calls() != 1 || repos[1].calls() != 0 {
+		t.Errorf("unexpected statistics %+v with calls %v %v", s, repos[0].metrics, repos[1].metrics)
+	}
+}
+
+func TestChainLoadNodeListData(t *testing.T) {
+	chain, repos := testChain()
+	from, to := time.Unix(testStart, 0), time.Unix(testStart+testDuration, 0)
+
+	repos[0].err = errors.New("unreachable")
+	data, totalNodes, hasNextPage, err := chain.LoadNodeListData("testcluster", "", "",
+		[]string{"cpu_load"}, nil, 0, from, to, nil, context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if totalNodes != 2 || !hasNextPage {
+		t.Errorf("unexpected page: %d nodes, next page %v", totalNodes, hasNextPage)
+	}
+	if got := seriesData(t, data["node01"], "cpu_load", schema.MetricScopeNode); got["node01"] != "[9 2 9 NaN]" {
+		t.Errorf("unexpected cpu_load: %v", got)
+	}
+}
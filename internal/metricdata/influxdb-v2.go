@@ -8,11 +8,7 @@ import (
 	"context"
 	"crypto/tls"
 	"encoding/json"
-	"errors"
 	"fmt"
-	"math"
-	"sort"
-	"strings"
 	"time"
 
 	"github.com/ClusterCockpit/cc-backend/internal/graph/model"
@@ -29,12 +25,16 @@ type InfluxDBv2DataRepositoryConfig struct {
 	Bucket  string `json:"bucket"`
 	Org     string `json:"org"`
 	SkipTls bool   `json:"skiptls"`
+	// Field holding the metric value, "value" if empty as written by
+	// cc-metric-collector
+	Field string `json:"field"`
 }
 
 type InfluxDBv2DataRepository struct {
 	client              influxdb2.Client
 	queryClient         influxdb2Api.QueryAPI
 	bucket, measurement string
+	field               string
 }
 
 func (idb *InfluxDBv2DataRepository) Init(rawConfig json.RawMessage) error {
@@ -47,6 +47,10 @@ func (idb *InfluxDBv2DataRepository) Init(rawConfig json.RawMessage) error {
 	idb.client = influxdb2.NewClientWithOptions(config.Url, config.Token, influxdb2.DefaultOptions().SetTLSConfig(&tls.Config{InsecureSkipVerify: config.SkipTls}))
 	idb.queryClient = idb.client.QueryAPI(config.Org)
 	idb.bucket = config.Bucket
+	idb.field = config.Field
+	if idb.field == "" {
+		idb.field = "value"
+	}
 
 	return nil
 }
@@ -55,188 +59,141 @@ func (idb *InfluxDBv2DataRepository) formatTime(t time.Time) string {
 	return t.Format(time.RFC3339) // Like “2006-01-02T15:04:05Z07:00”
 }
 
-func (idb *InfluxDBv2DataRepository) epochToTime(epoch int64) time.Time {
-	return time.Unix(epoch, 0)
-}
-
-func (idb *InfluxDBv2DataRepository) LoadData(
-	job *schema.Job,
+// Loads the requested metrics and scopes of all resources. Every metric is
+// queried at its native scope using the type and type-id tags written by
+// cc-metric-collector and aggregated to the requested scopes according to
+// the cluster topology. Returns the job data of each host.
+func (idb *InfluxDBv2DataRepository) load(
+	ctx context.Context,
+	cluster string,
+	resources []*schema.Resource,
 	metrics []string,
 	scopes []schema.MetricScope,
-	ctx context.Context,
-	resolution int) (schema.JobData, error) {
-
-	log.Infof("InfluxDB 2 Backend: Resolution Scaling not Implemented, will return default timestep. Requested Resolution %d", resolution)
-
-	measurementsConds := make([]string, 0, len(metrics))
-	for _, m := range metrics {
-		measurementsConds = append(measurementsConds, fmt.Sprintf(`r["_measurement"] == "%s"`, m))
+	from, to time.Time,
+) (map[string]schema.JobData, error) {
+	topologies, hosts, err := resourceTopologies(cluster, resources)
+	if err != nil {
+		return nil, err
 	}
-	measurementsCond := strings.Join(measurementsConds, " or ")
-
-	hostsConds := make([]string, 0, len(job.Resources))
-	for _, h := range job.Resources {
-		if h.HWThreads != nil || h.Accelerators != nil {
-			// TODO
-			return nil, errors.New("METRICDATA/INFLUXV2 > the InfluxDB metric data repository does not yet support HWThreads or Accelerators")
-		}
-		hostsConds = append(hostsConds, fmt.Sprintf(`r["hostname"] == "%s"`, h.Hostname))
+	if len(hosts) == 0 {
+		return make(map[string]schema.JobData), nil
 	}
-	hostsCond := strings.Join(hostsConds, " or ")
-
-	jobData := make(schema.JobData) // Empty Schema: map[<string>FIELD]map[<MetricScope>SCOPE]<*JobMetric>METRIC
-	// Requested Scopes
-	for _, scope := range scopes {
-		query := ""
-		switch scope {
-		case "node":
-			// Get Finest Granularity, Groupy By Measurement and Hostname (== Metric / Node), Calculate Mean for 60s windows <-- Resolution could be added here?
-			// log.Info("Scope 'node' requested. ")
-			query = fmt.Sprintf(`
-								from(bucket: "%s")
-								|> range(start: %s, stop: %s)
-								|> filter(fn: (r) => (%s) and (%s) )
-								|> drop(columns: ["_start", "_stop"])
-								|> group(columns: ["hostname", "_measurement"])
-		            |> aggregateWindow(every: 60s, fn: mean)
-								|> drop(columns: ["_time"])`,
-				idb.bucket,
-				idb.formatTime(job.StartTime), idb.formatTime(idb.epochToTime(job.StartTimeUnix+int64(job.Duration)+int64(1))),
-				measurementsCond, hostsCond)
-		case "socket":
-			log.Info("Scope 'socket' requested, but not yet supported: Will return 'node' scope only. ")
-			continue
-		case "core":
-			log.Info(" Scope 'core' requested, but not yet supported: Will return 'node' scope only. ")
-			continue
-			// Get Finest Granularity only, Set NULL to 0.0
-			// query = fmt.Sprintf(`
-			//  	from(bucket: "%s")
-			//  	|> range(start: %s, stop: %s)
-			//  	|> filter(fn: (r) => %s )
-			//  	|> filter(fn: (r) => %s )
-			//  	|> drop(columns: ["_start", "_stop", "cluster"])
-			//  	|> map(fn: (r) => (if exists r._value then {r with _value: r._value} else {r with _value: 0.0}))`,
-			//  	idb.bucket,
-			//  	idb.formatTime(job.StartTime), idb.formatTime(idb.epochToTime(job.StartTimeUnix + int64(job.Duration) + int64(1) )),
-			//  	measurementsCond, hostsCond)
-		case "hwthread":
-			log.Info(" Scope 'hwthread' requested, but not yet supported: Will return 'node' scope only. ")
-			continue
-		case "accelerator":
-			log.Info(" Scope 'accelerator' requested, but not yet supported: Will return 'node' scope only. ")
-			continue
-		default:
-			log.Infof("Unknown scope '%s' requested: Will return 'node' scope.", scope)
+	hostsCond := fmt.Sprintf(`r["hostname"] =~ /^(%s)$/`, nodeRegex(hosts))
+
+	mcs := make([]*schema.MetricConfig, 0, len(metrics))
+	native := make([]nativeSeries, 0, len(metrics))
+	for _, metric := range metrics {
+		mc := archive.GetMetricConfig(cluster, metric)
+		if mc == nil {
+			log.Warnf("Metric %s for cluster %s not configured", metric, cluster)
 			continue
-			// return nil, errors.New("METRICDATA/INFLUXV2 > the InfluxDB metric data repository does not yet support other scopes than 'node'")
 		}
 
-		rows, err := idb.queryClient.Query(ctx, query)
+		typeCond := fmt.Sprintf(`r["type"] == "%s"`, mc.Scope)
+		groupColumns := `["hostname", "type-id"]`
+		if mc.Scope == schema.MetricScopeNode {
+			typeCond = `(not exists r["type"] or r["type"] == "node")`
+			groupColumns = `["hostname"]`
+		}
+
+		// The stop of the range is exclusive
+		query := fmt.Sprintf(`
+			from(bucket: "%s")
+			|> range(start: %s, stop: %s)
+			|> filter(fn: (r) => r._measurement == "%s" and r._field == "%s")
+			|> filter(fn: (r) => %s and %s)
+			|> group(columns: %s)
+			|> aggregateWindow(every: %ds, fn: mean, createEmpty: false, timeSrc: "_start")`,
+			idb.bucket,
+			idb.formatTime(from), idb.formatTime(to.Add(time.Second)),
+			metric, idb.field, hostsCond, typeCond, groupColumns, mc.Timestep)
+
+		samples, err := idb.querySamples(ctx, query, mc)
 		if err != nil {
-			log.Error("Error while performing query")
 			return nil, err
 		}
 
-		// Init Metrics: Only Node level now -> TODO: Matching /check on scope level ...
-		for _, metric := range metrics {
-			jobMetric, ok := jobData[metric]
-			if !ok {
-				mc := archive.GetMetricConfig(job.Cluster, metric)
-				jobMetric = map[schema.MetricScope]*schema.JobMetric{
-					scope: { // uses scope var from above!
-						Unit:             mc.Unit,
-						Timestep:         mc.Timestep,
-						Series:           make([]schema.Series, 0, len(job.Resources)),
-						StatisticsSeries: nil, // Should be: &schema.StatsSeries{},
-					},
-				}
+		series := make(nativeSeries, len(samples))
+		for host, ids := range samples {
+			series[host] = make(map[string][]schema.Float, len(ids))
+			for id, s := range ids {
+				series[host][id] = binSamples(s, from, to, mc.Timestep)
 			}
-			jobData[metric] = jobMetric
 		}
+		mcs = append(mcs, mc)
+		native = append(native, series)
+	}
 
-		// Process Result: Time-Data
-		field, host, hostSeries := "", "", schema.Series{}
-		// typeId := 0
-		switch scope {
-		case "node":
-			for rows.Next() {
-				row := rows.Record()
-				if host == "" || host != row.ValueByKey("hostname").(string) || rows.TableChanged() {
-					if host != "" {
-						// Append Series before reset
-						jobData[field][scope].Series = append(jobData[field][scope].Series, hostSeries)
-					}
-					field, host = row.Measurement(), row.ValueByKey("hostname").(string)
-					hostSeries = schema.Series{
-						Hostname:   host,
-						Statistics: schema.MetricStatistics{}, //TODO Add Statistics
-						Data:       make([]schema.Float, 0),
-					}
-				}
-				val, ok := row.Value().(float64)
-				if ok {
-					hostSeries.Data = append(hostSeries.Data, schema.Float(val))
-				} else {
-					hostSeries.Data = append(hostSeries.Data, schema.Float(0))
-				}
-			}
-		case "socket":
-			continue
-		case "accelerator":
-			continue
-		case "hwthread":
-			// See below @ core
-			continue
-		case "core":
-			continue
-			// Include Series.Id in hostSeries
-			// for rows.Next() {
-			// 		row := rows.Record()
-			// 		if ( host == "" || host != row.ValueByKey("hostname").(string) || typeId != row.ValueByKey("type-id").(int) || rows.TableChanged() ) {
-			// 		 		if ( host != "" ) {
-			// 						// Append Series before reset
-			// 		 		  	jobData[field][scope].Series = append(jobData[field][scope].Series, hostSeries)
-			// 		 		}
-			// 		 		field, host, typeId = row.Measurement(), row.ValueByKey("hostname").(string), row.ValueByKey("type-id").(int)
-			// 		 		hostSeries  = schema.Series{
-			// 		 				Hostname:   host,
-			// 						Id:					&typeId,
-			// 		 				Statistics: nil,
-			// 		 				Data:       make([]schema.Float, 0),
-			// 		 		}
-			// 		}
-			// 		val := row.Value().(float64)
-			// 		hostSeries.Data = append(hostSeries.Data, schema.Float(val))
-			// }
+	return scopeNativeSeries(resources, topologies, mcs, native, scopes), nil
+}
+
+// Runs the query and returns the samples of each host and type-id, the
+// type-id is empty for metrics at node scope.
+func (idb *InfluxDBv2DataRepository) querySamples(
+	ctx context.Context,
+	query string,
+	mc *schema.MetricConfig,
+) (map[string]map[string][]rawSample, error) {
+	rows, err := idb.queryClient.Query(ctx, query)
+	if err != nil {
+		log.Error("Error while performing query")
+		return nil, err
+	}
+	defer rows.Close()
+
+	samples := make(map[string]map[string][]rawSample)
+	for rows.Next() {
+		row := rows.Record()
+		host, _ := row.ValueByKey("hostname").(string)
+		id := ""
+		if mc.Scope != schema.MetricScopeNode {
+			id, _ = row.ValueByKey("type-id").(string)
+		}
+
+		var val float64
+		switch v := row.Value().(type) {
+		case float64:
+			val = v
+		case int64:
+			val = float64(v)
+		case uint64:
+			val = float64(v)
 		default:
-			log.Infof("Unknown scope '%s' requested: Will return 'node' scope.", scope)
 			continue
-			// return nil, errors.New("the InfluxDB metric data repository does not yet support other scopes than 'node, core'")
 		}
-		// Append last Series
-		jobData[field][scope].Series = append(jobData[field][scope].Series, hostSeries)
+
+		if samples[host] == nil {
+			samples[host] = make(map[string][]rawSample)
+		}
+		samples[host][id] = append(samples[host][id], rawSample{Timestamp: row.Time().UnixMilli(), Value: val})
+	}
+	if err := rows.Err(); err != nil {
+		log.Error("Error while reading query result")
+		return nil, err
 	}
 
-	// Get Stats
-	stats, err := idb.LoadStats(job, metrics, ctx)
+	return samples, nil
+}
+
+func (idb *InfluxDBv2DataRepository) LoadData(
+	job *schema.Job,
+	metrics []string,
+	scopes []schema.MetricScope,
+	ctx context.Context,
+	resolution int,
+) (schema.JobData, error) {
+	if len(scopes) == 0 {
+		scopes = []schema.MetricScope{schema.MetricScopeNode}
+	}
+	from, to := jobRange(job)
+	hostData, err := idb.load(ctx, job.Cluster, job.Resources, metrics, scopes, from, to)
 	if err != nil {
-		log.Warn("Error while loading statistics")
 		return nil, err
 	}
 
-	for _, scope := range scopes {
-		if scope == "node" { // No 'socket/core' support yet
-			for metric, nodes := range stats {
-				for node, stats := range nodes {
-					for index, _ := range jobData[metric][scope].Series {
-						if jobData[metric][scope].Series[index].Hostname == node {
-							jobData[metric][scope].Series[index].Statistics = schema.MetricStatistics{Avg: stats.Avg, Min: stats.Min, Max: stats.Max}
-						}
-					}
-				}
-			}
-		}
+	jobData := hostsJobData(job.Resources, hostData)
+	if err := resampleJobData(jobData, resolution); err != nil {
+		return nil, err
 	}
 
 	return jobData, nil
@@ -245,248 +202,63 @@ func (idb *InfluxDBv2DataRepository) LoadData(
 func (idb *InfluxDBv2DataRepository) LoadStats(
 	job *schema.Job,
 	metrics []string,
-	ctx context.Context) (map[string]map[string]schema.MetricStatistics, error) {
-
-	stats := map[string]map[string]schema.MetricStatistics{}
-
-	hostsConds := make([]string, 0, len(job.Resources))
-	for _, h := range job.Resources {
-		if h.HWThreads != nil || h.Accelerators != nil {
-			// TODO
-			return nil, errors.New("METRICDATA/INFLUXV2 > the InfluxDB metric data repository does not yet support HWThreads or Accelerators")
-		}
-		hostsConds = append(hostsConds, fmt.Sprintf(`r["hostname"] == "%s"`, h.Hostname))
-	}
-	hostsCond := strings.Join(hostsConds, " or ")
-
-	// lenMet := len(metrics)
-
-	for _, metric := range metrics {
-		// log.Debugf("<< You are here: %s (Index %d of %d metrics)", metric, index, lenMet)
-
-		query := fmt.Sprintf(`
-				  data = from(bucket: "%s")
-				  |> range(start: %s, stop: %s)
-				  |> filter(fn: (r) => r._measurement == "%s" and r._field == "value" and (%s))
-				  union(tables: [data |> mean(column: "_value") |> set(key: "_field", value: "avg"),
-				                 data |>  min(column: "_value") |> set(key: "_field", value: "min"),
-				                 data |>  max(column: "_value") |> set(key: "_field", value: "max")])
-				  |> pivot(rowKey: ["hostname"], columnKey: ["_field"], valueColumn: "_value")
-				  |> group()`,
-			idb.bucket,
-			idb.formatTime(job.StartTime), idb.formatTime(idb.epochToTime(job.StartTimeUnix+int64(job.Duration)+int64(1))),
-			metric, hostsCond)
-
-		rows, err := idb.queryClient.Query(ctx, query)
-		if err != nil {
-			log.Error("Error while performing query")
-			return nil, err
-		}
-
-		nodes := map[string]schema.MetricStatistics{}
-		for rows.Next() {
-			row := rows.Record()
-			host := row.ValueByKey("hostname").(string)
-
-			avg, avgok := row.ValueByKey("avg").(float64)
-			if !avgok {
-				// log.Debugf(">> Assertion error for metric %s, statistic AVG. Expected 'float64', got %v", metric, avg)
-				avg = 0.0
-			}
-			min, minok := row.ValueByKey("min").(float64)
-			if !minok {
-				// log.Debugf(">> Assertion error for metric %s, statistic MIN. Expected 'float64', got %v", metric, min)
-				min = 0.0
-			}
-			max, maxok := row.ValueByKey("max").(float64)
-			if !maxok {
-				// log.Debugf(">> Assertion error for metric %s, statistic MAX. Expected 'float64', got %v", metric, max)
-				max = 0.0
-			}
-
-			nodes[host] = schema.MetricStatistics{
-				Avg: avg,
-				Min: min,
-				Max: max,
-			}
-		}
-		stats[metric] = nodes
+	ctx context.Context,
+) (map[string]map[string]schema.MetricStatistics, error) {
+	from, to := jobRange(job)
+	hostData, err := idb.load(ctx, job.Cluster, job.Resources, metrics,
+		[]schema.MetricScope{schema.MetricScopeNode}, from, to)
+	if err != nil {
+		log.Warn("Error while loading job for stats")
+		return nil, err
 	}
 
-	return stats, nil
+	return hostsStats(hostData, len(metrics)), nil
 }
 
 // Used in Job-View StatsTable
-// UNTESTED
 func (idb *InfluxDBv2DataRepository) LoadScopedStats(
 	job *schema.Job,
 	metrics []string,
 	scopes []schema.MetricScope,
-	ctx context.Context) (schema.ScopedJobStats, error) {
-
-	// Assumption: idb.loadData() only returns series node-scope - use node scope for statsTable
-	scopedJobStats := make(schema.ScopedJobStats)
-	data, err := idb.LoadData(job, metrics, []schema.MetricScope{schema.MetricScopeNode}, ctx, 0 /*resolution here*/)
+	ctx context.Context,
+) (schema.ScopedJobStats, error) {
+	if len(scopes) == 0 {
+		scopes = []schema.MetricScope{schema.MetricScopeNode}
+	}
+	from, to := jobRange(job)
+	hostData, err := idb.load(ctx, job.Cluster, job.Resources, metrics, scopes, from, to)
 	if err != nil {
 		log.Warn("Error while loading job for scopedJobStats")
 		return nil, err
 	}
 
-	for metric, metricData := range data {
-		for _, scope := range scopes {
-			if scope != schema.MetricScopeNode {
-				logOnce.Do(func() {
-					log.Infof("Note: Scope '%s' requested, but not yet supported: Will return 'node' scope only.", scope)
-				})
-				continue
-			}
-
-			if _, ok := scopedJobStats[metric]; !ok {
-				scopedJobStats[metric] = make(map[schema.MetricScope][]*schema.ScopedStats)
-			}
-
-			if _, ok := scopedJobStats[metric][scope]; !ok {
-				scopedJobStats[metric][scope] = make([]*schema.ScopedStats, 0)
-			}
-
-			for _, series := range metricData[scope].Series {
-				scopedJobStats[metric][scope] = append(scopedJobStats[metric][scope], &schema.ScopedStats{
-					Hostname: series.Hostname,
-					Data:     &series.Statistics,
-				})
-			}
-		}
-	}
-
-	return scopedJobStats, nil
+	return hostsScopedStats(job.Resources, hostData), nil
 }
 
 // Used in Systems-View @ Node-Overview
-// UNTESTED
 func (idb *InfluxDBv2DataRepository) LoadNodeData(
 	cluster string,
 	metrics, nodes []string,
 	scopes []schema.MetricScope,
 	from, to time.Time,
-	ctx context.Context) (map[string]map[string][]*schema.JobMetric, error) {
-
-	// Note: scopes[] Array will be ignored, only return node scope
-
-	// CONVERT ARGS TO INFLUX
-	measurementsConds := make([]string, 0)
-	for _, m := range metrics {
-		measurementsConds = append(measurementsConds, fmt.Sprintf(`r["_measurement"] == "%s"`, m))
-	}
-	measurementsCond := strings.Join(measurementsConds, " or ")
-
-	hostsConds := make([]string, 0)
+	ctx context.Context,
+) (map[string]map[string][]*schema.JobMetric, error) {
 	if nodes == nil {
-		var allNodes []string
-		subClusterNodeLists := archive.NodeLists[cluster]
-		for _, nodeList := range subClusterNodeLists {
-			allNodes = append(nodes, nodeList.PrintList()...)
-		}
-		for _, node := range allNodes {
-			nodes = append(nodes, node)
-			hostsConds = append(hostsConds, fmt.Sprintf(`r["hostname"] == "%s"`, node))
-		}
-	} else {
-		for _, node := range nodes {
-			hostsConds = append(hostsConds, fmt.Sprintf(`r["hostname"] == "%s"`, node))
-		}
+		nodes = clusterNodes(cluster, "")
+	}
+	if len(scopes) == 0 {
+		scopes = []schema.MetricScope{schema.MetricScopeNode}
 	}
-	hostsCond := strings.Join(hostsConds, " or ")
-
-	// BUILD AND PERFORM QUERY
-	query := fmt.Sprintf(`
-						from(bucket: "%s")
-						|> range(start: %s, stop: %s)
-						|> filter(fn: (r) => (%s) and (%s) )
-						|> drop(columns: ["_start", "_stop"])
-						|> group(columns: ["hostname", "_measurement"])
-			|> aggregateWindow(every: 60s, fn: mean)
-						|> drop(columns: ["_time"])`,
-		idb.bucket,
-		idb.formatTime(from), idb.formatTime(to),
-		measurementsCond, hostsCond)
 
-	rows, err := idb.queryClient.Query(ctx, query)
+	hostData, err := idb.load(ctx, cluster, nodeResources(nodes), metrics, scopes, from, to)
 	if err != nil {
-		log.Error("Error while performing query")
 		return nil, err
 	}
 
-	// HANDLE QUERY RETURN
-	// Collect Float Arrays for Node@Metric -> No Scope Handling!
-	influxData := make(map[string]map[string][]schema.Float)
-	for rows.Next() {
-		row := rows.Record()
-		host, field := row.ValueByKey("hostname").(string), row.Measurement()
-
-		influxHostData, ok := influxData[host]
-		if !ok {
-			influxHostData = make(map[string][]schema.Float)
-			influxData[host] = influxHostData
-		}
-
-		influxFieldData, ok := influxData[host][field]
-		if !ok {
-			influxFieldData = make([]schema.Float, 0)
-			influxData[host][field] = influxFieldData
-		}
-
-		val, ok := row.Value().(float64)
-		if ok {
-			influxData[host][field] = append(influxData[host][field], schema.Float(val))
-		} else {
-			influxData[host][field] = append(influxData[host][field], schema.Float(0))
-		}
-	}
-
-	// BUILD FUNCTION RETURN
-	data := make(map[string]map[string][]*schema.JobMetric)
-	for node, metricData := range influxData {
-
-		nodeData, ok := data[node]
-		if !ok {
-			nodeData = make(map[string][]*schema.JobMetric)
-			data[node] = nodeData
-		}
-
-		for metric, floatArray := range metricData {
-			avg, min, max := 0.0, 0.0, 0.0
-			for _, val := range floatArray {
-				avg += float64(val)
-				min = math.Min(min, float64(val))
-				max = math.Max(max, float64(val))
-			}
-
-			stats := schema.MetricStatistics{
-				Avg: (math.Round((avg/float64(len(floatArray)))*100) / 100),
-				Min: (math.Round(min*100) / 100),
-				Max: (math.Round(max*100) / 100),
-			}
-
-			mc := archive.GetMetricConfig(cluster, metric)
-			nodeData[metric] = append(nodeData[metric], &schema.JobMetric{
-				Unit:     mc.Unit,
-				Timestep: mc.Timestep,
-				Series: []schema.Series{
-					{
-						Hostname:   node,
-						Statistics: stats,
-						Data:       floatArray,
-					},
-				},
-			})
-		}
-	}
-
-	return data, nil
+	return hostsNodeData(hostData), nil
 }
 
 // Used in Systems-View @ Node-List
-// UNTESTED
 func (idb *InfluxDBv2DataRepository) LoadNodeListData(
 	cluster, subCluster, nodeFilter string,
 	metrics []string,
@@ -496,78 +268,20 @@ func (idb *InfluxDBv2DataRepository) LoadNodeListData(
 	page *model.PageRequest,
 	ctx context.Context,
 ) (map[string]schema.JobData, int, bool, error) {
+	nodes, totalNodes, hasNextPage := pagedNodeList(cluster, subCluster, nodeFilter, page)
 
-	// Assumption: idb.loadData() only returns series node-scope - use node scope for NodeList
-
-	// 0) Init additional vars
-	var totalNodes int = 0
-	var hasNextPage bool = false
-
-	// 1) Get list of all nodes
-	var nodes []string
-	if subCluster != "" {
-		scNodes := archive.NodeLists[cluster][subCluster]
-		nodes = scNodes.PrintList()
-	} else {
-		subClusterNodeLists := archive.NodeLists[cluster]
-		for _, nodeList := range subClusterNodeLists {
-			nodes = append(nodes, nodeList.PrintList()...)
-		}
-	}
-
-	// 2) Filter nodes
-	if nodeFilter != "" {
-		filteredNodes := []string{}
-		for _, node := range nodes {
-			if strings.Contains(node, nodeFilter) {
-				filteredNodes = append(filteredNodes, node)
-			}
-		}
-		nodes = filteredNodes
-	}
-
-	// 2.1) Count total nodes && Sort nodes -> Sorting invalidated after return ...
-	totalNodes = len(nodes)
-	sort.Strings(nodes)
-
-	// 3) Apply paging
-	if len(nodes) > page.ItemsPerPage {
-		start := (page.Page - 1) * page.ItemsPerPage
-		end := start + page.ItemsPerPage
-		if end > len(nodes) {
-			end = len(nodes)
-			hasNextPage = false
-		} else {
-			hasNextPage = true
-		}
-		nodes = nodes[start:end]
+	if len(scopes) == 0 {
+		scopes = []schema.MetricScope{schema.MetricScopeNode}
 	}
-
-	// 4) Fetch And Convert Data, use idb.LoadNodeData() for query
-
-	rawNodeData, err := idb.LoadNodeData(cluster, metrics, nodes, scopes, from, to, ctx)
+	data, err := idb.load(ctx, cluster, nodeResources(nodes), metrics, scopes, from, to)
 	if err != nil {
-		log.Error(fmt.Sprintf("Error while loading influx nodeData for nodeListData %#v\n", err))
+		log.Errorf("Error while loading influx data for nodeListData: %v", err)
 		return nil, totalNodes, hasNextPage, err
 	}
 
-	data := make(map[string]schema.JobData)
-	for node, nodeData := range rawNodeData {
-		// Init Nested Map Data Structures If Not Found
-		hostData, ok := data[node]
-		if !ok {
-			hostData = make(schema.JobData)
-			data[node] = hostData
-		}
-
-		for metric, nodeMetricData := range nodeData {
-			metricData, ok := hostData[metric]
-			if !ok {
-				metricData = make(map[schema.MetricScope]*schema.JobMetric)
-				data[node][metric] = metricData
-			}
-
-			data[node][metric][schema.MetricScopeNode] = nodeMetricData[0] // Only Node Scope Returned from loadNodeData
+	for _, jobData := range data {
+		if err := resampleJobData(jobData, resolution); err != nil {
+			return nil, totalNodes, hasNextPage, err
 		}
 	}
 
@@ -5,6 +5,7 @@
 package metricdata
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
@@ -39,33 +40,71 @@ type MetricDataRepository interface {
 
 var metricDataRepos map[string]MetricDataRepository = map[string]MetricDataRepository{}
 
+// Creates and initializes the repository described by rawConfig. The
+// repository is wrapped with timeouts, retries and a circuit breaker, see
+// ResilientMetricDataRepository.
+func initMetricDataRepository(cluster string, rawConfig json.RawMessage) (MetricDataRepository, error) {
+	var kind struct {
+		Kind string `json:"kind"`
+	}
+	if err := json.Unmarshal(rawConfig, &kind); err != nil {
+		log.Warn("Error while unmarshaling raw json MetricDataRepository")
+		return nil, err
+	}
+
+	mdr, err := newMetricDataRepository(cluster, kind.Kind)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := mdr.Init(rawConfig); err != nil {
+		log.Errorf("Error initializing MetricDataRepository %v for cluster %v", kind.Kind, cluster)
+		return nil, err
+	}
+
+	// Replayed responses are read from disk, retrying a missing recording
+	// would only delay the error.
+	if replay, ok := mdr.(*ReplayMetricDataRepository); ok && replay.recorder == nil {
+		return mdr, nil
+	}
+
+	return newResilientMetricDataRepository(cluster, kind.Kind, mdr, rawConfig)
+}
+
+func newMetricDataRepository(cluster, kind string) (MetricDataRepository, error) {
+	switch kind {
+	case "cc-metric-store":
+		return &CCMetricStore{}, nil
+	case "influxdb":
+		return &InfluxDBv2DataRepository{}, nil
+	case "prometheus":
+		return &PrometheusDataRepository{}, nil
+	case "prometheus-remote-read":
+		return &PrometheusRemoteReadRepository{}, nil
+	case "replay":
+		return &ReplayMetricDataRepository{cluster: cluster}, nil
+	case "test":
+		return &TestMetricDataRepository{}, nil
+	default:
+		return nil, fmt.Errorf("METRICDATA/METRICDATA > Unknown MetricDataRepository %v for cluster %v", kind, cluster)
+	}
+}
+
 func Init() error {
 	for _, cluster := range config.Keys.Clusters {
 		if cluster.MetricDataRepository != nil {
-			var kind struct {
-				Kind string `json:"kind"`
-			}
-			if err := json.Unmarshal(cluster.MetricDataRepository, &kind); err != nil {
-				log.Warn("Error while unmarshaling raw json MetricDataRepository")
-				return err
-			}
-
 			var mdr MetricDataRepository
-			switch kind.Kind {
-			case "cc-metric-store":
-				mdr = &CCMetricStore{}
-			case "influxdb":
-				mdr = &InfluxDBv2DataRepository{}
-			case "prometheus":
-				mdr = &PrometheusDataRepository{}
-			case "test":
-				mdr = &TestMetricDataRepository{}
-			default:
-				return fmt.Errorf("METRICDATA/METRICDATA > Unknown MetricDataRepository %v for cluster %v", kind.Kind, cluster.Name)
-			}
+			var err error
 
-			if err := mdr.Init(cluster.MetricDataRepository); err != nil {
-				log.Errorf("Error initializing MetricDataRepository %v for cluster %v", kind.Kind, cluster.Name)
+			// A list of repositories is chained, see ChainedMetricDataRepository
+			if raw := bytes.TrimSpace(cluster.MetricDataRepository); len(raw) > 0 && raw[0] == '[' {
+				chain := &ChainedMetricDataRepository{cluster: cluster.Name}
+				err = chain.Init(cluster.MetricDataRepository)
+				mdr = chain
+			} else {
+				mdr, err = initMetricDataRepository(cluster.Name, cluster.MetricDataRepository)
+			}
+			if err != nil {
 				return err
 			}
 			metricDataRepos[cluster.Name] = mdr
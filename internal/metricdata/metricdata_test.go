@@ -0,0 +1,202 @@
+// Copyright (C) NHR@FAU, University Erlangen-Nuremberg.
+// All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+package metricdata
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ClusterCockpit/cc-backend/internal/graph/model"
+	"github.com/ClusterCockpit/cc-backend/pkg/archive"
+	"github.com/ClusterCockpit/cc-backend/pkg/log"
+	"github.com/ClusterCockpit/cc-backend/pkg/schema"
+)
+
+// Start and duration of the test job, four timesteps of 60 seconds
+const (
+	testStart    = 1700000000
+	testDuration = 180
+)
+
+// Creates the cluster "testcluster" with the nodes node01 and node02 of two
+// sockets with two cores of two hardware threads each and two accelerators.
+func setupCluster(t *testing.T) {
+	log.Init("warn", true)
+
+	nl, err := archive.ParseNodeList("node[01-02]")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	clusters, nodeLists := archive.Clusters, archive.NodeLists
+	archive.Clusters = []*schema.Cluster{{
+		Name: "testcluster",
+		MetricConfig: []*schema.MetricConfig{
+			{Name: "cpu_load", Scope: schema.MetricScopeNode, Aggregation: "avg", Timestep: 60},
+			{Name: "flops_any", Scope: schema.MetricScopeHWThread, Aggregation: "sum", Timestep: 60,
+				Unit: schema.Unit{Prefix: "G", Base: "F/s"}},
+			{Name: "ipc", Scope: schema.MetricScopeCore, Aggregation: "avg", Timestep: 60},
+			{Name: "mem_bw", Scope: schema.MetricScopeSocket, Aggregation: "sum", Timestep: 60,
+				Unit: schema.Unit{Prefix: "G", Base: "B/s"}},
+			{Name: "acc_util", Scope: schema.MetricScopeAccelerator, Aggregation: "avg", Timestep: 60},
+		},
+		SubClusters: []*schema.SubCluster{{
+			Name:  "main",
+			Nodes: "node[01-02]",
+			Topology: schema.Topology{
+				Node:         []int{0, 1, 2, 3, 4, 5, 6, 7},
+				Socket:       [][]int{{0, 1, 2, 3}, {4, 5, 6, 7}},
+				MemoryDomain: [][]int{{0, 1, 2, 3}, {4, 5, 6, 7}},
+				Core:         [][]int{{0, 1}, {2, 3}, {4, 5}, {6, 7}},
+				Accelerators: []*schema.Accelerator{{ID: "gpu0"}, {ID: "gpu1"}},
+			},
+		}},
+	}}
+	archive.NodeLists = map[string]map[string]archive.NodeList{"testcluster": {"main": nl}}
+
+	t.Cleanup(func() {
+		archive.Clusters, archive.NodeLists = clusters, nodeLists
+	})
+}
+
+func testJob() *schema.Job {
+	job := &schema.Job{StartTime: time.Unix(testStart, 0)}
+	job.JobID = 1234
+	job.Cluster = "testcluster"
+	job.Duration = testDuration
+	job.Resources = []*schema.Resource{{Hostname: "node01"}}
+	return job
+}
+
+// Node scope series of a metric, one per host
+func nodeMetric(timestep int, hosts map[string][]schema.Float) *schema.JobMetric {
+	jm := &schema.JobMetric{Timestep: timestep}
+	for _, host := range []string{"node01", "node02"} {
+		if data, ok := hosts[host]; ok {
+			jm.Series = append(jm.Series, schema.Series{Hostname: host, Data: data, Statistics: seriesStatistics(data)})
+		}
+	}
+	return jm
+}
+
+// Returns the series data of the metric at the scope by host and id
+func seriesData(t *testing.T, data schema.JobData, metric string, scope schema.MetricScope) map[string]string {
+	t.Helper()
+	jm, ok := data[metric][scope]
+	if !ok {
+		t.Fatalf("%s at scope %s missing", metric, scope)
+	}
+
+	res := make(map[string]string, len(jm.Series))
+	for i := range jm.Series {
+		res[seriesKey(&jm.Series[i])] = fmt.Sprint(jm.Series[i].Data)
+	}
+	return res
+}
+
+// Repository returning fixed node scope data, or err if set. Records the
+// metrics of every call.
+type fakeRepository struct {
+	mu      sync.Mutex
+	data    schema.JobData
+	err     error
+	delay   time.Duration
+	panics  bool
+	metrics [][]string
+}
+
+func (f *fakeRepository) Init(rawConfig json.RawMessage) error {
+	return nil
+}
+
+func (f *fakeRepository) calls() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.metrics)
+}
+
+func (f *fakeRepository) load(ctx context.Context, metrics []string) (schema.JobData, error) {
+	f.mu.Lock()
+	f.metrics = append(f.metrics, metrics)
+	data, err, delay := f.data, f.err, f.delay
+	f.mu.Unlock()
+
+	if f.panics {
+		panic("broken repository")
+	}
+	if delay > 0 {
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	res := make(schema.JobData, len(metrics))
+	for _, metric := range metrics {
+		if scopes, ok := data[metric]; ok {
+			res[metric] = make(map[schema.MetricScope]*schema.JobMetric, len(scopes))
+			for scope, jm := range scopes {
+				// Callers may modify the result
+				c := *jm
+				c.Series = make([]schema.Series, len(jm.Series))
+				copy(c.Series, jm.Series)
+				res[metric][scope] = &c
+			}
+		}
+	}
+	return res, nil
+}
+
+func (f *fakeRepository) LoadData(job *schema.Job, metrics []string, scopes []schema.MetricScope, ctx context.Context, resolution int) (schema.JobData, error) {
+	return f.load(ctx, metrics)
+}
+
+func (f *fakeRepository) LoadStats(job *schema.Job, metrics []string, ctx context.Context) (map[string]map[string]schema.MetricStatistics, error) {
+	data, err := f.load(ctx, metrics)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := make(map[string]map[string]schema.MetricStatistics, len(data))
+	for metric, scopes := range data {
+		stats[metric] = make(map[string]schema.MetricStatistics)
+		for _, s := range scopes[schema.MetricScopeNode].Series {
+			stats[metric][s.Hostname] = s.Statistics
+		}
+	}
+	return stats, nil
+}
+
+func (f *fakeRepository) LoadScopedStats(job *schema.Job, metrics []string, scopes []schema.MetricScope, ctx context.Context) (schema.ScopedJobStats, error) {
+	data, err := f.load(ctx, metrics)
+	if err != nil {
+		return nil, err
+	}
+	return hostsScopedStats(job.Resources, map[string]schema.JobData{job.Resources[0].Hostname: data}), nil
+}
+
+func (f *fakeRepository) LoadNodeData(cluster string, metrics, nodes []string, scopes []schema.MetricScope, from, to time.Time, ctx context.Context) (map[string]map[string][]*schema.JobMetric, error) {
+	data, err := f.load(ctx, metrics)
+	if err != nil {
+		return nil, err
+	}
+	return hostsNodeData(map[string]schema.JobData{"node01": data}), nil
+}
+
+func (f *fakeRepository) LoadNodeListData(cluster, subCluster, nodeFilter string, metrics []string, scopes []schema.MetricScope, resolution int, from, to time.Time, page *model.PageRequest, ctx context.Context) (map[string]schema.JobData, int, bool, error) {
+	data, err := f.load(ctx, metrics)
+	if err != nil {
+		return nil, 0, false, err
+	}
+	return map[string]schema.JobData{"node01": data}, 2, true, nil
+}
@@ -0,0 +1,192 @@
+// Copyright (C) NHR@FAU, University Erlangen-Nuremberg.
+// All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+package metricdata
+
+import (
+	"fmt"
+	"math"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// Minimal encoding of the Prometheus remote-read protocol messages (see
+// prompb/remote.proto and prompb/types.proto in the Prometheus repository).
+// Only the fields required for sample responses are supported.
+
+type prrMatchType uint64
+
+const (
+	prrMatchEqual prrMatchType = iota
+	prrMatchNotEqual
+	prrMatchRegexp
+	prrMatchNotRegexp
+)
+
+type prrMatcher struct {
+	Type  prrMatchType
+	Name  string
+	Value string
+}
+
+type prrQuery struct {
+	StartMs  int64
+	EndMs    int64
+	Matchers []prrMatcher
+}
+
+type prrTimeSeries struct {
+	Labels  map[string]string
+	Samples []rawSample
+}
+
+func encodeReadRequest(queries []prrQuery) []byte {
+	var b []byte
+	for _, q := range queries {
+		var qb []byte
+		qb = protowire.AppendTag(qb, 1, protowire.VarintType)
+		qb = protowire.AppendVarint(qb, uint64(q.StartMs))
+		qb = protowire.AppendTag(qb, 2, protowire.VarintType)
+		qb = protowire.AppendVarint(qb, uint64(q.EndMs))
+		for _, m := range q.Matchers {
+			var mb []byte
+			mb = protowire.AppendTag(mb, 1, protowire.VarintType)
+			mb = protowire.AppendVarint(mb, uint64(m.Type))
+			mb = protowire.AppendTag(mb, 2, protowire.BytesType)
+			mb = protowire.AppendString(mb, m.Name)
+			mb = protowire.AppendTag(mb, 3, protowire.BytesType)
+			mb = protowire.AppendString(mb, m.Value)
+
+			qb = protowire.AppendTag(qb, 3, protowire.BytesType)
+			qb = protowire.AppendBytes(qb, mb)
+		}
+
+		b = protowire.AppendTag(b, 1, protowire.BytesType)
+		b = protowire.AppendBytes(b, qb)
+	}
+
+	// accepted_response_types: SAMPLES
+	b = protowire.AppendTag(b, 2, protowire.VarintType)
+	b = protowire.AppendVarint(b, 0)
+
+	return b
+}
+
+// Calls fn for every field of the message in b. Length-delimited fields are
+// passed as bytes, varint and fixed64 fields as number, all other fields are
+// skipped.
+func consumeFields(b []byte, fn func(num protowire.Number, bytes []byte, number uint64) error) error {
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		b = b[n:]
+
+		var err error
+		switch typ {
+		case protowire.BytesType:
+			var v []byte
+			v, n = protowire.ConsumeBytes(b)
+			if n >= 0 {
+				err = fn(num, v, 0)
+			}
+		case protowire.VarintType:
+			var v uint64
+			v, n = protowire.ConsumeVarint(b)
+			if n >= 0 {
+				err = fn(num, nil, v)
+			}
+		case protowire.Fixed64Type:
+			var v uint64
+			v, n = protowire.ConsumeFixed64(b)
+			if n >= 0 {
+				err = fn(num, nil, v)
+			}
+		default:
+			n = protowire.ConsumeFieldValue(num, typ, b)
+		}
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		if err != nil {
+			return err
+		}
+		b = b[n:]
+	}
+
+	return nil
+}
+
+// Decodes a ReadResponse into the time series of each query result
+func decodeReadResponse(b []byte) ([][]prrTimeSeries, error) {
+	results := make([][]prrTimeSeries, 0)
+	err := consumeFields(b, func(num protowire.Number, rb []byte, _ uint64) error {
+		if num != 1 {
+			return nil
+		}
+
+		series := make([]prrTimeSeries, 0)
+		if err := consumeFields(rb, func(num protowire.Number, tb []byte, _ uint64) error {
+			if num != 1 {
+				return nil
+			}
+			ts, err := decodeTimeSeries(tb)
+			if err != nil {
+				return err
+			}
+			series = append(series, ts)
+			return nil
+		}); err != nil {
+			return err
+		}
+
+		results = append(results, series)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("METRICDATA/PROMETHEUS-REMOTE-READ > decoding response failed: %w", err)
+	}
+
+	return results, nil
+}
+
+func decodeTimeSeries(b []byte) (prrTimeSeries, error) {
+	ts := prrTimeSeries{Labels: make(map[string]string)}
+	err := consumeFields(b, func(num protowire.Number, v []byte, _ uint64) error {
+		switch num {
+		case 1:
+			var name, value string
+			if err := consumeFields(v, func(num protowire.Number, s []byte, _ uint64) error {
+				switch num {
+				case 1:
+					name = string(s)
+				case 2:
+					value = string(s)
+				}
+				return nil
+			}); err != nil {
+				return err
+			}
+			ts.Labels[name] = value
+		case 2:
+			var s rawSample
+			if err := consumeFields(v, func(num protowire.Number, _ []byte, x uint64) error {
+				switch num {
+				case 1:
+					s.Value = math.Float64frombits(x)
+				case 2:
+					s.Timestamp = int64(x)
+				}
+				return nil
+			}); err != nil {
+				return err
+			}
+			ts.Samples = append(ts.Samples, s)
+		}
+		return nil
+	})
+
+	return ts, err
+}
@@ -0,0 +1,325 @@
+// Copyright (C) NHR@FAU, University Erlangen-Nuremberg.
+// All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+package metricdata
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ClusterCockpit/cc-backend/internal/graph/model"
+	"github.com/ClusterCockpit/cc-backend/pkg/archive"
+	"github.com/ClusterCockpit/cc-backend/pkg/log"
+	"github.com/ClusterCockpit/cc-backend/pkg/schema"
+	"github.com/klauspost/compress/s2"
+)
+
+type PrometheusRemoteReadConfig struct {
+	// Remote-read endpoint, e.g. http://thanos-query:10902/api/v1/read
+	Url      string `json:"url"`
+	Username string `json:"username,omitempty"`
+	Suffix   string `json:"suffix,omitempty"`
+
+	// Labels holding the hostname, the scope (node, socket, core, ...) and
+	// the id within the scope of a series.
+	HostLabel   string `json:"host-label,omitempty"`
+	TypeLabel   string `json:"type-label,omitempty"`
+	TypeIdLabel string `json:"type-id-label,omitempty"`
+
+	// Optional mapping of local to remote metric names
+	Renamings map[string]string `json:"metric-renamings,omitempty"`
+}
+
+// Fetches raw samples via the Prometheus remote-read protocol. All metrics
+// of a request are fetched with a single HTTP request, scopes are selected
+// by label matching and aggregated according to the cluster topology.
+type PrometheusRemoteReadRepository struct {
+	client      http.Client
+	url         string
+	username    string
+	password    string
+	suffix      string
+	hostLabel   string
+	typeLabel   string
+	typeIdLabel string
+	renamings   map[string]string
+}
+
+func (prr *PrometheusRemoteReadRepository) Init(rawConfig json.RawMessage) error {
+	var config PrometheusRemoteReadConfig
+	if err := json.Unmarshal(rawConfig, &config); err != nil {
+		log.Warn("Error while unmarshaling raw json config")
+		return err
+	}
+
+	prr.url = config.Url
+	prr.username = config.Username
+	if config.Username != "" {
+		if prr.password = os.Getenv("PROMETHEUS_PASSWORD"); prr.password == "" {
+			return errors.New("METRICDATA/PROMETHEUS-REMOTE-READ > Prometheus username provided, but PROMETHEUS_PASSWORD not set")
+		}
+	}
+	prr.suffix = config.Suffix
+	prr.hostLabel = config.HostLabel
+	if prr.hostLabel == "" {
+		prr.hostLabel = "exported_instance"
+	}
+	prr.typeLabel = config.TypeLabel
+	if prr.typeLabel == "" {
+		prr.typeLabel = "type"
+	}
+	prr.typeIdLabel = config.TypeIdLabel
+	if prr.typeIdLabel == "" {
+		prr.typeIdLabel = "type_id"
+	}
+	prr.renamings = config.Renamings
+	prr.client = http.Client{
+		Timeout: 30 * time.Second,
+	}
+
+	return nil
+}
+
+func (prr *PrometheusRemoteReadRepository) read(
+	ctx context.Context,
+	queries []prrQuery,
+) ([][]prrTimeSeries, error) {
+	body := s2.EncodeSnappy(nil, encodeReadRequest(queries))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, prr.url, bytes.NewReader(body))
+	if err != nil {
+		log.Warn("Error while building remote-read request")
+		return nil, err
+	}
+	req.Header.Set("Content-Encoding", "snappy")
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	req.Header.Set("X-Prometheus-Remote-Read-Version", "0.1.0")
+	if prr.username != "" {
+		req.SetBasicAuth(prr.username, prr.password)
+	}
+
+	res, err := prr.client.Do(req)
+	if err != nil {
+		log.Error("Error while performing remote-read request")
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		log.Warn("Error while reading remote-read response")
+		return nil, err
+	}
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("METRICDATA/PROMETHEUS-REMOTE-READ > '%s': HTTP Status: %s: %s",
+			prr.url, res.Status, strings.TrimSpace(string(data)))
+	}
+
+	if data, err = s2.Decode(nil, data); err != nil {
+		log.Warn("Error while decompressing remote-read response")
+		return nil, err
+	}
+	results, err := decodeReadResponse(data)
+	if err != nil {
+		return nil, err
+	}
+	if len(results) != len(queries) {
+		return nil, fmt.Errorf("METRICDATA/PROMETHEUS-REMOTE-READ > expected %d query results, got %d",
+			len(queries), len(results))
+	}
+
+	return results, nil
+}
+
+func (prr *PrometheusRemoteReadRepository) remoteName(metric string) string {
+	if name, ok := prr.renamings[metric]; ok {
+		return name
+	}
+	return metric
+}
+
+// Loads the requested metrics and scopes of all resources. Returns the job
+// data of each host at the native timestep of the metrics.
+func (prr *PrometheusRemoteReadRepository) load(
+	ctx context.Context,
+	cluster string,
+	resources []*schema.Resource,
+	metrics []string,
+	scopes []schema.MetricScope,
+	from, to time.Time,
+) (map[string]schema.JobData, error) {
+	topologies, hosts, err := resourceTopologies(cluster, resources)
+	if err != nil {
+		return nil, err
+	}
+
+	mcs := make([]*schema.MetricConfig, 0, len(metrics))
+	queries := make([]prrQuery, 0, len(metrics))
+	for _, metric := range metrics {
+		mc := archive.GetMetricConfig(cluster, metric)
+		if mc == nil {
+			log.Warnf("Metric %s for cluster %s not configured", metric, cluster)
+			continue
+		}
+
+		typeMatcher := prrMatcher{Type: prrMatchEqual, Name: prr.typeLabel, Value: string(mc.Scope)}
+		if mc.Scope == schema.MetricScopeNode {
+			typeMatcher = prrMatcher{Type: prrMatchRegexp, Name: prr.typeLabel, Value: "node|"}
+		}
+		mcs = append(mcs, mc)
+		queries = append(queries, prrQuery{
+			StartMs: from.UnixMilli(),
+			EndMs:   to.UnixMilli(),
+			Matchers: []prrMatcher{
+				{Type: prrMatchEqual, Name: "__name__", Value: prr.remoteName(metric)},
+				{Type: prrMatchRegexp, Name: prr.hostLabel, Value: fmt.Sprintf("(%s)%s", nodeRegex(hosts), prr.suffix)},
+				typeMatcher,
+			},
+		})
+	}
+
+	if len(queries) == 0 || len(hosts) == 0 {
+		return make(map[string]schema.JobData), nil
+	}
+
+	results, err := prr.read(ctx, queries)
+	if err != nil {
+		log.Errorf("Prometheus remote-read error: %v", err)
+		return nil, err
+	}
+
+	native := make([]nativeSeries, len(mcs))
+	for i, mc := range mcs {
+		native[i] = make(nativeSeries)
+		for _, ts := range results[i] {
+			host := strings.TrimSuffix(ts.Labels[prr.hostLabel], prr.suffix)
+			if native[i][host] == nil {
+				native[i][host] = make(map[string][]schema.Float)
+			}
+			id := ""
+			if mc.Scope != schema.MetricScopeNode {
+				id = ts.Labels[prr.typeIdLabel]
+			}
+			native[i][host][id] = binSamples(ts.Samples, from, to, mc.Timestep)
+		}
+	}
+
+	return scopeNativeSeries(resources, topologies, mcs, native, scopes), nil
+}
+
+func (prr *PrometheusRemoteReadRepository) LoadData(
+	job *schema.Job,
+	metrics []string,
+	scopes []schema.MetricScope,
+	ctx context.Context,
+	resolution int,
+) (schema.JobData, error) {
+	if len(scopes) == 0 {
+		scopes = []schema.MetricScope{schema.MetricScopeNode}
+	}
+	from, to := jobRange(job)
+	hostData, err := prr.load(ctx, job.Cluster, job.Resources, metrics, scopes, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	jobData := hostsJobData(job.Resources, hostData)
+	if err := resampleJobData(jobData, resolution); err != nil {
+		return nil, err
+	}
+
+	return jobData, nil
+}
+
+func (prr *PrometheusRemoteReadRepository) LoadStats(
+	job *schema.Job,
+	metrics []string,
+	ctx context.Context,
+) (map[string]map[string]schema.MetricStatistics, error) {
+	from, to := jobRange(job)
+	hostData, err := prr.load(ctx, job.Cluster, job.Resources, metrics,
+		[]schema.MetricScope{schema.MetricScopeNode}, from, to)
+	if err != nil {
+		log.Warn("Error while loading job for stats")
+		return nil, err
+	}
+
+	return hostsStats(hostData, len(metrics)), nil
+}
+
+func (prr *PrometheusRemoteReadRepository) LoadScopedStats(
+	job *schema.Job,
+	metrics []string,
+	scopes []schema.MetricScope,
+	ctx context.Context,
+) (schema.ScopedJobStats, error) {
+	if len(scopes) == 0 {
+		scopes = []schema.MetricScope{schema.MetricScopeNode}
+	}
+	from, to := jobRange(job)
+	hostData, err := prr.load(ctx, job.Cluster, job.Resources, metrics, scopes, from, to)
+	if err != nil {
+		log.Warn("Error while loading job for scopedJobStats")
+		return nil, err
+	}
+
+	return hostsScopedStats(job.Resources, hostData), nil
+}
+
+func (prr *PrometheusRemoteReadRepository) LoadNodeData(
+	cluster string,
+	metrics, nodes []string,
+	scopes []schema.MetricScope,
+	from, to time.Time,
+	ctx context.Context,
+) (map[string]map[string][]*schema.JobMetric, error) {
+	if nodes == nil {
+		nodes = clusterNodes(cluster, "")
+	}
+	if len(scopes) == 0 {
+		scopes = []schema.MetricScope{schema.MetricScopeNode}
+	}
+
+	hostData, err := prr.load(ctx, cluster, nodeResources(nodes), metrics, scopes, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	return hostsNodeData(hostData), nil
+}
+
+func (prr *PrometheusRemoteReadRepository) LoadNodeListData(
+	cluster, subCluster, nodeFilter string,
+	metrics []string,
+	scopes []schema.MetricScope,
+	resolution int,
+	from, to time.Time,
+	page *model.PageRequest,
+	ctx context.Context,
+) (map[string]schema.JobData, int, bool, error) {
+	nodes, totalNodes, hasNextPage := pagedNodeList(cluster, subCluster, nodeFilter, page)
+
+	if len(scopes) == 0 {
+		scopes = []schema.MetricScope{schema.MetricScopeNode}
+	}
+	data, err := prr.load(ctx, cluster, nodeResources(nodes), metrics, scopes, from, to)
+	if err != nil {
+		return nil, totalNodes, hasNextPage, err
+	}
+
+	for _, jobData := range data {
+		if err := resampleJobData(jobData, resolution); err != nil {
+			return nil, totalNodes, hasNextPage, err
+		}
+	}
+
+	return data, totalNodes, hasNextPage, nil
+}
@@ -0,0 +1,412 @@
+// Copyright (C) NHR@FAU, University Erlangen-Nuremberg.
+// All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+package metricdata
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/ClusterCockpit/cc-backend/pkg/schema"
+	"github.com/klauspost/compress/s2"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// Decodes a ReadRequest, the counterpart of encodeReadRequest. Returns the
+// queries and the accepted response types.
+func decodeReadRequest(b []byte) ([]prrQuery, []uint64, error) {
+	queries := make([]prrQuery, 0)
+	types := make([]uint64, 0)
+	err := consumeFields(b, func(num protowire.Number, qb []byte, x uint64) error {
+		if num == 2 {
+			types = append(types, x)
+			return nil
+		}
+
+		var q prrQuery
+		if err := consumeFields(qb, func(num protowire.Number, mb []byte, x uint64) error {
+			switch num {
+			case 1:
+				q.StartMs = int64(x)
+			case 2:
+				q.EndMs = int64(x)
+			case 3:
+				var m prrMatcher
+				if err := consumeFields(mb, func(num protowire.Number, s []byte, x uint64) error {
+					switch num {
+					case 1:
+						m.Type = prrMatchType(x)
+					case 2:
+						m.Name = string(s)
+					case 3:
+						m.Value = string(s)
+					}
+					return nil
+				}); err != nil {
+					return err
+				}
+				q.Matchers = append(q.Matchers, m)
+			}
+			return nil
+		}); err != nil {
+			return err
+		}
+		queries = append(queries, q)
+		return nil
+	})
+
+	return queries, types, err
+}
+
+// Encodes a ReadResponse, the counterpart of decodeReadResponse
+func encodeReadResponse(results [][]prrTimeSeries) []byte {
+	var b []byte
+	for _, series := range results {
+		var rb []byte
+		for _, ts := range series {
+			var tb []byte
+			names := make([]string, 0, len(ts.Labels))
+			for name := range ts.Labels {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			for _, name := range names {
+				var lb []byte
+				lb = protowire.AppendTag(lb, 1, protowire.BytesType)
+				lb = protowire.AppendString(lb, name)
+				lb = protowire.AppendTag(lb, 2, protowire.BytesType)
+				lb = protowire.AppendString(lb, ts.Labels[name])
+				tb = protowire.AppendTag(tb, 1, protowire.BytesType)
+				tb = protowire.AppendBytes(tb, lb)
+			}
+			for _, s := range ts.Samples {
+				var sb []byte
+				sb = protowire.AppendTag(sb, 1, protowire.Fixed64Type)
+				sb = protowire.AppendFixed64(sb, math.Float64bits(s.Value))
+				sb = protowire.AppendTag(sb, 2, protowire.VarintType)
+				sb = protowire.AppendVarint(sb, uint64(s.Timestamp))
+				tb = protowire.AppendTag(tb, 2, protowire.BytesType)
+				tb = protowire.AppendBytes(tb, sb)
+			}
+			rb = protowire.AppendTag(rb, 1, protowire.BytesType)
+			rb = protowire.AppendBytes(rb, tb)
+		}
+		b = protowire.AppendTag(b, 1, protowire.BytesType)
+		b = protowire.AppendBytes(b, rb)
+	}
+
+	return b
+}
+
+func (m prrMatcher) matches(labels map[string]string) bool {
+	value := labels[m.Name]
+	switch m.Type {
+	case prrMatchEqual:
+		return value == m.Value
+	case prrMatchNotEqual:
+		return value != m.Value
+	case prrMatchRegexp:
+		return regexp.MustCompile("^(?:" + m.Value + ")$").MatchString(value)
+	case prrMatchNotRegexp:
+		return !regexp.MustCompile("^(?:" + m.Value + ")$").MatchString(value)
+	}
+	return false
+}
+
+// Remote-read endpoint answering every query with the series matching all
+// of its matchers. The decoded queries of every request are recorded.
+type remoteReadServer struct {
+	*httptest.Server
+	mu       sync.Mutex
+	series   []prrTimeSeries
+	requests [][]prrQuery
+}
+
+func newRemoteReadServer(t *testing.T, series []prrTimeSeries) *remoteReadServer {
+	srv := &remoteReadServer{series: series}
+	srv.Server = httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.Header.Get("Content-Encoding") != "snappy" ||
+			r.Header.Get("Content-Type") != "application/x-protobuf" {
+			http.Error(rw, "invalid request", http.StatusBadRequest)
+			return
+		}
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(rw, err.Error(), http.StatusBadRequest)
+			return
+		}
+		// Snappy block format, as required by the protocol
+		data, err := s2.Decode(nil, body)
+		if err != nil {
+			http.Error(rw, err.Error(), http.StatusBadRequest)
+			return
+		}
+		queries, types, err := decodeReadRequest(data)
+		if err != nil || !reflect.DeepEqual(types, []uint64{0}) {
+			http.Error(rw, fmt.Sprintf("invalid read request: %v %v", types, err), http.StatusBadRequest)
+			return
+		}
+
+		srv.mu.Lock()
+		srv.requests = append(srv.requests, queries)
+		srv.mu.Unlock()
+
+		results := make([][]prrTimeSeries, 0, len(queries))
+		for _, q := range queries {
+			res := make([]prrTimeSeries, 0)
+		series:
+			for _, ts := range srv.series {
+				for _, m := range q.Matchers {
+					if !m.matches(ts.Labels) {
+						continue series
+					}
+				}
+				samples := make([]rawSample, 0, len(ts.Samples))
+				for _, s := range ts.Samples {
+					if s.Timestamp >= q.StartMs && s.Timestamp <= q.EndMs {
+						samples = append(samples, s)
+					}
+				}
+				res = append(res, prrTimeSeries{Labels: ts.Labels, Samples: samples})
+			}
+			results = append(results, res)
+		}
+
+		rw.Header().Set("Content-Type", "application/x-protobuf")
+		rw.Header().Set("Content-Encoding", "snappy")
+		rw.Write(s2.EncodeSnappy(nil, encodeReadResponse(results)))
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv
+}
+
+// Samples at the timesteps of the test job, NaN values are left out
+func testSamples(values ...float64) []rawSample {
+	samples := make([]rawSample, 0, len(values))
+	for i, v := range values {
+		if !math.IsNaN(v) {
+			samples = append(samples, rawSample{Timestamp: (testStart + int64(i)*60) * 1000, Value: v})
+		}
+	}
+	return samples
+}
+
+// Series of node01 and node02 as written by cc-metric-collector: cpu_load
+// without type label, flops_any of every hardware thread.
+func testSeries() []prrTimeSeries {
+	series := []prrTimeSeries{
+		{Labels: map[string]string{"__name__": "cpu_load", "exported_instance": "node01"}, Samples: testSamples(1, 2, 3, 4)},
+		{Labels: map[string]string{"__name__": "cpu_load", "exported_instance": "node02"}, Samples: testSamples(9, 9, 9, 9)},
+	}
+	for i := 0; i < 8; i++ {
+		series = append(series, prrTimeSeries{
+			Labels: map[string]string{
+				"__name__": "flops_any", "exported_instance": "node01",
+				"type": "hwthread", "type_id": strconv.Itoa(i),
+			},
+			Samples: testSamples(float64(i+1), float64(i+1), math.NaN(), math.NaN()),
+		})
+	}
+	return series
+}
+
+func TestReadRequestEncoding(t *testing.T) {
+	queries := []prrQuery{
+		{StartMs: 1700000000000, EndMs: 1700000180000, Matchers: []prrMatcher{
+			{Type: prrMatchEqual, Name: "__name__", Value: "cpu_load"},
+			{Type: prrMatchRegexp, Name: "exported_instance", Value: "(node0[12])"},
+		}},
+		{StartMs: 0, EndMs: 1, Matchers: []prrMatcher{
+			{Type: prrMatchNotEqual, Name: "type", Value: ""},
+			{Type: prrMatchNotRegexp, Name: "type_id", Value: "1|2"},
+		}},
+	}
+
+	got, types, err := decodeReadRequest(encodeReadRequest(queries))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, queries) {
+		t.Errorf("got %+v, want %+v", got, queries)
+	}
+	if !reflect.DeepEqual(types, []uint64{0}) {
+		t.Errorf("expected sample response type, got %v", types)
+	}
+}
+
+func TestDecodeReadResponse(t *testing.T) {
+	results := [][]prrTimeSeries{
+		{
+			{Labels: map[string]string{"__name__": "cpu_load", "exported_instance": "node01"},
+				Samples: []rawSample{{Timestamp: 1700000000000, Value: 1.5}, {Timestamp: 1700000060000, Value: -2}}},
+			{Labels: map[string]string{"__name__": "cpu_load", "exported_instance": "node02"},
+				Samples: []rawSample{{Timestamp: 1700000000000, Value: 0}}},
+		},
+		{},
+	}
+
+	got, err := decodeReadResponse(encodeReadResponse(results))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || len(got[1]) != 0 || !reflect.DeepEqual(got[0], results[0]) {
+		t.Errorf("got %+v, want %+v", got, results)
+	}
+
+	// Unknown fields are skipped
+	b := protowire.AppendTag(encodeReadResponse(results), 7, protowire.Fixed32Type)
+	b = protowire.AppendFixed32(b, 42)
+	if got, err := decodeReadResponse(b); err != nil || len(got) != 2 {
+		t.Errorf("unknown field not skipped: %v (%v)", got, err)
+	}
+
+	invalid := map[string][]byte{
+		"truncated":      encodeReadResponse(results)[:20],
+		"invalid tag":    {0x00},
+		"invalid length": {0x0a, 0xff, 0xff, 0xff, 0xff, 0x0f},
+	}
+	for name, b := range invalid {
+		if _, err := decodeReadResponse(b); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func newTestRemoteRead(t *testing.T, url string, config string) *PrometheusRemoteReadRepository {
+	prr := &PrometheusRemoteReadRepository{}
+	if err := prr.Init(json.RawMessage(fmt.Sprintf(`{"kind": "prometheus-remote-read", "url": "%s"%s}`, url, config))); err != nil {
+		t.Fatal(err)
+	}
+	return prr
+}
+
+func TestRemoteReadLoadData(t *testing.T) {
+	setupCluster(t)
+	srv := newRemoteReadServer(t, testSeries())
+	prr := newTestRemoteRead(t, srv.URL, "")
+
+	data, err := prr.LoadData(testJob(), []string{"cpu_load", "flops_any"},
+		[]schema.MetricScope{schema.MetricScopeNode, schema.MetricScopeCore}, context.Background(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		metric string
+		scope  schema.MetricScope
+		want   map[string]string
+	}{
+		{"cpu_load", schema.MetricScopeNode, map[string]string{"node01": "[1 2 3 4]"}},
+		{"flops_any", schema.MetricScopeNode, map[string]string{"node01": "[36 36 NaN NaN]"}},
+		{"flops_any", schema.MetricScopeCore, map[string]string{
+			"node01/0": "[3 3 NaN NaN]", "node01/1": "[7 7 NaN NaN]",
+			"node01/2": "[11 11 NaN NaN]", "node01/3": "[15 15 NaN NaN]",
+		}},
+	}
+	for _, tt := range tests {
+		if got := seriesData(t, data, tt.metric, tt.scope); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s at %s: got %v, want %v", tt.metric, tt.scope, got, tt.want)
+		}
+	}
+	if _, ok := data["cpu_load"][schema.MetricScopeCore]; ok {
+		t.Error("cpu_load at core scope without core series")
+	}
+
+	// All metrics are fetched with a single request
+	if len(srv.requests) != 1 || len(srv.requests[0]) != 2 {
+		t.Fatalf("unexpected requests: %+v", srv.requests)
+	}
+	q := srv.requests[0][0]
+	if q.StartMs != testStart*1000 || q.EndMs != (testStart+testDuration)*1000 {
+		t.Errorf("unexpected time range: %d - %d", q.StartMs, q.EndMs)
+	}
+
+	stats, err := prr.LoadStats(testJob(), []string{"cpu_load"}, context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := stats["cpu_load"]["node01"]; s.Min != 1 || s.Max != 4 || s.Avg != 2.5 {
+		t.Errorf("unexpected statistics: %+v", s)
+	}
+}
+
+func TestRemoteReadRenamings(t *testing.T) {
+	setupCluster(t)
+	series := []prrTimeSeries{{
+		Labels:  map[string]string{"__name__": "load_one", "host": "node01.cluster", "scope": "node"},
+		Samples: testSamples(1, 1, 1, 1),
+	}}
+	srv := newRemoteReadServer(t, series)
+	prr := newTestRemoteRead(t, srv.URL, `, "suffix": ".cluster", "host-label": "host", "type-label": "scope",
+		"metric-renamings": {"cpu_load": "load_one"}`)
+
+	data, err := prr.LoadData(testJob(), []string{"cpu_load"}, nil, context.Background(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := seriesData(t, data, "cpu_load", schema.MetricScopeNode); got["node01"] != "[1 1 1 1]" {
+		t.Errorf("unexpected data: %v", got)
+	}
+}
+
+func TestRemoteReadErrors(t *testing.T) {
+	setupCluster(t)
+
+	failing := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		http.Error(rw, "query timed out", http.StatusServiceUnavailable)
+	}))
+	defer failing.Close()
+	// A response with one result for two queries
+	short := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.Write(s2.EncodeSnappy(nil, encodeReadResponse([][]prrTimeSeries{{}})))
+	}))
+	defer short.Close()
+	uncompressed := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.Write([]byte("not snappy"))
+	}))
+	defer uncompressed.Close()
+
+	tests := []struct {
+		name string
+		url  string
+		want string
+	}{
+		{"status", failing.URL, "query timed out"},
+		{"results", short.URL, "expected 2 query results, got 1"},
+		{"encoding", uncompressed.URL, ""},
+	}
+	for _, tt := range tests {
+		prr := newTestRemoteRead(t, tt.url, "")
+		_, err := prr.LoadData(testJob(), []string{"cpu_load", "flops_any"}, nil, context.Background(), 0)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: unexpected error %v", tt.name, err)
+		}
+	}
+
+	prr := &PrometheusRemoteReadRepository{}
+	t.Setenv("PROMETHEUS_PASSWORD", "")
+	if err := prr.Init(json.RawMessage(`{"url": "http://localhost", "username": "cc"}`)); err == nil {
+		t.Error("expected error for username without password")
+	}
+
+	// Nothing to query
+	prr = newTestRemoteRead(t, failing.URL, "")
+	if data, err := prr.LoadData(testJob(), []string{"unknown"}, nil, context.Background(), 0); err != nil || len(data) != 0 {
+		t.Errorf("unexpected result for unknown metric: %v (%v)", data, err)
+	}
+}
@@ -246,6 +246,9 @@ func (pdb *PrometheusDataRepository) RowToSeries(
 	// copy recorded values from prom sample pair
 	for _, v := range row.Values {
 		idx := (v.Timestamp.Unix() - ts) / step
+		if idx < 0 || idx > steps {
+			continue
+		}
 		values[idx] = schema.Float(v.Value)
 	}
 	min, max, mean := MinMaxMean(values)
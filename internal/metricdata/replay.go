@@ -0,0 +1,297 @@
+// Copyright (C) NHR@FAU, University Erlangen-Nuremberg.
+// All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+package metricdata
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/ClusterCockpit/cc-backend/internal/graph/model"
+	"github.com/ClusterCockpit/cc-backend/pkg/log"
+	"github.com/ClusterCockpit/cc-backend/pkg/schema"
+)
+
+type ReplayMetricDataRepositoryConfig struct {
+	// Directory containing the recordings
+	Path string `json:"path"`
+	// If set, requests are passed to this repository and its responses are
+	// written to Path. Otherwise all responses are read from Path.
+	Record json.RawMessage `json:"record,omitempty"`
+}
+
+// Serves metric data from files recorded by a previous run in recording
+// mode. Every request is stored in its own file, named after the method
+// and a hash of the request arguments:
+//
+//	<path>/<cluster>/<method>-<hash>.json
+//
+// The files contain the request, the response and the error message (if
+// any) as JSON and can be edited by hand.
+type ReplayMetricDataRepository struct {
+	cluster  string
+	path     string
+	recorder MetricDataRepository
+}
+
+// Arguments of a request, used to look up the recording
+type replayRequest struct {
+	Method     string               `json:"method"`
+	Cluster    string               `json:"cluster"`
+	SubCluster string               `json:"subCluster,omitempty"`
+	JobID      int64                `json:"jobId,omitempty"`
+	StartTime  int64                `json:"startTime,omitempty"`
+	Metrics    []string             `json:"metrics"`
+	Scopes     []schema.MetricScope `json:"scopes,omitempty"`
+	Nodes      []string             `json:"nodes,omitempty"`
+	NodeFilter string               `json:"nodeFilter,omitempty"`
+	Resolution int                  `json:"resolution,omitempty"`
+	From       int64                `json:"from,omitempty"`
+	To         int64                `json:"to,omitempty"`
+	Page       *model.PageRequest   `json:"page,omitempty"`
+}
+
+type replayRecording[T any] struct {
+	Request  *replayRequest `json:"request"`
+	Response T              `json:"response"`
+	Error    string         `json:"error,omitempty"`
+}
+
+type replayNodeListResponse struct {
+	Data        map[string]schema.JobData `json:"data"`
+	TotalNodes  int                       `json:"totalNodes"`
+	HasNextPage bool                      `json:"hasNextPage"`
+}
+
+func (r *ReplayMetricDataRepository) Init(rawConfig json.RawMessage) error {
+	var config ReplayMetricDataRepositoryConfig
+	if err := json.Unmarshal(rawConfig, &config); err != nil {
+		log.Warn("Error while unmarshaling raw json config")
+		return err
+	}
+	if config.Path == "" {
+		return errors.New("METRICDATA/REPLAY > no path configured")
+	}
+	r.path = config.Path
+
+	if config.Record == nil {
+		if _, err := os.Stat(r.path); err != nil {
+			return fmt.Errorf("METRICDATA/REPLAY > recordings not found: %w", err)
+		}
+		return nil
+	}
+
+	var kind struct {
+		Kind string `json:"kind"`
+	}
+	if err := json.Unmarshal(config.Record, &kind); err != nil {
+		log.Warn("Error while unmarshaling raw json config")
+		return err
+	}
+	if kind.Kind == "replay" {
+		return errors.New("METRICDATA/REPLAY > cannot record a replay repository")
+	}
+	recorder, err := newMetricDataRepository(r.cluster, kind.Kind)
+	if err != nil {
+		return err
+	}
+	if err := recorder.Init(config.Record); err != nil {
+		return err
+	}
+	r.recorder = recorder
+	log.Infof("Recording metric data of cluster %s (%s) to %s", r.cluster, kind.Kind, r.path)
+
+	return nil
+}
+
+// Metrics, scopes and nodes are sorted so that the recording does not depend
+// on the order of the arguments.
+func newReplayRequest(method, cluster string, metrics []string, scopes []schema.MetricScope) *replayRequest {
+	req := &replayRequest{
+		Method:  method,
+		Cluster: cluster,
+		Metrics: slices.Clone(metrics),
+		Scopes:  slices.Clone(scopes),
+	}
+	slices.Sort(req.Metrics)
+	slices.Sort(req.Scopes)
+	return req
+}
+
+func newReplayJobRequest(method string, job *schema.Job, metrics []string, scopes []schema.MetricScope) *replayRequest {
+	req := newReplayRequest(method, job.Cluster, metrics, scopes)
+	req.JobID = job.JobID
+	req.StartTime = job.StartTime.Unix()
+	return req
+}
+
+func (r *ReplayMetricDataRepository) file(req *replayRequest) string {
+	b, _ := json.Marshal(req)
+	hash := sha256.Sum256(b)
+	return filepath.Join(r.path, req.Cluster,
+		fmt.Sprintf("%s-%s.json", req.Method, hex.EncodeToString(hash[:8])))
+}
+
+func replay[T any](r *ReplayMetricDataRepository, req *replayRequest) (T, error) {
+	var rec replayRecording[T]
+	b, err := os.ReadFile(r.file(req))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return rec.Response, fmt.Errorf("METRICDATA/REPLAY > no recording of %s for cluster %s", req.Method, req.Cluster)
+		}
+		return rec.Response, fmt.Errorf("METRICDATA/REPLAY > %w", err)
+	}
+	if err := json.Unmarshal(b, &rec); err != nil {
+		return rec.Response, fmt.Errorf("METRICDATA/REPLAY > invalid recording %s: %w", r.file(req), err)
+	}
+	if rec.Error != "" {
+		return rec.Response, errors.New(rec.Error)
+	}
+	return rec.Response, nil
+}
+
+// Writes the response to disk. Failures are only logged as the response is
+// still passed to the caller. Requests cancelled by the caller are not
+// recorded.
+func record[T any](ctx context.Context, r *ReplayMetricDataRepository, req *replayRequest, res T, err error) {
+	if ctx.Err() != nil {
+		return
+	}
+
+	rec := replayRecording[T]{Request: req, Response: res}
+	if err != nil {
+		rec.Error = err.Error()
+	}
+
+	b, merr := json.MarshalIndent(rec, "", "  ")
+	if merr != nil {
+		log.Warnf("METRICDATA/REPLAY > cannot record %s for cluster %s: %s", req.Method, req.Cluster, merr.Error())
+		return
+	}
+
+	file := r.file(req)
+	if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+		log.Warnf("METRICDATA/REPLAY > cannot record %s for cluster %s: %s", req.Method, req.Cluster, err.Error())
+		return
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(file), ".recording-*")
+	if err != nil {
+		log.Warnf("METRICDATA/REPLAY > cannot record %s for cluster %s: %s", req.Method, req.Cluster, err.Error())
+		return
+	}
+	_, err = tmp.Write(b)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), file)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		log.Warnf("METRICDATA/REPLAY > cannot record %s for cluster %s: %s", req.Method, req.Cluster, err.Error())
+	}
+}
+
+func (r *ReplayMetricDataRepository) LoadData(
+	job *schema.Job,
+	metrics []string,
+	scopes []schema.MetricScope,
+	ctx context.Context,
+	resolution int,
+) (schema.JobData, error) {
+	req := newReplayJobRequest("LoadData", job, metrics, scopes)
+	req.Resolution = resolution
+
+	if r.recorder == nil {
+		return replay[schema.JobData](r, req)
+	}
+	data, err := r.recorder.LoadData(job, metrics, scopes, ctx, resolution)
+	record(ctx, r, req, data, err)
+	return data, err
+}
+
+func (r *ReplayMetricDataRepository) LoadStats(
+	job *schema.Job,
+	metrics []string,
+	ctx context.Context,
+) (map[string]map[string]schema.MetricStatistics, error) {
+	req := newReplayJobRequest("LoadStats", job, metrics, nil)
+
+	if r.recorder == nil {
+		return replay[map[string]map[string]schema.MetricStatistics](r, req)
+	}
+	stats, err := r.recorder.LoadStats(job, metrics, ctx)
+	record(ctx, r, req, stats, err)
+	return stats, err
+}
+
+func (r *ReplayMetricDataRepository) LoadScopedStats(
+	job *schema.Job,
+	metrics []string,
+	scopes []schema.MetricScope,
+	ctx context.Context,
+) (schema.ScopedJobStats, error) {
+	req := newReplayJobRequest("LoadScopedStats", job, metrics, scopes)
+
+	if r.recorder == nil {
+		return replay[schema.ScopedJobStats](r, req)
+	}
+	stats, err := r.recorder.LoadScopedStats(job, metrics, scopes, ctx)
+	record(ctx, r, req, stats, err)
+	return stats, err
+}
+
+func (r *ReplayMetricDataRepository) LoadNodeData(
+	cluster string,
+	metrics, nodes []string,
+	scopes []schema.MetricScope,
+	from, to time.Time,
+	ctx context.Context,
+) (map[string]map[string][]*schema.JobMetric, error) {
+	req := newReplayRequest("LoadNodeData", cluster, metrics, scopes)
+	req.Nodes = slices.Clone(nodes)
+	slices.Sort(req.Nodes)
+	req.From, req.To = from.Unix(), to.Unix()
+
+	if r.recorder == nil {
+		return replay[map[string]map[string][]*schema.JobMetric](r, req)
+	}
+	data, err := r.recorder.LoadNodeData(cluster, metrics, nodes, scopes, from, to, ctx)
+	record(ctx, r, req, data, err)
+	return data, err
+}
+
+func (r *ReplayMetricDataRepository) LoadNodeListData(
+	cluster, subCluster, nodeFilter string,
+	metrics []string,
+	scopes []schema.MetricScope,
+	resolution int,
+	from, to time.Time,
+	page *model.PageRequest,
+	ctx context.Context,
+) (map[string]schema.JobData, int, bool, error) {
+	req := newReplayRequest("LoadNodeListData", cluster, metrics, scopes)
+	req.SubCluster = subCluster
+	req.NodeFilter = nodeFilter
+	req.Resolution = resolution
+	req.From, req.To = from.Unix(), to.Unix()
+	req.Page = page
+
+	if r.recorder == nil {
+		res, err := replay[replayNodeListResponse](r, req)
+		return res.Data, res.TotalNodes, res.HasNextPage, err
+	}
+	data, totalNodes, hasNextPage, err := r.recorder.LoadNodeListData(
+		cluster, subCluster, nodeFilter, metrics, scopes, resolution, from, to, page, ctx)
+	record(ctx, r, req, replayNodeListResponse{Data: data, TotalNodes: totalNodes, HasNextPage: hasNextPage}, err)
+	return data, totalNodes, hasNextPage, err
+}
@@ -0,0 +1,198 @@
+// Copyright (C) NHR@FAU, University Erlangen-Nuremberg.
+// All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+package metricdata
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ClusterCockpit/cc-backend/internal/graph/model"
+	"github.com/ClusterCockpit/cc-backend/pkg/schema"
+)
+
+func newTestReplay(t *testing.T, config string) *ReplayMetricDataRepository {
+	t.Helper()
+	r := &ReplayMetricDataRepository{cluster: "testcluster"}
+	if err := r.Init(json.RawMessage(config)); err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+func recordings(t *testing.T, path string) []string {
+	t.Helper()
+	files, err := filepath.Glob(filepath.Join(path, "testcluster", "*.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return files
+}
+
+func TestReplayInit(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		config string
+		valid  bool
+	}{
+		{fmt.Sprintf(`{"path": "%s"}`, dir), true},
+		{fmt.Sprintf(`{"path": "%s", "record": {"kind": "test"}}`, filepath.Join(dir, "new")), true},
+		{`{}`, false},
+		{fmt.Sprintf(`{"path": "%s"}`, filepath.Join(dir, "missing")), false},
+		{fmt.Sprintf(`{"path": "%s", "record": {"kind": "replay"}}`, dir), false},
+		{fmt.Sprintf(`{"path": "%s", "record": {"kind": "unknown"}}`, dir), false},
+	}
+	for _, tt := range tests {
+		r := &ReplayMetricDataRepository{cluster: "testcluster"}
+		if err := r.Init(json.RawMessage(tt.config)); (err == nil) != tt.valid {
+			t.Errorf("%s: unexpected error %v", tt.config, err)
+		}
+	}
+
+	// Replayed responses are not retried
+	mdr, err := initMetricDataRepository("testcluster", json.RawMessage(fmt.Sprintf(`{"kind": "replay", "path": "%s"}`, dir)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := mdr.(*ReplayMetricDataRepository); !ok {
+		t.Errorf("replay repository wrapped: %T", mdr)
+	}
+}
+
+func TestReplayKeys(t *testing.T) {
+	r := &ReplayMetricDataRepository{path: "/recordings"}
+	job := testJob()
+	key := func(req *replayRequest) string {
+		return filepath.Base(r.file(req))
+	}
+	base := key(newReplayJobRequest("LoadData", job, []string{"cpu_load", "flops_any"},
+		[]schema.MetricScope{schema.MetricScopeNode, schema.MetricScopeCore}))
+
+	if !strings.HasPrefix(base, "LoadData-") || filepath.Dir(r.file(newReplayRequest("LoadData", "testcluster", nil, nil))) != "/recordings/testcluster" {
+		t.Errorf("unexpected file %s", base)
+	}
+
+	// The order of metrics and scopes does not matter
+	if k := key(newReplayJobRequest("LoadData", job, []string{"flops_any", "cpu_load"},
+		[]schema.MetricScope{schema.MetricScopeCore, schema.MetricScopeNode})); k != base {
+		t.Errorf("order changes the key: %s != %s", k, base)
+	}
+
+	other := testJob()
+	other.StartTime = other.StartTime.Add(time.Second)
+	tests := []struct {
+		name string
+		req  *replayRequest
+	}{
+		{"method", newReplayJobRequest("LoadScopedStats", job, []string{"cpu_load", "flops_any"},
+			[]schema.MetricScope{schema.MetricScopeNode, schema.MetricScopeCore})},
+		{"metrics", newReplayJobRequest("LoadData", job, []string{"cpu_load"},
+			[]schema.MetricScope{schema.MetricScopeNode, schema.MetricScopeCore})},
+		{"scopes", newReplayJobRequest("LoadData", job, []string{"cpu_load", "flops_any"},
+			[]schema.MetricScope{schema.MetricScopeNode})},
+		{"start time", newReplayJobRequest("LoadData", other, []string{"cpu_load", "flops_any"},
+			[]schema.MetricScope{schema.MetricScopeNode, schema.MetricScopeCore})},
+	}
+	for _, tt := range tests {
+		if k := key(tt.req); k == base {
+			t.Errorf("%s does not change the key", tt.name)
+		}
+	}
+}
+
+func TestRecordReplay(t *testing.T) {
+	setupCluster(t)
+	srv := newRemoteReadServer(t, testSeries())
+	dir := t.TempDir()
+
+	job := testJob()
+	from, to := time.Unix(testStart, 0), time.Unix(testStart+testDuration, 0)
+	page := &model.PageRequest{ItemsPerPage: 1, Page: 1}
+	scopes := []schema.MetricScope{schema.MetricScopeNode, schema.MetricScopeCore}
+
+	rec := newTestReplay(t, fmt.Sprintf(`{"path": "%s", "record": {"kind": "prometheus-remote-read", "url": "%s"}}`, dir, srv.URL))
+	recData, err := rec.LoadData(job, []string{"cpu_load", "flops_any"}, scopes, context.Background(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	recStats, err := rec.LoadStats(job, []string{"cpu_load"}, context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	recNodes, recTotal, recNext, err := rec.LoadNodeListData("testcluster", "main", "", []string{"cpu_load"},
+		nil, 0, from, to, page, context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Requests cancelled by the caller are not recorded
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	rec.LoadData(job, []string{"ipc"}, scopes, ctx, 0)
+
+	if files := recordings(t, dir); len(files) != 3 {
+		t.Fatalf("expected 3 recordings, got %v", files)
+	}
+	if len(srv.requests) != 3 {
+		t.Errorf("expected 3 remote-read requests, got %d", len(srv.requests))
+	}
+
+	// Errors are recorded as well
+	srv.Close()
+	_, recErr := rec.LoadData(job, []string{"mem_bw"}, nil, context.Background(), 0)
+	if recErr == nil {
+		t.Fatal("expected error of closed server")
+	}
+
+	replay := newTestReplay(t, fmt.Sprintf(`{"path": "%s"}`, dir))
+	data, err := replay.LoadData(job, []string{"flops_any", "cpu_load"}, scopes, context.Background(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := seriesData(t, data, "flops_any", schema.MetricScopeCore), seriesData(t, recData, "flops_any", schema.MetricScopeCore); !reflect.DeepEqual(got, want) {
+		t.Errorf("replayed %v, recorded %v", got, want)
+	}
+	if got, want := seriesData(t, data, "cpu_load", schema.MetricScopeNode), seriesData(t, recData, "cpu_load", schema.MetricScopeNode); !reflect.DeepEqual(got, want) {
+		t.Errorf("replayed %v, recorded %v", got, want)
+	}
+
+	stats, err := replay.LoadStats(job, []string{"cpu_load"}, context.Background())
+	if err != nil || !reflect.DeepEqual(stats, recStats) {
+		t.Errorf("replayed %v (%v), recorded %v", stats, err, recStats)
+	}
+
+	nodes, total, next, err := replay.LoadNodeListData("testcluster", "main", "", []string{"cpu_load"},
+		nil, 0, from, to, page, context.Background())
+	if err != nil || total != recTotal || next != recNext || len(nodes) != len(recNodes) {
+		t.Errorf("replayed %v %d %v (%v), recorded %v %d %v", nodes, total, next, err, recNodes, recTotal, recNext)
+	}
+
+	if _, err := replay.LoadData(job, []string{"mem_bw"}, nil, context.Background(), 0); err == nil || err.Error() != recErr.Error() {
+		t.Errorf("replayed error %v, recorded %v", err, recErr)
+	}
+
+	// Requests without recording
+	if _, err := replay.LoadData(job, []string{"cpu_load", "flops_any"}, scopes, context.Background(), 60); err == nil ||
+		!strings.Contains(err.Error(), "no recording of LoadData") {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	// Invalid recordings
+	for _, file := range recordings(t, dir) {
+		if err := os.WriteFile(file, []byte("{"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := replay.LoadStats(job, []string{"cpu_load"}, context.Background()); err == nil ||
+		!strings.Contains(err.Error(), "invalid recording") {
+		t.Errorf("unexpected error %v", err)
+	}
+}
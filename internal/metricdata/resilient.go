@@ -0,0 +1,379 @@
+// Copyright (C) NHR@FAU, University Erlangen-Nuremberg.
+// All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+package metricdata
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ClusterCockpit/cc-backend/internal/graph/model"
+	"github.com/ClusterCockpit/cc-backend/pkg/log"
+	"github.com/ClusterCockpit/cc-backend/pkg/schema"
+)
+
+var ErrCircuitOpen = errors.New("METRICDATA/RESILIENT > metric data repository unreachable, circuit open")
+
+// Optional options of every metric data repository
+type ResilienceConfig struct {
+	// Timeout of a single request, default "30s"
+	Timeout string `json:"timeout,omitempty"`
+	// Number of retries of failed requests, default 1
+	Retries *int `json:"retries,omitempty"`
+	// Wait time before the first retry, doubled for every further retry,
+	// default "500ms"
+	RetryBackoff string `json:"retry-backoff,omitempty"`
+	// Number of consecutive failed requests after which the repository is
+	// not queried anymore, default 5
+	BreakerThreshold int `json:"breaker-threshold,omitempty"`
+	// Time after which a single request is sent again to a repository that
+	// was not queried anymore, default "30s"
+	BreakerCooldown string `json:"breaker-cooldown,omitempty"`
+}
+
+const (
+	CircuitClosed   = "closed"
+	CircuitOpen     = "open"
+	CircuitHalfOpen = "half-open"
+)
+
+// Health state of a metric data repository. ErrorRate and Latency (in
+// milliseconds) are moving averages over recent requests.
+type RepositoryHealth struct {
+	Cluster       string     `json:"cluster"`
+	Kind          string     `json:"kind"`
+	State         string     `json:"state"`
+	Healthy       bool       `json:"healthy"`
+	LastSuccess   *time.Time `json:"lastSuccess,omitempty"`
+	LastError     *string    `json:"lastError,omitempty"`
+	LastErrorTime *time.Time `json:"lastErrorTime,omitempty"`
+	ErrorRate     float64    `json:"errorRate"`
+	Latency       float64    `json:"latency"`
+}
+
+// Weight of the latest request in the moving averages of the health state
+const healthSmoothing = 0.1
+
+// Wraps a repository with a timeout per request, retries with exponential
+// backoff and a circuit breaker. Requests of a caller are abandoned after
+// the timeout even if the repository ignores the context.
+type ResilientMetricDataRepository struct {
+	repo      MetricDataRepository
+	timeout   time.Duration
+	retries   int
+	backoff   time.Duration
+	threshold int
+	cooldown  time.Duration
+
+	mu       sync.Mutex
+	health   RepositoryHealth
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+var (
+	healthLock   sync.Mutex
+	healthStates []*ResilientMetricDataRepository
+)
+
+func parseDuration(value string, def time.Duration) (time.Duration, error) {
+	if value == "" {
+		return def, nil
+	}
+	return time.ParseDuration(value)
+}
+
+func newResilientMetricDataRepository(
+	cluster, kind string,
+	repo MetricDataRepository,
+	rawConfig json.RawMessage,
+) (*ResilientMetricDataRepository, error) {
+	var config ResilienceConfig
+	if err := json.Unmarshal(rawConfig, &config); err != nil {
+		log.Warn("Error while unmarshaling raw json config")
+		return nil, err
+	}
+
+	rmdr := &ResilientMetricDataRepository{
+		repo:      repo,
+		retries:   1,
+		threshold: 5,
+		health: RepositoryHealth{
+			Cluster: cluster,
+			Kind:    kind,
+			State:   CircuitClosed,
+			Healthy: true,
+		},
+	}
+	var err error
+	if rmdr.timeout, err = parseDuration(config.Timeout, 30*time.Second); err != nil {
+		return nil, fmt.Errorf("METRICDATA/RESILIENT > invalid timeout: %w", err)
+	}
+	if rmdr.backoff, err = parseDuration(config.RetryBackoff, 500*time.Millisecond); err != nil {
+		return nil, fmt.Errorf("METRICDATA/RESILIENT > invalid retry-backoff: %w", err)
+	}
+	if rmdr.cooldown, err = parseDuration(config.BreakerCooldown, 30*time.Second); err != nil {
+		return nil, fmt.Errorf("METRICDATA/RESILIENT > invalid breaker-cooldown: %w", err)
+	}
+	if config.Retries != nil {
+		rmdr.retries = max(*config.Retries, 0)
+	}
+	if config.BreakerThreshold > 0 {
+		rmdr.threshold = config.BreakerThreshold
+	}
+
+	healthLock.Lock()
+	healthStates = append(healthStates, rmdr)
+	healthLock.Unlock()
+
+	return rmdr, nil
+}
+
+// Returns the health state of all metric data repositories of the cluster,
+// or of all clusters if cluster is empty.
+func GetHealth(cluster string) []RepositoryHealth {
+	healthLock.Lock()
+	defer healthLock.Unlock()
+
+	res := make([]RepositoryHealth, 0, len(healthStates))
+	for _, rmdr := range healthStates {
+		if h := rmdr.Health(); cluster == "" || h.Cluster == cluster {
+			res = append(res, h)
+		}
+	}
+	sort.SliceStable(res, func(i, j int) bool {
+		return res[i].Cluster < res[j].Cluster
+	})
+
+	return res
+}
+
+func (rmdr *ResilientMetricDataRepository) Health() RepositoryHealth {
+	rmdr.mu.Lock()
+	defer rmdr.mu.Unlock()
+
+	h := rmdr.health
+	if h.State == CircuitOpen && time.Since(rmdr.openedAt) >= rmdr.cooldown {
+		h.State = CircuitHalfOpen
+	}
+	return h
+}
+
+// Returns false if the circuit is open. After the cooldown a single request
+// is let through to probe the repository.
+func (rmdr *ResilientMetricDataRepository) allow() bool {
+	rmdr.mu.Lock()
+	defer rmdr.mu.Unlock()
+
+	if rmdr.health.State != CircuitOpen {
+		return true
+	}
+	if rmdr.probing || time.Since(rmdr.openedAt) < rmdr.cooldown {
+		return false
+	}
+	rmdr.probing = true
+	return true
+}
+
+func (rmdr *ResilientMetricDataRepository) record(err error, latency time.Duration) {
+	rmdr.mu.Lock()
+	defer rmdr.mu.Unlock()
+
+	now := time.Now()
+	h := &rmdr.health
+	h.Latency = (1-healthSmoothing)*h.Latency + healthSmoothing*float64(latency.Milliseconds())
+	rmdr.probing = false
+
+	if err == nil {
+		h.ErrorRate = (1 - healthSmoothing) * h.ErrorRate
+		h.LastSuccess = &now
+		if h.State != CircuitClosed {
+			log.Infof("MetricDataRepository %s for cluster %s reachable again", h.Kind, h.Cluster)
+		}
+		h.State = CircuitClosed
+		h.Healthy = true
+		rmdr.failures = 0
+		return
+	}
+
+	msg := err.Error()
+	h.ErrorRate = (1-healthSmoothing)*h.ErrorRate + healthSmoothing
+	h.LastError = &msg
+	h.LastErrorTime = &now
+	h.Healthy = false
+	rmdr.failures++
+	if h.State == CircuitOpen || rmdr.failures >= rmdr.threshold {
+		if h.State != CircuitOpen {
+			log.Warnf("MetricDataRepository %s for cluster %s unreachable after %d failed requests, retry in %s",
+				h.Kind, h.Cluster, rmdr.failures, rmdr.cooldown)
+		}
+		h.State = CircuitOpen
+		rmdr.openedAt = now
+	}
+}
+
+// Ends a probing request without result
+func (rmdr *ResilientMetricDataRepository) abort() {
+	rmdr.mu.Lock()
+	defer rmdr.mu.Unlock()
+
+	rmdr.probing = false
+}
+
+type attemptResult[T any] struct {
+	value T
+	err   error
+}
+
+// Runs fn with a timeout. Returns when fn returns or the timeout expired,
+// whatever happens first.
+func attempt[T any](rmdr *ResilientMetricDataRepository, ctx context.Context, fn func(ctx context.Context) (T, error)) (T, error) {
+	ctx, cancel := context.WithTimeout(ctx, rmdr.timeout)
+	defer cancel()
+
+	done := make(chan attemptResult[T], 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- attemptResult[T]{err: fmt.Errorf("METRICDATA/RESILIENT > panic in metric data repository: %v", r)}
+			}
+		}()
+		v, err := fn(ctx)
+		done <- attemptResult[T]{value: v, err: err}
+	}()
+
+	select {
+	case res := <-done:
+		return res.value, res.err
+	case <-ctx.Done():
+		var zero T
+		return zero, fmt.Errorf("METRICDATA/RESILIENT > request to %s failed: %w", rmdr.health.Kind, ctx.Err())
+	}
+}
+
+// Calls fn until it succeeds or all retries are used up
+func call[T any](rmdr *ResilientMetricDataRepository, ctx context.Context, fn func(ctx context.Context) (T, error)) (T, error) {
+	backoff := rmdr.backoff
+	var v T
+	var err error
+	for i := 0; i <= rmdr.retries; i++ {
+		if i > 0 {
+			select {
+			case <-time.After(backoff):
+			case <-ctx.Done():
+				return v, err
+			}
+			backoff *= 2
+		}
+
+		if !rmdr.allow() {
+			return v, ErrCircuitOpen
+		}
+		t0 := time.Now()
+		v, err = attempt(rmdr, ctx, fn)
+		if ctx.Err() != nil {
+			// Cancelled by the caller, not a failure of the repository
+			rmdr.abort()
+			return v, err
+		}
+		rmdr.record(err, time.Since(t0))
+		if err == nil {
+			return v, nil
+		}
+	}
+
+	return v, err
+}
+
+func (rmdr *ResilientMetricDataRepository) Init(rawConfig json.RawMessage) error {
+	return rmdr.repo.Init(rawConfig)
+}
+
+func (rmdr *ResilientMetricDataRepository) LoadData(
+	job *schema.Job,
+	metrics []string,
+	scopes []schema.MetricScope,
+	ctx context.Context,
+	resolution int,
+) (schema.JobData, error) {
+	type partial struct {
+		jobData schema.JobData
+		err     error
+	}
+	res, err := call(rmdr, ctx, func(ctx context.Context) (partial, error) {
+		jd, err := rmdr.repo.LoadData(job, metrics, scopes, ctx, resolution)
+		// Partial results are not retried
+		if err != nil && len(jd) == 0 {
+			return partial{}, err
+		}
+		return partial{jd, err}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return res.jobData, res.err
+}
+
+func (rmdr *ResilientMetricDataRepository) LoadStats(
+	job *schema.Job,
+	metrics []string,
+	ctx context.Context,
+) (map[string]map[string]schema.MetricStatistics, error) {
+	return call(rmdr, ctx, func(ctx context.Context) (map[string]map[string]schema.MetricStatistics, error) {
+		return rmdr.repo.LoadStats(job, metrics, ctx)
+	})
+}
+
+func (rmdr *ResilientMetricDataRepository) LoadScopedStats(
+	job *schema.Job,
+	metrics []string,
+	scopes []schema.MetricScope,
+	ctx context.Context,
+) (schema.ScopedJobStats, error) {
+	return call(rmdr, ctx, func(ctx context.Context) (schema.ScopedJobStats, error) {
+		return rmdr.repo.LoadScopedStats(job, metrics, scopes, ctx)
+	})
+}
+
+func (rmdr *ResilientMetricDataRepository) LoadNodeData(
+	cluster string,
+	metrics, nodes []string,
+	scopes []schema.MetricScope,
+	from, to time.Time,
+	ctx context.Context,
+) (map[string]map[string][]*schema.JobMetric, error) {
+	return call(rmdr, ctx, func(ctx context.Context) (map[string]map[string][]*schema.JobMetric, error) {
+		return rmdr.repo.LoadNodeData(cluster, metrics, nodes, scopes, from, to, ctx)
+	})
+}
+
+func (rmdr *ResilientMetricDataRepository) LoadNodeListData(
+	cluster, subCluster, nodeFilter string,
+	metrics []string,
+	scopes []schema.MetricScope,
+	resolution int,
+	from, to time.Time,
+	page *model.PageRequest,
+	ctx context.Context,
+) (map[string]schema.JobData, int, bool, error) {
+	type nodeList struct {
+		data        map[string]schema.JobData
+		totalNodes  int
+		hasNextPage bool
+	}
+	res, err := call(rmdr, ctx, func(ctx context.Context) (nodeList, error) {
+		data, totalNodes, hasNextPage, err := rmdr.repo.LoadNodeListData(
+			cluster, subCluster, nodeFilter, metrics, scopes, resolution, from, to, page, ctx)
+		return nodeList{data, totalNodes, hasNextPage}, err
+	})
+
+	return res.data, res.totalNodes, res.hasNextPage, err
+}
@@ -0,0 +1,181 @@
+// Copyright (C) NHR@FAU, University Erlangen-Nuremberg.
+// All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+package metricdata
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ClusterCockpit/cc-backend/pkg/schema"
+)
+
+func newTestResilient(t *testing.T, cluster string, repo *fakeRepository, config string) *ResilientMetricDataRepository {
+	t.Helper()
+	rmdr, err := newResilientMetricDataRepository(cluster, "fake", repo, json.RawMessage(config))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return rmdr
+}
+
+func TestResilientConfig(t *testing.T) {
+	tests := []struct {
+		config string
+		valid  bool
+	}{
+		{`{}`, true},
+		{`{"timeout": "1s", "retries": 0, "retry-backoff": "10ms", "breaker-threshold": 1, "breaker-cooldown": "1m"}`, true},
+		{`{"timeout": "1"}`, false},
+		{`{"retry-backoff": "soon"}`, false},
+		{`{"breaker-cooldown": "-"}`, false},
+		{`{"retries": "1"}`, false},
+	}
+	for _, tt := range tests {
+		_, err := newResilientMetricDataRepository("configcluster", "fake", &fakeRepository{}, json.RawMessage(tt.config))
+		if (err == nil) != tt.valid {
+			t.Errorf("%s: unexpected error %v", tt.config, err)
+		}
+	}
+
+	rmdr := newTestResilient(t, "configcluster", &fakeRepository{}, `{}`)
+	if rmdr.timeout != 30*time.Second || rmdr.retries != 1 || rmdr.backoff != 500*time.Millisecond ||
+		rmdr.threshold != 5 || rmdr.cooldown != 30*time.Second {
+		t.Errorf("unexpected defaults: %+v", rmdr)
+	}
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	repo := &fakeRepository{
+		data: schema.JobData{"cpu_load": {schema.MetricScopeNode: nodeMetric(60, map[string][]schema.Float{"node01": {1, 2}})}},
+		err:  errors.New("connection refused"),
+	}
+	rmdr := newTestResilient(t, "breakercluster", repo,
+		`{"retries": 1, "retry-backoff": "1ms", "breaker-threshold": 3, "breaker-cooldown": "50ms"}`)
+	job := testJob()
+	load := func() error {
+		_, err := rmdr.LoadStats(job, []string{"cpu_load"}, context.Background())
+		return err
+	}
+
+	tests := []struct {
+		name    string
+		setup   func()
+		load    bool
+		err     string
+		calls   int
+		state   string
+		healthy bool
+	}{
+		// Every request is tried twice
+		{"failed", nil, true, "connection refused", 2, CircuitClosed, false},
+		// The retry is rejected by the open circuit
+		{"threshold reached", nil, true, ErrCircuitOpen.Error(), 3, CircuitOpen, false},
+		{"open", nil, true, ErrCircuitOpen.Error(), 3, CircuitOpen, false},
+		{"cooldown expired", func() { time.Sleep(60 * time.Millisecond) }, false, "", 3, CircuitHalfOpen, false},
+		// A single probe, the circuit opens again
+		{"probe failed", nil, true, ErrCircuitOpen.Error(), 4, CircuitOpen, false},
+		{"still open", nil, true, ErrCircuitOpen.Error(), 4, CircuitOpen, false},
+		{"recovered", func() {
+			time.Sleep(60 * time.Millisecond)
+			repo.mu.Lock()
+			repo.err = nil
+			repo.mu.Unlock()
+		}, true, "", 5, CircuitClosed, true},
+		{"closed", nil, true, "", 6, CircuitClosed, true},
+	}
+	for _, tt := range tests {
+		if tt.setup != nil {
+			tt.setup()
+		}
+		if tt.load {
+			err := load()
+			if (tt.err == "" && err != nil) || (tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err))) {
+				t.Errorf("%s: unexpected error %v", tt.name, err)
+			}
+		}
+		if repo.calls() != tt.calls {
+			t.Errorf("%s: expected %d calls, got %d", tt.name, tt.calls, repo.calls())
+		}
+		if h := rmdr.Health(); h.State != tt.state || h.Healthy != tt.healthy {
+			t.Errorf("%s: unexpected health %+v", tt.name, h)
+		}
+	}
+
+	// The health states are listed in the order of registration
+	hs := GetHealth("breakercluster")
+	if len(hs) == 0 {
+		t.Fatal("health state not registered")
+	}
+	if h := hs[len(hs)-1]; h.Cluster != "breakercluster" || h.Kind != "fake" ||
+		h.LastSuccess == nil || h.LastError == nil || h.ErrorRate <= 0 {
+		t.Errorf("unexpected health: %+v", h)
+	}
+}
+
+func TestResilientTimeout(t *testing.T) {
+	repo := &fakeRepository{delay: time.Second}
+	rmdr := newTestResilient(t, "timeoutcluster", repo, `{"timeout": "20ms", "retries": 0}`)
+
+	t0 := time.Now()
+	_, err := rmdr.LoadData(testJob(), []string{"cpu_load"}, nil, context.Background(), 0)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected timeout, got %v", err)
+	}
+	if d := time.Since(t0); d > 500*time.Millisecond {
+		t.Errorf("request not abandoned after the timeout: %s", d)
+	}
+
+	// Requests cancelled by the caller are not failures of the repository
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	failures := rmdr.failures
+	if _, err := rmdr.LoadData(testJob(), []string{"cpu_load"}, nil, ctx, 0); err == nil {
+		t.Error("expected error for cancelled request")
+	}
+	if rmdr.failures != failures {
+		t.Errorf("cancelled request counted as failure")
+	}
+
+	// Panics are failures
+	repo = &fakeRepository{panics: true}
+	rmdr = newTestResilient(t, "timeoutcluster", repo, `{"retries": 0}`)
+	if _, err := rmdr.LoadData(testJob(), []string{"cpu_load"}, nil, context.Background(), 0); err == nil ||
+		!strings.Contains(err.Error(), "panic") {
+		t.Errorf("expected panic error, got %v", err)
+	}
+}
+
+// Repository returning partial results with an error
+type partialRepository struct {
+	fakeRepository
+}
+
+func (p *partialRepository) LoadData(job *schema.Job, metrics []string, scopes []schema.MetricScope, ctx context.Context, resolution int) (schema.JobData, error) {
+	data, _ := p.load(ctx, metrics)
+	return data, errors.New("some metrics missing")
+}
+
+func TestResilientPartialResult(t *testing.T) {
+	repo := &partialRepository{fakeRepository{
+		data: schema.JobData{"cpu_load": {schema.MetricScopeNode: nodeMetric(60, map[string][]schema.Float{"node01": {1}})}},
+	}}
+	rmdr, err := newResilientMetricDataRepository("partialcluster", "fake", repo, json.RawMessage(`{"retries": 3}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Partial results are returned with the error and not retried
+	data, err := rmdr.LoadData(testJob(), []string{"cpu_load", "mem_bw"}, nil, context.Background(), 0)
+	if err == nil || len(data) != 1 || repo.calls() != 1 {
+		t.Errorf("unexpected result %v (%v) after %d calls", data, err, repo.calls())
+	}
+	if !rmdr.Health().Healthy {
+		t.Error("partial result counted as failure")
+	}
+}
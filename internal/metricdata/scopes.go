@@ -0,0 +1,462 @@
+// Copyright (C) NHR@FAU, University Erlangen-Nuremberg.
+// All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+package metricdata
+
+import (
+	"math"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ClusterCockpit/cc-backend/internal/graph/model"
+	"github.com/ClusterCockpit/cc-backend/pkg/archive"
+	"github.com/ClusterCockpit/cc-backend/pkg/log"
+	"github.com/ClusterCockpit/cc-backend/pkg/resampler"
+	"github.com/ClusterCockpit/cc-backend/pkg/schema"
+)
+
+// Helpers for backends that return the raw series of each metric at its
+// native scope (as tagged by cc-metric-collector: type and type-id) and
+// leave the aggregation to the requested scopes to cc-backend.
+
+// Raw sample with a timestamp in milliseconds
+type rawSample struct {
+	Timestamp int64
+	Value     float64
+}
+
+// Series of one metric at its native scope by host and type id. The type id
+// of node scope series is empty.
+type nativeSeries map[string]map[string][]schema.Float
+
+// Native series aggregated into one series of the requested scope
+type scopeGroup struct {
+	id     string
+	native []string
+}
+
+// Returns the IDs of the given CPU scope that contain at least one of the
+// hwthreads
+func cpuScopeIds(topo *schema.Topology, scope schema.MetricScope, hwthreads []int) []int {
+	var ids []int
+	switch scope {
+	case schema.MetricScopeHWThread:
+		ids = slices.Clone(hwthreads)
+	case schema.MetricScopeCore:
+		ids, _ = topo.GetCoresFromHWThreads(hwthreads)
+	case schema.MetricScopeMemoryDomain:
+		ids, _ = topo.GetMemoryDomainsFromHWThreads(hwthreads)
+	case schema.MetricScopeSocket:
+		ids, _ = topo.GetSocketsFromHWThreads(hwthreads)
+	}
+	sort.Ints(ids)
+
+	return ids
+}
+
+func cpuScopeHWThreads(topo *schema.Topology, scope schema.MetricScope, id int) []int {
+	var list [][]int
+	switch scope {
+	case schema.MetricScopeHWThread:
+		return []int{id}
+	case schema.MetricScopeCore:
+		list = topo.Core
+	case schema.MetricScopeMemoryDomain:
+		list = topo.MemoryDomain
+	case schema.MetricScopeSocket:
+		list = topo.Socket
+	}
+	if id < 0 || id >= len(list) {
+		return nil
+	}
+
+	return list[id]
+}
+
+// Groups the native series of a host by the series of the requested scope
+// they are aggregated to. Returns false if the scope cannot be derived from
+// the native scope.
+func scopeGroups(
+	topo *schema.Topology,
+	resource *schema.Resource,
+	nativeScope, scope schema.MetricScope,
+) ([]scopeGroup, bool) {
+	if nativeScope == schema.MetricScopeNode {
+		return []scopeGroup{{native: []string{""}}}, scope == schema.MetricScopeNode
+	}
+
+	if nativeScope == schema.MetricScopeAccelerator {
+		accs := resource.Accelerators
+		if accs == nil {
+			accs = topo.GetAcceleratorIDs()
+		}
+		switch scope {
+		case schema.MetricScopeAccelerator:
+			groups := make([]scopeGroup, 0, len(accs))
+			for _, acc := range accs {
+				groups = append(groups, scopeGroup{id: acc, native: []string{acc}})
+			}
+			return groups, true
+		case schema.MetricScopeNode:
+			return []scopeGroup{{native: accs}}, len(accs) > 0
+		}
+		return nil, false
+	}
+
+	if scope == schema.MetricScopeAccelerator {
+		return nil, false
+	}
+
+	hwthreads := resource.HWThreads
+	if hwthreads == nil {
+		hwthreads = topo.Node
+	}
+	nativeIds := cpuScopeIds(topo, nativeScope, hwthreads)
+	native := func(filter func(id int) bool) []string {
+		ids := make([]string, 0)
+		for _, id := range nativeIds {
+			if filter(id) {
+				ids = append(ids, strconv.Itoa(id))
+			}
+		}
+		return ids
+	}
+
+	if scope == schema.MetricScopeNode {
+		return []scopeGroup{{native: native(func(int) bool { return true })}}, true
+	}
+
+	targetIds := cpuScopeIds(topo, scope, hwthreads)
+	groups := make([]scopeGroup, 0, len(targetIds))
+	for _, tid := range targetIds {
+		target := cpuScopeHWThreads(topo, scope, tid)
+		groups = append(groups, scopeGroup{
+			id: strconv.Itoa(tid),
+			native: native(func(id int) bool {
+				for _, hwthread := range cpuScopeHWThreads(topo, nativeScope, id) {
+					if !slices.Contains(target, hwthread) {
+						return false
+					}
+				}
+				return true
+			}),
+		})
+	}
+
+	return groups, true
+}
+
+// Maps the raw samples onto the timestep grid between from and to. Steps
+// without samples are NaN.
+func binSamples(samples []rawSample, from, to time.Time, timestep int) []schema.Float {
+	step := int64(timestep)
+	steps := int64(to.Sub(from).Seconds()) / step
+	data := make([]schema.Float, steps+1)
+	for i := range data {
+		data[i] = schema.NaN
+	}
+	for _, s := range samples {
+		idx := (s.Timestamp/1000 - from.Unix()) / step
+		if idx < 0 || idx > steps {
+			continue
+		}
+		data[idx] = schema.Float(s.Value)
+	}
+
+	return data
+}
+
+func aggregateSeries(series [][]schema.Float, aggregation string) []schema.Float {
+	if len(series) == 1 {
+		return series[0]
+	}
+
+	data := make([]schema.Float, len(series[0]))
+	for i := range data {
+		sum, n := 0.0, 0
+		for _, s := range series {
+			if !s[i].IsNaN() {
+				sum += float64(s[i])
+				n++
+			}
+		}
+		switch {
+		case n == 0:
+			data[i] = schema.NaN
+		case aggregation == "avg":
+			data[i] = schema.Float(sum / float64(n))
+		default:
+			data[i] = schema.Float(sum)
+		}
+	}
+
+	return data
+}
+
+func seriesStatistics(data []schema.Float) schema.MetricStatistics {
+	min, max, avg := MinMaxMean(data)
+	if math.IsNaN(avg) {
+		return schema.MetricStatistics{}
+	}
+
+	return schema.MetricStatistics{Min: min, Max: max, Avg: avg}
+}
+
+func jobRange(job *schema.Job) (time.Time, time.Time) {
+	return job.StartTime, job.StartTime.Add(time.Duration(job.Duration) * time.Second)
+}
+
+// Reduces all series to the requested resolution if it is a multiple of
+// their timestep
+func resampleJobData(jobData schema.JobData, resolution int) error {
+	for _, scopes := range jobData {
+		for _, jm := range scopes {
+			if resolution <= jm.Timestep || resolution%jm.Timestep != 0 {
+				continue
+			}
+			timestep := jm.Timestep
+			for i := range jm.Series {
+				var err error
+				if jm.Series[i].Data, timestep, err = resampler.LargestTriangleThreeBucket(
+					jm.Series[i].Data, jm.Timestep, resolution); err != nil {
+					return err
+				}
+			}
+			jm.Timestep = timestep
+		}
+	}
+
+	return nil
+}
+
+func nodeResources(nodes []string) []*schema.Resource {
+	resources := make([]*schema.Resource, 0, len(nodes))
+	for _, node := range nodes {
+		resources = append(resources, &schema.Resource{Hostname: node})
+	}
+	return resources
+}
+
+// Returns the topology of every resource and the list of hosts
+func resourceTopologies(cluster string, resources []*schema.Resource) (map[string]*schema.Topology, []string, error) {
+	topologies := make(map[string]*schema.Topology, len(resources))
+	hosts := make([]string, 0, len(resources))
+	for _, res := range resources {
+		scName, err := archive.GetSubClusterByNode(cluster, res.Hostname)
+		if err != nil {
+			return nil, nil, err
+		}
+		sc, err := archive.GetSubCluster(cluster, scName)
+		if err != nil {
+			return nil, nil, err
+		}
+		topologies[res.Hostname] = &sc.Topology
+		hosts = append(hosts, res.Hostname)
+	}
+
+	return topologies, hosts, nil
+}
+
+// Aggregates the native series of each metric to the requested scopes of
+// every resource. Hardware threads and accelerators not listed in a
+// resource are ignored, resources without them cover the whole node.
+// Returns the job data of each host.
+func scopeNativeSeries(
+	resources []*schema.Resource,
+	topologies map[string]*schema.Topology,
+	mcs []*schema.MetricConfig,
+	native []nativeSeries,
+	scopes []schema.MetricScope,
+) map[string]schema.JobData {
+	data := make(map[string]schema.JobData, len(resources))
+	for i, mc := range mcs {
+		for _, res := range resources {
+			done := make(map[schema.MetricScope]bool, len(scopes))
+			for _, requested := range scopes {
+				scope := mc.Scope.Max(requested)
+				if done[scope] {
+					continue
+				}
+				done[scope] = true
+
+				groups, ok := scopeGroups(topologies[res.Hostname], res, mc.Scope, scope)
+				if !ok {
+					log.Debugf("Scope %s of metric %s cannot be derived from native scope %s", scope, mc.Name, mc.Scope)
+					continue
+				}
+
+				for _, g := range groups {
+					series := make([][]schema.Float, 0, len(g.native))
+					for _, id := range g.native {
+						if s, ok := native[i][res.Hostname][id]; ok {
+							series = append(series, s)
+						}
+					}
+					if len(series) == 0 {
+						continue
+					}
+
+					if data[res.Hostname] == nil {
+						data[res.Hostname] = make(schema.JobData)
+					}
+					if data[res.Hostname][mc.Name] == nil {
+						data[res.Hostname][mc.Name] = make(map[schema.MetricScope]*schema.JobMetric)
+					}
+					jm, ok := data[res.Hostname][mc.Name][scope]
+					if !ok {
+						jm = &schema.JobMetric{
+							Unit:     mc.Unit,
+							Timestep: mc.Timestep,
+							Series:   make([]schema.Series, 0, len(groups)),
+						}
+						data[res.Hostname][mc.Name][scope] = jm
+					}
+
+					s := schema.Series{
+						Hostname: res.Hostname,
+						Data:     aggregateSeries(series, mc.Aggregation),
+					}
+					if scope != schema.MetricScopeNode {
+						id := g.id
+						s.Id = &id
+					}
+					s.Statistics = seriesStatistics(s.Data)
+					jm.Series = append(jm.Series, s)
+				}
+			}
+		}
+	}
+
+	return data
+}
+
+// Merges the job data of all hosts of a job
+func hostsJobData(resources []*schema.Resource, hostData map[string]schema.JobData) schema.JobData {
+	jobData := make(schema.JobData)
+	for _, res := range resources {
+		for metric, scopes := range hostData[res.Hostname] {
+			if jobData[metric] == nil {
+				jobData[metric] = make(map[schema.MetricScope]*schema.JobMetric)
+			}
+			for scope, jm := range scopes {
+				if merged, ok := jobData[metric][scope]; ok {
+					merged.Series = append(merged.Series, jm.Series...)
+				} else {
+					jobData[metric][scope] = jm
+				}
+			}
+		}
+	}
+
+	return jobData
+}
+
+// Returns the node scope statistics of the job data of all hosts
+func hostsStats(hostData map[string]schema.JobData, metrics int) map[string]map[string]schema.MetricStatistics {
+	stats := make(map[string]map[string]schema.MetricStatistics, metrics)
+	for host, jobData := range hostData {
+		for metric, scopes := range jobData {
+			jm, ok := scopes[schema.MetricScopeNode]
+			if !ok {
+				continue
+			}
+			if stats[metric] == nil {
+				stats[metric] = make(map[string]schema.MetricStatistics)
+			}
+			for _, s := range jm.Series {
+				stats[metric][host] = s.Statistics
+			}
+		}
+	}
+
+	return stats
+}
+
+// Returns the statistics of all series of the job data of all hosts
+func hostsScopedStats(resources []*schema.Resource, hostData map[string]schema.JobData) schema.ScopedJobStats {
+	scopedJobStats := make(schema.ScopedJobStats)
+	for _, res := range resources {
+		for metric, scopes := range hostData[res.Hostname] {
+			if scopedJobStats[metric] == nil {
+				scopedJobStats[metric] = make(map[schema.MetricScope][]*schema.ScopedStats)
+			}
+			for scope, jm := range scopes {
+				for i := range jm.Series {
+					scopedJobStats[metric][scope] = append(scopedJobStats[metric][scope], &schema.ScopedStats{
+						Hostname: jm.Series[i].Hostname,
+						Id:       jm.Series[i].Id,
+						Data:     &jm.Series[i].Statistics,
+					})
+				}
+			}
+		}
+	}
+
+	return scopedJobStats
+}
+
+// Converts the job data of all hosts to the result of LoadNodeData
+func hostsNodeData(hostData map[string]schema.JobData) map[string]map[string][]*schema.JobMetric {
+	data := make(map[string]map[string][]*schema.JobMetric, len(hostData))
+	for host, jobData := range hostData {
+		data[host] = make(map[string][]*schema.JobMetric, len(jobData))
+		for metric, scopes := range jobData {
+			for _, jm := range scopes {
+				data[host][metric] = append(data[host][metric], jm)
+			}
+		}
+	}
+
+	return data
+}
+
+// Returns the nodes of a cluster or subcluster
+func clusterNodes(cluster, subCluster string) []string {
+	var nodes []string
+	if subCluster != "" {
+		scNodes := archive.NodeLists[cluster][subCluster]
+		nodes = scNodes.PrintList()
+	} else {
+		for _, nodeList := range archive.NodeLists[cluster] {
+			nodes = append(nodes, nodeList.PrintList()...)
+		}
+	}
+
+	return nodes
+}
+
+// Returns the requested page of the sorted nodes of a cluster or subcluster
+// that contain nodeFilter, the total number of matching nodes and whether
+// there is a next page.
+func pagedNodeList(cluster, subCluster, nodeFilter string, page *model.PageRequest) ([]string, int, bool) {
+	nodes := clusterNodes(cluster, subCluster)
+	if nodeFilter != "" {
+		filteredNodes := []string{}
+		for _, node := range nodes {
+			if strings.Contains(node, nodeFilter) {
+				filteredNodes = append(filteredNodes, node)
+			}
+		}
+		nodes = filteredNodes
+	}
+
+	totalNodes := len(nodes)
+	hasNextPage := false
+	sort.Strings(nodes)
+	if page != nil && len(nodes) > page.ItemsPerPage {
+		start := min((page.Page-1)*page.ItemsPerPage, len(nodes))
+		end := start + page.ItemsPerPage
+		if end >= len(nodes) {
+			end = len(nodes)
+		} else {
+			hasNextPage = true
+		}
+		nodes = nodes[start:end]
+	}
+
+	return nodes, totalNodes, hasNextPage
+}
@@ -0,0 +1,268 @@
+// Copyright (C) NHR@FAU, University Erlangen-Nuremberg.
+// All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+package metricdata
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ClusterCockpit/cc-backend/internal/graph/model"
+	"github.com/ClusterCockpit/cc-backend/pkg/archive"
+	"github.com/ClusterCockpit/cc-backend/pkg/schema"
+)
+
+func TestScopeGroups(t *testing.T) {
+	setupCluster(t)
+	topo := &archive.Clusters[0].SubClusters[0].Topology
+
+	node := &schema.Resource{Hostname: "node01"}
+	tests := []struct {
+		name     string
+		resource *schema.Resource
+		native   schema.MetricScope
+		scope    schema.MetricScope
+		want     string
+	}{
+		{"hwthread to core", node, schema.MetricScopeHWThread, schema.MetricScopeCore, "[{0 [0 1]} {1 [2 3]} {2 [4 5]} {3 [6 7]}]"},
+		{"partial cores", &schema.Resource{HWThreads: []int{0, 1, 2}}, schema.MetricScopeHWThread, schema.MetricScopeCore, "[{0 [0 1]} {1 [2]}]"},
+		{"hwthread to socket", node, schema.MetricScopeHWThread, schema.MetricScopeSocket, "[{0 [0 1 2 3]} {1 [4 5 6 7]}]"},
+		{"hwthread to node", node, schema.MetricScopeHWThread, schema.MetricScopeNode, "[{ [0 1 2 3 4 5 6 7]}]"},
+		{"core to socket", node, schema.MetricScopeCore, schema.MetricScopeSocket, "[{0 [0 1]} {1 [2 3]}]"},
+		{"core to memory domain", node, schema.MetricScopeCore, schema.MetricScopeMemoryDomain, "[{0 [0 1]} {1 [2 3]}]"},
+		{"partial socket", &schema.Resource{HWThreads: []int{4, 5}}, schema.MetricScopeSocket, schema.MetricScopeNode, "[{ [1]}]"},
+		{"node", node, schema.MetricScopeNode, schema.MetricScopeNode, "[{ []}]"},
+		{"accelerators", node, schema.MetricScopeAccelerator, schema.MetricScopeAccelerator, "[{gpu0 [gpu0]} {gpu1 [gpu1]}]"},
+		{"job accelerators", &schema.Resource{Accelerators: []string{"gpu1"}}, schema.MetricScopeAccelerator, schema.MetricScopeNode, "[{ [gpu1]}]"},
+		{"node to core", node, schema.MetricScopeNode, schema.MetricScopeCore, ""},
+		{"accelerator to socket", node, schema.MetricScopeAccelerator, schema.MetricScopeSocket, ""},
+		{"hwthread to accelerator", node, schema.MetricScopeHWThread, schema.MetricScopeAccelerator, ""},
+	}
+	for _, tt := range tests {
+		groups, ok := scopeGroups(topo, tt.resource, tt.native, tt.scope)
+		if tt.want == "" {
+			if ok {
+				t.Errorf("%s: expected scope not to be derivable, got %v", tt.name, groups)
+			}
+			continue
+		}
+		if got := fmt.Sprint(groups); !ok || got != tt.want {
+			t.Errorf("%s: got %s (%v), want %s", tt.name, got, ok, tt.want)
+		}
+	}
+}
+
+func TestBinSamples(t *testing.T) {
+	from, to := time.Unix(testStart, 0), time.Unix(testStart+testDuration, 0)
+	samples := []rawSample{
+		{Timestamp: (testStart - 60) * 1000, Value: 9},
+		{Timestamp: testStart * 1000, Value: 1},
+		{Timestamp: (testStart + 59) * 1000, Value: 2},
+		{Timestamp: (testStart + 120) * 1000, Value: 3},
+		{Timestamp: (testStart + 240) * 1000, Value: 9},
+	}
+	if got := fmt.Sprint(binSamples(samples, from, to, 60)); got != "[2 NaN 3 NaN]" {
+		t.Errorf("got %s", got)
+	}
+	if got := fmt.Sprint(binSamples(nil, from, to, 60)); got != "[NaN NaN NaN NaN]" {
+		t.Errorf("got %s", got)
+	}
+}
+
+func TestAggregateSeries(t *testing.T) {
+	nan := schema.NaN
+	series := [][]schema.Float{{1, nan, nan}, {3, 4, nan}}
+	tests := []struct {
+		aggregation string
+		series      [][]schema.Float
+		want        string
+	}{
+		{"sum", series, "[4 4 NaN]"},
+		{"avg", series, "[2 4 NaN]"},
+		{"", series, "[4 4 NaN]"},
+		{"avg", series[:1], "[1 NaN NaN]"},
+	}
+	for _, tt := range tests {
+		if got := fmt.Sprint(aggregateSeries(tt.series, tt.aggregation)); got != tt.want {
+			t.Errorf("%s of %v: got %s, want %s", tt.aggregation, tt.series, got, tt.want)
+		}
+	}
+}
+
+func TestScopeNativeSeries(t *testing.T) {
+	setupCluster(t)
+	resources := []*schema.Resource{{Hostname: "node01", Accelerators: []string{"gpu1"}}}
+	topologies, _, err := resourceTopologies("testcluster", resources)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	nan := schema.NaN
+	mcs := []*schema.MetricConfig{archive.GetMetricConfig("testcluster", "mem_bw"), archive.GetMetricConfig("testcluster", "acc_util")}
+	native := []nativeSeries{
+		{"node01": {"0": {1, 1}, "1": {2, nan}}, "node02": {"0": {5, 5}}},
+		{"node01": {"gpu0": {10, 10}, "gpu1": {50, 70}}},
+	}
+	scopes := []schema.MetricScope{schema.MetricScopeNode, schema.MetricScopeSocket, schema.MetricScopeCore, schema.MetricScopeAccelerator}
+	data := scopeNativeSeries(resources, topologies, mcs, native, scopes)
+
+	if len(data) != 1 {
+		t.Fatalf("unexpected hosts: %v", data)
+	}
+	tests := []struct {
+		metric string
+		scope  schema.MetricScope
+		want   map[string]string
+	}{
+		{"mem_bw", schema.MetricScopeNode, map[string]string{"node01": "[3 1]"}},
+		{"mem_bw", schema.MetricScopeSocket, map[string]string{"node01/0": "[1 1]", "node01/1": "[2 NaN]"}},
+		// Only the accelerators of the job
+		{"acc_util", schema.MetricScopeNode, map[string]string{"node01": "[50 70]"}},
+		{"acc_util", schema.MetricScopeAccelerator, map[string]string{"node01/gpu1": "[50 70]"}},
+	}
+	for _, tt := range tests {
+		if got := seriesData(t, data["node01"], tt.metric, tt.scope); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s at %s: got %v, want %v", tt.metric, tt.scope, got, tt.want)
+		}
+	}
+	if len(data["node01"]["mem_bw"]) != 2 || len(data["node01"]["acc_util"]) != 2 {
+		t.Errorf("unexpected scopes: %v %v", data["node01"]["mem_bw"], data["node01"]["acc_util"])
+	}
+
+	jm := data["node01"]["mem_bw"][schema.MetricScopeNode]
+	if jm.Unit.Prefix != "G" || jm.Timestep != 60 {
+		t.Errorf("unexpected metric: %+v", jm)
+	}
+	if s := jm.Series[0].Statistics; s.Min != 1 || s.Max != 3 || s.Avg != 2 {
+		t.Errorf("unexpected statistics: %+v", s)
+	}
+}
+
+func TestPagedNodeList(t *testing.T) {
+	setupCluster(t)
+	tests := []struct {
+		subCluster, filter string
+		page               *model.PageRequest
+		want               string
+	}{
+		{"", "", nil, "[node01 node02] 2 false"},
+		{"main", "", &model.PageRequest{ItemsPerPage: 1, Page: 1}, "[node01] 2 true"},
+		{"main", "", &model.PageRequest{ItemsPerPage: 1, Page: 2}, "[node02] 2 false"},
+		{"", "02", &model.PageRequest{ItemsPerPage: 1, Page: 1}, "[node02] 1 false"},
+		{"", "", &model.PageRequest{ItemsPerPage: 1, Page: 3}, "[] 2 false"},
+	}
+	for _, tt := range tests {
+		nodes, total, next := pagedNodeList("testcluster", tt.subCluster, tt.filter, tt.page)
+		if got := fmt.Sprint(nodes, total, next); got != tt.want {
+			t.Errorf("%+v: got %s, want %s", tt, got, tt.want)
+		}
+	}
+}
+
+// InfluxDB query endpoint answering with the test series of the queried
+// measurement as annotated CSV. The queries are recorded.
+type influxServer struct {
+	*httptest.Server
+	mu      sync.Mutex
+	queries []string
+}
+
+func newInfluxServer(t *testing.T) *influxServer {
+	srv := &influxServer{}
+	srv.Server = httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Query string `json:"query"`
+		}
+		if r.URL.Path != "/api/v2/query" || json.NewDecoder(r.Body).Decode(&body) != nil {
+			http.Error(rw, `{"code": "invalid", "message": "invalid request"}`, http.StatusBadRequest)
+			return
+		}
+		srv.mu.Lock()
+		srv.queries = append(srv.queries, body.Query)
+		srv.mu.Unlock()
+
+		rw.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		fmt.Fprint(rw, "#datatype,string,long,dateTime:RFC3339,double,string,string\r\n")
+		fmt.Fprint(rw, "#group,false,false,false,false,true,true\r\n")
+		fmt.Fprint(rw, "#default,_result,,,,,\r\n")
+		fmt.Fprint(rw, ",result,table,_time,_value,hostname,type-id\r\n")
+		for i, ts := range testSeries() {
+			if !strings.Contains(body.Query, fmt.Sprintf(`r._measurement == "%s"`, ts.Labels["__name__"])) {
+				continue
+			}
+			for _, s := range ts.Samples {
+				fmt.Fprintf(rw, ",,%d,%s,%v,%s,%s\r\n", i, time.UnixMilli(s.Timestamp).UTC().Format(time.RFC3339),
+					s.Value, ts.Labels["exported_instance"], ts.Labels["type_id"])
+			}
+		}
+		fmt.Fprint(rw, "\r\n")
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv
+}
+
+func TestInfluxLoadData(t *testing.T) {
+	setupCluster(t)
+	srv := newInfluxServer(t)
+
+	idb := &InfluxDBv2DataRepository{}
+	if err := idb.Init(json.RawMessage(fmt.Sprintf(`{"url": "%s", "token": "secret", "org": "cc", "bucket": "metrics"}`, srv.URL))); err != nil {
+		t.Fatal(err)
+	}
+	data, err := idb.LoadData(testJob(), []string{"cpu_load", "flops_any"},
+		[]schema.MetricScope{schema.MetricScopeNode, schema.MetricScopeSocket}, context.Background(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		metric string
+		scope  schema.MetricScope
+		want   map[string]string
+	}{
+		{"cpu_load", schema.MetricScopeNode, map[string]string{"node01": "[1 2 3 4]"}},
+		{"flops_any", schema.MetricScopeNode, map[string]string{"node01": "[36 36 NaN NaN]"}},
+		{"flops_any", schema.MetricScopeSocket, map[string]string{"node01/0": "[10 10 NaN NaN]", "node01/1": "[26 26 NaN NaN]"}},
+	}
+	for _, tt := range tests {
+		if got := seriesData(t, data, tt.metric, tt.scope); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s at %s: got %v, want %v", tt.metric, tt.scope, got, tt.want)
+		}
+	}
+
+	if len(srv.queries) != 2 {
+		t.Fatalf("unexpected queries: %v", srv.queries)
+	}
+	for _, want := range []string{`r._field == "value"`, `r["hostname"] =~ /^(node01)$/`, `r["type"] == "hwthread"`, `group(columns: ["hostname", "type-id"])`} {
+		if !strings.Contains(srv.queries[1], want) {
+			t.Errorf("%q missing in query %s", want, srv.queries[1])
+		}
+	}
+
+	// The field holding the value is configurable
+	if err := idb.Init(json.RawMessage(fmt.Sprintf(`{"url": "%s", "field": "val"}`, srv.URL))); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := idb.LoadStats(testJob(), []string{"cpu_load"}, context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if q := srv.queries[len(srv.queries)-1]; !strings.Contains(q, `r._field == "val"`) {
+		t.Errorf("configured field not queried: %s", q)
+	}
+
+	// Query errors are returned
+	srv.Close()
+	if _, err := idb.LoadData(testJob(), []string{"cpu_load"}, nil, context.Background(), 0); err == nil {
+		t.Error("expected error of closed server")
+	}
+}
@@ -0,0 +1,188 @@
+// Copyright (C) NHR@FAU, University Erlangen-Nuremberg.
+// All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+// Package notification emails job owners a summary of the efficiency of their
+// jobs once they are archived. Users opt in with the user configuration key
+// `notification_jobSummary`:
+//
+//   - off:    no emails (default)
+//   - job:    one email per job
+//   - daily:  one digest per day with the summaries of all jobs of that day
+//   - weekly: one digest per week, sent on Mondays
+//
+// Summaries for digests are queued in the notification_queue table. Jobs
+// shorter than `short-running-jobs-duration` are skipped. Nothing is sent if no
+// mail server is configured.
+package notification
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ClusterCockpit/cc-backend/internal/config"
+	"github.com/ClusterCockpit/cc-backend/internal/mailer"
+	"github.com/ClusterCockpit/cc-backend/internal/repository"
+	"github.com/ClusterCockpit/cc-backend/pkg/log"
+	"github.com/ClusterCockpit/cc-backend/pkg/schema"
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jmoiron/sqlx"
+)
+
+// User configuration key of the notification mode
+const ConfigKey = "notification_jobSummary"
+
+const (
+	ModeOff    = "off"
+	ModeJob    = "job"
+	ModeDaily  = "daily"
+	ModeWeekly = "weekly"
+)
+
+type queued struct {
+	ID        int64  `db:"id"`
+	Username  string `db:"username"`
+	Summary   string `db:"summary"`
+	CreatedAt int64  `db:"created_at"`
+}
+
+var db *sqlx.DB
+
+// Init sets the database handle for queued summaries. Nothing is sent if
+// Init was not called.
+func Init(dbHandle *sqlx.DB) {
+	db = dbHandle
+}
+
+// Enabled returns true if summaries can be sent.
+func Enabled() bool {
+	return db != nil && mailer.Enabled()
+}
+
+// JobArchived sends or queues the summary of the archived job, depending on
+// the notification mode of its owner. Errors are only logged.
+func JobArchived(job *schema.Job, jobMeta *schema.JobMeta) {
+	if !Enabled() || int(job.Duration) < config.Keys.ShortRunningJobsDuration {
+		return
+	}
+
+	switch mode := userMode(job.User); mode {
+	case ModeJob:
+		summary := Summary(job, jobMeta)
+		go func() {
+			subject := fmt.Sprintf("Summary of job %d on %s", job.JobID, job.Cluster)
+			if err := send(job.User, subject, summary); err != nil {
+				log.Errorf("Notification: sending summary of job (dbid: %d) failed: %s", job.ID, err.Error())
+			}
+		}()
+	case ModeDaily, ModeWeekly:
+		if _, err := sq.Insert("notification_queue").
+			Columns("username", "job_id", "summary", "created_at").
+			Values(job.User, job.ID, Summary(job, jobMeta), time.Now().Unix()).
+			RunWith(db).Exec(); err != nil {
+			log.Errorf("Notification: queueing summary of job (dbid: %d) failed: %s", job.ID, err.Error())
+		}
+	}
+}
+
+// SendDigests sends the queued summaries to all users with daily digests,
+// and on Mondays to all users with weekly digests. Summaries of users which
+// turned notifications off are dropped.
+func SendDigests(now time.Time) {
+	if !Enabled() {
+		return
+	}
+
+	query, args, err := sq.Select("id", "username", "summary", "created_at").
+		From("notification_queue").OrderBy("id").ToSql()
+	if err != nil {
+		log.Errorf("Notification: building query failed: %s", err.Error())
+		return
+	}
+
+	var items []queued
+	if err := db.Select(&items, query, args...); err != nil {
+		log.Errorf("Notification: loading queued summaries failed: %s", err.Error())
+		return
+	}
+
+	users := make([]string, 0)
+	byUser := make(map[string][]queued)
+	for _, item := range items {
+		if _, ok := byUser[item.Username]; !ok {
+			users = append(users, item.Username)
+		}
+		byUser[item.Username] = append(byUser[item.Username], item)
+	}
+
+	for _, user := range users {
+		items := byUser[user]
+		switch userMode(user) {
+		case ModeWeekly:
+			// Also send summaries older than a week, the server might have
+			// been down on the last Monday
+			if now.Weekday() != time.Monday && now.Sub(time.Unix(items[0].CreatedAt, 0)) < 7*24*time.Hour {
+				continue
+			}
+			fallthrough
+		case ModeDaily:
+			if err := send(user, digestSubject(items), digest(items)); err != nil {
+				log.Errorf("Notification: sending digest to user '%s' failed: %s", user, err.Error())
+				continue
+			}
+		}
+
+		ids := make([]int64, 0, len(items))
+		for _, item := range items {
+			ids = append(ids, item.ID)
+		}
+		if _, err := sq.Delete("notification_queue").Where(sq.Eq{"id": ids}).RunWith(db).Exec(); err != nil {
+			log.Errorf("Notification: deleting sent summaries of user '%s' failed: %s", user, err.Error())
+		}
+	}
+}
+
+func userMode(username string) string {
+	cfg, err := repository.GetUserCfgRepo().GetUIConfig(&schema.User{Username: username})
+	if err != nil {
+		log.Warnf("Notification: loading configuration of user '%s' failed: %s", username, err.Error())
+		return ModeOff
+	}
+
+	mode, _ := cfg[ConfigKey].(string)
+	return mode
+}
+
+func send(username string, subject string, body string) error {
+	user, err := repository.GetUserRepository().GetUser(username)
+	if err != nil {
+		return err
+	}
+	if user.Email == "" {
+		log.Warnf("Notification: no email address of user '%s'", username)
+		return nil
+	}
+
+	return mailer.Send([]string{user.Email}, subject, body)
+}
+
+func digestSubject(items []queued) string {
+	if len(items) == 1 {
+		return "Summary of 1 job"
+	}
+	return fmt.Sprintf("Summaries of %d jobs", len(items))
+}
+
+func digest(items []queued) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Summaries of your jobs finished since %s:\n", time.Unix(items[0].CreatedAt, 0).Format(time.RFC1123))
+	for _, item := range items {
+		b.WriteString("\n")
+		b.WriteString(strings.Repeat("-", 72))
+		b.WriteString("\n\n")
+		b.WriteString(item.Summary)
+	}
+	return b.String()
+}
@@ -0,0 +1,165 @@
+// Copyright (C) NHR@FAU, University Erlangen-Nuremberg.
+// All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+package notification
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ClusterCockpit/cc-backend/internal/mailer"
+	"github.com/ClusterCockpit/cc-backend/internal/mailer/mailertest"
+	"github.com/ClusterCockpit/cc-backend/internal/repository"
+	"github.com/ClusterCockpit/cc-backend/pkg/archive"
+	"github.com/ClusterCockpit/cc-backend/pkg/log"
+	"github.com/ClusterCockpit/cc-backend/pkg/schema"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func setup(t *testing.T) *mailertest.Server {
+	log.Init("warn", true)
+
+	dbfile := filepath.Join(t.TempDir(), "test.db")
+	if err := repository.MigrateDB("sqlite3", dbfile); err != nil {
+		t.Fatal(err)
+	}
+	repository.Connect("sqlite3", dbfile)
+
+	clusters := archive.Clusters
+	archive.Clusters = []*schema.Cluster{{
+		Name: "testcluster",
+		SubClusters: []*schema.SubCluster{{
+			Name:      "sc0",
+			Footprint: []string{"flops_any"},
+			MetricConfig: []schema.MetricConfig{
+				{Name: "flops_any", Peak: 500, Footprint: "avg"},
+				{Name: "mem_used"},
+			},
+		}},
+	}}
+	t.Cleanup(func() { archive.Clusters = clusters })
+
+	sink := mailertest.NewServer(t)
+	mailer.Init(&schema.SmtpConfig{Addr: sink.Addr, From: "cc@example.org", BaseUrl: "https://monitoring.example.org"})
+	Init(repository.GetConnection().DB)
+	t.Cleanup(func() { db = nil })
+
+	for user, mode := range map[string]string{"alice": ModeJob, "bob": ModeDaily, "carol": ModeWeekly, "dave": ""} {
+		u := &schema.User{Username: user, Email: user + "@example.org", Roles: []string{"user"}}
+		if err := repository.GetUserRepository().AddUser(u); err != nil {
+			t.Fatal(err)
+		}
+		if mode != "" {
+			if err := repository.GetUserCfgRepo().UpdateConfig(ConfigKey, `"`+mode+`"`, u); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	return sink
+}
+
+func archiveJob(t *testing.T, jobId int64, user string, duration int32) {
+	jobMeta := &schema.JobMeta{
+		BaseJob: schema.BaseJob{
+			JobID:      jobId,
+			User:       user,
+			Project:    "abcd100",
+			Cluster:    "testcluster",
+			SubCluster: "sc0",
+			NumNodes:   1,
+			Duration:   duration,
+			State:      schema.JobStateCompleted,
+			Resources:  []*schema.Resource{{Hostname: "host1"}},
+		},
+		StartTime: 1700000000,
+		Statistics: map[string]schema.JobStatistics{
+			"flops_any": {Unit: schema.Unit{Prefix: "G", Base: "F/s"}, Avg: 25, Min: 10, Max: 40},
+			"mem_used":  {Unit: schema.Unit{Prefix: "G", Base: "B"}, Avg: 12, Min: 2, Max: 16},
+		},
+	}
+
+	id, err := repository.GetJobRepository().Start(jobMeta)
+	if err != nil {
+		t.Fatal(err)
+	}
+	job := &schema.Job{ID: id, BaseJob: jobMeta.BaseJob, StartTime: time.Unix(jobMeta.StartTime, 0)}
+	JobArchived(job, jobMeta)
+}
+
+func queuedFor(t *testing.T, user string) int {
+	var count int
+	if err := db.Get(&count, "SELECT COUNT(*) FROM notification_queue WHERE username = ?", user); err != nil {
+		t.Fatal(err)
+	}
+	return count
+}
+
+func TestNotifications(t *testing.T) {
+	sink := setup(t)
+
+	archiveJob(t, 1, "alice", 3600)
+	archiveJob(t, 2, "alice", 60) // too short
+	archiveJob(t, 3, "bob", 3600)
+	archiveJob(t, 4, "bob", 7200)
+	archiveJob(t, 5, "carol", 3600)
+	archiveJob(t, 6, "dave", 3600)
+
+	// Summaries of single jobs are sent in the background
+	deadline := time.Now().Add(5 * time.Second)
+	for len(sink.Messages()) == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	msgs := sink.Messages()
+	if len(msgs) != 1 || msgs[0].To[0] != "alice@example.org" || msgs[0].Subject != "Summary of job 1 on testcluster" {
+		t.Fatalf("unexpected messages: %#v", msgs)
+	}
+	for _, s := range []string{
+		"Duration:  1h0m0s",
+		"flops_any (avg):  25.00 GF/s  5.0% of peak",
+		"mem_used   12.00 GB    2.00 GB     16.00 GB\n",
+		"https://monitoring.example.org/monitoring/job/",
+	} {
+		if !strings.Contains(msgs[0].Body, s) {
+			t.Errorf("summary misses %q:\n%s", s, msgs[0].Body)
+		}
+	}
+
+	if queuedFor(t, "bob") != 2 || queuedFor(t, "carol") != 1 || queuedFor(t, "alice") != 0 || queuedFor(t, "dave") != 0 {
+		t.Fatal("wrong queued summaries")
+	}
+
+	// 2024-01-02 is a Tuesday: only the daily digest is sent
+	SendDigests(time.Date(2024, 1, 2, 7, 0, 0, 0, time.Local))
+	msgs = sink.Messages()
+	if len(msgs) != 2 || msgs[1].To[0] != "bob@example.org" || msgs[1].Subject != "Summaries of 2 jobs" ||
+		strings.Count(msgs[1].Body, "Job ") != 2 {
+		t.Fatalf("unexpected daily digest: %#v", msgs[len(msgs)-1])
+	}
+	if queuedFor(t, "bob") != 0 || queuedFor(t, "carol") != 1 {
+		t.Fatal("wrong queued summaries after daily digest")
+	}
+
+	// Summaries of users who turned notifications off are dropped
+	if err := repository.GetUserCfgRepo().UpdateConfig(ConfigKey, `"off"`, &schema.User{Username: "bob"}); err != nil {
+		t.Fatal(err)
+	}
+	archiveJob(t, 7, "carol", 3600)
+	if _, err := db.Exec("INSERT INTO notification_queue (username, job_id, summary, created_at) SELECT 'bob', id, 'summary', 0 FROM job LIMIT 1"); err != nil {
+		t.Fatal(err)
+	}
+
+	// 2024-01-08 is a Monday
+	SendDigests(time.Date(2024, 1, 8, 7, 0, 0, 0, time.Local))
+	msgs = sink.Messages()
+	if len(msgs) != 3 || msgs[2].To[0] != "carol@example.org" || msgs[2].Subject != "Summaries of 2 jobs" {
+		t.Fatalf("unexpected weekly digest: %#v", msgs[len(msgs)-1])
+	}
+	if queuedFor(t, "bob") != 0 || queuedFor(t, "carol") != 0 {
+		t.Fatal("queue not empty")
+	}
+}
@@ -0,0 +1,117 @@
+// Copyright (C) NHR@FAU, University Erlangen-Nuremberg.
+// All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+package notification
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ClusterCockpit/cc-backend/internal/mailer"
+	"github.com/ClusterCockpit/cc-backend/pkg/archive"
+	"github.com/ClusterCockpit/cc-backend/pkg/schema"
+)
+
+// Summary returns the plain text summary of the archived job, with the
+// footprint and statistics of all metrics compared to their peak values.
+func Summary(job *schema.Job, jobMeta *schema.JobMeta) string {
+	var metricConfig []schema.MetricConfig
+	var footprint []string
+	if sc, err := archive.GetSubCluster(job.Cluster, job.SubCluster); err == nil {
+		metricConfig, footprint = sc.MetricConfig, sc.Footprint
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Job %d on %s (project %s)\n\n", job.JobID, job.Cluster, job.Project)
+
+	w := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "State:\t%s\n", job.State)
+	fmt.Fprintf(w, "Started:\t%s\n", job.StartTime.Format(time.RFC1123))
+	fmt.Fprintf(w, "Duration:\t%s\n", time.Duration(job.Duration)*time.Second)
+	fmt.Fprintf(w, "Nodes:\t%d\n", job.NumNodes)
+	if job.NumAcc > 0 {
+		fmt.Fprintf(w, "Accelerators:\t%d\n", job.NumAcc)
+	}
+	if job.Energy > 0 {
+		fmt.Fprintf(w, "Energy:\t%.2f kWh\n", job.Energy)
+	}
+	w.Flush()
+
+	if len(footprint) > 0 {
+		b.WriteString("\nFootprint:\n")
+		w = tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+		for _, metric := range footprint {
+			stats, ok := jobMeta.Statistics[metric]
+			i, err := archive.MetricIndex(metricConfig, metric)
+			if !ok || err != nil {
+				continue
+			}
+
+			mc := &metricConfig[i]
+			var value float64
+			switch mc.Footprint {
+			case "min":
+				value = stats.Min
+			case "max":
+				value = stats.Max
+			default:
+				value = stats.Avg
+			}
+			fmt.Fprintf(w, "  %s (%s):\t%s\t%s\n", metric, mc.Footprint, format(value, stats.Unit), ofPeak(value, mc))
+		}
+		w.Flush()
+	}
+
+	metrics := make([]string, 0, len(jobMeta.Statistics))
+	for metric := range jobMeta.Statistics {
+		metrics = append(metrics, metric)
+	}
+	sort.Strings(metrics)
+
+	if len(metrics) > 0 {
+		b.WriteString("\nStatistics:\n")
+		w = tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+		fmt.Fprintf(w, "  Metric\tAvg\tMin\tMax\n")
+		for _, metric := range metrics {
+			stats := jobMeta.Statistics[metric]
+			var peak string
+			if i, err := archive.MetricIndex(metricConfig, metric); err == nil {
+				peak = ofPeak(stats.Avg, &metricConfig[i])
+			}
+			fmt.Fprintf(w, "  %s\t%s\t%s\t%s\t%s\n", metric,
+				format(stats.Avg, stats.Unit), format(stats.Min, stats.Unit), format(stats.Max, stats.Unit), peak)
+		}
+		w.Flush()
+	}
+
+	if url := mailer.JobUrl(job.ID); url != "" {
+		fmt.Fprintf(&b, "\n%s\n", url)
+	}
+
+	// Drop the padding of empty last columns
+	lines := strings.Split(b.String(), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " ")
+	}
+	return strings.Join(lines, "\n")
+}
+
+func format(value float64, unit schema.Unit) string {
+	u := unit.Base
+	if unit.Prefix != "" {
+		u = unit.Prefix + u
+	}
+	return strings.TrimSpace(fmt.Sprintf("%.2f %s", value, u))
+}
+
+// Value relative to the peak of the metric, empty if no peak is configured
+func ofPeak(value float64, mc *schema.MetricConfig) string {
+	if mc.Peak <= 0 {
+		return ""
+	}
+	return fmt.Sprintf("%.1f%% of peak", value/mc.Peak*100)
+}
@@ -0,0 +1,58 @@
+// Copyright (C) NHR@FAU, University Erlangen-Nuremberg.
+// All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+package repository
+
+import (
+	"github.com/ClusterCockpit/cc-backend/pkg/log"
+	"github.com/ClusterCockpit/cc-backend/pkg/schema"
+	sq "github.com/Masterminds/squirrel"
+)
+
+// AddJobAlert records the alert and sets its id. Returns false if an alert
+// with that name was already recorded for the job.
+func (r *JobRepository) AddJobAlert(alert *schema.JobAlert) (added bool, err error) {
+	var count int
+	if err := sq.Select("COUNT(*)").From("job_alert").
+		Where("job_alert.job_id = ?", alert.JobID).Where("job_alert.name = ?", alert.Name).
+		RunWith(r.stmtCache).QueryRow().Scan(&count); err != nil {
+		log.Errorf("Error while checking alerts of job %d: %v", alert.JobID, err)
+		return false, err
+	}
+	if count > 0 {
+		return false, nil
+	}
+
+	q := sq.Insert("job_alert").Columns("job_id", "name", "message", "since", "created_at").
+		Values(alert.JobID, alert.Name, alert.Message, alert.Since, alert.CreatedAt)
+
+	res, err := q.RunWith(r.stmtCache).Exec()
+	if err != nil {
+		s, _, _ := q.ToSql()
+		log.Errorf("Error adding alert with %s: %v", s, err)
+		return false, err
+	}
+
+	alert.ID, err = res.LastInsertId()
+	return true, err
+}
+
+// FindJobAlerts returns all alerts recorded for the job with the database id,
+// oldest first.
+func (r *JobRepository) FindJobAlerts(jobId int64) ([]*schema.JobAlert, error) {
+	query, args, err := sq.Select("id", "job_id", "name", "message", "since", "created_at").
+		From("job_alert").Where("job_alert.job_id = ?", jobId).OrderBy("job_alert.id").ToSql()
+	if err != nil {
+		log.Warn("Error while converting query to sql")
+		return nil, err
+	}
+
+	alerts := make([]*schema.JobAlert, 0)
+	if err := r.DB.Select(&alerts, query, args...); err != nil {
+		log.Errorf("Error while loading alerts of job %d: %v", jobId, err)
+		return nil, err
+	}
+
+	return alerts, nil
+}
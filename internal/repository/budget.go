@@ -0,0 +1,220 @@
+// Copyright (C) NHR@FAU, University Erlangen-Nuremberg.
+// All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ClusterCockpit/cc-backend/pkg/archive"
+	"github.com/ClusterCockpit/cc-backend/pkg/log"
+	"github.com/ClusterCockpit/cc-backend/pkg/schema"
+	sq "github.com/Masterminds/squirrel"
+)
+
+// AddProjectBudget checks and stores the budget and sets its id.
+func (r *JobRepository) AddProjectBudget(budget *schema.ProjectBudget) error {
+	switch {
+	case budget.Project == "":
+		return errors.New("REPOSITORY/BUDGET > budget without project")
+	case !budget.Unit.Valid():
+		return fmt.Errorf("REPOSITORY/BUDGET > invalid unit '%s'", budget.Unit)
+	case budget.Amount <= 0:
+		return errors.New("REPOSITORY/BUDGET > amount must be positive")
+	case budget.EndTime <= budget.StartTime:
+		return errors.New("REPOSITORY/BUDGET > period ends before it starts")
+	}
+
+	q := sq.Insert("project_budget").Columns("project", "cluster", "unit", "amount", "start_time", "end_time").
+		Values(budget.Project, budget.Cluster, budget.Unit, budget.Amount, budget.StartTime, budget.EndTime)
+
+	res, err := q.RunWith(r.stmtCache).Exec()
+	if err != nil {
+		s, _, _ := q.ToSql()
+		log.Errorf("Error adding budget with %s: %v", s, err)
+		return err
+	}
+
+	budget.ID, err = res.LastInsertId()
+	return err
+}
+
+// DeleteProjectBudget removes the budget with the id.
+func (r *JobRepository) DeleteProjectBudget(id int64) error {
+	res, err := sq.Delete("project_budget").Where("project_budget.id = ?", id).RunWith(r.stmtCache).Exec()
+	if err != nil {
+		log.Errorf("Error while deleting budget %d: %v", id, err)
+		return err
+	}
+
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return fmt.Errorf("REPOSITORY/BUDGET > no budget with id %d", id)
+	}
+	return nil
+}
+
+// FindProjectBudgets returns the budgets visible to the user, optionally
+// filtered by project and cluster. Admins, support staff and API users see all
+// budgets, managers only the budgets of their projects.
+func (r *JobRepository) FindProjectBudgets(user *schema.User, project *string, cluster *string) ([]*schema.ProjectBudget, error) {
+	query := sq.Select("id", "project", "cluster", "unit", "amount", "start_time", "end_time").
+		From("project_budget").OrderBy("project_budget.project", "project_budget.start_time", "project_budget.id")
+
+	query, err := budgetSecurityCheck(user, query)
+	if err != nil {
+		return nil, err
+	}
+	if project != nil {
+		query = query.Where("project_budget.project = ?", *project)
+	}
+	if cluster != nil {
+		query = query.Where("project_budget.cluster = ?", *cluster)
+	}
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		log.Warn("Error while converting query to sql")
+		return nil, err
+	}
+
+	budgets := make([]*schema.ProjectBudget, 0)
+	if err := r.DB.Select(&budgets, sql, args...); err != nil {
+		log.Errorf("Error while loading budgets: %v", err)
+		return nil, err
+	}
+
+	return budgets, nil
+}
+
+// ProjectBudgetStatus returns the consumption of all budgets visible to the
+// user, see FindProjectBudgets.
+func (r *JobRepository) ProjectBudgetStatus(user *schema.User, project *string, cluster *string) ([]*schema.BudgetStatus, error) {
+	budgets, err := r.FindProjectBudgets(user, project, cluster)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	res := make([]*schema.BudgetStatus, 0, len(budgets))
+	for _, budget := range budgets {
+		status, err := r.BudgetStatus(budget, now)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, status)
+	}
+
+	return res, nil
+}
+
+// BudgetStatus computes the consumption of the budget from all jobs of its
+// project which ran during its period, up to now for running jobs. Only the
+// part of a job within the period is accounted: core-hours are based on the
+// number of cores, derived from the hardware threads of the job and the
+// topology of its subcluster, energy is prorated by the runtime.
+func (r *JobRepository) BudgetStatus(budget *schema.ProjectBudget, now time.Time) (*schema.BudgetStatus, error) {
+	query := sq.Select("job.cluster", "COALESCE(job.subcluster, '')", "job.start_time", "job.duration", "job.job_state",
+		"COALESCE(job.num_hwthreads, 0)", "COALESCE(job.num_acc, 0)", "job.energy").
+		From("job").
+		Where("job.project = ?", budget.Project).
+		Where("job.start_time < ?", budget.EndTime).
+		Where(sq.Or{
+			sq.Eq{"job.job_state": schema.JobStateRunning},
+			sq.Expr("job.start_time + job.duration > ?", budget.StartTime),
+		})
+	if budget.Cluster != "" {
+		query = query.Where("job.cluster = ?", budget.Cluster)
+	}
+
+	rows, err := query.RunWith(r.stmtCache).Query()
+	if err != nil {
+		log.Errorf("Error while loading jobs of project '%s': %v", budget.Project, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	status := &schema.BudgetStatus{Budget: budget}
+	for rows.Next() {
+		var cluster, subCluster string
+		var startTime int64
+		var duration, numHWThreads, numAcc int32
+		var state schema.JobState
+		var energy float64
+		if err := rows.Scan(&cluster, &subCluster, &startTime, &duration, &state, &numHWThreads, &numAcc, &energy); err != nil {
+			log.Warn("Error while scanning rows")
+			return nil, err
+		}
+
+		endTime := startTime + int64(duration)
+		if state == schema.JobStateRunning {
+			endTime = now.Unix()
+		}
+		overlap := min(endTime, budget.EndTime) - max(startTime, budget.StartTime)
+		if overlap <= 0 {
+			continue
+		}
+
+		switch budget.Unit {
+		case schema.BudgetUnitCoreHours:
+			status.Used += float64(overlap) * float64(numCores(cluster, subCluster, numHWThreads)) / 3600
+		case schema.BudgetUnitAccHours:
+			status.Used += float64(overlap) * float64(numAcc) / 3600
+		case schema.BudgetUnitEnergy:
+			status.Used += energy * float64(overlap) / float64(endTime-startTime)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	status.Remaining = budget.Amount - status.Used
+	status.Projected = status.Used
+	if elapsed := min(now.Unix(), budget.EndTime) - budget.StartTime; elapsed > 0 {
+		status.BurnRate = status.Used / (float64(elapsed) / 86400)
+		if rest := budget.EndTime - max(now.Unix(), budget.StartTime); rest > 0 {
+			status.Projected += status.BurnRate * float64(rest) / 86400
+		}
+	}
+
+	return status, nil
+}
+
+// Returns the number of cores of the hardware threads, rounded up to full
+// cores. The threads per core are derived from the topology of the
+// subcluster, or taken from its configuration if the topology lists no cores.
+// Each hardware thread is counted as core if the subcluster is unknown.
+func numCores(cluster, subCluster string, numHWThreads int32) int32 {
+	sc, err := archive.GetSubCluster(cluster, subCluster)
+	if err != nil {
+		return numHWThreads
+	}
+
+	threadsPerCore := int32(sc.ThreadsPerCore)
+	if len(sc.Topology.Core) > 0 {
+		threadsPerCore = int32(len(sc.Topology.Node) / len(sc.Topology.Core))
+	}
+	if threadsPerCore <= 1 {
+		return numHWThreads
+	}
+	return (numHWThreads + threadsPerCore - 1) / threadsPerCore
+}
+
+func budgetSecurityCheck(user *schema.User, query sq.SelectBuilder) (sq.SelectBuilder, error) {
+	switch {
+	case user == nil:
+		return query, errors.New("user context is nil")
+	case len(user.Roles) == 1 && user.HasRole(schema.RoleApi): // API-User : All budgets
+		return query, nil
+	case user.HasAnyRole([]schema.Role{schema.RoleAdmin, schema.RoleSupport}): // Admin & Support : All budgets
+		return query, nil
+	case user.HasRole(schema.RoleManager): // Manager : Budgets of managed projects only
+		return query.Where(sq.Eq{"project_budget.project": user.Projects}), nil
+	default:
+		return query, ErrForbidden
+	}
+}
@@ -0,0 +1,160 @@
+// Copyright (C) NHR@FAU, University Erlangen-Nuremberg.
+// All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+package repository
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/ClusterCockpit/cc-backend/pkg/archive"
+	"github.com/ClusterCockpit/cc-backend/pkg/schema"
+)
+
+func TestBudgetStatus(t *testing.T) {
+	r := setup(t)
+
+	// The three k106eb jobs on fritz start at 1675957496 and run for 2034s,
+	// 1870s and 7152s on 72 hardware threads each
+	budget := &schema.ProjectBudget{
+		Project:   "k106eb",
+		Cluster:   "fritz",
+		Unit:      schema.BudgetUnitCoreHours,
+		Amount:    100,
+		StartTime: 1675957496 + 1800,
+		EndTime:   1675957496 + 3600,
+	}
+	noErr(t, r.AddProjectBudget(budget))
+	t.Cleanup(func() { noErr(t, r.DeleteProjectBudget(budget.ID)) })
+
+	status, err := r.BudgetStatus(budget, time.Unix(budget.EndTime+86400, 0))
+	noErr(t, err)
+
+	used := float64(234+70+1800) * 72 / 3600
+	if math.Abs(status.Used-used) > 1e-9 || math.Abs(status.Remaining-(100-used)) > 1e-9 {
+		t.Errorf("wrong consumption: used %f, remaining %f", status.Used, status.Remaining)
+	}
+	if math.Abs(status.BurnRate-used*48) > 1e-9 || status.Projected != status.Used {
+		t.Errorf("wrong burn rate: %f per day, projected %f", status.BurnRate, status.Projected)
+	}
+
+	// Half way through the period
+	status, err = r.BudgetStatus(budget, time.Unix(budget.StartTime+900, 0))
+	noErr(t, err)
+	if math.Abs(status.Projected-2*status.Used) > 1e-9 {
+		t.Errorf("wrong projection: used %f, projected %f", status.Used, status.Projected)
+	}
+
+	// Hardware threads of the same core are counted once
+	setTestTopology(t, "fritz", "main", 2)
+	status, err = r.BudgetStatus(budget, time.Unix(budget.EndTime+86400, 0))
+	noErr(t, err)
+	if math.Abs(status.Used-used/2) > 1e-9 {
+		t.Errorf("wrong consumption with SMT: used %f", status.Used)
+	}
+}
+
+// Configures a subcluster with 72 cores of the given number of hardware
+// threads each for the duration of the test. The subcluster "configured" has
+// no topology but 4 threads per core, "topology" both.
+func setTestTopology(t *testing.T, cluster, subCluster string, threadsPerCore int) {
+	topology := schema.Topology{}
+	for c := range 72 {
+		core := make([]int, 0, threadsPerCore)
+		for i := range threadsPerCore {
+			core = append(core, c+i*72)
+			topology.Node = append(topology.Node, c+i*72)
+		}
+		topology.Core = append(topology.Core, core)
+	}
+
+	clusters := archive.Clusters
+	archive.Clusters = []*schema.Cluster{{Name: cluster, SubClusters: []*schema.SubCluster{
+		{Name: subCluster, Topology: topology},
+		{Name: "configured", ThreadsPerCore: 4},
+		{Name: "topology", ThreadsPerCore: 4, Topology: topology},
+	}}}
+	t.Cleanup(func() { archive.Clusters = clusters })
+}
+
+func TestNumCores(t *testing.T) {
+	setTestTopology(t, "testcluster", "smt", 2)
+
+	tests := []struct {
+		cluster, subCluster string
+		hwthreads, want     int32
+	}{
+		{"testcluster", "smt", 144, 72},
+		{"testcluster", "smt", 3, 2},
+		{"testcluster", "configured", 144, 36},
+		{"testcluster", "topology", 144, 72},
+		{"testcluster", "other", 144, 144},
+		{"unknown", "smt", 10, 10},
+	}
+	for _, tt := range tests {
+		if got := numCores(tt.cluster, tt.subCluster, tt.hwthreads); got != tt.want {
+			t.Errorf("%d hwthreads on %s/%s: got %d cores, want %d", tt.hwthreads, tt.cluster, tt.subCluster, got, tt.want)
+		}
+	}
+}
+
+func TestFindProjectBudgets(t *testing.T) {
+	r := setup(t)
+
+	for _, project := range []string{"k106eb", "caph"} {
+		budget := &schema.ProjectBudget{Project: project, Unit: schema.BudgetUnitAccHours, Amount: 10, StartTime: 0, EndTime: 1}
+		noErr(t, r.AddProjectBudget(budget))
+		t.Cleanup(func() { noErr(t, r.DeleteProjectBudget(budget.ID)) })
+	}
+
+	tests := []struct {
+		user *schema.User
+		want int
+	}{
+		{&schema.User{Username: "admin", Roles: []string{"admin"}}, 2},
+		{&schema.User{Username: "api", Roles: []string{"api"}}, 2},
+		{&schema.User{Username: "manager", Roles: []string{"manager"}, Projects: []string{"caph"}}, 1},
+		{&schema.User{Username: "manager", Roles: []string{"manager"}}, 0},
+	}
+	for _, tt := range tests {
+		budgets, err := r.FindProjectBudgets(tt.user, nil, nil)
+		noErr(t, err)
+		if len(budgets) != tt.want {
+			t.Errorf("%v: got %d budgets, want %d", tt.user.Roles, len(budgets), tt.want)
+		}
+	}
+
+	project := "caph"
+	budgets, err := r.FindProjectBudgets(tests[0].user, &project, nil)
+	noErr(t, err)
+	if len(budgets) != 1 || budgets[0].Project != "caph" || budgets[0].Unit != schema.BudgetUnitAccHours {
+		t.Errorf("wrong budgets: %#v", budgets)
+	}
+
+	if _, err := r.FindProjectBudgets(&schema.User{Username: "user", Roles: []string{"user"}}, nil, nil); !errors.Is(err, ErrForbidden) {
+		t.Errorf("expected ErrForbidden, got %v", err)
+	}
+}
+
+func TestAddProjectBudget(t *testing.T) {
+	r := setup(t)
+
+	invalid := []*schema.ProjectBudget{
+		{Unit: schema.BudgetUnitCoreHours, Amount: 10, EndTime: 1},
+		{Project: "caph", Unit: "nodeHours", Amount: 10, EndTime: 1},
+		{Project: "caph", Unit: schema.BudgetUnitEnergy, EndTime: 1},
+		{Project: "caph", Unit: schema.BudgetUnitEnergy, Amount: 10, StartTime: 1},
+	}
+	for _, budget := range invalid {
+		if err := r.AddProjectBudget(budget); err == nil {
+			t.Errorf("expected error for %#v", budget)
+		}
+	}
+
+	if err := r.DeleteProjectBudget(-1); err == nil {
+		t.Error("expected error deleting unknown budget")
+	}
+}
@@ -33,6 +33,10 @@ type JobRepository struct {
 	stmtCache *sq.StmtCache
 	cache     *lrucache.Cache
 	driver    string
+
+	// Held while checking for an existing job and starting a new one, shared
+	// by all code paths adding jobs to avoid duplicates
+	Mutex sync.Mutex
 }
 
 func GetJobRepository() *JobRepository {
@@ -56,6 +60,10 @@ var jobColumns []string = []string{
 	"job.duration", "job.walltime", "job.resources", "job.footprint", "job.energy",
 }
 
+// Columns of IterateJobs, jobColumns followed by the columns loaded on
+// demand for single jobs
+var jobColumnsWithMeta []string = append(jobColumns[:len(jobColumns):len(jobColumns)], "job.energy_footprint", "job.meta_data")
+
 func scanJob(row interface{ Scan(...interface{}) error }) (*schema.Job, error) {
 	job := &schema.Job{}
 
@@ -67,15 +75,56 @@ func scanJob(row interface{ Scan(...interface{}) error }) (*schema.Job, error) {
 		return nil, err
 	}
 
+	if err := decodeJob(job); err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+func scanJobWithMeta(row interface{ Scan(...interface{}) error }) (*schema.Job, error) {
+	job := &schema.Job{}
+
+	if err := row.Scan(
+		&job.ID, &job.JobID, &job.User, &job.Project, &job.Cluster, &job.SubCluster, &job.StartTimeUnix, &job.Partition, &job.ArrayJobId,
+		&job.NumNodes, &job.NumHWThreads, &job.NumAcc, &job.Exclusive, &job.MonitoringStatus, &job.SMT, &job.State,
+		&job.Duration, &job.Walltime, &job.RawResources, &job.RawFootprint, &job.Energy,
+		&job.RawEnergyFootprint, &job.RawMetaData); err != nil {
+		log.Warnf("Error while scanning rows (Job): %v", err)
+		return nil, err
+	}
+
+	if len(job.RawEnergyFootprint) != 0 {
+		if err := json.Unmarshal(job.RawEnergyFootprint, &job.EnergyFootprint); err != nil {
+			log.Warnf("Error while unmarshaling raw energy footprint json: %v", err)
+			return nil, err
+		}
+	}
+	job.RawEnergyFootprint = nil
+
+	if len(job.RawMetaData) != 0 {
+		if err := json.Unmarshal(job.RawMetaData, &job.MetaData); err != nil {
+			log.Warnf("Error while unmarshaling raw metadata json: %v", err)
+			return nil, err
+		}
+	}
+	job.RawMetaData = nil
+
+	if err := decodeJob(job); err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+func decodeJob(job *schema.Job) error {
 	if err := json.Unmarshal(job.RawResources, &job.Resources); err != nil {
 		log.Warn("Error while unmarshaling raw resources json")
-		return nil, err
+		return err
 	}
 	job.RawResources = nil
 
 	if err := json.Unmarshal(job.RawFootprint, &job.Footprint); err != nil {
 		log.Warnf("Error while unmarshaling raw footprint json: %v", err)
-		return nil, err
+		return err
 	}
 	job.RawFootprint = nil
 
@@ -85,7 +134,7 @@ func scanJob(row interface{ Scan(...interface{}) error }) (*schema.Job, error) {
 		job.Duration = int32(time.Since(job.StartTime).Seconds())
 	}
 
-	return job, nil
+	return nil
 }
 
 func (r *JobRepository) Optimize() error {
@@ -555,6 +604,30 @@ func (r *JobRepository) FindJobsBetween(startTimeBegin int64, startTimeEnd int64
 	return jobs, nil
 }
 
+func (r *JobRepository) FindJobsByMonitoringStatus(monitoringStatus int32) ([]*schema.Job, error) {
+	query := sq.Select(jobColumns...).From("job").
+		Where("job.monitoring_status = ?", monitoringStatus)
+
+	rows, err := query.RunWith(r.stmtCache).Query()
+	if err != nil {
+		log.Error("Error while running query")
+		return nil, err
+	}
+	defer rows.Close()
+
+	jobs := make([]*schema.Job, 0, 50)
+	for rows.Next() {
+		job, err := scanJob(rows)
+		if err != nil {
+			log.Warn("Error while scanning rows")
+			return nil, err
+		}
+		jobs = append(jobs, job)
+	}
+
+	return jobs, rows.Err()
+}
+
 func (r *JobRepository) UpdateMonitoringStatus(job int64, monitoringStatus int32) (err error) {
 	stmt := sq.Update("job").
 		Set("monitoring_status", monitoringStatus).
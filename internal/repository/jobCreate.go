@@ -39,22 +39,39 @@ func (r *JobRepository) InsertJob(job *schema.JobMeta) (int64, error) {
 // Start inserts a new job in the table, returning the unique job ID.
 // Statistics are not transfered!
 func (r *JobRepository) Start(job *schema.JobMeta) (id int64, err error) {
+	if err := encodeJobMeta(job); err != nil {
+		return -1, err
+	}
+
+	return r.InsertJob(job)
+}
+
+// TransactionStart inserts a new job within the transaction t, see Start.
+func (r *JobRepository) TransactionStart(t *Transaction, job *schema.JobMeta) (id int64, err error) {
+	if err := encodeJobMeta(job); err != nil {
+		return -1, err
+	}
+
+	return r.TransactionAddNamed(t, NamedJobInsert, job)
+}
+
+func encodeJobMeta(job *schema.JobMeta) (err error) {
 	job.RawFootprint, err = json.Marshal(job.Footprint)
 	if err != nil {
-		return -1, fmt.Errorf("REPOSITORY/JOB > encoding footprint field failed: %w", err)
+		return fmt.Errorf("REPOSITORY/JOB > encoding footprint field failed: %w", err)
 	}
 
 	job.RawResources, err = json.Marshal(job.Resources)
 	if err != nil {
-		return -1, fmt.Errorf("REPOSITORY/JOB > encoding resources field failed: %w", err)
+		return fmt.Errorf("REPOSITORY/JOB > encoding resources field failed: %w", err)
 	}
 
 	job.RawMetaData, err = json.Marshal(job.MetaData)
 	if err != nil {
-		return -1, fmt.Errorf("REPOSITORY/JOB > encoding metaData field failed: %w", err)
+		return fmt.Errorf("REPOSITORY/JOB > encoding metaData field failed: %w", err)
 	}
 
-	return r.InsertJob(job)
+	return nil
 }
 
 // Stop updates the job with the database id jobId using the provided arguments.
@@ -73,3 +90,21 @@ func (r *JobRepository) Stop(
 	_, err = stmt.RunWith(r.stmtCache).Exec()
 	return
 }
+
+// TransactionStop updates the job within the transaction t, see Stop.
+func (r *JobRepository) TransactionStop(
+	t *Transaction,
+	jobId int64,
+	duration int32,
+	state schema.JobState,
+	monitoringStatus int32,
+) (err error) {
+	stmt := sq.Update("job").
+		Set("job_state", state).
+		Set("duration", duration).
+		Set("monitoring_status", monitoringStatus).
+		Where("job.id = ?", jobId)
+
+	_, err = stmt.RunWith(t.tx).Exec()
+	return
+}
@@ -83,6 +83,23 @@ func (r *JobRepository) FindAll(
 	return jobs, nil
 }
 
+// FindDuplicate returns a job with the same batch job id and cluster that
+// started less than a day before startTime, or nil if there is none. This is
+// the rule used to reject a job that was already started or imported.
+func (r *JobRepository) FindDuplicate(jobId int64, cluster string, startTime int64) (*schema.Job, error) {
+	jobs, err := r.FindAll(&jobId, &cluster, nil)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	}
+
+	for _, job := range jobs {
+		if (startTime - job.StartTimeUnix) < 86400 {
+			return job, nil
+		}
+	}
+	return nil, nil
+}
+
 // FindById executes a SQL query to find a specific batch job.
 // The job is queried using the database id.
 // It returns a pointer to a schema.Job data structure and an error variable.
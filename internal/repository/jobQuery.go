@@ -13,6 +13,7 @@ import (
 	"time"
 
 	"github.com/ClusterCockpit/cc-backend/internal/graph/model"
+	"github.com/ClusterCockpit/cc-backend/pkg/archive"
 	"github.com/ClusterCockpit/cc-backend/pkg/log"
 	"github.com/ClusterCockpit/cc-backend/pkg/schema"
 	sq "github.com/Masterminds/squirrel"
@@ -29,31 +30,9 @@ func (r *JobRepository) QueryJobs(
 		return nil, qerr
 	}
 
-	if order != nil {
-		field := toSnakeCase(order.Field)
-		if order.Type == "col" {
-			// "col": Fixed column name query
-			switch order.Order {
-			case model.SortDirectionEnumAsc:
-				query = query.OrderBy(fmt.Sprintf("job.%s ASC", field))
-			case model.SortDirectionEnumDesc:
-				query = query.OrderBy(fmt.Sprintf("job.%s DESC", field))
-			default:
-				return nil, errors.New("REPOSITORY/QUERY > invalid sorting order for column")
-			}
-		} else {
-			// "foot": Order by footprint JSON field values
-			// Verify and Search Only in Valid Jsons
-			query = query.Where("JSON_VALID(meta_data)")
-			switch order.Order {
-			case model.SortDirectionEnumAsc:
-				query = query.OrderBy(fmt.Sprintf("JSON_EXTRACT(footprint, \"$.%s\") ASC", field))
-			case model.SortDirectionEnumDesc:
-				query = query.OrderBy(fmt.Sprintf("JSON_EXTRACT(footprint, \"$.%s\") DESC", field))
-			default:
-				return nil, errors.New("REPOSITORY/QUERY > invalid sorting order for footprint")
-			}
-		}
+	query, err := orderBy(query, order)
+	if err != nil {
+		return nil, err
 	}
 
 	if page != nil && page.ItemsPerPage != -1 {
@@ -85,6 +64,124 @@ func (r *JobRepository) QueryJobs(
 	return jobs, nil
 }
 
+// IterateJobs calls fn for every job matching the filters. The rows are read
+// one by one, unlike QueryJobs the result is never held in memory completely.
+// Energy footprint and meta data are loaded as well. Iteration stops at the
+// first error returned by fn.
+func (r *JobRepository) IterateJobs(
+	ctx context.Context,
+	filters []*model.JobFilter,
+	order *model.OrderByInput,
+	fn func(job *schema.Job) error,
+) error {
+	query, qerr := SecurityCheck(ctx, sq.Select(jobColumnsWithMeta...).From("job"))
+	if qerr != nil {
+		return qerr
+	}
+
+	query, err := orderBy(query, order)
+	if err != nil {
+		return err
+	}
+
+	for _, f := range filters {
+		query = BuildWhereClause(f, query)
+	}
+
+	rows, err := query.RunWith(r.DB).QueryContext(ctx)
+	if err != nil {
+		log.Errorf("Error while running query: %v", err)
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		job, err := scanJobWithMeta(rows)
+		if err != nil {
+			log.Warn("Error while scanning rows (Jobs)")
+			return err
+		}
+		if err := fn(job); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+// Checks that jobs can be sorted as requested. Only the scalar job columns and
+// the footprint keys of the configured clusters are accepted as fields, as they
+// end up in the SQL query.
+func ValidateOrder(order *model.OrderByInput) error {
+	switch order.Order {
+	case model.SortDirectionEnumAsc, model.SortDirectionEnumDesc:
+	default:
+		return fmt.Errorf("REPOSITORY/QUERY > invalid sorting order '%s'", order.Order)
+	}
+
+	field := toSnakeCase(order.Field)
+	switch order.Type {
+	case "col":
+		for _, c := range jobColumns {
+			if c == "job."+field && field != "resources" && field != "footprint" {
+				return nil
+			}
+		}
+		return fmt.Errorf("REPOSITORY/QUERY > cannot sort by column '%s'", order.Field)
+	case "foot":
+		for _, cluster := range archive.Clusters {
+			for _, sc := range cluster.SubClusters {
+				for _, fp := range sc.Footprint {
+					for _, stat := range []string{"avg", "min", "max"} {
+						if field == fp+"_"+stat {
+							return nil
+						}
+					}
+				}
+			}
+		}
+		return fmt.Errorf("REPOSITORY/QUERY > cannot sort by footprint '%s'", order.Field)
+	default:
+		return fmt.Errorf("REPOSITORY/QUERY > invalid sorting type '%s'", order.Type)
+	}
+}
+
+func orderBy(query sq.SelectBuilder, order *model.OrderByInput) (sq.SelectBuilder, error) {
+	if order == nil {
+		return query, nil
+	}
+	if err := ValidateOrder(order); err != nil {
+		return query, err
+	}
+
+	field := toSnakeCase(order.Field)
+	if order.Type == "col" {
+		// "col": Fixed column name query
+		switch order.Order {
+		case model.SortDirectionEnumAsc:
+			query = query.OrderBy(fmt.Sprintf("job.%s ASC", field))
+		case model.SortDirectionEnumDesc:
+			query = query.OrderBy(fmt.Sprintf("job.%s DESC", field))
+		default:
+			return query, errors.New("REPOSITORY/QUERY > invalid sorting order for column")
+		}
+	} else {
+		// "foot": Order by footprint JSON field values
+		// Verify and Search Only in Valid Jsons
+		query = query.Where("JSON_VALID(meta_data)")
+		switch order.Order {
+		case model.SortDirectionEnumAsc:
+			query = query.OrderBy(fmt.Sprintf("JSON_EXTRACT(footprint, \"$.%s\") ASC", field))
+		case model.SortDirectionEnumDesc:
+			query = query.OrderBy(fmt.Sprintf("JSON_EXTRACT(footprint, \"$.%s\") DESC", field))
+		default:
+			return query, errors.New("REPOSITORY/QUERY > invalid sorting order for footprint")
+		}
+	}
+
+	return query, nil
+}
+
 func (r *JobRepository) CountJobs(
 	ctx context.Context,
 	filters []*model.JobFilter,
@@ -107,6 +204,30 @@ func (r *JobRepository) CountJobs(
 	return count, nil
 }
 
+// JobMatches returns whether the job with the database id is visible to the
+// user of ctx and matches all filters.
+func (r *JobRepository) JobMatches(
+	ctx context.Context,
+	id int64,
+	filters []*model.JobFilter,
+) (bool, error) {
+	query, qerr := SecurityCheck(ctx, sq.Select("count(DISTINCT job.id)").From("job").Where("job.id = ?", id))
+	if qerr != nil {
+		return false, qerr
+	}
+
+	for _, f := range filters {
+		query = BuildWhereClause(f, query)
+	}
+
+	var count int
+	if err := query.RunWith(r.DB).Scan(&count); err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
 func SecurityCheckWithUser(user *schema.User, query sq.SelectBuilder) (sq.SelectBuilder, error) {
 	if user == nil {
 		var qnil sq.SelectBuilder
@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"testing"
 
+	"github.com/ClusterCockpit/cc-backend/internal/graph/model"
 	"github.com/ClusterCockpit/cc-backend/pkg/schema"
 	_ "github.com/mattn/go-sqlite3"
 )
@@ -71,3 +72,89 @@ func TestGetTags(t *testing.T) {
 		t.Errorf("wrong tag count \ngot: %d \nwant: 0", counts["bandwidth"])
 	}
 }
+
+func TestFindJobsByMonitoringStatus(t *testing.T) {
+	r := setup(t)
+
+	jobs, err := r.FindJobsByMonitoringStatus(schema.MonitoringStatusArchivingSuccessful)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, job := range jobs {
+		if job.MonitoringStatus != schema.MonitoringStatusArchivingSuccessful {
+			t.Errorf("wrong monitoring status for job %d\ngot: %d \nwant: %d",
+				job.JobID, job.MonitoringStatus, schema.MonitoringStatusArchivingSuccessful)
+		}
+	}
+}
+
+func TestJobMatches(t *testing.T) {
+	r := setup(t)
+
+	cluster, other := "fritz", "alex"
+	tests := []struct {
+		ctx     context.Context
+		filters []*model.JobFilter
+		want    bool
+	}{
+		{getContext(t), nil, true},
+		{getContext(t), []*model.JobFilter{{Cluster: &model.StringInput{Eq: &cluster}}, {State: []schema.JobState{schema.JobStateCompleted}}}, true},
+		{getContext(t), []*model.JobFilter{{Cluster: &model.StringInput{Eq: &other}}}, false},
+		{getContext(t), []*model.JobFilter{{State: []schema.JobState{schema.JobStateRunning}}}, false},
+		// Jobs of other users are not visible to users
+		{context.WithValue(context.Background(), ContextUserKey, &schema.User{Username: "testuser", Roles: []string{"user"}}), nil, false},
+	}
+	for i, tt := range tests {
+		got, err := r.JobMatches(tt.ctx, 5, tt.filters)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tt.want {
+			t.Errorf("%d: got %v, want %v", i, got, tt.want)
+		}
+	}
+}
+
+func TestIterateJobs(t *testing.T) {
+	r := setup(t)
+
+	cluster := "fritz"
+	filters := []*model.JobFilter{{Cluster: &model.StringInput{Eq: &cluster}}}
+	order := &model.OrderByInput{Field: "startTime", Type: "col", Order: model.SortDirectionEnumAsc}
+
+	count, err := r.CountJobs(getContext(t), filters)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var jobs []*schema.Job
+	if err := r.IterateJobs(getContext(t), filters, order, func(job *schema.Job) error {
+		jobs = append(jobs, job)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(jobs) != count || count == 0 {
+		t.Fatalf("expected %d jobs, got %d", count, len(jobs))
+	}
+	for i, job := range jobs {
+		if job.Cluster != cluster || (i > 0 && job.StartTimeUnix < jobs[i-1].StartTimeUnix) {
+			t.Fatalf("unexpected job %d: %#v", i, job)
+		}
+		if job.MetaData == nil {
+			t.Errorf("meta data of job %d not loaded", job.ID)
+		}
+	}
+
+	// Iteration stops at the first error
+	stop := fmt.Errorf("stop")
+	n := 0
+	if err := r.IterateJobs(getContext(t), filters, order, func(job *schema.Job) error {
+		n++
+		return stop
+	}); err != stop || n != 1 {
+		t.Errorf("expected iteration to stop after one job, got %d jobs (%v)", n, err)
+	}
+}
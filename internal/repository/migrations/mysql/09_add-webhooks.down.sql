@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS webhook_delivery_next_attempt ON webhook_delivery;
+DROP TABLE IF EXISTS webhook_delivery;
@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS job_alert (
+    id INTEGER AUTO_INCREMENT PRIMARY KEY,
+    job_id INTEGER NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    message TEXT NOT NULL,
+    since BIGINT NOT NULL,
+    created_at BIGINT NOT NULL,
+    UNIQUE (job_id, name),
+    FOREIGN KEY (job_id) REFERENCES job (id) ON DELETE CASCADE
+);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS notification_queue_username ON notification_queue;
+DROP TABLE IF EXISTS notification_queue;
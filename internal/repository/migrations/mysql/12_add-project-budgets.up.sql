@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS project_budget (
+    id INTEGER AUTO_INCREMENT PRIMARY KEY,
+    project VARCHAR(255) NOT NULL,
+    cluster VARCHAR(255) NOT NULL DEFAULT '',
+    unit VARCHAR(255) NOT NULL CHECK (unit IN ('coreHours', 'accHours', 'energy')),
+    amount REAL NOT NULL,
+    start_time BIGINT NOT NULL,
+    end_time BIGINT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS project_budget_project ON project_budget (project);
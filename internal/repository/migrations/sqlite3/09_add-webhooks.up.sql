@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS webhook_delivery (
+    id INTEGER PRIMARY KEY,
+    url TEXT NOT NULL,
+    event VARCHAR(255) NOT NULL,
+    payload TEXT NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt BIGINT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS webhook_delivery_next_attempt ON webhook_delivery (next_attempt);
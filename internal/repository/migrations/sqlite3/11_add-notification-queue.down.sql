@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS notification_queue_username;
+DROP TABLE IF EXISTS notification_queue;
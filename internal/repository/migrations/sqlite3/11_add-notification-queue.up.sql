@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS notification_queue (
+    id INTEGER PRIMARY KEY,
+    username VARCHAR(255) NOT NULL,
+    job_id INTEGER NOT NULL,
+    summary TEXT NOT NULL,
+    created_at BIGINT NOT NULL,
+    FOREIGN KEY (job_id) REFERENCES job (id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS notification_queue_username ON notification_queue (username);
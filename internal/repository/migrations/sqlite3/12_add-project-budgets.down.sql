@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS project_budget_project;
+DROP TABLE IF EXISTS project_budget;
@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/ClusterCockpit/cc-backend/internal/webhook"
 	"github.com/ClusterCockpit/cc-backend/pkg/archive"
 	"github.com/ClusterCockpit/cc-backend/pkg/log"
 	"github.com/ClusterCockpit/cc-backend/pkg/schema"
@@ -42,6 +43,12 @@ func (r *JobRepository) AddTag(user *schema.User, job int64, tag int64) ([]*sche
 		return nil, err
 	}
 
+	for _, t := range tags {
+		if t.ID == tag {
+			webhook.Emit(webhook.TagAdded, j, t)
+		}
+	}
+
 	return tags, archive.UpdateTags(j, archiveTags)
 }
 
@@ -270,6 +277,33 @@ func (r *JobRepository) getArchiveTags(job *int64) ([]*schema.Tag, error) {
 	return tags, nil
 }
 
+// FindJobIdsByTag returns the database ids of all jobs tagged with a tag of
+// that name, regardless of tag type and scope.
+func (r *JobRepository) FindJobIdsByTag(tagName string) ([]int64, error) {
+	q := sq.Select("jobtag.job_id").From("jobtag").
+		Join("tag ON jobtag.tag_id = tag.id").Where("tag.tag_name = ?", tagName)
+
+	rows, err := q.RunWith(r.stmtCache).Query()
+	if err != nil {
+		s, _, _ := q.ToSql()
+		log.Errorf("Error get tagged jobs with %s: %v", s, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := make([]int64, 0)
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			log.Warn("Error while scanning rows")
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, nil
+}
+
 func (r *JobRepository) ImportTag(jobId int64, tagType string, tagName string, tagScope string) (err error) {
 	// Import has no scope ctx, only import from metafile to DB (No recursive archive update required), only returns err
 
@@ -292,6 +326,48 @@ func (r *JobRepository) ImportTag(jobId int64, tagType string, tagName string, t
 	return nil
 }
 
+// AttachTag adds the tag with the specified type, name and scope to the job
+// without any scope authorization, the tag is created if it does not exist
+// yet. Used for tags set by cc-backend itself. Returns false if the job already
+// had the tag.
+func (r *JobRepository) AttachTag(job *schema.Job, tagType string, tagName string, tagScope string) (added bool, err error) {
+	tagId, exists := r.TagId(tagType, tagName, tagScope)
+	if !exists {
+		tagId, err = r.CreateTag(tagType, tagName, tagScope)
+		if err != nil {
+			return false, err
+		}
+	} else {
+		var count int
+		if err := sq.Select("COUNT(*)").From("jobtag").
+			Where("jobtag.job_id = ?", job.ID).Where("jobtag.tag_id = ?", tagId).
+			RunWith(r.stmtCache).QueryRow().Scan(&count); err != nil {
+			log.Errorf("Error while checking tags of job %d: %v", job.ID, err)
+			return false, err
+		}
+		if count > 0 {
+			return false, nil
+		}
+	}
+
+	q := sq.Insert("jobtag").Columns("job_id", "tag_id").Values(job.ID, tagId)
+
+	if _, err := q.RunWith(r.stmtCache).Exec(); err != nil {
+		s, _, _ := q.ToSql()
+		log.Errorf("Error attaching tag with %s: %v", s, err)
+		return false, err
+	}
+
+	archiveTags, err := r.getArchiveTags(&job.ID)
+	if err != nil {
+		log.Warn("Error while getting tags for job")
+		return true, err
+	}
+
+	webhook.Emit(webhook.TagAdded, job, &schema.Tag{ID: tagId, Type: tagType, Name: tagName, Scope: tagScope})
+	return true, archive.UpdateTags(job, archiveTags)
+}
+
 func (r *JobRepository) checkScopeAuth(user *schema.User, operation string, scope string) (pass bool, err error) {
 	if user != nil {
 		switch {
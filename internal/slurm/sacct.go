@@ -0,0 +1,196 @@
+// Copyright (C) NHR@FAU, University Erlangen-Nuremberg.
+// All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+package slurm
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/ClusterCockpit/cc-backend/pkg/archive"
+	"github.com/ClusterCockpit/cc-backend/pkg/schema"
+)
+
+// Output of `sacct --json` and of the slurmdb jobs endpoint of slurmrestd.
+// Only the fields used for ClusterCockpit are decoded.
+type sacctResponse struct {
+	Jobs   []sacctJob   `json:"jobs"`
+	Errors []sacctError `json:"errors"`
+}
+
+type sacctError struct {
+	Description string `json:"description"`
+	Error       string `json:"error"`
+}
+
+type sacctJob struct {
+	JobID     int64  `json:"job_id"`
+	Name      string `json:"name"`
+	User      string `json:"user"`
+	Account   string `json:"account"`
+	Cluster   string `json:"cluster"`
+	Partition string `json:"partition"`
+	Nodes     string `json:"nodes"`
+	Array     struct {
+		JobID int64 `json:"job_id"`
+	} `json:"array"`
+	State struct {
+		Current sacctStates `json:"current"`
+	} `json:"state"`
+	Time struct {
+		Start int64       `json:"start"`
+		End   int64       `json:"end"`
+		Limit sacctNumber `json:"limit"` // in minutes
+	} `json:"time"`
+	Tres struct {
+		Allocated []sacctTres `json:"allocated"`
+	} `json:"tres"`
+}
+
+type sacctTres struct {
+	Type  string `json:"type"`
+	Name  string `json:"name"`
+	Count int64  `json:"count"`
+}
+
+// Numbers are plain integers up to data_parser v0.0.38 and objects like
+// {"set": true, "infinite": false, "number": 60} since then.
+type sacctNumber struct {
+	Set      bool  `json:"set"`
+	Infinite bool  `json:"infinite"`
+	Number   int64 `json:"number"`
+}
+
+func (n *sacctNumber) UnmarshalJSON(b []byte) error {
+	b = bytes.TrimSpace(b)
+	if bytes.Equal(b, []byte("null")) {
+		return nil
+	}
+	if bytes.HasPrefix(b, []byte("{")) {
+		type number sacctNumber
+		return json.Unmarshal(b, (*number)(n))
+	}
+	if err := json.Unmarshal(b, &n.Number); err != nil {
+		return err
+	}
+	n.Set = true
+	return nil
+}
+
+// The job state is a single string up to data_parser v0.0.38 and a list of
+// the state and its flags since then.
+type sacctStates []string
+
+func (s *sacctStates) UnmarshalJSON(b []byte) error {
+	if bytes.HasPrefix(bytes.TrimSpace(b), []byte("[")) {
+		return json.Unmarshal(b, (*[]string)(s))
+	}
+	var state string
+	if err := json.Unmarshal(b, &state); err != nil {
+		return err
+	}
+	*s = sacctStates{state}
+	return nil
+}
+
+// Slurm job states which have a counterpart in ClusterCockpit. Jobs in any
+// other state (PENDING, REQUEUED, ...) are ignored.
+var jobStates = map[string]schema.JobState{
+	"RUNNING":       schema.JobStateRunning,
+	"SUSPENDED":     schema.JobStateRunning,
+	"COMPLETED":     schema.JobStateCompleted,
+	"CANCELLED":     schema.JobStateCancelled,
+	"FAILED":        schema.JobStateFailed,
+	"NODE_FAIL":     schema.JobStateFailed,
+	"BOOT_FAIL":     schema.JobStateFailed,
+	"TIMEOUT":       schema.JobStateTimeout,
+	"DEADLINE":      schema.JobStateTimeout,
+	"PREEMPTED":     schema.JobStatePreempted,
+	"OUT_OF_MEMORY": schema.JobStateOutOfMemory,
+}
+
+func (j *sacctJob) state() (schema.JobState, bool) {
+	for _, s := range j.State.Current {
+		if state, ok := jobStates[s]; ok {
+			return state, true
+		}
+	}
+	return "", false
+}
+
+// Number of allocated GPUs. Typed GPUs (gpu:a100) are only counted if the
+// untyped total is missing.
+func (j *sacctJob) gpus() int32 {
+	var total, typed int64
+	for _, t := range j.Tres.Allocated {
+		if t.Type != "gres" {
+			continue
+		}
+		if t.Name == "gpu" {
+			total += t.Count
+		} else if strings.HasPrefix(t.Name, "gpu:") {
+			typed += t.Count
+		}
+	}
+	if total == 0 {
+		total = typed
+	}
+	return int32(total)
+}
+
+func (j *sacctJob) cpus() int32 {
+	for _, t := range j.Tres.Allocated {
+		if t.Type == "cpu" {
+			return int32(t.Count)
+		}
+	}
+	return 0
+}
+
+// Maps a Slurm job to a job of cluster. Returns nil for jobs which never
+// started, these are not added to ClusterCockpit.
+func convertJob(cluster string, j *sacctJob) (*schema.JobMeta, error) {
+	state, ok := j.state()
+	if !ok || j.Time.Start == 0 {
+		return nil, nil
+	}
+	if j.Nodes == "" || j.Nodes == "None assigned" {
+		return nil, nil
+	}
+
+	nl, err := archive.ParseNodeList(j.Nodes)
+	if err != nil {
+		return nil, fmt.Errorf("SLURM > job %d: %w", j.JobID, err)
+	}
+	hosts := nl.PrintList()
+
+	job := &schema.JobMeta{BaseJob: schema.JobDefaults}
+	job.JobID = j.JobID
+	job.User = j.User
+	job.Project = j.Account
+	job.Cluster = cluster
+	job.Partition = j.Partition
+	job.ArrayJobId = j.Array.JobID
+	job.NumNodes = int32(len(hosts))
+	job.NumHWThreads = j.cpus()
+	job.NumAcc = j.gpus()
+	job.State = state
+	job.StartTime = j.Time.Start
+	if j.Time.Limit.Set && !j.Time.Limit.Infinite {
+		job.Walltime = j.Time.Limit.Number * 60
+	}
+	if state != schema.JobStateRunning && j.Time.End > j.Time.Start {
+		job.Duration = int32(j.Time.End - j.Time.Start)
+	}
+	job.MetaData = map[string]string{"jobName": j.Name}
+
+	job.Resources = make([]*schema.Resource, 0, len(hosts))
+	for _, host := range hosts {
+		job.Resources = append(job.Resources, &schema.Resource{Hostname: host})
+	}
+
+	return job, nil
+}
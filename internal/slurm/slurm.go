@@ -0,0 +1,220 @@
+// Copyright (C) NHR@FAU, University Erlangen-Nuremberg.
+// All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+// Package slurm adds the jobs of a cluster to ClusterCockpit based on the
+// Slurm accounting data, read either from `sacct --json` output files or from
+// the slurmdb endpoint of slurmrestd.
+package slurm
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/ClusterCockpit/cc-backend/internal/archiver"
+	"github.com/ClusterCockpit/cc-backend/internal/eventBus"
+	"github.com/ClusterCockpit/cc-backend/internal/importer"
+	"github.com/ClusterCockpit/cc-backend/internal/repository"
+	"github.com/ClusterCockpit/cc-backend/internal/webhook"
+	"github.com/ClusterCockpit/cc-backend/pkg/log"
+	"github.com/ClusterCockpit/cc-backend/pkg/schema"
+)
+
+// The job is rejected and not retried in the next sync
+var errInvalidJob = errors.New("invalid job")
+
+type Ingester struct {
+	cluster      string
+	slurmCluster string
+	source       source
+	repo         *repository.JobRepository
+
+	// Start of the time window of the next sync
+	from time.Time
+
+	// Called for jobs which are finished, replaced in tests
+	archive func(job *schema.Job)
+}
+
+func NewIngester(cfg *schema.SlurmConfig) (*Ingester, error) {
+	if cfg.Cluster == "" {
+		return nil, errors.New("SLURM > no cluster configured")
+	}
+
+	in := &Ingester{
+		cluster:      cfg.Cluster,
+		slurmCluster: cfg.SlurmCluster,
+		repo:         repository.GetJobRepository(),
+		archive:      archiver.TriggerArchiving,
+	}
+	if in.slurmCluster == "" {
+		in.slurmCluster = cfg.Cluster
+	}
+
+	lookback := 24 * time.Hour
+	if cfg.Lookback != "" {
+		d, err := time.ParseDuration(cfg.Lookback)
+		if err != nil {
+			return nil, fmt.Errorf("SLURM > invalid lookback: %w", err)
+		}
+		lookback = d
+	}
+	in.from = time.Now().Add(-lookback)
+
+	switch {
+	case len(cfg.Files) > 0:
+		fs, err := newFileSource(cfg.Files)
+		if err != nil {
+			return nil, err
+		}
+		in.source = fs
+	case cfg.Url != "":
+		rs := &restSource{
+			url:     cfg.Url,
+			version: cfg.ApiVersion,
+			cluster: in.slurmCluster,
+			user:    cfg.User,
+			token:   os.Getenv("SLURM_JWT"),
+		}
+		if rs.version == "" {
+			rs.version = "v0.0.40"
+		}
+		in.source = rs
+	default:
+		return nil, fmt.Errorf("SLURM > neither files nor url configured for cluster %s", cfg.Cluster)
+	}
+
+	return in, nil
+}
+
+// Sync adds the jobs which are not known yet and stops the jobs which have
+// finished since the last sync. Finished jobs are archived. Errors of single
+// jobs are logged and do not abort the sync.
+func (in *Ingester) Sync(ctx context.Context) error {
+	to := time.Now()
+	jobs, err := in.source.fetch(ctx, in.from, to)
+	if err != nil {
+		return err
+	}
+
+	// The next sync starts at the earliest job which failed to be reconciled
+	next := to.Unix()
+	var added, stopped int
+	for i := range jobs {
+		sj := &jobs[i]
+		if sj.Cluster != "" && sj.Cluster != in.slurmCluster {
+			continue
+		}
+
+		job, err := convertJob(in.cluster, sj)
+		if err != nil {
+			log.Warn(err.Error())
+			continue
+		} else if job == nil {
+			continue
+		}
+
+		a, s, err := in.reconcile(job)
+		if err != nil {
+			log.Warnf("SLURM > job %d on %s: %s", job.JobID, job.Cluster, err.Error())
+			// Fetch the job again in the next sync, unless it is invalid
+			if !errors.Is(err, errInvalidJob) {
+				next = min(next, max(in.from.Unix(), job.StartTime))
+			}
+			continue
+		}
+		added, stopped = added+a, stopped+s
+	}
+
+	log.Infof("SLURM > synced %d jobs of cluster %s: %d added, %d stopped", len(jobs), in.cluster, added, stopped)
+	in.from = time.Unix(next, 0)
+	return nil
+}
+
+// Jobs started via the REST API may have a slightly different start time,
+// the job with the closest start time within a day is used.
+func (in *Ingester) findJob(job *schema.JobMeta) (*schema.Job, error) {
+	jobs, err := in.repo.FindAll(&job.JobID, &job.Cluster, nil)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	}
+
+	var match *schema.Job
+	for _, j := range jobs {
+		diff := abs(job.StartTime - j.StartTimeUnix)
+		if diff < 86400 && (match == nil || diff < abs(job.StartTime-match.StartTimeUnix)) {
+			match = j
+		}
+	}
+	return match, nil
+}
+
+func abs(x int64) int64 {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+// Adds the job if it is unknown and stops it if it is running but has
+// finished in Slurm. Returns whether the job was added or stopped.
+func (in *Ingester) reconcile(job *schema.JobMeta) (added, stopped int, err error) {
+	// The REST API may start the same job concurrently
+	var unlockOnce sync.Once
+	in.repo.Mutex.Lock()
+	defer unlockOnce.Do(in.repo.Mutex.Unlock)
+
+	dbJob, err := in.findJob(job)
+	if err != nil {
+		return 0, 0, fmt.Errorf("checking for existing job failed: %w", err)
+	}
+
+	if dbJob == nil {
+		if err := importer.SanityChecks(&job.BaseJob); err != nil {
+			return 0, 0, fmt.Errorf("%w: %v", errInvalidJob, err)
+		}
+		id, err := in.repo.Start(job)
+		if err != nil {
+			return 0, 0, fmt.Errorf("insert into database failed: %w", err)
+		}
+		unlockOnce.Do(in.repo.Mutex.Unlock)
+		log.Printf("new job (id: %d) from slurm: cluster=%s, jobId=%d, user=%s, startTime=%d, state=%s", id, job.Cluster, job.JobID, job.User, job.StartTime, job.State)
+		webhook.EmitJobMeta(webhook.JobStarted, id, job)
+		eventBus.PublishJobStarted(id, job)
+
+		if job.State != schema.JobStateRunning {
+			dbJob, err := in.repo.FindByIdDirect(id)
+			if err != nil {
+				return 1, 0, fmt.Errorf("loading new job failed: %w", err)
+			}
+			webhook.Emit(webhook.JobStopped, dbJob, nil)
+			eventBus.JobStateChanged.Publish(dbJob)
+			in.archive(dbJob)
+		}
+		return 1, 0, nil
+	}
+
+	if dbJob.State != schema.JobStateRunning || job.State == schema.JobStateRunning {
+		return 0, 0, nil
+	}
+
+	dbJob.State = job.State
+	dbJob.Duration = job.Duration
+	if err := in.repo.Stop(dbJob.ID, dbJob.Duration, dbJob.State, dbJob.MonitoringStatus); err != nil {
+		return 0, 0, fmt.Errorf("marking job as '%s' (duration: %d) in DB failed: %w", dbJob.State, dbJob.Duration, err)
+	}
+	log.Printf("archiving job... (dbid: %d): cluster=%s, jobId=%d, user=%s, startTime=%s, duration=%d, state=%s", dbJob.ID, dbJob.Cluster, dbJob.JobID, dbJob.User, dbJob.StartTime, dbJob.Duration, dbJob.State)
+	webhook.Emit(webhook.JobStopped, dbJob, nil)
+	eventBus.JobStateChanged.Publish(dbJob)
+
+	if dbJob.MonitoringStatus != schema.MonitoringStatusDisabled {
+		in.archive(dbJob)
+	}
+	return 0, 1, nil
+}
@@ -0,0 +1,331 @@
+// Copyright (C) NHR@FAU, University Erlangen-Nuremberg.
+// All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+package slurm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ClusterCockpit/cc-backend/internal/config"
+	"github.com/ClusterCockpit/cc-backend/internal/repository"
+	"github.com/ClusterCockpit/cc-backend/internal/util"
+	"github.com/ClusterCockpit/cc-backend/pkg/archive"
+	"github.com/ClusterCockpit/cc-backend/pkg/log"
+	"github.com/ClusterCockpit/cc-backend/pkg/schema"
+)
+
+func setup(t *testing.T) *repository.JobRepository {
+	const testconfig = `{
+	"addr":            "0.0.0.0:8080",
+	"validate": false,
+	"archive": {
+		"kind": "file",
+		"path": "./var/job-archive"
+	},
+    "jwts": {
+        "max-age": "2m"
+    },
+	"clusters": [
+	{
+	   "name": "fritz",
+	   "metricDataRepository": {"kind": "test", "url": "bla:8081"},
+	   "filterRanges": {
+		"numNodes": { "from": 1, "to": 944 },
+		"duration": { "from": 0, "to": 86400 },
+		"startTime": { "from": "2022-01-01T00:00:00Z", "to": null }
+	   }
+	}
+	]}`
+
+	log.Init("info", true)
+	tmpdir := t.TempDir()
+
+	jobarchive := filepath.Join(tmpdir, "job-archive")
+	if err := os.MkdirAll(filepath.Join(jobarchive, "fritz"), 0777); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(jobarchive, "version.txt"), []byte(fmt.Sprintf("%d", 2)), 0666); err != nil {
+		t.Fatal(err)
+	}
+	if err := util.CopyFile(filepath.Join("..", "importer", "testdata", "cluster-fritz.json"),
+		filepath.Join(jobarchive, "fritz", "cluster.json")); err != nil {
+		t.Fatal(err)
+	}
+
+	dbfilepath := filepath.Join(tmpdir, "test.db")
+	if err := repository.MigrateDB("sqlite3", dbfilepath); err != nil {
+		t.Fatal(err)
+	}
+
+	cfgFilePath := filepath.Join(tmpdir, "config.json")
+	if err := os.WriteFile(cfgFilePath, []byte(testconfig), 0666); err != nil {
+		t.Fatal(err)
+	}
+
+	config.Init(cfgFilePath)
+	archiveCfg := fmt.Sprintf("{\"kind\": \"file\",\"path\": \"%s\"}", jobarchive)
+
+	if err := archive.Init(json.RawMessage(archiveCfg), config.Keys.DisableArchive); err != nil {
+		t.Fatal(err)
+	}
+
+	repository.Connect("sqlite3", dbfilepath)
+	return repository.GetJobRepository()
+}
+
+func readJobs(t *testing.T, file string) []sacctJob {
+	fs, err := newFileSource([]string{filepath.Join("testdata", file)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	jobs, err := fs.fetch(context.Background(), time.Time{}, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return jobs
+}
+
+func TestConvertJob(t *testing.T) {
+	jobs := readJobs(t, "sacct-running.json")
+	if len(jobs) != 4 {
+		t.Fatalf("expected 4 jobs, got %d", len(jobs))
+	}
+
+	job, err := convertJob("fritz", &jobs[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if job.JobID != 1001 || job.User != "abcd100h" || job.Project != "abcd100" ||
+		job.Partition != "multinode" || job.State != schema.JobStateRunning ||
+		job.StartTime != 1700000000 || job.Duration != 0 || job.Walltime != 3600 ||
+		job.NumNodes != 2 || job.NumHWThreads != 144 || job.NumAcc != 0 || job.ArrayJobId != 0 {
+		t.Errorf("unexpected job: %#v", job.BaseJob)
+	}
+	if len(job.Resources) != 2 || job.Resources[0].Hostname != "f0101" || job.Resources[1].Hostname != "f0102" {
+		t.Errorf("unexpected resources: %v", job.Resources)
+	}
+	if job.MetaData["jobName"] != "lammps" {
+		t.Errorf("unexpected meta data: %v", job.MetaData)
+	}
+
+	// Pending jobs are skipped
+	if job, err := convertJob("fritz", &jobs[1]); err != nil || job != nil {
+		t.Errorf("expected pending job to be skipped, got %v (%v)", job, err)
+	}
+
+	job, err = convertJob("fritz", &jobs[2])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if job.ArrayJobId != 1003 || job.State != schema.JobStateOutOfMemory ||
+		job.Duration != 600 || job.Walltime != 0 || job.NumAcc != 2 {
+		t.Errorf("unexpected array job: %#v", job.BaseJob)
+	}
+	if len(job.Resources) != 2 || job.Resources[0].Hostname != "f0301" || job.Resources[1].Hostname != "f0305" {
+		t.Errorf("unexpected resources: %v", job.Resources)
+	}
+
+	// Output of data_parser v0.0.38
+	jobs = readJobs(t, "sacct-finished.json")
+	job, err = convertJob("fritz", &jobs[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if job.State != schema.JobStateFailed || job.Walltime != 7200 || job.Duration != 1000 ||
+		len(job.Resources) != 1 || job.Resources[0].Hostname != "f0210" {
+		t.Errorf("unexpected job: %#v", job.BaseJob)
+	}
+}
+
+func TestSync(t *testing.T) {
+	r := setup(t)
+
+	file := filepath.Join(t.TempDir(), "sacct.json")
+	if err := util.CopyFile(filepath.Join("testdata", "sacct-running.json"), file); err != nil {
+		t.Fatal(err)
+	}
+
+	in, err := NewIngester(&schema.SlurmConfig{Cluster: "fritz", Files: []string{file}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	archived := make([]*schema.Job, 0)
+	in.archive = func(job *schema.Job) {
+		archived = append(archived, job)
+	}
+
+	if err := in.Sync(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	cluster := "fritz"
+	find := func(jobId int64, startTime int64) *schema.Job {
+		job, err := r.Find(&jobId, &cluster, &startTime)
+		if err != nil {
+			t.Fatalf("job %d: %s", jobId, err.Error())
+		}
+		return job
+	}
+
+	if job := find(1001, 1700000000); job.State != schema.JobStateRunning || job.SubCluster != "main" {
+		t.Errorf("unexpected job 1001: %#v", job.BaseJob)
+	}
+	if job := find(1004, 1700000100); job.State != schema.JobStateOutOfMemory || job.ArrayJobId != 1003 {
+		t.Errorf("unexpected job 1004: %#v", job.BaseJob)
+	}
+	for _, jobId := range []int64{1002, 1005} {
+		if jobs, err := r.FindAll(&jobId, nil, nil); err != nil {
+			t.Fatal(err)
+		} else if len(jobs) != 0 {
+			t.Errorf("job %d should have been skipped", jobId)
+		}
+	}
+	if len(archived) != 1 || archived[0].JobID != 1004 {
+		t.Fatalf("expected job 1004 to be archived, got %v", archived)
+	}
+
+	if err := util.CopyFile(filepath.Join("testdata", "sacct-finished.json"), file); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(file, time.Now(), time.Now().Add(time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	if err := in.Sync(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if job := find(1001, 1700000000); job.State != schema.JobStateCompleted || job.Duration != 3000 {
+		t.Errorf("unexpected job 1001: %#v", job.BaseJob)
+	}
+	if job := find(1006, 1700001000); job.State != schema.JobStateFailed || job.Walltime != 7200 {
+		t.Errorf("unexpected job 1006: %#v", job.BaseJob)
+	}
+	if len(archived) != 3 || archived[1].JobID != 1001 || archived[2].JobID != 1006 {
+		t.Fatalf("expected jobs 1001 and 1006 to be archived, got %v", archived)
+	}
+
+	// Unmodified files are not read again
+	if err := in.Sync(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(archived) != 3 {
+		t.Errorf("expected no further archiving, got %v", archived)
+	}
+}
+
+// Source returning fixed jobs, records the requested time windows
+type testSource struct {
+	jobs []sacctJob
+	from []time.Time
+}
+
+func (ts *testSource) fetch(ctx context.Context, from, to time.Time) ([]sacctJob, error) {
+	ts.from = append(ts.from, from)
+	return ts.jobs, nil
+}
+
+func TestSyncRetry(t *testing.T) {
+	r := setup(t)
+
+	// The repository is shared with TestSync, which already added job 1001
+	jobs := readJobs(t, "sacct-running.json")
+	jobs[0].JobID = 2001
+	invalid := jobs[0]
+	invalid.JobID, invalid.User, invalid.Time.Start = 1099, "", 1699999500
+	src := &testSource{jobs: []sacctJob{jobs[0], invalid}}
+
+	in, err := NewIngester(&schema.SlurmConfig{Cluster: "fritz", Files: []string{filepath.Join("testdata", "sacct-running.json")}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	in.source = src
+	in.from = time.Unix(1699999000, 0)
+
+	// Fail the insert of job 2001
+	if _, err := r.DB.Exec(`CREATE TRIGGER fail_insert BEFORE INSERT ON job WHEN NEW.job_id = 2001
+		BEGIN SELECT RAISE(ABORT, 'insert failed'); END`); err != nil {
+		t.Fatal(err)
+	}
+	if err := in.Sync(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	// The window starts at the failed job, the invalid job is not retried
+	if in.from.Unix() != 1700000000 {
+		t.Errorf("expected next sync from the failed job, got %d", in.from.Unix())
+	}
+
+	if _, err := r.DB.Exec(`DROP TRIGGER fail_insert`); err != nil {
+		t.Fatal(err)
+	}
+	if err := in.Sync(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if src.from[1].Unix() != 1700000000 {
+		t.Errorf("unexpected window start %d", src.from[1].Unix())
+	}
+	if in.from.Unix() <= 1700000000 {
+		t.Errorf("expected window to advance, got %d", in.from.Unix())
+	}
+	jobId, cluster := int64(2001), "fritz"
+	if _, err := r.Find(&jobId, &cluster, nil); err != nil {
+		t.Errorf("job 2001 not added on retry: %s", err.Error())
+	}
+}
+
+func TestRestSource(t *testing.T) {
+	body, err := os.ReadFile(filepath.Join("testdata", "sacct-running.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	from, to := time.Unix(1700000000, 0), time.Unix(1700003600, 0)
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/slurmdb/v0.0.40/jobs" {
+			rw.WriteHeader(http.StatusNotFound)
+			rw.Write([]byte(`{"jobs": [], "errors": [{"error": "Unknown path", "description": "not found"}]}`))
+			return
+		}
+		q := r.URL.Query()
+		if q.Get("start_time") != "1700000000" || q.Get("end_time") != "1700003600" || q.Get("cluster") != "fritz" {
+			t.Errorf("unexpected query: %s", r.URL.RawQuery)
+		}
+		if r.Header.Get("X-SLURM-USER-NAME") != "ccbackend" || r.Header.Get("X-SLURM-USER-TOKEN") != "secret" {
+			rw.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		rw.Write(body)
+	}))
+	defer srv.Close()
+
+	t.Setenv("SLURM_JWT", "secret")
+	in, err := NewIngester(&schema.SlurmConfig{Cluster: "fritz", Url: srv.URL, User: "ccbackend"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	jobs, err := in.source.fetch(context.Background(), from, to)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(jobs) != 4 || jobs[0].JobID != 1001 {
+		t.Errorf("unexpected jobs: %v", jobs)
+	}
+
+	in, err = NewIngester(&schema.SlurmConfig{Cluster: "fritz", Url: srv.URL, ApiVersion: "v0.0.99"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := in.source.fetch(context.Background(), from, to); err == nil {
+		t.Error("expected error for unknown api version")
+	}
+}
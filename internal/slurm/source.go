@@ -0,0 +1,139 @@
+// Copyright (C) NHR@FAU, University Erlangen-Nuremberg.
+// All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+package slurm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type source interface {
+	// Returns the accounting data of the jobs running between from and to.
+	fetch(ctx context.Context, from, to time.Time) ([]sacctJob, error)
+}
+
+// Reads files written by `sacct --json`. A file is only read again once it
+// was modified, the time window is ignored.
+type fileSource struct {
+	patterns []string
+	modified map[string]time.Time
+}
+
+func newFileSource(patterns []string) (*fileSource, error) {
+	for _, pattern := range patterns {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("SLURM > invalid file pattern %#v: %w", pattern, err)
+		}
+	}
+	return &fileSource{patterns: patterns, modified: map[string]time.Time{}}, nil
+}
+
+func (fs *fileSource) fetch(ctx context.Context, from, to time.Time) ([]sacctJob, error) {
+	jobs := make([]sacctJob, 0)
+	for _, pattern := range fs.patterns {
+		files, _ := filepath.Glob(pattern)
+		for _, file := range files {
+			info, err := os.Stat(file)
+			if err != nil {
+				return nil, fmt.Errorf("SLURM > %w", err)
+			}
+			if info.IsDir() || info.ModTime().Equal(fs.modified[file]) {
+				continue
+			}
+
+			f, err := os.Open(file)
+			if err != nil {
+				return nil, fmt.Errorf("SLURM > %w", err)
+			}
+			res, err := decodeResponse(f)
+			f.Close()
+			if err != nil {
+				return nil, fmt.Errorf("SLURM > %s: %w", file, err)
+			}
+
+			jobs = append(jobs, res.Jobs...)
+			fs.modified[file] = info.ModTime()
+		}
+	}
+	return jobs, nil
+}
+
+// Queries the slurmdb jobs endpoint of slurmrestd.
+type restSource struct {
+	url, version string
+	cluster      string
+	user, token  string
+	client       http.Client
+}
+
+func (rs *restSource) fetch(ctx context.Context, from, to time.Time) ([]sacctJob, error) {
+	query := url.Values{}
+	query.Set("start_time", strconv.FormatInt(from.Unix(), 10))
+	query.Set("end_time", strconv.FormatInt(to.Unix(), 10))
+	query.Set("cluster", rs.cluster)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet,
+		fmt.Sprintf("%s/slurmdb/%s/jobs?%s", strings.TrimSuffix(rs.url, "/"), rs.version, query.Encode()), nil)
+	if err != nil {
+		return nil, fmt.Errorf("SLURM > %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if rs.user != "" {
+		req.Header.Set("X-SLURM-USER-NAME", rs.user)
+	}
+	if rs.token != "" {
+		req.Header.Set("X-SLURM-USER-TOKEN", rs.token)
+	}
+
+	res, err := rs.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("SLURM > %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		// slurmrestd describes the problem in the errors of the response
+		if _, err := decodeResponse(res.Body); err != nil {
+			return nil, fmt.Errorf("SLURM > %s: %s: %w", rs.url, res.Status, err)
+		}
+		return nil, fmt.Errorf("SLURM > %s: %s", rs.url, res.Status)
+	}
+
+	jobs, err := decodeResponse(res.Body)
+	if err != nil {
+		return nil, fmt.Errorf("SLURM > %s: %w", rs.url, err)
+	}
+	return jobs.Jobs, nil
+}
+
+func decodeResponse(r io.Reader) (*sacctResponse, error) {
+	var res sacctResponse
+	if err := json.NewDecoder(r).Decode(&res); err != nil {
+		return nil, err
+	}
+
+	if len(res.Errors) > 0 {
+		msgs := make([]string, 0, len(res.Errors))
+		for _, e := range res.Errors {
+			if e.Description != "" {
+				msgs = append(msgs, fmt.Sprintf("%s: %s", e.Error, e.Description))
+			} else {
+				msgs = append(msgs, e.Error)
+			}
+		}
+		return nil, errors.New(strings.Join(msgs, ", "))
+	}
+	return &res, nil
+}
@@ -0,0 +1,262 @@
+// Copyright (C) NHR@FAU, University Erlangen-Nuremberg.
+// All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+// Package tagger attaches tags to jobs based on the rules configured in
+// `job-rules`. Rules are applied by the archiver once a job is archived and by
+// the footprint worker to running jobs. Tags are only ever added, a running
+// job keeps its tag if it stops matching the rule later on.
+package tagger
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/ClusterCockpit/cc-backend/internal/repository"
+	"github.com/ClusterCockpit/cc-backend/pkg/archive"
+	"github.com/ClusterCockpit/cc-backend/pkg/log"
+	"github.com/ClusterCockpit/cc-backend/pkg/schema"
+)
+
+var rules []*schema.JobRuleConfig
+
+// Init checks the rules and fills in defaults. No rules are applied if Init
+// was not called or returned an error.
+func Init(cfgs []*schema.JobRuleConfig) error {
+	for _, rule := range cfgs {
+		if rule.Tag == nil || rule.Tag.Name == "" {
+			return fmt.Errorf("TAGGER > rule '%s': no tag name", rule.Name)
+		}
+		if rule.Tag.Type == "" {
+			rule.Tag.Type = "jobClass"
+		}
+		if rule.Tag.Scope == "" {
+			rule.Tag.Scope = "global"
+		}
+		if len(rule.Conditions) == 0 {
+			return fmt.Errorf("TAGGER > rule '%s': no conditions", rule.Name)
+		}
+
+		for _, c := range rule.Conditions {
+			if err := CheckCondition(c); err != nil {
+				return fmt.Errorf("TAGGER > rule '%s': %w", rule.Name, err)
+			}
+		}
+	}
+
+	rules = cfgs
+	if len(rules) > 0 {
+		log.Infof("Tagger: %d job rules configured", len(rules))
+	}
+	return nil
+}
+
+// CheckCondition returns an error if the condition is invalid and fills in
+// defaults.
+func CheckCondition(c *schema.JobRuleCondition) error {
+	switch {
+	case c.Property != "" && c.Metric != "":
+		return fmt.Errorf("condition with property and metric")
+	case c.Property != "":
+		if _, ok := property(&schema.Job{}, c.Property); !ok {
+			return fmt.Errorf("unknown property '%s'", c.Property)
+		}
+		if c.Threshold != "" {
+			return fmt.Errorf("threshold for property '%s'", c.Property)
+		}
+	case c.Metric != "":
+		if c.Stat == "" {
+			c.Stat = "avg"
+		}
+		if _, ok := statistic(schema.JobStatistics{}, c.Stat); !ok {
+			return fmt.Errorf("unknown statistic '%s'", c.Stat)
+		}
+		if _, ok := threshold(&schema.MetricConfig{}, c.Threshold); !ok && c.Threshold != "" {
+			return fmt.Errorf("unknown threshold '%s'", c.Threshold)
+		}
+	default:
+		return fmt.Errorf("condition without property or metric")
+	}
+
+	if _, ok := compare(0, c.Op, 0); !ok {
+		return fmt.Errorf("unknown operator '%s'", c.Op)
+	}
+	return nil
+}
+
+// Enabled returns true if any rules are configured.
+func Enabled() bool {
+	return len(rules) > 0
+}
+
+// TagJob attaches the tags of all rules matching the job. stats are the
+// statistics of the job per metric, metrics missing there are looked up in
+// the footprint of the job. Errors are only logged.
+func TagJob(job *schema.Job, stats map[string]schema.JobStatistics) {
+	if len(rules) == 0 {
+		return
+	}
+
+	var metricConfig []schema.MetricConfig
+	if sc, err := archive.GetSubCluster(job.Cluster, job.SubCluster); err == nil {
+		metricConfig = sc.MetricConfig
+	}
+
+	jobRepo := repository.GetJobRepository()
+	for _, rule := range rules {
+		if !matches(rule, job, stats, metricConfig) {
+			continue
+		}
+
+		added, err := jobRepo.AttachTag(job, rule.Tag.Type, rule.Tag.Name, rule.Tag.Scope)
+		if err != nil {
+			log.Errorf("Tagger: attaching tag of rule '%s' to job (dbid: %d) failed: %s", rule.Name, job.ID, err.Error())
+		} else if added {
+			log.Debugf("Tagger: rule '%s' matches job (dbid: %d)", rule.Name, job.ID)
+		}
+	}
+}
+
+// TagRunningJob applies the rules to a running job. stats are the current
+// statistics of the job per metric and host, as loaded by the footprint worker.
+func TagRunningJob(job *schema.Job, stats map[string]map[string]schema.MetricStatistics) {
+	if len(rules) == 0 {
+		return
+	}
+
+	jobStats := make(map[string]schema.JobStatistics, len(stats))
+	for metric, hosts := range stats {
+		jobStats[metric] = Aggregate(hosts)
+	}
+	TagJob(job, jobStats)
+}
+
+// Aggregate returns the statistics of a job from the statistics of its hosts.
+func Aggregate(hosts map[string]schema.MetricStatistics) schema.JobStatistics {
+	res := schema.JobStatistics{Min: math.Inf(1), Max: math.Inf(-1)}
+	for _, s := range hosts {
+		res.Avg += s.Avg
+		res.Min = math.Min(res.Min, s.Min)
+		res.Max = math.Max(res.Max, s.Max)
+	}
+	if len(hosts) > 0 {
+		res.Avg /= float64(len(hosts))
+	} else {
+		res.Min, res.Max = 0, 0
+	}
+	return res
+}
+
+func matches(rule *schema.JobRuleConfig, job *schema.Job, stats map[string]schema.JobStatistics, metricConfig []schema.MetricConfig) bool {
+	if len(rule.Clusters) > 0 && !slices.Contains(rule.Clusters, job.Cluster) {
+		return false
+	}
+
+	for _, c := range rule.Conditions {
+		if !ConditionMatches(c, job, stats, metricConfig) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// ConditionMatches evaluates the condition for the job. stats are the
+// statistics of the job per metric, metrics missing there are looked up in the
+// footprint of the job. metricConfig is the metric configuration of the
+// subcluster of the job.
+func ConditionMatches(c *schema.JobRuleCondition, job *schema.Job, stats map[string]schema.JobStatistics, metricConfig []schema.MetricConfig) bool {
+	var value float64
+	if c.Property != "" {
+		value, _ = property(job, c.Property)
+	} else {
+		var ok bool
+		if s, found := stats[c.Metric]; found {
+			value, _ = statistic(s, c.Stat)
+		} else if value, ok = job.Footprint[c.Metric+"_"+c.Stat]; !ok {
+			return false
+		}
+	}
+
+	limit := c.Value
+	if c.Threshold != "" {
+		i, err := archive.MetricIndex(metricConfig, c.Metric)
+		if err != nil {
+			return false
+		}
+		t, _ := threshold(&metricConfig[i], c.Threshold)
+		if t == 0 {
+			return false
+		}
+		limit *= t
+	}
+
+	ok, _ := compare(value, c.Op, limit)
+	return ok
+}
+
+func property(job *schema.Job, name string) (float64, bool) {
+	switch name {
+	case "duration":
+		if job.State == schema.JobStateRunning {
+			return time.Since(job.StartTime).Seconds(), true
+		}
+		return float64(job.Duration), true
+	case "numNodes":
+		return float64(job.NumNodes), true
+	case "numHwthreads":
+		return float64(job.NumHWThreads), true
+	case "numAcc":
+		return float64(job.NumAcc), true
+	case "energy":
+		return job.Energy, true
+	default:
+		return 0, false
+	}
+}
+
+func statistic(s schema.JobStatistics, stat string) (float64, bool) {
+	switch stat {
+	case "avg":
+		return s.Avg, true
+	case "min":
+		return s.Min, true
+	case "max":
+		return s.Max, true
+	default:
+		return 0, false
+	}
+}
+
+func threshold(mc *schema.MetricConfig, name string) (float64, bool) {
+	switch name {
+	case "peak":
+		return mc.Peak, true
+	case "normal":
+		return mc.Normal, true
+	case "caution":
+		return mc.Caution, true
+	case "alert":
+		return mc.Alert, true
+	default:
+		return 0, false
+	}
+}
+
+func compare(a float64, op string, b float64) (bool, bool) {
+	switch op {
+	case "<":
+		return a < b, true
+	case "<=":
+		return a <= b, true
+	case ">":
+		return a > b, true
+	case ">=":
+		return a >= b, true
+	default:
+		return false, false
+	}
+}
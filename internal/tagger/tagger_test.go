@@ -0,0 +1,110 @@
+// Copyright (C) NHR@FAU, University Erlangen-Nuremberg.
+// All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+package tagger
+
+import (
+	"testing"
+
+	"github.com/ClusterCockpit/cc-backend/pkg/schema"
+)
+
+func TestInit(t *testing.T) {
+	valid := &schema.JobRuleConfig{
+		Name: "idle",
+		Tag:  &schema.JobRuleTag{Name: "idle"},
+		Conditions: []*schema.JobRuleCondition{
+			{Metric: "flops_any", Op: "<", Value: 0.05, Threshold: "peak"},
+			{Property: "duration", Op: ">=", Value: 3600},
+		},
+	}
+	if err := Init([]*schema.JobRuleConfig{valid}); err != nil {
+		t.Fatal(err)
+	}
+	if valid.Tag.Type != "jobClass" || valid.Tag.Scope != "global" || valid.Conditions[0].Stat != "avg" {
+		t.Errorf("defaults not set: %#v %#v", valid.Tag, valid.Conditions[0])
+	}
+	rules = nil
+
+	invalid := []*schema.JobRuleCondition{
+		{Op: "<"},
+		{Property: "duration", Metric: "flops_any", Op: "<"},
+		{Property: "walltime", Op: "<"},
+		{Property: "duration", Op: "<", Threshold: "peak"},
+		{Metric: "flops_any", Stat: "median", Op: "<"},
+		{Metric: "flops_any", Op: "<", Threshold: "max"},
+		{Metric: "flops_any", Op: "=="},
+	}
+	for i, c := range invalid {
+		cfg := &schema.JobRuleConfig{Name: "invalid", Tag: &schema.JobRuleTag{Name: "invalid"}, Conditions: []*schema.JobRuleCondition{c}}
+		if err := Init([]*schema.JobRuleConfig{cfg}); err == nil {
+			t.Errorf("%d: expected error for %#v", i, c)
+		}
+	}
+	if err := Init([]*schema.JobRuleConfig{{Name: "no tag", Conditions: valid.Conditions}}); err == nil {
+		t.Error("expected error for rule without tag")
+	}
+	if Enabled() {
+		t.Error("rules set although Init failed")
+	}
+}
+
+func TestMatches(t *testing.T) {
+	rule := &schema.JobRuleConfig{
+		Name:     "idle",
+		Tag:      &schema.JobRuleTag{Name: "idle"},
+		Clusters: []string{"fritz"},
+		Conditions: []*schema.JobRuleCondition{
+			{Metric: "flops_any", Op: "<", Value: 0.05, Threshold: "peak"},
+			{Metric: "mem_bw", Op: "<", Value: 0.1, Threshold: "peak"},
+			{Property: "numNodes", Op: ">=", Value: 2},
+		},
+	}
+	if err := Init([]*schema.JobRuleConfig{rule}); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { rules = nil }()
+
+	metricConfig := []schema.MetricConfig{
+		{Name: "flops_any", Peak: 1000},
+		{Name: "mem_bw", Peak: 300},
+	}
+	job := func(cluster string, numNodes int32, footprint map[string]float64) *schema.Job {
+		return &schema.Job{BaseJob: schema.BaseJob{Cluster: cluster, NumNodes: numNodes, Footprint: footprint}}
+	}
+
+	tests := []struct {
+		job   *schema.Job
+		stats map[string]schema.JobStatistics
+		want  bool
+	}{
+		{job("fritz", 2, nil), map[string]schema.JobStatistics{"flops_any": {Avg: 10}, "mem_bw": {Avg: 20}}, true},
+		{job("fritz", 2, nil), map[string]schema.JobStatistics{"flops_any": {Avg: 60}, "mem_bw": {Avg: 20}}, false},
+		{job("fritz", 1, nil), map[string]schema.JobStatistics{"flops_any": {Avg: 10}, "mem_bw": {Avg: 20}}, false},
+		{job("alex", 2, nil), map[string]schema.JobStatistics{"flops_any": {Avg: 10}, "mem_bw": {Avg: 20}}, false},
+		// Metrics missing in the statistics are taken from the footprint
+		{job("fritz", 2, map[string]float64{"mem_bw_avg": 20}), map[string]schema.JobStatistics{"flops_any": {Avg: 10}}, true},
+		{job("fritz", 2, nil), map[string]schema.JobStatistics{"flops_any": {Avg: 10}}, false},
+	}
+	for i, tt := range tests {
+		if got := matches(rule, tt.job, tt.stats, metricConfig); got != tt.want {
+			t.Errorf("%d: got %v, want %v", i, got, tt.want)
+		}
+	}
+
+	// Without a configured threshold, conditions relative to it never match
+	if matches(rule, job("fritz", 2, nil), tests[0].stats, metricConfig[:1]) {
+		t.Error("expected no match without threshold")
+	}
+}
+
+func TestAggregate(t *testing.T) {
+	got := Aggregate(map[string]schema.MetricStatistics{
+		"host1": {Avg: 2, Min: 1, Max: 4},
+		"host2": {Avg: 4, Min: 3, Max: 5},
+	})
+	if got.Avg != 3 || got.Min != 1 || got.Max != 5 {
+		t.Errorf("got %#v", got)
+	}
+}
@@ -0,0 +1,26 @@
+// Copyright (C) NHR@FAU, University Erlangen-Nuremberg.
+// All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+package taskManager
+
+import (
+	"time"
+
+	"github.com/ClusterCockpit/cc-backend/internal/notification"
+	"github.com/ClusterCockpit/cc-backend/pkg/log"
+	"github.com/go-co-op/gocron/v2"
+)
+
+func RegisterNotificationDigestService() {
+	log.Info("Register notification digest service")
+
+	s.NewJob(gocron.DailyJob(1, gocron.NewAtTimes(gocron.NewAtTime(07, 0, 0))),
+		gocron.NewTask(
+			func() {
+				start := time.Now()
+				log.Printf("Sending job summary digests started at %s", start.Format(time.RFC3339))
+				notification.SendDigests(start)
+				log.Printf("Sending job summary digests is done and took %s", time.Since(start))
+			}))
+}
@@ -0,0 +1,202 @@
+// Copyright (C) NHR@FAU, University Erlangen-Nuremberg.
+// All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+package taskManager
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ClusterCockpit/cc-backend/pkg/archive"
+	"github.com/ClusterCockpit/cc-backend/pkg/log"
+	"github.com/ClusterCockpit/cc-backend/pkg/schema"
+	"github.com/go-co-op/gocron/v2"
+)
+
+func describeRule(rule *schema.RetentionRule) string {
+	criteria := make([]string, 0, 5)
+	for _, c := range []struct{ key, value string }{
+		{"cluster", rule.Cluster},
+		{"partition", rule.Partition},
+		{"project", rule.Project},
+		{"tag", rule.Tag},
+		{"jobState", rule.State},
+	} {
+		if c.value != "" {
+			criteria = append(criteria, fmt.Sprintf("%s=%s", c.key, c.value))
+		}
+	}
+	if len(criteria) == 0 {
+		return "all jobs"
+	}
+
+	return strings.Join(criteria, ",")
+}
+
+func ruleMatches(rule *schema.RetentionRule, job *schema.Job, tagged map[string]map[int64]bool) bool {
+	return (rule.Cluster == "" || rule.Cluster == job.Cluster) &&
+		(rule.Partition == "" || rule.Partition == job.Partition) &&
+		(rule.Project == "" || rule.Project == job.Project) &&
+		(rule.State == "" || rule.State == string(job.State)) &&
+		(rule.Tag == "" || tagged[rule.Tag][job.ID])
+}
+
+// Fills in unset rule options from the retention configuration and appends
+// the global policy as rule matching all remaining jobs.
+func prepareRules(cfg schema.Retention) []schema.RetentionRule {
+	rules := make([]schema.RetentionRule, 0, len(cfg.Rules)+1)
+	for _, rule := range cfg.Rules {
+		if rule.Location == "" {
+			rule.Location = cfg.Location
+		}
+		if rule.IncludeDB == nil {
+			rule.IncludeDB = &cfg.IncludeDB
+		}
+
+		switch rule.Policy {
+		case "keep":
+		case "delete", "move", "downsample":
+			if rule.Age <= 0 {
+				log.Errorf("Retention: rule for %s needs an age greater than zero, keep matching jobs", describeRule(&rule))
+				rule.Policy = "keep"
+			}
+		default:
+			log.Errorf("Retention: unknown policy '%s' in rule for %s, keep matching jobs", rule.Policy, describeRule(&rule))
+			rule.Policy = "keep"
+		}
+		rules = append(rules, rule)
+	}
+
+	switch cfg.Policy {
+	case "delete", "move", "downsample":
+		rules = append(rules, schema.RetentionRule{
+			Policy:    cfg.Policy,
+			Location:  cfg.Location,
+			Age:       cfg.Age,
+			IncludeDB: &cfg.IncludeDB,
+		})
+	}
+
+	return rules
+}
+
+func applyRule(rule *schema.RetentionRule, jobs []*schema.Job, resolution int, dropBefore int64) int {
+	if len(jobs) == 0 {
+		return 0
+	}
+
+	switch rule.Policy {
+	case "delete":
+		archive.GetHandle().CleanUp(jobs)
+	case "move":
+		archive.GetHandle().Move(jobs, rule.Location)
+	case "downsample":
+		return downsampleJobs(jobs, resolution, dropBefore)
+	}
+
+	if !*rule.IncludeDB {
+		return len(jobs)
+	}
+
+	cnt := 0
+	for _, job := range jobs {
+		if err := jobRepo.DeleteJobById(job.ID); err != nil {
+			log.Errorf("Error while deleting retention job %d from db: %s", job.ID, err.Error())
+			continue
+		}
+		cnt++
+	}
+	if err := jobRepo.Optimize(); err != nil {
+		log.Errorf("Error occured in db optimization: %s", err.Error())
+	}
+
+	return cnt
+}
+
+func RegisterRetentionRulesService(cfg schema.Retention) {
+	log.Info("Register retention rules service")
+
+	rules := prepareRules(cfg)
+	for _, rule := range rules {
+		if rule.Policy == "downsample" && !checkDownsampleResolution(cfg.Resolution) {
+			return
+		}
+	}
+
+	// As for the retention downsample service, the first run covers all
+	// jobs and later runs only the jobs that exceeded the age of their rule
+	// or the drop scopes age since the previous run.
+	lastDownsample := make([]int64, len(rules))
+	var lastDrop int64
+
+	s.NewJob(gocron.DailyJob(1, gocron.NewAtTimes(gocron.NewAtTime(04, 0, 0))),
+		gocron.NewTask(
+			func() {
+				now := time.Now().Unix()
+				var dropBefore int64
+				if cfg.DropScopesAge > 0 {
+					dropBefore = now - int64(cfg.DropScopesAge*24*3600)
+				}
+
+				// Only jobs older than the youngest age of all rules can be affected
+				var end int64
+				tagged := make(map[string]map[int64]bool)
+				for _, rule := range rules {
+					if rule.Policy != "keep" {
+						end = max(end, now-int64(rule.Age*24*3600))
+					}
+					if rule.Tag != "" && tagged[rule.Tag] == nil {
+						ids, err := jobRepo.FindJobIdsByTag(rule.Tag)
+						if err != nil {
+							log.Warnf("Error while looking for tagged retention jobs: %s", err.Error())
+							return
+						}
+						tagged[rule.Tag] = make(map[int64]bool, len(ids))
+						for _, id := range ids {
+							tagged[rule.Tag][id] = true
+						}
+					}
+				}
+				if end == 0 {
+					return
+				}
+
+				jobs, err := jobRepo.FindJobsBetween(0, end)
+				if err != nil {
+					log.Warnf("Error while looking for retention jobs: %s", err.Error())
+					return
+				}
+
+				matched := make([]int, len(rules))
+				affected := make([][]*schema.Job, len(rules))
+				for _, job := range jobs {
+					for i := range rules {
+						if !ruleMatches(&rules[i], job, tagged) {
+							continue
+						}
+						matched[i]++
+						if rules[i].Policy == "keep" || job.StartTimeUnix >= now-int64(rules[i].Age*24*3600) {
+							break
+						}
+						if rules[i].Policy == "downsample" && job.StartTimeUnix < lastDownsample[i] &&
+							(dropBefore == 0 || job.StartTimeUnix < lastDrop || job.StartTimeUnix >= dropBefore) {
+							break
+						}
+						affected[i] = append(affected[i], job)
+						break
+					}
+				}
+
+				for i := range rules {
+					cnt := applyRule(&rules[i], affected[i], cfg.Resolution, dropBefore)
+					log.Infof("Retention: rule %d (%s): %d jobs matched, %s %d jobs",
+						i+1, describeRule(&rules[i]), matched[i], rules[i].Policy, cnt)
+					if rules[i].Policy == "downsample" {
+						lastDownsample[i] = now - int64(rules[i].Age*24*3600)
+					}
+				}
+				lastDrop = dropBefore
+			}))
+}
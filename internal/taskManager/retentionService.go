@@ -7,8 +7,10 @@ package taskManager
 import (
 	"time"
 
+	"github.com/ClusterCockpit/cc-backend/internal/config"
 	"github.com/ClusterCockpit/cc-backend/pkg/archive"
 	"github.com/ClusterCockpit/cc-backend/pkg/log"
+	"github.com/ClusterCockpit/cc-backend/pkg/schema"
 	"github.com/go-co-op/gocron/v2"
 )
 
@@ -65,3 +67,111 @@ func RegisterRetentionMoveService(age int, includeDB bool, location string) {
 				}
 			}))
 }
+
+// Rewrites the archived metric data of a job with a coarser timestep
+func downsampleJob(ar archive.ArchiveBackend, job *schema.Job, resolution int, dropSubNode bool) (bool, error) {
+	jobData, err := ar.LoadJobData(job)
+	if err != nil {
+		return false, err
+	}
+
+	jobData, changed, err := archive.DownsampleJobData(jobData, resolution, dropSubNode)
+	if err != nil || !changed {
+		return false, err
+	}
+
+	jobMeta, err := ar.LoadJobMeta(job)
+	if err != nil {
+		return false, err
+	}
+
+	return true, ar.ImportJob(jobMeta, &jobData)
+}
+
+func checkDownsampleResolution(resolution int) bool {
+	if resolution <= 0 {
+		log.Error("Retention downsample: resolution must be greater than zero")
+		return false
+	}
+	if rc := config.Keys.EnableResampling; rc != nil {
+		for _, r := range rc.Resolutions {
+			if r > resolution && r%resolution != 0 {
+				log.Warnf("Retention downsample: resampling resolution %d is not a multiple of %d, downsampled jobs cannot be shown at this resolution", r, resolution)
+			}
+		}
+	}
+
+	return true
+}
+
+func RegisterRetentionDownsampleService(age int, resolution int, dropScopesAge int) {
+	log.Info("Register retention downsample service")
+
+	if !checkDownsampleResolution(resolution) {
+		return
+	}
+
+	// The first run after startup covers all jobs, later runs only the jobs
+	// that exceeded the ages since the previous run. Already downsampled
+	// jobs are not written again.
+	var lastDownsample, lastDrop int64
+
+	s.NewJob(gocron.DailyJob(1, gocron.NewAtTimes(gocron.NewAtTime(04, 0, 0))),
+		gocron.NewTask(
+			func() {
+				now := time.Now().Unix()
+				downsampleBefore := now - int64(age*24*3600)
+				dropBefore := now - int64(dropScopesAge*24*3600)
+
+				jobs, err := jobRepo.FindJobsBetween(lastDownsample, downsampleBefore)
+				if err != nil {
+					log.Warnf("Error while looking for retention jobs: %s", err.Error())
+					return
+				}
+				lastDownsample = downsampleBefore
+
+				if dropScopesAge > 0 {
+					dropJobs, err := jobRepo.FindJobsBetween(lastDrop, dropBefore)
+					if err != nil {
+						log.Warnf("Error while looking for retention jobs: %s", err.Error())
+						return
+					}
+					lastDrop = dropBefore
+					jobs = append(jobs, dropJobs...)
+				}
+
+				if dropScopesAge == 0 {
+					dropBefore = 0
+				}
+				cnt := downsampleJobs(jobs, resolution, dropBefore)
+				log.Infof("Retention: Downsampled %d of %d jobs", cnt, len(jobs))
+			}))
+}
+
+// Downsamples all archived jobs and drops the sub-node scopes of jobs started
+// before dropBefore. Returns the number of rewritten jobs.
+func downsampleJobs(jobs []*schema.Job, resolution int, dropBefore int64) int {
+	ar := archive.GetHandle()
+	done := make(map[int64]bool, len(jobs))
+	compress := make([]*schema.Job, 0)
+	for _, job := range jobs {
+		if done[job.ID] || job.MonitoringStatus != schema.MonitoringStatusArchivingSuccessful {
+			continue
+		}
+		done[job.ID] = true
+
+		changed, err := downsampleJob(ar, job, resolution, job.StartTimeUnix < dropBefore)
+		if err != nil {
+			log.Errorf("Retention: Downsampling job %d on %s failed: %s",
+				job.JobID, job.Cluster, err.Error())
+			continue
+		}
+		if changed {
+			compress = append(compress, job)
+		}
+	}
+
+	// Rewritten jobs are stored uncompressed
+	ar.Compress(compress)
+	return len(compress)
+}
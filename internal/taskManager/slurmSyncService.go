@@ -0,0 +1,45 @@
+// Copyright (C) NHR@FAU, University Erlangen-Nuremberg.
+// All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+package taskManager
+
+import (
+	"context"
+
+	"github.com/ClusterCockpit/cc-backend/internal/slurm"
+	"github.com/ClusterCockpit/cc-backend/pkg/log"
+	"github.com/ClusterCockpit/cc-backend/pkg/schema"
+	"github.com/go-co-op/gocron/v2"
+)
+
+func RegisterSlurmSyncService(cfg *schema.SlurmConfig) {
+	frequency := "1m"
+	if cfg.SyncInterval != "" {
+		frequency = cfg.SyncInterval
+	}
+	interval, err := parseDuration(frequency)
+	if err != nil || interval == 0 {
+		log.Warnf("Could not register slurm sync service for cluster %s", cfg.Cluster)
+		return
+	}
+
+	ingester, err := slurm.NewIngester(cfg)
+	if err != nil {
+		log.Errorf("Could not register slurm sync service: %s", err.Error())
+		return
+	}
+
+	log.Infof("Register slurm sync service for cluster %s with %s interval", cfg.Cluster, frequency)
+	s.NewJob(gocron.DurationJob(interval),
+		gocron.NewTask(
+			func() {
+				ctx, cancel := context.WithTimeout(context.Background(), interval)
+				defer cancel()
+				if err := ingester.Sync(ctx); err != nil {
+					log.Errorf("slurm sync for cluster %s failed: %s", cfg.Cluster, err.Error())
+				}
+			}),
+		// A slow sync must not overlap with the next one
+		gocron.WithSingletonMode(gocron.LimitModeReschedule))
+}
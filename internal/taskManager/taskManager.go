@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/ClusterCockpit/cc-backend/internal/config"
+	"github.com/ClusterCockpit/cc-backend/internal/notification"
 	"github.com/ClusterCockpit/cc-backend/internal/repository"
 	"github.com/ClusterCockpit/cc-backend/pkg/log"
 	"github.com/ClusterCockpit/cc-backend/pkg/schema"
@@ -57,16 +58,23 @@ func Start() {
 		log.Warn("Error while unmarshaling raw config json")
 	}
 
-	switch cfg.Retention.Policy {
-	case "delete":
+	switch {
+	case len(cfg.Retention.Rules) > 0:
+		RegisterRetentionRulesService(cfg.Retention)
+	case cfg.Retention.Policy == "delete":
 		RegisterRetentionDeleteService(
 			cfg.Retention.Age,
 			cfg.Retention.IncludeDB)
-	case "move":
+	case cfg.Retention.Policy == "move":
 		RegisterRetentionMoveService(
 			cfg.Retention.Age,
 			cfg.Retention.IncludeDB,
 			cfg.Retention.Location)
+	case cfg.Retention.Policy == "downsample":
+		RegisterRetentionDownsampleService(
+			cfg.Retention.Age,
+			cfg.Retention.Resolution,
+			cfg.Retention.DropScopesAge)
 	}
 
 	if cfg.Compression > 0 {
@@ -79,9 +87,17 @@ func Start() {
 		RegisterLdapSyncService(lc.SyncInterval)
 	}
 
+	for _, sc := range config.Keys.Slurm {
+		RegisterSlurmSyncService(sc)
+	}
+
 	RegisterFootprintWorker()
 	RegisterUpdateDurationWorker()
 
+	if notification.Enabled() {
+		RegisterNotificationDigestService()
+	}
+
 	s.Start()
 }
 
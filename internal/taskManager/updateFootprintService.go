@@ -9,8 +9,12 @@ import (
 	"math"
 	"time"
 
+	"github.com/ClusterCockpit/cc-backend/internal/alerting"
 	"github.com/ClusterCockpit/cc-backend/internal/config"
+	"github.com/ClusterCockpit/cc-backend/internal/eventBus"
+	"github.com/ClusterCockpit/cc-backend/internal/metricDataDispatcher"
 	"github.com/ClusterCockpit/cc-backend/internal/metricdata"
+	"github.com/ClusterCockpit/cc-backend/internal/tagger"
 	"github.com/ClusterCockpit/cc-backend/pkg/archive"
 	"github.com/ClusterCockpit/cc-backend/pkg/log"
 	"github.com/ClusterCockpit/cc-backend/pkg/schema"
@@ -52,13 +56,13 @@ func RegisterFootprintWorker() {
 						allMetrics = append(allMetrics, mc.Name)
 					}
 
-					repo, err := metricdata.GetMetricDataRepo(cluster.Name)
-					if err != nil {
+					if _, err := metricdata.GetMetricDataRepo(cluster.Name); err != nil {
 						log.Errorf("no metric data repository configured for '%s'", cluster.Name)
 						continue
 					}
 
 					pendingStatements := []sq.UpdateBuilder{}
+					updatedJobs := []*schema.Job{}
 
 					for _, job := range jobs {
 						log.Debugf("Prepare job %d", job.JobID)
@@ -66,13 +70,16 @@ func RegisterFootprintWorker() {
 
 						s_job := time.Now()
 
-						jobStats, err := repo.LoadStats(job, allMetrics, context.Background())
+						jobStats, err := metricDataDispatcher.LoadStats(job, allMetrics, context.Background())
 						if err != nil {
 							log.Errorf("error wile loading job data stats for footprint update: %v", err)
 							ce++
 							continue
 						}
 
+						alerting.Check(job, jobStats)
+						tagger.TagRunningJob(job, jobStats)
+
 						jobMeta := &schema.JobMeta{
 							BaseJob:    job.BaseJob,
 							StartTime:  job.StartTime.Unix(),
@@ -117,6 +124,7 @@ func RegisterFootprintWorker() {
 						stmt = stmt.Where("job.id = ?", job.ID)
 
 						pendingStatements = append(pendingStatements, stmt)
+						updatedJobs = append(updatedJobs, job)
 						log.Debugf("Job %d took %s", job.JobID, time.Since(s_job))
 					}
 
@@ -137,10 +145,15 @@ func RegisterFootprintWorker() {
 								c++
 							}
 						}
-						jobRepo.TransactionEnd(t)
+						if err := jobRepo.TransactionEnd(t); err == nil {
+							for _, job := range updatedJobs {
+								eventBus.JobMetricsUpdated.Publish(job)
+							}
+						}
 					}
 					log.Debugf("Finish Cluster %s, took %s", cluster.Name, time.Since(s_cluster))
 				}
+				alerting.Prune(s)
 				log.Printf("Updating %d (of %d; Skipped %d) Footprints is done and took %s", c, cl, ce, time.Since(s))
 			}))
 }
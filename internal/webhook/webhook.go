@@ -0,0 +1,285 @@
+// Copyright (C) NHR@FAU, University Erlangen-Nuremberg.
+// All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+// Package webhook posts job lifecycle events to the URLs configured in
+// `webhooks`. Events are stored in the webhook_delivery table before they are
+// sent, failed deliveries are retried with exponential backoff, also after a
+// restart.
+//
+// The payload is signed with the secret of the webhook using HMAC-SHA256, the
+// hex encoded signature is sent in the X-CC-Signature header as
+// "sha256=<signature>".
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ClusterCockpit/cc-backend/pkg/log"
+	"github.com/ClusterCockpit/cc-backend/pkg/schema"
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jmoiron/sqlx"
+)
+
+type Event string
+
+const (
+	JobStarted         Event = "job.started"
+	JobStopped         Event = "job.stopped"
+	JobArchived        Event = "job.archived"
+	JobArchivingFailed Event = "job.archiving_failed"
+	TagAdded           Event = "tag.added"
+	JobAlert           Event = "job.alert"
+)
+
+// Body of the POST request
+type Payload struct {
+	Event Event            `json:"event"`
+	Time  int64            `json:"time"`
+	Job   *schema.Job      `json:"job"`
+	Tag   *schema.Tag      `json:"tag,omitempty"`
+	Alert *schema.JobAlert `json:"alert,omitempty"`
+}
+
+type delivery struct {
+	ID       int64  `db:"id"`
+	Url      string `db:"url"`
+	Event    string `db:"event"`
+	Payload  []byte `db:"payload"`
+	Attempts int    `db:"attempts"`
+}
+
+const (
+	// Deliveries are dropped after this many failed attempts
+	maxAttempts = 10
+	// Delay after the first failed attempt, doubled on every further attempt
+	retryDelay    = 30 * time.Second
+	maxRetryDelay = time.Hour
+	// Due deliveries are looked up at least this often
+	pollInterval = 10 * time.Second
+)
+
+var (
+	webhooks []*schema.WebhookConfig
+	db       *sqlx.DB
+	client   = http.Client{Timeout: 10 * time.Second}
+
+	wake     chan struct{}
+	stop     chan struct{}
+	stopOnce sync.Once
+	done     sync.WaitGroup
+)
+
+// Start starts the delivery of events. Events are dropped if Start was not
+// called or no webhooks are configured.
+func Start(cfgs []*schema.WebhookConfig, dbHandle *sqlx.DB) {
+	if len(cfgs) == 0 {
+		return
+	}
+
+	webhooks, db = cfgs, dbHandle
+	wake = make(chan struct{}, 1)
+	stop = make(chan struct{})
+
+	done.Add(1)
+	go deliveryWorker()
+	log.Infof("Webhooks: posting events to %d URLs", len(webhooks))
+}
+
+// Shutdown waits for the current delivery to finish. Pending deliveries are
+// sent after the next start.
+func Shutdown() {
+	if stop == nil {
+		return
+	}
+	stopOnce.Do(func() { close(stop) })
+	done.Wait()
+}
+
+// Emit queues event for all webhooks matching job. Errors are only logged,
+// webhooks never interfere with the operation triggering the event.
+func Emit(event Event, job *schema.Job, tag *schema.Tag) {
+	emit(Payload{Event: event, Job: job, Tag: tag})
+}
+
+// EmitAlert queues a job.alert event for the alert which fired for job.
+func EmitAlert(job *schema.Job, alert *schema.JobAlert) {
+	emit(Payload{Event: JobAlert, Job: job, Alert: alert})
+}
+
+func emit(p Payload) {
+	event, job := p.Event, p.Job
+	if len(webhooks) == 0 || job == nil {
+		return
+	}
+
+	var payload []byte
+	now := time.Now()
+	for _, wh := range webhooks {
+		if !matches(wh, event, job) {
+			continue
+		}
+
+		if payload == nil {
+			var err error
+			p.Time = now.Unix()
+			payload, err = json.Marshal(p)
+			if err != nil {
+				log.Errorf("Webhooks: encoding %s event of job %d failed: %s", event, job.ID, err.Error())
+				return
+			}
+		}
+
+		if _, err := sq.Insert("webhook_delivery").
+			Columns("url", "event", "payload", "attempts", "next_attempt").
+			Values(wh.Url, string(event), string(payload), 0, now.Unix()).
+			RunWith(db).Exec(); err != nil {
+			log.Errorf("Webhooks: queueing %s event of job %d for %s failed: %s", event, job.ID, wh.Url, err.Error())
+		}
+	}
+
+	if payload != nil {
+		select {
+		case wake <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// EmitJobMeta is Emit for jobs which were just added to the database with
+// the id.
+func EmitJobMeta(event Event, id int64, job *schema.JobMeta) {
+	Emit(event, &schema.Job{
+		ID:            id,
+		BaseJob:       job.BaseJob,
+		StartTime:     time.Unix(job.StartTime, 0),
+		StartTimeUnix: job.StartTime,
+	}, nil)
+}
+
+func matches(wh *schema.WebhookConfig, event Event, job *schema.Job) bool {
+	return (len(wh.Events) == 0 || slices.Contains(wh.Events, string(event))) &&
+		(len(wh.Clusters) == 0 || slices.Contains(wh.Clusters, job.Cluster)) &&
+		(len(wh.Projects) == 0 || slices.Contains(wh.Projects, job.Project)) &&
+		(len(wh.States) == 0 || slices.Contains(wh.States, job.State))
+}
+
+func deliveryWorker() {
+	defer done.Done()
+	for {
+		deliverDue()
+
+		select {
+		case <-stop:
+			return
+		case <-wake:
+		case <-time.After(pollInterval):
+		}
+	}
+}
+
+func deliverDue() {
+	query, args, err := sq.Select("id", "url", "event", "payload", "attempts").
+		From("webhook_delivery").
+		Where("next_attempt <= ?", time.Now().Unix()).
+		OrderBy("id").Limit(100).ToSql()
+	if err != nil {
+		log.Errorf("Webhooks: building query failed: %s", err.Error())
+		return
+	}
+
+	var deliveries []delivery
+	if err := db.Select(&deliveries, query, args...); err != nil {
+		log.Errorf("Webhooks: loading pending deliveries failed: %s", err.Error())
+		return
+	}
+
+	for _, d := range deliveries {
+		select {
+		case <-stop:
+			return
+		default:
+		}
+
+		idx := slices.IndexFunc(webhooks, func(wh *schema.WebhookConfig) bool { return wh.Url == d.Url })
+		if idx == -1 {
+			log.Warnf("Webhooks: dropping %s event (delivery %d), %s is not configured anymore", d.Event, d.ID, d.Url)
+			remove(d.ID)
+			continue
+		}
+
+		err := send(webhooks[idx], &d)
+		if err == nil {
+			remove(d.ID)
+			continue
+		}
+
+		d.Attempts++
+		if d.Attempts >= maxAttempts {
+			log.Errorf("Webhooks: dropping %s event (delivery %d) after %d attempts: %s", d.Event, d.ID, d.Attempts, err.Error())
+			remove(d.ID)
+			continue
+		}
+
+		delay := min(retryDelay<<(d.Attempts-1), maxRetryDelay)
+		log.Warnf("Webhooks: delivery %d of %s event failed, retrying in %s: %s", d.ID, d.Event, delay, err.Error())
+		if _, err := sq.Update("webhook_delivery").
+			Set("attempts", d.Attempts).
+			Set("next_attempt", time.Now().Add(delay).Unix()).
+			Where("id = ?", d.ID).
+			RunWith(db).Exec(); err != nil {
+			log.Errorf("Webhooks: updating delivery %d failed: %s", d.ID, err.Error())
+		}
+	}
+}
+
+func remove(id int64) {
+	if _, err := sq.Delete("webhook_delivery").Where("id = ?", id).RunWith(db).Exec(); err != nil {
+		log.Errorf("Webhooks: removing delivery %d failed: %s", id, err.Error())
+	}
+}
+
+func send(wh *schema.WebhookConfig, d *delivery) error {
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, wh.Url, bytes.NewReader(d.Payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-CC-Event", d.Event)
+	req.Header.Set("X-CC-Delivery", strconv.FormatInt(d.ID, 10))
+	if wh.Secret != "" {
+		req.Header.Set("X-CC-Signature", "sha256="+Sign(wh.Secret, d.Payload))
+	}
+
+	res, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	io.Copy(io.Discard, res.Body)
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return fmt.Errorf("%s responded with %s", wh.Url, res.Status)
+	}
+	return nil
+}
+
+// Sign returns the hex encoded HMAC-SHA256 of payload.
+func Sign(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
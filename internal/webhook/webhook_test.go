@@ -0,0 +1,153 @@
+// Copyright (C) NHR@FAU, University Erlangen-Nuremberg.
+// All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+package webhook
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ClusterCockpit/cc-backend/pkg/log"
+	"github.com/ClusterCockpit/cc-backend/pkg/schema"
+	"github.com/jmoiron/sqlx"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func setup(t *testing.T, cfgs []*schema.WebhookConfig) {
+	log.Init("warn", true)
+
+	dbHandle, err := sqlx.Open("sqlite3", filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { dbHandle.Close() })
+
+	migration, err := os.ReadFile(filepath.Join("..", "repository", "migrations", "sqlite3", "09_add-webhooks.up.sql"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := dbHandle.Exec(string(migration)); err != nil {
+		t.Fatal(err)
+	}
+
+	// The delivery worker is not started, deliverDue is called by the tests
+	webhooks, db = cfgs, dbHandle
+	wake = make(chan struct{}, 1)
+	t.Cleanup(func() { webhooks, db = nil, nil })
+}
+
+func pending(t *testing.T) []delivery {
+	var deliveries []delivery
+	if err := db.Select(&deliveries, "SELECT id, url, event, payload, attempts FROM webhook_delivery ORDER BY id"); err != nil {
+		t.Fatal(err)
+	}
+	return deliveries
+}
+
+func TestMatches(t *testing.T) {
+	job := &schema.Job{BaseJob: schema.BaseJob{Cluster: "fritz", Project: "abcd100", State: schema.JobStateFailed}}
+
+	tests := []struct {
+		wh   schema.WebhookConfig
+		want bool
+	}{
+		{schema.WebhookConfig{}, true},
+		{schema.WebhookConfig{Events: []string{"job.stopped"}, Clusters: []string{"alex", "fritz"}}, true},
+		{schema.WebhookConfig{Events: []string{"job.started"}}, false},
+		{schema.WebhookConfig{Clusters: []string{"alex"}}, false},
+		{schema.WebhookConfig{Projects: []string{"abcd100"}, States: []schema.JobState{schema.JobStateFailed}}, true},
+		{schema.WebhookConfig{States: []schema.JobState{schema.JobStateCompleted}}, false},
+	}
+	for i, tt := range tests {
+		if got := matches(&tt.wh, JobStopped, job); got != tt.want {
+			t.Errorf("%d: got %v, want %v", i, got, tt.want)
+		}
+	}
+}
+
+func TestDelivery(t *testing.T) {
+	type request struct {
+		header  http.Header
+		payload []byte
+	}
+	requests := make([]request, 0)
+	status := http.StatusInternalServerError
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		requests = append(requests, request{header: r.Header, payload: b})
+		rw.WriteHeader(status)
+	}))
+	defer srv.Close()
+
+	setup(t, []*schema.WebhookConfig{{Url: srv.URL, Clusters: []string{"fritz"}, Secret: "secret"}})
+
+	job := &schema.Job{ID: 42, BaseJob: schema.BaseJob{JobID: 1001, Cluster: "fritz", State: schema.JobStateRunning}}
+	Emit(JobStarted, job, nil)
+	Emit(JobStarted, &schema.Job{ID: 43, BaseJob: schema.BaseJob{Cluster: "alex"}}, nil)
+
+	if d := pending(t); len(d) != 1 || d[0].Event != string(JobStarted) {
+		t.Fatalf("expected one pending delivery, got %v", d)
+	}
+
+	// Failed deliveries are rescheduled
+	deliverDue()
+	if len(requests) != 1 {
+		t.Fatalf("expected one request, got %d", len(requests))
+	}
+	d := pending(t)
+	if len(d) != 1 || d[0].Attempts != 1 {
+		t.Fatalf("expected delivery to be retried, got %v", d)
+	}
+	deliverDue()
+	if len(requests) != 1 {
+		t.Fatalf("expected retry to be delayed, got %d requests", len(requests))
+	}
+
+	if _, err := db.Exec("UPDATE webhook_delivery SET next_attempt = ?", time.Now().Unix()); err != nil {
+		t.Fatal(err)
+	}
+	status = http.StatusNoContent
+	deliverDue()
+	if len(requests) != 2 {
+		t.Fatalf("expected two requests, got %d", len(requests))
+	}
+	if d := pending(t); len(d) != 0 {
+		t.Fatalf("expected no pending deliveries, got %v", d)
+	}
+
+	req := requests[1]
+	if req.header.Get("X-CC-Event") != "job.started" || req.header.Get("Content-Type") != "application/json" {
+		t.Errorf("unexpected headers: %v", req.header)
+	}
+	if req.header.Get("X-CC-Signature") != "sha256="+Sign("secret", req.payload) {
+		t.Errorf("wrong signature: %s", req.header.Get("X-CC-Signature"))
+	}
+
+	var payload Payload
+	if err := json.Unmarshal(req.payload, &payload); err != nil {
+		t.Fatal(err)
+	}
+	if payload.Event != JobStarted || payload.Job == nil || payload.Job.ID != 42 || payload.Job.JobID != 1001 {
+		t.Errorf("unexpected payload: %s", req.payload)
+	}
+}
+
+func TestDeliveryUnknownUrl(t *testing.T) {
+	setup(t, []*schema.WebhookConfig{{Url: "http://localhost:1/hook"}})
+
+	Emit(TagAdded, &schema.Job{ID: 1}, &schema.Tag{ID: 2, Type: "issue", Name: "lowutil"})
+
+	// Deliveries of webhooks removed from the configuration are dropped
+	webhooks = []*schema.WebhookConfig{{Url: "http://localhost:1/other"}}
+	deliverDue()
+	if d := pending(t); len(d) != 0 {
+		t.Fatalf("expected delivery to be dropped, got %v", d)
+	}
+}
@@ -19,10 +19,14 @@ const Version uint64 = 2
 type ArchiveBackend interface {
 	Init(rawConfig json.RawMessage) (uint64, error)
 
+	SetVersion(version uint64) error
+
 	Info()
 
 	Exists(job *schema.Job) bool
 
+	Verify(job *schema.Job) error
+
 	LoadJobMeta(job *schema.Job) (*schema.JobMeta, error)
 
 	LoadJobData(job *schema.Job) (schema.JobData, error)
@@ -31,6 +35,8 @@ type ArchiveBackend interface {
 
 	LoadClusterCfg(name string) (*schema.Cluster, error)
 
+	StoreClusterCfg(name string, cfg *schema.Cluster) error
+
 	StoreJobMeta(jobMeta *schema.JobMeta) error
 
 	ImportJob(jobMeta *schema.JobMeta, jobData *schema.JobData) error
@@ -47,7 +53,7 @@ type ArchiveBackend interface {
 
 	CompressLast(starttime int64) int64
 
-	Iter(loadMetricData bool) <-chan JobContainer
+	Iter(loadMetricData bool, filter *IterFilter) <-chan JobContainer
 }
 
 type JobContainer struct {
@@ -55,6 +61,27 @@ type JobContainer struct {
 	Data *schema.JobData
 }
 
+// Restricts the jobs returned by Iter. Empty fields match all jobs, the
+// start time range is inclusive. A nil filter matches all jobs.
+type IterFilter struct {
+	Cluster       string
+	StartTimeFrom int64
+	StartTimeTo   int64
+}
+
+func (f *IterFilter) matchCluster(cluster string) bool {
+	return f == nil || f.Cluster == "" || f.Cluster == cluster
+}
+
+func (f *IterFilter) matchStartTime(startTime int64) bool {
+	if f == nil {
+		return true
+	}
+
+	return (f.StartTimeFrom == 0 || startTime >= f.StartTimeFrom) &&
+		(f.StartTimeTo == 0 || startTime <= f.StartTimeTo)
+}
+
 var (
 	initOnce   sync.Once
 	cache      *lrucache.Cache = lrucache.New(128 * 1024 * 1024)
@@ -68,31 +95,10 @@ func Init(rawConfig json.RawMessage, disableArchive bool) error {
 	initOnce.Do(func() {
 		useArchive = !disableArchive
 
-		var cfg struct {
-			Kind string `json:"kind"`
-		}
-
-		if err = json.Unmarshal(rawConfig, &cfg); err != nil {
-			log.Warn("Error while unmarshaling raw config json")
-			return
-		}
-
-		switch cfg.Kind {
-		case "file":
-			ar = &FsArchive{}
-			// case "s3":
-			// 	ar = &S3Archive{}
-		default:
-			err = fmt.Errorf("ARCHIVE/ARCHIVE > unkown archive backend '%s''", cfg.Kind)
-		}
-
-		var version uint64
-		version, err = ar.Init(rawConfig)
+		ar, err = New(rawConfig)
 		if err != nil {
-			log.Errorf("Error while initializing archiveBackend: %s", err.Error())
 			return
 		}
-		log.Infof("Load archive version %d", version)
 
 		err = initClusterConfig()
 	})
@@ -100,6 +106,56 @@ func Init(rawConfig json.RawMessage, disableArchive bool) error {
 	return err
 }
 
+// New creates and initializes an archive backend from its configuration.
+// In contrast to Init the backend is not registered as the global handle,
+// which allows to work with multiple archives at once.
+func New(rawConfig json.RawMessage) (ArchiveBackend, error) {
+	backend, version, err := Open(rawConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	if version != Version {
+		return nil, fmt.Errorf("unsupported version %d, need %d", version, Version)
+	}
+	log.Infof("Load archive version %d", version)
+
+	return backend, nil
+}
+
+// Open creates and initializes an archive backend without checking the
+// archive version. It is used to upgrade archives of older versions.
+func Open(rawConfig json.RawMessage) (ArchiveBackend, uint64, error) {
+	var cfg struct {
+		Kind string `json:"kind"`
+	}
+
+	if err := json.Unmarshal(rawConfig, &cfg); err != nil {
+		log.Warn("Error while unmarshaling raw config json")
+		return nil, 0, err
+	}
+
+	var backend ArchiveBackend
+	switch cfg.Kind {
+	case "file":
+		backend = &FsArchive{}
+	case "s3":
+		backend = &S3Archive{}
+	case "sqlite":
+		backend = &SqliteArchive{}
+	default:
+		return nil, 0, fmt.Errorf("ARCHIVE/ARCHIVE > unkown archive backend '%s''", cfg.Kind)
+	}
+
+	version, err := backend.Init(rawConfig)
+	if err != nil {
+		log.Errorf("Error while initializing archiveBackend: %s", err.Error())
+		return nil, version, err
+	}
+
+	return backend, version, nil
+}
+
 func GetHandle() ArchiveBackend {
 	return ar
 }
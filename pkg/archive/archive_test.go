@@ -67,3 +67,17 @@ func TestCleanUp(t *testing.T) {
 // 		t.Error("Jobs still exist")
 // 	}
 // }
+
+func TestNew(t *testing.T) {
+	a, err := archive.New(json.RawMessage("{\"kind\": \"file\",\"path\": \"./testdata/archive\"}"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(a.GetClusters()) != 3 {
+		t.Errorf("unexpected clusters %v", a.GetClusters())
+	}
+
+	if _, err := archive.New(json.RawMessage("{\"kind\": \"tape\"}")); err == nil {
+		t.Error("expected error for unknown archive backend")
+	}
+}
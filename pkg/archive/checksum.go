@@ -0,0 +1,90 @@
+// Copyright (C) NHR@FAU, University Erlangen-Nuremberg.
+// All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+package archive
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Name of the file holding the checksums of all files of a job. It uses the
+// format of sha256sum, so it can also be checked with `sha256sum -c`.
+const checksumFile = "checksums.sha256"
+
+// Returned by Verify for jobs that were archived without checksums.
+var ErrNoChecksums = errors.New("no checksums recorded")
+
+// Maps job file names to their SHA-256 hex digest
+type Checksums map[string]string
+
+func computeChecksum(b []byte) string {
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+func (c Checksums) encode() []byte {
+	files := make([]string, 0, len(c))
+	for file := range c {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+
+	var buf bytes.Buffer
+	for _, file := range files {
+		fmt.Fprintf(&buf, "%s  %s\n", c[file], file)
+	}
+
+	return buf.Bytes()
+}
+
+func decodeChecksums(b []byte) (Checksums, error) {
+	c := make(Checksums)
+	scanner := bufio.NewScanner(bytes.NewReader(b))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		sum, file, ok := strings.Cut(line, "  ")
+		if !ok || len(sum) != sha256.Size*2 {
+			return nil, fmt.Errorf("invalid checksum line '%s'", line)
+		}
+		c[file] = sum
+	}
+
+	return c, scanner.Err()
+}
+
+// Jobs whose meta.json was rewritten after archiving without checksums only
+// have a checksum for the metadata.
+func (c Checksums) hasJobData() error {
+	_, raw := c["data.json"]
+	_, compressed := c["data.json.gz"]
+	if !raw && !compressed {
+		return fmt.Errorf("job data: %w", ErrNoChecksums)
+	}
+
+	return nil
+}
+
+// Compares the content of a file with its recorded checksum.
+func (c Checksums) verify(file string, b []byte) error {
+	sum, ok := c[file]
+	if !ok {
+		return fmt.Errorf("%s: no checksum recorded", file)
+	}
+	if computeChecksum(b) != sum {
+		return fmt.Errorf("%s: checksum mismatch", file)
+	}
+
+	return nil
+}
@@ -0,0 +1,125 @@
+// Copyright (C) NHR@FAU, University Erlangen-Nuremberg.
+// All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+package archive
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ClusterCockpit/cc-backend/internal/util"
+	"github.com/ClusterCockpit/cc-backend/pkg/schema"
+)
+
+func TestChecksumsEncoding(t *testing.T) {
+	c := Checksums{
+		"meta.json": computeChecksum([]byte("meta")),
+		"data.json": computeChecksum([]byte("data")),
+	}
+
+	d, err := decodeChecksums(c.encode())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(d) != 2 || d["meta.json"] != c["meta.json"] || d["data.json"] != c["data.json"] {
+		t.Errorf("unexpected checksums %v", d)
+	}
+
+	if err := d.verify("data.json", []byte("data")); err != nil {
+		t.Error(err)
+	}
+	if err := d.verify("data.json", []byte("dat")); err == nil {
+		t.Error("expected checksum mismatch")
+	}
+	if _, err := decodeChecksums([]byte("abc meta.json\n")); err == nil {
+		t.Error("expected error for invalid checksum line")
+	}
+}
+
+func TestFsVerify(t *testing.T) {
+	tmpdir := t.TempDir()
+	jobarchive := filepath.Join(tmpdir, "job-archive")
+	util.CopyDir("./testdata/archive/", jobarchive)
+
+	var fsa FsArchive
+	if _, err := fsa.Init(json.RawMessage(fmt.Sprintf("{\"path\": \"%s\"}", jobarchive))); err != nil {
+		t.Fatal(err)
+	}
+
+	job := schema.Job{StartTime: time.Unix(1608923076, 0)}
+	job.JobID = 1403244
+	job.Cluster = "emmy"
+
+	if err := fsa.Verify(&job); !errors.Is(err, ErrNoChecksums) {
+		t.Fatalf("expected ErrNoChecksums, got %v", err)
+	}
+
+	jobMeta, err := fsa.LoadJobMeta(&job)
+	if err != nil {
+		t.Fatal(err)
+	}
+	jobData, err := fsa.LoadJobData(&job)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := fsa.ImportJob(jobMeta, &jobData); err != nil {
+		t.Fatal(err)
+	}
+	if err := fsa.Verify(&job); err != nil {
+		t.Fatal(err)
+	}
+
+	jobMeta.MetaData = map[string]string{"jobName": "renamed"}
+	if err := fsa.StoreJobMeta(jobMeta); err != nil {
+		t.Fatal(err)
+	}
+	if err := fsa.Verify(&job); err != nil {
+		t.Fatal(err)
+	}
+
+	fsa.Compress([]*schema.Job{&job})
+	if !util.CheckFileExists(getPath(&job, fsa.path, "data.json.gz")) {
+		t.Fatal("job data was not compressed")
+	}
+	if err := fsa.Verify(&job); err != nil {
+		t.Fatal(err)
+	}
+
+	// Truncate the compressed job data
+	filename := getPath(&job, fsa.path, "data.json.gz")
+	if err := os.Truncate(filename, util.GetFilesize(filename)/2); err != nil {
+		t.Fatal(err)
+	}
+	if err := fsa.Verify(&job); err == nil {
+		t.Fatal("expected checksum mismatch for truncated job data")
+	}
+}
+
+func TestSqliteVerify(t *testing.T) {
+	sa := setupSqlite(t)
+
+	job := schema.Job{StartTime: time.Unix(1608923076, 0)}
+	job.JobID = 1403244
+	job.Cluster = "emmy"
+
+	if err := sa.Verify(&job); err != nil {
+		t.Fatal(err)
+	}
+	sa.Compress([]*schema.Job{&job})
+	if err := sa.Verify(&job); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := sa.db.Exec(`UPDATE job SET data = substr(data, 1, 100) WHERE job_id = ?`, job.JobID); err != nil {
+		t.Fatal(err)
+	}
+	if err := sa.Verify(&job); err == nil {
+		t.Fatal("expected checksum mismatch for truncated job data")
+	}
+}
@@ -0,0 +1,113 @@
+// Copyright (C) NHR@FAU, University Erlangen-Nuremberg.
+// All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+package archive
+
+import (
+	"github.com/ClusterCockpit/cc-backend/pkg/resampler"
+	"github.com/ClusterCockpit/cc-backend/pkg/schema"
+)
+
+func downsampleSeries(data []schema.Float, timestep int, resolution int) ([]schema.Float, error) {
+	d, _, err := resampler.LargestTriangleThreeBucket(data, timestep, resolution)
+	return d, err
+}
+
+func downsampleMetric(jm *schema.JobMetric, resolution int) (*schema.JobMetric, error) {
+	// Series that are already coarser or cannot be resampled to an integer
+	// multiple of their timestep are kept as they are
+	if jm.Timestep <= 0 || jm.Timestep >= resolution || resolution%jm.Timestep != 0 {
+		return jm, nil
+	}
+
+	res := &schema.JobMetric{
+		Unit:     jm.Unit,
+		Timestep: resolution,
+		Series:   make([]schema.Series, len(jm.Series)),
+	}
+
+	// The resampler keeps short series, only change the timestep if all
+	// series were resampled.
+	resampled := true
+	for i, s := range jm.Series {
+		data, err := downsampleSeries(s.Data, jm.Timestep, resolution)
+		if err != nil {
+			return nil, err
+		}
+		if len(data) == len(s.Data) {
+			resampled = false
+			break
+		}
+		res.Series[i] = s
+		res.Series[i].Data = data
+	}
+	if !resampled {
+		return jm, nil
+	}
+
+	if ss := jm.StatisticsSeries; ss != nil {
+		res.StatisticsSeries = &schema.StatsSeries{}
+		var err error
+		for _, f := range []struct {
+			dst *[]schema.Float
+			src []schema.Float
+		}{
+			{&res.StatisticsSeries.Mean, ss.Mean},
+			{&res.StatisticsSeries.Median, ss.Median},
+			{&res.StatisticsSeries.Min, ss.Min},
+			{&res.StatisticsSeries.Max, ss.Max},
+		} {
+			if *f.dst, err = downsampleSeries(f.src, jm.Timestep, resolution); err != nil {
+				return nil, err
+			}
+		}
+		if ss.Percentiles != nil {
+			res.StatisticsSeries.Percentiles = make(map[int][]schema.Float, len(ss.Percentiles))
+			for p, data := range ss.Percentiles {
+				if res.StatisticsSeries.Percentiles[p], err = downsampleSeries(data, jm.Timestep, resolution); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	return res, nil
+}
+
+// DownsampleJobData reduces all series of jobData to a timestep of
+// resolution seconds. With dropSubNode set, all scopes finer than node are
+// removed for metrics that also have node scope data. The input is not
+// modified, as it may be shared with the archive cache. Returns whether the
+// job data was changed.
+func DownsampleJobData(
+	jobData schema.JobData,
+	resolution int,
+	dropSubNode bool,
+) (schema.JobData, bool, error) {
+	res := make(schema.JobData, len(jobData))
+	changed := false
+
+	for metric, scopes := range jobData {
+		_, hasNode := scopes[schema.MetricScopeNode]
+		res[metric] = make(map[schema.MetricScope]*schema.JobMetric, len(scopes))
+
+		for scope, jm := range scopes {
+			if dropSubNode && hasNode && scope != schema.MetricScopeNode {
+				changed = true
+				continue
+			}
+
+			djm, err := downsampleMetric(jm, resolution)
+			if err != nil {
+				return nil, false, err
+			}
+			if djm != jm {
+				changed = true
+			}
+			res[metric][scope] = djm
+		}
+	}
+
+	return res, changed, nil
+}
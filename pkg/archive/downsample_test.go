@@ -0,0 +1,84 @@
+// Copyright (C) NHR@FAU, University Erlangen-Nuremberg.
+// All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+package archive
+
+import (
+	"testing"
+
+	"github.com/ClusterCockpit/cc-backend/pkg/schema"
+)
+
+func makeJobMetric(n int, timestep int) *schema.JobMetric {
+	data := make([]schema.Float, n)
+	for i := range data {
+		data[i] = schema.Float(i % 7)
+	}
+
+	return &schema.JobMetric{
+		Timestep: timestep,
+		Series:   []schema.Series{{Hostname: "e0101", Data: data}},
+		StatisticsSeries: &schema.StatsSeries{
+			Mean: data, Median: data, Min: data, Max: data,
+			Percentiles: map[int][]schema.Float{10: data},
+		},
+	}
+}
+
+func TestDownsampleJobData(t *testing.T) {
+	jobData := schema.JobData{
+		"flops_any": {
+			schema.MetricScopeNode: makeJobMetric(1440, 60),
+			schema.MetricScopeCore: makeJobMetric(1440, 60),
+		},
+		"acc_util": {
+			schema.MetricScopeAccelerator: makeJobMetric(1440, 60),
+		},
+		"short": {
+			schema.MetricScopeNode: makeJobMetric(50, 60),
+		},
+	}
+
+	res, changed, err := DownsampleJobData(jobData, 600, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !changed {
+		t.Fatal("expected job data to change")
+	}
+
+	for metric, scopes := range res {
+		for scope, jm := range scopes {
+			want, length := 600, 144
+			if metric == "short" {
+				want, length = 60, 50
+			}
+			if jm.Timestep != want || len(jm.Series[0].Data) != length ||
+				len(jm.StatisticsSeries.Mean) != length || len(jm.StatisticsSeries.Percentiles[10]) != length {
+				t.Errorf("%s/%s: unexpected timestep %d and length %d",
+					metric, scope, jm.Timestep, len(jm.Series[0].Data))
+			}
+		}
+	}
+	if jobData["flops_any"][schema.MetricScopeNode].Timestep != 60 ||
+		len(jobData["flops_any"][schema.MetricScopeNode].Series[0].Data) != 1440 {
+		t.Error("input job data was modified")
+	}
+
+	// A second run on the downsampled data is a no-op
+	if _, changed, err := DownsampleJobData(res, 600, false); err != nil || changed {
+		t.Errorf("expected no change, got %v (%v)", changed, err)
+	}
+
+	res, changed, err = DownsampleJobData(res, 600, true)
+	if err != nil || !changed {
+		t.Fatalf("expected dropped scopes, got %v (%v)", changed, err)
+	}
+	if _, ok := res["flops_any"][schema.MetricScopeCore]; ok {
+		t.Error("core scope was not dropped")
+	}
+	if _, ok := res["acc_util"][schema.MetricScopeAccelerator]; !ok {
+		t.Error("metric without node scope must keep its scopes")
+	}
+}
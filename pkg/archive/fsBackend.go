@@ -8,13 +8,17 @@ import (
 	"bufio"
 	"bytes"
 	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"math"
 	"os"
 	"path"
 	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
 	"text/tabwriter"
@@ -34,6 +38,7 @@ type FsArchiveConfig struct {
 type FsArchive struct {
 	path     string
 	clusters []string
+	index    *fsIndex
 }
 
 type clusterInfo struct {
@@ -175,10 +180,6 @@ func (fsa *FsArchive) Init(rawConfig json.RawMessage) (uint64, error) {
 		return 0, err
 	}
 
-	if version != Version {
-		return version, fmt.Errorf("unsupported version %d, need %d", version, Version)
-	}
-
 	entries, err := os.ReadDir(fsa.path)
 	if err != nil {
 		log.Errorf("Init() > ReadDir() error: %v", err)
@@ -192,18 +193,79 @@ func (fsa *FsArchive) Init(rawConfig json.RawMessage) (uint64, error) {
 		fsa.clusters = append(fsa.clusters, de.Name())
 	}
 
+	if util.CheckFileExists(filepath.Join(fsa.path, fsIndexFile)) {
+		if fsa.index, err = openFsIndex(fsa.path); err != nil {
+			log.Errorf("fsBackend Init() > open index error: %v", err)
+			return 0, err
+		}
+	} else {
+		log.Infof("fsBackend Init() - no job index in %s, use archive-manager -rebuild-index to create it", fsa.path)
+	}
+
 	return version, nil
 }
 
+// RebuildIndex creates the job index of the archive from scratch and returns
+// the number of indexed jobs. Once an index exists, it is maintained by all
+// operations writing to the archive.
+func (fsa *FsArchive) RebuildIndex() (int, error) {
+	if fsa.index == nil {
+		index, err := openFsIndex(fsa.path)
+		if err != nil {
+			return 0, err
+		}
+		fsa.index = index
+	}
+
+	return fsa.index.rebuild()
+}
+
+func (fsa *FsArchive) SetVersion(version uint64) error {
+	return os.WriteFile(filepath.Join(fsa.path, "version.txt"), []byte(fmt.Sprintf("%d\n", version)), 0644)
+}
+
 func (fsa *FsArchive) Info() {
 	fmt.Printf("Job archive %s\n", fsa.path)
+
+	var ci map[string]*clusterInfo
+	if fsa.index != nil {
+		var err error
+		if ci, err = fsa.index.clusterInfo(); err != nil {
+			log.Fatalf("Reading job index failed: %s", err.Error())
+		}
+	} else {
+		ci = fsa.walkInfo()
+	}
+
+	cit := clusterInfo{dateFirst: time.Now().Unix()}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.Debug)
+	fmt.Fprintln(w, "cluster\t#jobs\tfrom\tto\tdu (MB)")
+	for cluster, clusterInfo := range ci {
+		fmt.Fprintf(w, "%s\t%d\t%s\t%s\t%.2f\n", cluster,
+			clusterInfo.numJobs,
+			time.Unix(clusterInfo.dateFirst, 0),
+			time.Unix(clusterInfo.dateLast, 0),
+			clusterInfo.diskSize)
+
+		cit.numJobs += clusterInfo.numJobs
+		cit.dateFirst = util.Min(cit.dateFirst, clusterInfo.dateFirst)
+		cit.dateLast = util.Max(cit.dateLast, clusterInfo.dateLast)
+		cit.diskSize += clusterInfo.diskSize
+	}
+
+	fmt.Fprintf(w, "TOTAL\t%d\t%s\t%s\t%.2f\n",
+		cit.numJobs, time.Unix(cit.dateFirst, 0), time.Unix(cit.dateLast, 0), cit.diskSize)
+	w.Flush()
+}
+
+// Collects the cluster statistics by walking the archive directory tree
+func (fsa *FsArchive) walkInfo() map[string]*clusterInfo {
 	clusters, err := os.ReadDir(fsa.path)
 	if err != nil {
 		log.Fatalf("Reading clusters failed: %s", err.Error())
 	}
 
 	ci := make(map[string]*clusterInfo)
-
 	for _, cluster := range clusters {
 		if !cluster.IsDir() {
 			continue
@@ -248,25 +310,7 @@ func (fsa *FsArchive) Info() {
 		}
 	}
 
-	cit := clusterInfo{dateFirst: time.Now().Unix()}
-	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.Debug)
-	fmt.Fprintln(w, "cluster\t#jobs\tfrom\tto\tdu (MB)")
-	for cluster, clusterInfo := range ci {
-		fmt.Fprintf(w, "%s\t%d\t%s\t%s\t%.2f\n", cluster,
-			clusterInfo.numJobs,
-			time.Unix(clusterInfo.dateFirst, 0),
-			time.Unix(clusterInfo.dateLast, 0),
-			clusterInfo.diskSize)
-
-		cit.numJobs += clusterInfo.numJobs
-		cit.dateFirst = util.Min(cit.dateFirst, clusterInfo.dateFirst)
-		cit.dateLast = util.Max(cit.dateLast, clusterInfo.dateLast)
-		cit.diskSize += clusterInfo.diskSize
-	}
-
-	fmt.Fprintf(w, "TOTAL\t%d\t%s\t%s\t%.2f\n",
-		cit.numJobs, time.Unix(cit.dateFirst, 0), time.Unix(cit.dateLast, 0), cit.diskSize)
-	w.Flush()
+	return ci
 }
 
 func (fsa *FsArchive) Exists(job *schema.Job) bool {
@@ -281,6 +325,20 @@ func (fsa *FsArchive) Clean(before int64, after int64) {
 		after = math.MaxInt64
 	}
 
+	if fsa.index != nil {
+		entries, err := fsa.index.outside(before, after)
+		if err != nil {
+			log.Fatalf("Reading job index failed: %s", err.Error())
+		}
+
+		jobs := make([]*schema.Job, 0, len(entries))
+		for i := range entries {
+			jobs = append(jobs, entries[i].job())
+		}
+		fsa.CleanUp(jobs)
+		return
+	}
+
 	clusters, err := os.ReadDir(fsa.path)
 	if err != nil {
 		log.Fatalf("Reading clusters failed: %s", err.Error())
@@ -347,6 +405,7 @@ func (fsa *FsArchive) Move(jobs []*schema.Job, path string) {
 		if err := os.Rename(source, target); err != nil {
 			log.Errorf("JobArchive Move() error: %v", err)
 		}
+		fsa.index.remove(job)
 
 		parent := filepath.Clean(filepath.Join(source, ".."))
 		if util.GetFilecount(parent) == 0 {
@@ -364,6 +423,7 @@ func (fsa *FsArchive) CleanUp(jobs []*schema.Job) {
 		if err := os.RemoveAll(dir); err != nil {
 			log.Errorf("JobArchive Cleanup() error: %v", err)
 		}
+		fsa.index.remove(job)
 
 		parent := filepath.Clean(filepath.Join(dir, ".."))
 		if util.GetFilecount(parent) == 0 {
@@ -383,14 +443,39 @@ func (fsa *FsArchive) Compress(jobs []*schema.Job) {
 	for _, job := range jobs {
 		fileIn := getPath(job, fsa.path, "data.json")
 		if util.CheckFileExists(fileIn) && util.GetFilesize(fileIn) > 2000 {
-			util.CompressFile(fileIn, getPath(job, fsa.path, "data.json.gz"))
+			fileOut := getPath(job, fsa.path, "data.json.gz")
+			if err := util.CompressFile(fileIn, fileOut); err != nil {
+				continue
+			}
 			cnt++
+
+			updateCompressedChecksum(getDirectory(job, fsa.path))
+			fsa.index.update(job)
 		}
 	}
 
 	log.Infof("Compression Service - %d files took %s", cnt, time.Since(start))
 }
 
+// Replaces the checksum of data.json by the one of data.json.gz. Jobs
+// archived without checksums are left untouched.
+func updateCompressedChecksum(dir string) {
+	checksums, err := readChecksums(dir)
+	if err != nil {
+		return
+	}
+	b, err := os.ReadFile(filepath.Join(dir, "data.json.gz"))
+	if err != nil {
+		log.Errorf("JobArchive Compress() error: %v", err)
+		return
+	}
+	delete(checksums, "data.json")
+	checksums["data.json.gz"] = computeChecksum(b)
+	if err := writeChecksums(dir, checksums); err != nil {
+		log.Errorf("JobArchive Compress() error: %v", err)
+	}
+}
+
 func (fsa *FsArchive) CompressLast(starttime int64) int64 {
 
 	filename := filepath.Join(fsa.path, "compress.txt")
@@ -411,6 +496,46 @@ func (fsa *FsArchive) CompressLast(starttime int64) int64 {
 	return last
 }
 
+func readChecksums(dir string) (Checksums, error) {
+	b, err := os.ReadFile(filepath.Join(dir, checksumFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNoChecksums
+	} else if err != nil {
+		return nil, err
+	}
+
+	return decodeChecksums(b)
+}
+
+func writeChecksums(dir string, checksums Checksums) error {
+	if err := os.WriteFile(filepath.Join(dir, checksumFile), checksums.encode(), 0644); err != nil {
+		log.Errorf("Error while writing %s file", checksumFile)
+		return err
+	}
+
+	return nil
+}
+
+func (fsa *FsArchive) Verify(job *schema.Job) error {
+	dir := getDirectory(job, fsa.path)
+	checksums, err := readChecksums(dir)
+	if err != nil {
+		return err
+	}
+
+	for file := range checksums {
+		b, err := os.ReadFile(filepath.Join(dir, file))
+		if err != nil {
+			return err
+		}
+		if err := checksums.verify(file, b); err != nil {
+			return err
+		}
+	}
+
+	return checksums.hasJobData()
+}
+
 func (fsa *FsArchive) LoadJobData(job *schema.Job) (schema.JobData, error) {
 	var isCompressed bool = true
 	filename := getPath(job, fsa.path, "data.json.gz")
@@ -455,17 +580,85 @@ func (fsa *FsArchive) LoadClusterCfg(name string) (*schema.Cluster, error) {
 	return DecodeCluster(bytes.NewReader(b))
 }
 
-func (fsa *FsArchive) Iter(loadMetricData bool) <-chan JobContainer {
+func (fsa *FsArchive) StoreClusterCfg(name string, cfg *schema.Cluster) error {
+	dir := filepath.Join(fsa.path, name)
+	if err := os.MkdirAll(dir, 0777); err != nil {
+		log.Error("Error while creating cluster directory")
+		return err
+	}
+
+	f, err := os.Create(filepath.Join(dir, "cluster.json"))
+	if err != nil {
+		log.Error("Error while creating filepath for cluster.json")
+		return err
+	}
+	if err := EncodeCluster(f, cfg); err != nil {
+		log.Error("Error while encoding cluster config to cluster.json file")
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		log.Warn("Error while closing cluster.json file")
+		return err
+	}
+
+	if !slices.Contains(fsa.clusters, name) {
+		fsa.clusters = append(fsa.clusters, name)
+	}
+
+	return nil
+}
+
+// Loads the job in dir and sends it to ch
+func sendJob(ch chan<- JobContainer, dir string, loadMetricData bool) {
+	job, err := loadJobMeta(filepath.Join(dir, "meta.json"))
+	if err != nil && !errors.Is(err, &jsonschema.ValidationError{}) {
+		log.Errorf("in %s: %s", dir, err.Error())
+	}
+
+	if loadMetricData {
+		var isCompressed bool = true
+		filename := filepath.Join(dir, "data.json.gz")
+
+		if !util.CheckFileExists(filename) {
+			filename = filepath.Join(dir, "data.json")
+			isCompressed = false
+		}
+
+		data, err := loadJobData(filename, isCompressed)
+		if err != nil && !errors.Is(err, &jsonschema.ValidationError{}) {
+			log.Errorf("in %s: %s", dir, err.Error())
+		}
+		ch <- JobContainer{Meta: job, Data: &data}
+	} else {
+		ch <- JobContainer{Meta: job, Data: nil}
+	}
+}
+
+func (fsa *FsArchive) Iter(loadMetricData bool, filter *IterFilter) <-chan JobContainer {
 
 	ch := make(chan JobContainer)
 	go func() {
+		if fsa.index != nil {
+			entries, err := fsa.index.query(filter)
+			if err != nil {
+				log.Fatalf("Reading job index failed: %s", err.Error())
+			}
+
+			for _, e := range entries {
+				sendJob(ch, filepath.Join(fsa.path, e.Location), loadMetricData)
+			}
+			close(ch)
+			return
+		}
+
 		clustersDir, err := os.ReadDir(fsa.path)
 		if err != nil {
 			log.Fatalf("Reading clusters failed @ cluster dirs: %s", err.Error())
 		}
 
 		for _, clusterDir := range clustersDir {
-			if !clusterDir.IsDir() {
+			if !clusterDir.IsDir() || !filter.matchCluster(clusterDir.Name()) {
 				continue
 			}
 			lvl1Dirs, err := os.ReadDir(filepath.Join(fsa.path, clusterDir.Name()))
@@ -492,31 +685,16 @@ func (fsa *FsArchive) Iter(loadMetricData bool) <-chan JobContainer {
 					}
 
 					for _, startTimeDir := range startTimeDirs {
-						if startTimeDir.IsDir() {
-							job, err := loadJobMeta(filepath.Join(dirpath, startTimeDir.Name(), "meta.json"))
-							if err != nil && !errors.Is(err, &jsonschema.ValidationError{}) {
-								log.Errorf("in %s: %s", filepath.Join(dirpath, startTimeDir.Name()), err.Error())
-							}
-
-							if loadMetricData {
-								var isCompressed bool = true
-								filename := filepath.Join(dirpath, startTimeDir.Name(), "data.json.gz")
-
-								if !util.CheckFileExists(filename) {
-									filename = filepath.Join(dirpath, startTimeDir.Name(), "data.json")
-									isCompressed = false
-								}
-
-								data, err := loadJobData(filename, isCompressed)
-								if err != nil && !errors.Is(err, &jsonschema.ValidationError{}) {
-									log.Errorf("in %s: %s", filepath.Join(dirpath, startTimeDir.Name()), err.Error())
-								}
-								ch <- JobContainer{Meta: job, Data: &data}
-								log.Errorf("in %s: %s", filepath.Join(dirpath, startTimeDir.Name()), err.Error())
-							} else {
-								ch <- JobContainer{Meta: job, Data: nil}
+						if !startTimeDir.IsDir() {
+							continue
+						}
+						if filter != nil {
+							startTime, err := strconv.ParseInt(startTimeDir.Name(), 10, 64)
+							if err != nil || !filter.matchStartTime(startTime) {
+								continue
 							}
 						}
+						sendJob(ch, filepath.Join(dirpath, startTimeDir.Name()), loadMetricData)
 					}
 				}
 			}
@@ -538,7 +716,8 @@ func (fsa *FsArchive) StoreJobMeta(jobMeta *schema.JobMeta) error {
 		log.Error("Error while creating filepath for meta.json")
 		return err
 	}
-	if err := EncodeJobMeta(f, jobMeta); err != nil {
+	h := sha256.New()
+	if err := EncodeJobMeta(io.MultiWriter(f, h), jobMeta); err != nil {
 		log.Error("Error while encoding job metadata to meta.json file")
 		return err
 	}
@@ -547,6 +726,18 @@ func (fsa *FsArchive) StoreJobMeta(jobMeta *schema.JobMeta) error {
 		return err
 	}
 
+	// Jobs archived without checksums only get a checksum for meta.json
+	dir := getDirectory(&job, fsa.path)
+	checksums, err := readChecksums(dir)
+	if err != nil {
+		checksums = make(Checksums)
+	}
+	checksums["meta.json"] = hex.EncodeToString(h.Sum(nil))
+	if err := writeChecksums(dir, checksums); err != nil {
+		return err
+	}
+	fsa.index.update(&job)
+
 	return nil
 }
 
@@ -574,7 +765,8 @@ func (fsa *FsArchive) ImportJob(
 		log.Error("Error while creating filepath for meta.json")
 		return err
 	}
-	if err := EncodeJobMeta(f, jobMeta); err != nil {
+	metaHash := sha256.New()
+	if err := EncodeJobMeta(io.MultiWriter(f, metaHash), jobMeta); err != nil {
 		log.Error("Error while encoding job metadata to meta.json file")
 		return err
 	}
@@ -605,17 +797,54 @@ func (fsa *FsArchive) ImportJob(
 	// 	}
 	// }
 
-	f, err = os.Create(path.Join(dir, "data.json"))
+	// Jobs that were compressed before, e.g. when a job is migrated in
+	// place, stay compressed
+	isCompressed := util.CheckFileExists(path.Join(dir, "data.json.gz"))
+	dataFile := "data.json"
+	if isCompressed {
+		dataFile = "data.json.gz"
+	}
+
+	f, err = os.Create(path.Join(dir, dataFile))
 	if err != nil {
-		log.Error("Error while creating filepath for data.json")
+		log.Errorf("Error while creating filepath for %s", dataFile)
 		return err
 	}
-	if err := EncodeJobData(f, jobData); err != nil {
+	dataHash := sha256.New()
+	if isCompressed {
+		w := gzip.NewWriter(io.MultiWriter(f, dataHash))
+		if err := EncodeJobData(w, jobData); err != nil {
+			log.Error("Error while encoding job metricdata to data.json.gz file")
+			return err
+		}
+		if err := w.Close(); err != nil {
+			log.Error("Error while compressing data.json.gz file")
+			return err
+		}
+	} else if err := EncodeJobData(io.MultiWriter(f, dataHash), jobData); err != nil {
 		log.Error("Error while encoding job metricdata to data.json file")
 		return err
 	}
 	if err := f.Close(); err != nil {
-		log.Warn("Error while closing data.json file")
+		log.Warnf("Error while closing %s file", dataFile)
+		return err
 	}
-	return err
+
+	// An outdated uncompressed version is not used anymore
+	if isCompressed {
+		if err := os.Remove(path.Join(dir, "data.json")); err != nil && !os.IsNotExist(err) {
+			log.Warn("Error while removing outdated data.json file")
+			return err
+		}
+	}
+
+	if err := writeChecksums(dir, Checksums{
+		"meta.json": hex.EncodeToString(metaHash.Sum(nil)),
+		dataFile:    hex.EncodeToString(dataHash.Sum(nil)),
+	}); err != nil {
+		return err
+	}
+	fsa.index.update(&job)
+
+	return nil
 }
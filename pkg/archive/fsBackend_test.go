@@ -200,7 +200,7 @@ func TestIter(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	for job := range fsa.Iter(false) {
+	for job := range fsa.Iter(false, nil) {
 		fmt.Printf("Job %d\n", job.Meta.JobID)
 
 		if job.Meta.Cluster != "emmy" {
@@ -208,3 +208,34 @@ func TestIter(t *testing.T) {
 		}
 	}
 }
+
+func TestStoreClusterCfg(t *testing.T) {
+	tmpdir := t.TempDir()
+	jobarchive := filepath.Join(tmpdir, "job-archive")
+	util.CopyDir("./testdata/archive/", jobarchive)
+
+	var fsa FsArchive
+	if _, err := fsa.Init(json.RawMessage(fmt.Sprintf("{\"path\": \"%s\"}", jobarchive))); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := fsa.LoadClusterCfg("emmy")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.Name = "emmy2"
+	if err := fsa.StoreClusterCfg("emmy2", cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(fsa.GetClusters()) != 4 {
+		t.Errorf("unexpected clusters %v", fsa.GetClusters())
+	}
+	cfg, err = fsa.LoadClusterCfg("emmy2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Name != "emmy2" || cfg.SubClusters[0].CoresPerSocket != 4 {
+		t.Fail()
+	}
+}
@@ -0,0 +1,278 @@
+// Copyright (C) NHR@FAU, University Erlangen-Nuremberg.
+// All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+package archive
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/ClusterCockpit/cc-backend/internal/util"
+	"github.com/ClusterCockpit/cc-backend/pkg/log"
+	"github.com/ClusterCockpit/cc-backend/pkg/schema"
+	"github.com/jmoiron/sqlx"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// Name of the index database in the root of a file archive. The index is
+// optional, without it all operations walk the directory tree.
+const fsIndexFile = "index.db"
+
+const fsIndexSchema = `
+CREATE TABLE IF NOT EXISTS job (
+	cluster    VARCHAR(255) NOT NULL,
+	job_id     BIGINT NOT NULL,
+	start_time BIGINT NOT NULL,
+	location   TEXT NOT NULL,
+	size       BIGINT NOT NULL,
+	compressed TINYINT NOT NULL DEFAULT 0,
+	PRIMARY KEY (cluster, job_id, start_time)
+);
+
+CREATE INDEX IF NOT EXISTS job_start_time ON job (start_time);
+CREATE INDEX IF NOT EXISTS job_cluster_start_time ON job (cluster, start_time);
+`
+
+// Location, size in bytes and compression state of an archived job. The
+// location is the job directory relative to the archive root.
+type fsIndexEntry struct {
+	Cluster    string `db:"cluster"`
+	JobID      int64  `db:"job_id"`
+	StartTime  int64  `db:"start_time"`
+	Location   string `db:"location"`
+	Size       int64  `db:"size"`
+	Compressed bool   `db:"compressed"`
+}
+
+func (e *fsIndexEntry) job() *schema.Job {
+	job := &schema.Job{StartTime: time.Unix(e.StartTime, 0), StartTimeUnix: e.StartTime}
+	job.Cluster = e.Cluster
+	job.JobID = e.JobID
+
+	return job
+}
+
+type fsIndex struct {
+	db   *sqlx.DB
+	root string
+}
+
+func openFsIndex(root string) (*fsIndex, error) {
+	db, err := sqlx.Open("sqlite3", filepath.Join(root, fsIndexFile)+"?_journal=WAL&_timeout=5000")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := db.Exec(fsIndexSchema); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &fsIndex{db: db, root: root}, nil
+}
+
+// Reads the state of a job directory from disk
+func (idx *fsIndex) stat(cluster string, jobID int64, startTime int64, dir string) (*fsIndexEntry, error) {
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	e := &fsIndexEntry{Cluster: cluster, JobID: jobID, StartTime: startTime}
+	if e.Location, err = filepath.Rel(idx.root, dir); err != nil {
+		return nil, err
+	}
+	for _, f := range files {
+		info, err := f.Info()
+		if err != nil {
+			return nil, err
+		}
+		e.Size += info.Size()
+		if f.Name() == "data.json.gz" {
+			e.Compressed = true
+		}
+	}
+
+	return e, nil
+}
+
+func (idx *fsIndex) insert(db sqlx.Execer, e *fsIndexEntry) error {
+	_, err := db.Exec(`INSERT INTO job (cluster, job_id, start_time, location, size, compressed)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT (cluster, job_id, start_time) DO UPDATE SET
+		location = excluded.location, size = excluded.size, compressed = excluded.compressed`,
+		e.Cluster, e.JobID, e.StartTime, e.Location, e.Size, e.Compressed)
+	return err
+}
+
+// Updates the entry of a job after its files were written. Does nothing if
+// the archive has no index.
+func (idx *fsIndex) update(job *schema.Job) {
+	if idx == nil {
+		return
+	}
+
+	e, err := idx.stat(job.Cluster, job.JobID, job.StartTime.Unix(), getDirectory(job, idx.root))
+	if err == nil {
+		err = idx.insert(idx.db, e)
+	}
+	if err != nil {
+		log.Errorf("JobArchive index update error: %v", err)
+	}
+}
+
+// Removes the entry of a job. Does nothing if the archive has no index.
+func (idx *fsIndex) remove(job *schema.Job) {
+	if idx == nil {
+		return
+	}
+
+	if _, err := idx.db.Exec(`DELETE FROM job WHERE cluster = ? AND job_id = ? AND start_time = ?`,
+		job.Cluster, job.JobID, job.StartTime.Unix()); err != nil {
+		log.Errorf("JobArchive index remove error: %v", err)
+	}
+}
+
+func (idx *fsIndex) query(filter *IterFilter) ([]fsIndexEntry, error) {
+	q := `SELECT * FROM job WHERE 1 = 1`
+	args := make([]any, 0, 3)
+	if filter != nil {
+		if filter.Cluster != "" {
+			q += ` AND cluster = ?`
+			args = append(args, filter.Cluster)
+		}
+		if filter.StartTimeFrom != 0 {
+			q += ` AND start_time >= ?`
+			args = append(args, filter.StartTimeFrom)
+		}
+		if filter.StartTimeTo != 0 {
+			q += ` AND start_time <= ?`
+			args = append(args, filter.StartTimeTo)
+		}
+	}
+	q += ` ORDER BY cluster, start_time`
+
+	entries := make([]fsIndexEntry, 0)
+	err := idx.db.Select(&entries, q, args...)
+	return entries, err
+}
+
+// Returns all jobs started before before or after after
+func (idx *fsIndex) outside(before int64, after int64) ([]fsIndexEntry, error) {
+	entries := make([]fsIndexEntry, 0)
+	err := idx.db.Select(&entries, `SELECT * FROM job WHERE start_time < ? OR start_time > ?`,
+		before, after)
+	return entries, err
+}
+
+func (idx *fsIndex) clusterInfo() (map[string]*clusterInfo, error) {
+	var rows []struct {
+		Cluster   string `db:"cluster"`
+		NumJobs   int    `db:"num_jobs"`
+		DateFirst int64  `db:"date_first"`
+		DateLast  int64  `db:"date_last"`
+		Size      int64  `db:"size"`
+	}
+	if err := idx.db.Select(&rows, `SELECT cluster, COUNT(*) AS num_jobs,
+		MIN(start_time) AS date_first, MAX(start_time) AS date_last, SUM(size) AS size
+		FROM job GROUP BY cluster`); err != nil {
+		return nil, err
+	}
+
+	ci := make(map[string]*clusterInfo)
+	for _, r := range rows {
+		ci[r.Cluster] = &clusterInfo{
+			numJobs:   r.NumJobs,
+			dateFirst: r.DateFirst,
+			dateLast:  r.DateLast,
+			diskSize:  float64(r.Size) * 1e-6,
+		}
+	}
+
+	return ci, nil
+}
+
+// Replaces the content of the index with the jobs found by walking the
+// archive directory tree. The index stays usable while it is rebuilt.
+func (idx *fsIndex) rebuild() (int, error) {
+	tx, err := idx.db.Beginx()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM job`); err != nil {
+		return 0, err
+	}
+
+	cnt := 0
+	clusters, err := os.ReadDir(idx.root)
+	if err != nil {
+		return 0, err
+	}
+	for _, cluster := range clusters {
+		if !cluster.IsDir() {
+			continue
+		}
+
+		lvl1Dirs, err := os.ReadDir(filepath.Join(idx.root, cluster.Name()))
+		if err != nil {
+			return cnt, err
+		}
+		for _, lvl1Dir := range lvl1Dirs {
+			if !lvl1Dir.IsDir() {
+				continue
+			}
+			lvl1, err := strconv.ParseInt(lvl1Dir.Name(), 10, 64)
+			if err != nil {
+				log.Warnf("JobArchive index: skip %s", filepath.Join(cluster.Name(), lvl1Dir.Name()))
+				continue
+			}
+
+			lvl2Dirs, err := os.ReadDir(filepath.Join(idx.root, cluster.Name(), lvl1Dir.Name()))
+			if err != nil {
+				return cnt, err
+			}
+			for _, lvl2Dir := range lvl2Dirs {
+				lvl2, err := strconv.ParseInt(lvl2Dir.Name(), 10, 64)
+				if err != nil || !lvl2Dir.IsDir() {
+					continue
+				}
+
+				dirpath := filepath.Join(idx.root, cluster.Name(), lvl1Dir.Name(), lvl2Dir.Name())
+				startTimeDirs, err := os.ReadDir(dirpath)
+				if err != nil {
+					return cnt, err
+				}
+				for _, startTimeDir := range startTimeDirs {
+					if !startTimeDir.IsDir() {
+						continue
+					}
+					startTime, err := strconv.ParseInt(startTimeDir.Name(), 10, 64)
+					if err != nil {
+						log.Warnf("JobArchive index: skip %s", filepath.Join(dirpath, startTimeDir.Name()))
+						continue
+					}
+
+					dir := filepath.Join(dirpath, startTimeDir.Name())
+					if !util.CheckFileExists(filepath.Join(dir, "meta.json")) {
+						log.Warnf("JobArchive index: no meta.json in %s", dir)
+						continue
+					}
+					e, err := idx.stat(cluster.Name(), lvl1*1000+lvl2, startTime, dir)
+					if err != nil {
+						return cnt, err
+					}
+					if err := idx.insert(tx, e); err != nil {
+						return cnt, err
+					}
+					cnt++
+				}
+			}
+		}
+	}
+
+	return cnt, tx.Commit()
+}
@@ -0,0 +1,117 @@
+// Copyright (C) NHR@FAU, University Erlangen-Nuremberg.
+// All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+package archive
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ClusterCockpit/cc-backend/internal/util"
+	"github.com/ClusterCockpit/cc-backend/pkg/schema"
+)
+
+func countJobs(ch <-chan JobContainer) int {
+	cnt := 0
+	for range ch {
+		cnt++
+	}
+	return cnt
+}
+
+func TestIterFilter(t *testing.T) {
+	var fsa FsArchive
+	if _, err := fsa.Init(json.RawMessage("{\"path\":\"testdata/archive\"}")); err != nil {
+		t.Fatal(err)
+	}
+
+	if cnt := countJobs(fsa.Iter(false, &IterFilter{Cluster: "emmy"})); cnt != 2 {
+		t.Errorf("expected 2 jobs on emmy, got %d", cnt)
+	}
+	if cnt := countJobs(fsa.Iter(false, &IterFilter{Cluster: "fritz"})); cnt != 0 {
+		t.Errorf("expected no jobs on fritz, got %d", cnt)
+	}
+	if cnt := countJobs(fsa.Iter(false, &IterFilter{StartTimeFrom: 1609000000})); cnt != 1 {
+		t.Errorf("expected 1 job after start time, got %d", cnt)
+	}
+}
+
+func TestFsIndex(t *testing.T) {
+	tmpdir := t.TempDir()
+	jobarchive := filepath.Join(tmpdir, "job-archive")
+	util.CopyDir("./testdata/archive/", jobarchive)
+	cfg := json.RawMessage(fmt.Sprintf("{\"path\": \"%s\"}", jobarchive))
+
+	var fsa FsArchive
+	if _, err := fsa.Init(cfg); err != nil {
+		t.Fatal(err)
+	}
+	if fsa.index != nil {
+		t.Fatal("archive must not have an index before it is built")
+	}
+	if cnt, err := fsa.RebuildIndex(); err != nil || cnt != 2 {
+		t.Fatalf("expected 2 indexed jobs, got %d (%v)", cnt, err)
+	}
+
+	// A reopened archive uses the existing index
+	fsa = FsArchive{}
+	if _, err := fsa.Init(cfg); err != nil {
+		t.Fatal(err)
+	}
+	if fsa.index == nil {
+		t.Fatal("archive index was not opened")
+	}
+	if cnt := countJobs(fsa.Iter(false, &IterFilter{StartTimeTo: 1609000000})); cnt != 1 {
+		t.Errorf("expected 1 job before start time, got %d", cnt)
+	}
+
+	job := schema.Job{StartTime: time.Unix(1608923076, 0)}
+	job.JobID = 1403244
+	job.Cluster = "emmy"
+	jobMeta, err := fsa.LoadJobMeta(&job)
+	if err != nil {
+		t.Fatal(err)
+	}
+	jobData, err := fsa.LoadJobData(&job)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Import a copy of the job with a new start time
+	jobMeta.StartTime = 1700000000
+	if err := fsa.ImportJob(jobMeta, &jobData); err != nil {
+		t.Fatal(err)
+	}
+	entries, err := fsa.index.query(&IterFilter{StartTimeFrom: 1700000000})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Compressed || entries[0].Size == 0 ||
+		entries[0].Location != filepath.Join("emmy", "1403", "244", "1700000000") {
+		t.Fatalf("unexpected index entries %+v", entries)
+	}
+
+	imported := entries[0].job()
+	fsa.Compress([]*schema.Job{imported})
+	entries, _ = fsa.index.query(&IterFilter{StartTimeFrom: 1700000000})
+	if len(entries) != 1 || !entries[0].Compressed {
+		t.Fatalf("compression not recorded in index %+v", entries)
+	}
+
+	fsa.CleanUp([]*schema.Job{imported})
+	if cnt := countJobs(fsa.Iter(false, nil)); cnt != 2 {
+		t.Errorf("expected 2 jobs after cleanup, got %d", cnt)
+	}
+
+	fsa.Clean(1609000000, 0)
+	if cnt := countJobs(fsa.Iter(false, nil)); cnt != 1 {
+		t.Errorf("expected 1 job after clean, got %d", cnt)
+	}
+	if util.CheckFileExists(getDirectory(&job, jobarchive)) {
+		t.Error("job directory was not removed")
+	}
+}
@@ -34,39 +34,43 @@ func DecodeJobData(r io.Reader, k string) (schema.JobData, error) {
 
 func DecodeJobStats(r io.Reader, k string) (schema.ScopedJobStats, error) {
 	jobData, err := DecodeJobData(r, k)
-	// Convert schema.JobData to schema.ScopedJobStats
 	if jobData != nil {
-		scopedJobStats := make(schema.ScopedJobStats)
-		for metric, metricData := range jobData {
-			if _, ok := scopedJobStats[metric]; !ok {
-				scopedJobStats[metric] = make(map[schema.MetricScope][]*schema.ScopedStats)
-			}
+		return JobDataToStats(jobData), nil
+	}
+	return nil, err
+}
 
-			for scope, jobMetric := range metricData {
-				if _, ok := scopedJobStats[metric][scope]; !ok {
-					scopedJobStats[metric][scope] = make([]*schema.ScopedStats, 0)
-				}
+// Convert schema.JobData to schema.ScopedJobStats
+func JobDataToStats(jobData schema.JobData) schema.ScopedJobStats {
+	scopedJobStats := make(schema.ScopedJobStats)
+	for metric, metricData := range jobData {
+		if _, ok := scopedJobStats[metric]; !ok {
+			scopedJobStats[metric] = make(map[schema.MetricScope][]*schema.ScopedStats)
+		}
 
-				for _, series := range jobMetric.Series {
-					scopedJobStats[metric][scope] = append(scopedJobStats[metric][scope], &schema.ScopedStats{
-						Hostname: series.Hostname,
-						Id:       series.Id,
-						Data:     &series.Statistics,
-					})
-				}
+		for scope, jobMetric := range metricData {
+			if _, ok := scopedJobStats[metric][scope]; !ok {
+				scopedJobStats[metric][scope] = make([]*schema.ScopedStats, 0)
+			}
+
+			for _, series := range jobMetric.Series {
+				scopedJobStats[metric][scope] = append(scopedJobStats[metric][scope], &schema.ScopedStats{
+					Hostname: series.Hostname,
+					Id:       series.Id,
+					Data:     &series.Statistics,
+				})
+			}
 
-				// So that one can later check len(scopedJobStats[metric][scope]): Remove from map if empty
-				if len(scopedJobStats[metric][scope]) == 0 {
-					delete(scopedJobStats[metric], scope)
-					if len(scopedJobStats[metric]) == 0 {
-						delete(scopedJobStats, metric)
-					}
+			// So that one can later check len(scopedJobStats[metric][scope]): Remove from map if empty
+			if len(scopedJobStats[metric][scope]) == 0 {
+				delete(scopedJobStats[metric], scope)
+				if len(scopedJobStats[metric]) == 0 {
+					delete(scopedJobStats, metric)
 				}
 			}
 		}
-		return scopedJobStats, nil
 	}
-	return nil, err
+	return scopedJobStats
 }
 
 func DecodeJobMeta(r io.Reader) (*schema.JobMeta, error) {
@@ -93,6 +97,16 @@ func DecodeCluster(r io.Reader) (*schema.Cluster, error) {
 	return &c, nil
 }
 
+func EncodeCluster(w io.Writer, c *schema.Cluster) error {
+	// Sanitize parameters
+	if err := json.NewEncoder(w).Encode(c); err != nil {
+		log.Warn("Error while encoding new cluster json")
+		return err
+	}
+
+	return nil
+}
+
 func EncodeJobData(w io.Writer, d *schema.JobData) error {
 	// Sanitize parameters
 	if err := json.NewEncoder(w).Encode(d); err != nil {
@@ -0,0 +1,244 @@
+// Copyright (C) NHR@FAU, University Erlangen-Nuremberg.
+// All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+package archive
+
+import (
+	"fmt"
+
+	"github.com/ClusterCockpit/cc-backend/pkg/log"
+	"github.com/ClusterCockpit/cc-backend/pkg/schema"
+)
+
+// A Migration upgrades the job archive format from version From to From+1.
+// Each transformation reports whether it changed its input. Transformations
+// that are not required for a step can be left nil.
+type Migration struct {
+	From        uint64
+	Description string
+	JobMeta     func(jobMeta *schema.JobMeta) (bool, error)
+	JobData     func(jobMeta *schema.JobMeta, jobData *schema.JobData) (bool, error)
+	Cluster     func(cluster *schema.Cluster) (bool, error)
+}
+
+// Result of a single migration step
+type MigrationStats struct {
+	From            uint64
+	Description     string
+	JobsChanged     int
+	ClustersChanged int
+}
+
+// Result of a complete archive migration
+type MigrationResult struct {
+	From   uint64
+	Jobs   int
+	Failed int
+	Steps  []*MigrationStats
+}
+
+var migrations = make(map[uint64]Migration)
+
+func init() {
+	// Version 2 introduced units split into prefix and base, and units for
+	// the performance properties of subclusters. Version 1 files are decoded
+	// with the old units converted, see schema.Unit, so all files are written
+	// again.
+	registerMigration(Migration{
+		From:        1,
+		Description: "Structured units",
+		JobMeta: func(jobMeta *schema.JobMeta) (bool, error) {
+			return true, nil
+		},
+		JobData: func(jobMeta *schema.JobMeta, jobData *schema.JobData) (bool, error) {
+			return true, nil
+		},
+		Cluster: migrateClusterV1,
+	})
+}
+
+func migrateClusterV1(cluster *schema.Cluster) (bool, error) {
+	for _, sc := range cluster.SubClusters {
+		if sc.FlopRateScalar.Unit.Base == "" {
+			sc.FlopRateScalar.Unit = schema.Unit{Prefix: "G", Base: "F/s"}
+		}
+		if sc.FlopRateSimd.Unit.Base == "" {
+			sc.FlopRateSimd.Unit = schema.Unit{Prefix: "G", Base: "F/s"}
+		}
+		if sc.MemoryBandwidth.Unit.Base == "" {
+			sc.MemoryBandwidth.Unit = schema.Unit{Prefix: "G", Base: "B/s"}
+		}
+	}
+
+	for _, mc := range cluster.MetricConfig {
+		if mc.Aggregation == "" {
+			log.Warnf("Migration: Metric %s of cluster %s has no aggregation, using sum", mc.Name, cluster.Name)
+			mc.Aggregation = "sum"
+		}
+	}
+
+	return true, nil
+}
+
+// Registers a version step. New steps are added together with an increment
+// of Version, there must be exactly one step per version.
+func registerMigration(m Migration) {
+	if _, ok := migrations[m.From]; ok {
+		log.Fatalf("ARCHIVE/MIGRATION > duplicate migration from version %d", m.From)
+	}
+	migrations[m.From] = m
+}
+
+// Returns the ordered migration steps required to upgrade from version to
+// the current Version.
+func migrationPath(version uint64) ([]Migration, error) {
+	if version > Version {
+		return nil, fmt.Errorf("archive version %d is newer than supported version %d", version, Version)
+	}
+
+	steps := make([]Migration, 0, Version-version)
+	for v := version; v < Version; v++ {
+		m, ok := migrations[v]
+		if !ok {
+			return nil, fmt.Errorf("no migration registered from version %d to %d", v, v+1)
+		}
+		steps = append(steps, m)
+	}
+
+	return steps, nil
+}
+
+// MigrateArchive upgrades all cluster configurations and jobs of src, which
+// has the format version, to the current Version and writes them to dst.
+// If src and dst are the same archive, only changed jobs are written back.
+// Jobs whose data cannot be loaded are counted as failed and left untouched.
+// With dryRun set nothing is written and the result only reports how many
+// jobs and clusters each step would change.
+func MigrateArchive(
+	src ArchiveBackend,
+	version uint64,
+	dst ArchiveBackend,
+	dryRun bool,
+) (*MigrationResult, error) {
+	steps, err := migrationPath(version)
+	if err != nil {
+		return nil, err
+	}
+
+	inPlace := src == dst
+	result := &MigrationResult{From: version}
+	loadData := !inPlace
+	for _, m := range steps {
+		result.Steps = append(result.Steps, &MigrationStats{From: m.From, Description: m.Description})
+		if m.JobData != nil {
+			loadData = true
+		}
+	}
+
+	for _, name := range src.GetClusters() {
+		cluster, err := src.LoadClusterCfg(name)
+		if err != nil {
+			log.Errorf("Migration: Loading cluster config for %s failed: %s", name, err.Error())
+			return result, err
+		}
+
+		changed := false
+		for i, m := range steps {
+			if m.Cluster == nil {
+				continue
+			}
+			c, err := m.Cluster(cluster)
+			if err != nil {
+				return result, fmt.Errorf("migrate cluster %s from version %d: %w", name, m.From, err)
+			}
+			if c {
+				result.Steps[i].ClustersChanged++
+				changed = true
+			}
+		}
+
+		if !dryRun && (changed || !inPlace) {
+			if err := dst.StoreClusterCfg(name, cluster); err != nil {
+				return result, err
+			}
+		}
+	}
+
+	for job := range src.Iter(loadData, nil) {
+		result.Jobs++
+		if job.Meta == nil || job.Meta.Cluster == "" {
+			result.Failed++
+			continue
+		}
+
+		// Writing a job without its data would lose the data
+		if loadData && (job.Data == nil || *job.Data == nil) {
+			log.Errorf("Migration: Loading data of job %d on %s failed",
+				job.Meta.JobID, job.Meta.Cluster)
+			result.Failed++
+			continue
+		}
+
+		metaChanged, dataChanged := false, false
+		failed := false
+		for i, m := range steps {
+			var mc, dc bool
+			var err error
+
+			if m.JobMeta != nil {
+				if mc, err = m.JobMeta(job.Meta); err != nil {
+					log.Errorf("Migration: Job %d on %s from version %d failed: %s",
+						job.Meta.JobID, job.Meta.Cluster, m.From, err.Error())
+					failed = true
+					break
+				}
+			}
+			if m.JobData != nil {
+				if dc, err = m.JobData(job.Meta, job.Data); err != nil {
+					log.Errorf("Migration: Job %d on %s from version %d failed: %s",
+						job.Meta.JobID, job.Meta.Cluster, m.From, err.Error())
+					failed = true
+					break
+				}
+			}
+
+			if mc || dc {
+				result.Steps[i].JobsChanged++
+			}
+			metaChanged = metaChanged || mc
+			dataChanged = dataChanged || dc
+		}
+
+		if failed {
+			result.Failed++
+			continue
+		}
+		if dryRun {
+			continue
+		}
+
+		switch {
+		case !inPlace || dataChanged:
+			err = dst.ImportJob(job.Meta, job.Data)
+		case metaChanged:
+			err = dst.StoreJobMeta(job.Meta)
+		default:
+			continue
+		}
+		if err != nil {
+			log.Errorf("Migration: Writing job %d on %s failed: %s",
+				job.Meta.JobID, job.Meta.Cluster, err.Error())
+			result.Failed++
+		}
+	}
+
+	if dryRun {
+		return result, nil
+	}
+	if result.Failed > 0 {
+		return result, fmt.Errorf("migration of %d jobs failed, archive version is not updated", result.Failed)
+	}
+
+	return result, dst.SetVersion(Version)
+}
@@ -0,0 +1,252 @@
+// Copyright (C) NHR@FAU, University Erlangen-Nuremberg.
+// All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+package archive
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ClusterCockpit/cc-backend/internal/util"
+	"github.com/ClusterCockpit/cc-backend/pkg/schema"
+)
+
+// Creates a copy of the test archive in format version 1, with units as
+// plain strings and subcluster performance properties without units.
+func setupMigration(t *testing.T) string {
+	jobarchive := filepath.Join(t.TempDir(), "job-archive")
+	util.CopyDir("./testdata/archive-v1/", jobarchive)
+	return jobarchive
+}
+
+func testJob() *schema.Job {
+	job := &schema.Job{StartTime: time.Unix(1608923076, 0)}
+	job.JobID = 1403244
+	job.Cluster = "emmy"
+	return job
+}
+
+// Checks that the migrated job and cluster have the current format
+func checkMigrated(t *testing.T, ar ArchiveBackend) {
+	t.Helper()
+
+	jobMeta, err := ar.LoadJobMeta(testJob())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u := jobMeta.Statistics["mem_bw"].Unit; u != (schema.Unit{Prefix: "G", Base: "B/s"}) {
+		t.Errorf("wrong unit of mem_bw statistics: %#v", u)
+	}
+	if u := jobMeta.Statistics["ipc"].Unit; u != (schema.Unit{Base: "IPC"}) {
+		t.Errorf("wrong unit of ipc statistics: %#v", u)
+	}
+
+	data, err := ar.LoadJobData(testJob())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u := data["flops_any"][schema.MetricScopeNode].Unit; u != (schema.Unit{Prefix: "G", Base: "F/s"}) {
+		t.Errorf("wrong unit of flops_any data: %#v", u)
+	}
+
+	cluster, err := ar.LoadClusterCfg("emmy")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sc := cluster.SubClusters[0]
+	if sc.FlopRateSimd != (schema.MetricValue{Unit: schema.Unit{Prefix: "G", Base: "F/s"}, Value: 112}) ||
+		sc.MemoryBandwidth != (schema.MetricValue{Unit: schema.Unit{Prefix: "G", Base: "B/s"}, Value: 24}) {
+		t.Errorf("wrong subcluster properties: %#v %#v", sc.FlopRateSimd, sc.MemoryBandwidth)
+	}
+	for _, mc := range cluster.MetricConfig {
+		if mc.Aggregation == "" {
+			t.Errorf("metric %s without aggregation", mc.Name)
+		}
+	}
+}
+
+func TestMigrationPath(t *testing.T) {
+	if steps, err := migrationPath(Version); err != nil || len(steps) != 0 {
+		t.Errorf("expected no steps for current version, got %d (%v)", len(steps), err)
+	}
+	if steps, err := migrationPath(1); err != nil || len(steps) != 1 {
+		t.Errorf("expected one step from version 1, got %d (%v)", len(steps), err)
+	}
+	if _, err := migrationPath(Version + 1); err == nil {
+		t.Error("expected error for newer archive version")
+	}
+	if _, err := migrationPath(0); err == nil {
+		t.Error("expected error for missing migration step")
+	}
+}
+
+func TestMigrateArchiveDryRun(t *testing.T) {
+	jobarchive := setupMigration(t)
+	cfg := json.RawMessage(fmt.Sprintf("{\"kind\": \"file\", \"path\": \"%s\"}", jobarchive))
+
+	ar, version, err := Open(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version != 1 {
+		t.Fatalf("unexpected version %d", version)
+	}
+
+	result, err := MigrateArchive(ar, version, ar, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Jobs != 1 || len(result.Steps) != 1 ||
+		result.Steps[0].JobsChanged != 1 || result.Steps[0].ClustersChanged != 1 {
+		t.Errorf("unexpected result %+v %+v", result, result.Steps[0])
+	}
+
+	if _, err := New(cfg); err == nil {
+		t.Error("dry run must not update the archive version")
+	}
+	b, err := os.ReadFile(filepath.Join(jobarchive, "emmy", "1403", "244", "1608923076", "meta.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(b, []byte(`"unit": "GB/s"`)) {
+		t.Error("dry run must not write jobs")
+	}
+}
+
+func TestMigrateArchiveInPlace(t *testing.T) {
+	jobarchive := setupMigration(t)
+	cfg := json.RawMessage(fmt.Sprintf("{\"kind\": \"file\", \"path\": \"%s\"}", jobarchive))
+
+	ar, version, err := Open(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := MigrateArchive(ar, version, ar, false); err != nil {
+		t.Fatal(err)
+	}
+
+	ar, err = New(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkMigrated(t, ar)
+
+	// The files are written in the current format
+	b, err := os.ReadFile(filepath.Join(jobarchive, "emmy", "1403", "244", "1608923076", "meta.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var raw struct {
+		Statistics map[string]struct {
+			Unit map[string]string `json:"unit"`
+		} `json:"statistics"`
+	}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		t.Fatalf("unit of meta.json not migrated: %s", err.Error())
+	}
+	if raw.Statistics["mem_bw"].Unit["prefix"] != "G" {
+		t.Errorf("wrong unit in meta.json: %v", raw.Statistics["mem_bw"].Unit)
+	}
+}
+
+func TestMigrateArchiveCopy(t *testing.T) {
+	jobarchive := setupMigration(t)
+
+	src, version, err := Open(json.RawMessage(fmt.Sprintf("{\"kind\": \"file\", \"path\": \"%s\"}", jobarchive)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dst, err := New(json.RawMessage(fmt.Sprintf("{\"kind\": \"sqlite\", \"dbPath\": \"%s\"}",
+		filepath.Join(t.TempDir(), "job-archive.db"))))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := MigrateArchive(src, version, dst, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Jobs != 1 || result.Failed != 0 {
+		t.Errorf("unexpected result %+v", result)
+	}
+	if len(dst.GetClusters()) != 1 {
+		t.Errorf("unexpected clusters %v", dst.GetClusters())
+	}
+	checkMigrated(t, dst)
+}
+
+func TestMigrateArchiveCompressed(t *testing.T) {
+	jobarchive := setupMigration(t)
+	cfg := json.RawMessage(fmt.Sprintf("{\"kind\": \"file\", \"path\": \"%s\"}", jobarchive))
+	dir := filepath.Join(jobarchive, "emmy", "1403", "244", "1608923076")
+	if err := util.CompressFile(filepath.Join(dir, "data.json"), filepath.Join(dir, "data.json.gz")); err != nil {
+		t.Fatal(err)
+	}
+
+	ar, version, err := Open(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := MigrateArchive(ar, version, ar, false); err != nil {
+		t.Fatal(err)
+	}
+
+	if util.CheckFileExists(filepath.Join(dir, "data.json")) ||
+		!util.CheckFileExists(filepath.Join(dir, "data.json.gz")) {
+		t.Error("in place migration must keep the data compressed")
+	}
+
+	ar, err = New(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkMigrated(t, ar)
+	if err := ar.Verify(testJob()); err != nil {
+		t.Errorf("checksums of migrated job: %s", err.Error())
+	}
+}
+
+func TestMigrateArchiveCorruptData(t *testing.T) {
+	jobarchive := setupMigration(t)
+	cfg := json.RawMessage(fmt.Sprintf("{\"kind\": \"file\", \"path\": \"%s\"}", jobarchive))
+	dir := filepath.Join(jobarchive, "emmy", "1403", "244", "1608923076")
+	if err := os.WriteFile(filepath.Join(dir, "data.json"), []byte("{\"flops_any\":"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ar, version, err := Open(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := MigrateArchive(ar, version, ar, false)
+	if err == nil {
+		t.Fatal("expected error for job with corrupt data")
+	}
+	if result.Jobs != 1 || result.Failed != 1 {
+		t.Errorf("unexpected result %+v", result)
+	}
+
+	if _, err := New(cfg); err == nil {
+		t.Error("failed migration must not update the archive version")
+	}
+	b, err := os.ReadFile(filepath.Join(dir, "data.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != "{\"flops_any\":" {
+		t.Errorf("data of failed job was overwritten: %s", string(b))
+	}
+	b, err = os.ReadFile(filepath.Join(dir, "meta.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(b, []byte(`"unit": "GB/s"`)) {
+		t.Error("meta of failed job was overwritten")
+	}
+}
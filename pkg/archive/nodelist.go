@@ -230,12 +230,11 @@ func ParseNodeList(raw string) (NodeList, error) {
 				nles := NLExprIntRanges{}
 
 				for _, part := range parts {
-					minus := strings.Index(part, "-")
-					if minus == -1 {
-						return nil, fmt.Errorf("ARCHIVE/NODELIST > no '-' found inside '[...]'")
+					// Single numbers are ranges with equal start and end
+					s1, s2 := part, part
+					if minus := strings.Index(part, "-"); minus != -1 {
+						s1, s2 = part[0:minus], part[minus+1:]
 					}
-
-					s1, s2 := part[0:minus], part[minus+1:]
 					if len(s1) != len(s2) || len(s1) == 0 {
 						return nil, fmt.Errorf("ARCHIVE/NODELIST > %v and %v are not of equal length or of length zero", s1, s2)
 					}
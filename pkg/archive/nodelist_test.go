@@ -73,3 +73,23 @@ func TestNodeListCommasOutsideBrackets(t *testing.T) {
 		t.Fatal("2")
 	}
 }
+
+func TestNodeListSingleNumbersInBrackets(t *testing.T) {
+	nl, err := ParseNodeList("f01[01-03,07],f0210")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if nl.Contains("f0104") || nl.Contains("f0106") || nl.Contains("f0108") {
+		t.Fatal("1")
+	}
+
+	if !nl.Contains("f0101") || !nl.Contains("f0103") || !nl.Contains("f0107") || !nl.Contains("f0210") {
+		t.Fatal("2")
+	}
+
+	hosts := nl.PrintList()
+	if len(hosts) != 5 || hosts[3] != "f0107" || nl.NodeCount() != 5 {
+		t.Fatalf("3: %v", hosts)
+	}
+}
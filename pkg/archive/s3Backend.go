@@ -4,10 +4,738 @@
 // license that can be found in the LICENSE file.
 package archive
 
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ClusterCockpit/cc-backend/internal/config"
+	"github.com/ClusterCockpit/cc-backend/internal/util"
+	"github.com/ClusterCockpit/cc-backend/pkg/log"
+	"github.com/ClusterCockpit/cc-backend/pkg/schema"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
 type S3ArchiveConfig struct {
-	Path string `json:"filePath"`
+	Endpoint     string `json:"endpoint"`
+	AccessKey    string `json:"accessKey"`
+	SecretKey    string `json:"secretKey"`
+	Bucket       string `json:"bucket"`
+	Region       string `json:"region"`
+	UsePathStyle bool   `json:"usePathStyle"`
 }
 
 type S3Archive struct {
-	path string
+	client   *s3.Client
+	bucket   string
+	clusters []string
+}
+
+// Maximum number of keys accepted by a single DeleteObjects request.
+const s3DeleteBatchSize = 1000
+
+// Object keys use the same layout as the directories of the file backend:
+// <cluster>/<jobId / 1000>/<jobId % 1000>/<startTime>/<file>
+func getS3Directory(job *schema.Job) string {
+	lvl1, lvl2 := fmt.Sprintf("%d", job.JobID/1000), fmt.Sprintf("%03d", job.JobID%1000)
+
+	return path.Join(
+		job.Cluster,
+		lvl1, lvl2,
+		strconv.FormatInt(job.StartTime.Unix(), 10))
+}
+
+func getS3Key(job *schema.Job, file string) string {
+	return path.Join(getS3Directory(job), file)
+}
+
+func isS3NotFound(err error) bool {
+	var nsk *types.NoSuchKey
+	var nf *types.NotFound
+	return errors.As(err, &nsk) || errors.As(err, &nf)
+}
+
+func (s3a *S3Archive) getObject(key string) ([]byte, error) {
+	out, err := s3a.client.GetObject(context.Background(), &s3.GetObjectInput{
+		Bucket: aws.String(s3a.bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer out.Body.Close()
+
+	return io.ReadAll(out.Body)
+}
+
+func (s3a *S3Archive) putObject(key string, b []byte) error {
+	_, err := s3a.client.PutObject(context.Background(), &s3.PutObjectInput{
+		Bucket:        aws.String(s3a.bucket),
+		Key:           aws.String(key),
+		Body:          bytes.NewReader(b),
+		ContentLength: aws.Int64(int64(len(b))),
+	})
+	return err
+}
+
+func (s3a *S3Archive) isEmpty() bool {
+	out, err := s3a.client.ListObjectsV2(context.Background(), &s3.ListObjectsV2Input{
+		Bucket:  aws.String(s3a.bucket),
+		MaxKeys: aws.Int32(1),
+	})
+	return err == nil && len(out.Contents) == 0
+}
+
+func (s3a *S3Archive) objectExists(key string) bool {
+	_, err := s3a.client.HeadObject(context.Background(), &s3.HeadObjectInput{
+		Bucket: aws.String(s3a.bucket),
+		Key:    aws.String(key),
+	})
+	return err == nil
+}
+
+// Calls fn for every object below prefix. Iteration stops on the first
+// error returned by the object store.
+func (s3a *S3Archive) listObjects(prefix string, fn func(obj types.Object)) error {
+	p := s3.NewListObjectsV2Paginator(s3a.client, &s3.ListObjectsV2Input{
+		Bucket: aws.String(s3a.bucket),
+		Prefix: aws.String(prefix),
+	})
+
+	for p.HasMorePages() {
+		page, err := p.NextPage(context.Background())
+		if err != nil {
+			return err
+		}
+		for _, obj := range page.Contents {
+			fn(obj)
+		}
+	}
+
+	return nil
+}
+
+// Returns the top level key prefixes of the bucket, which are the clusters.
+func (s3a *S3Archive) listClusters() ([]string, error) {
+	clusters := make([]string, 0)
+	p := s3.NewListObjectsV2Paginator(s3a.client, &s3.ListObjectsV2Input{
+		Bucket:    aws.String(s3a.bucket),
+		Delimiter: aws.String("/"),
+	})
+
+	for p.HasMorePages() {
+		page, err := p.NextPage(context.Background())
+		if err != nil {
+			return nil, err
+		}
+		for _, cp := range page.CommonPrefixes {
+			clusters = append(clusters, strings.TrimSuffix(aws.ToString(cp.Prefix), "/"))
+		}
+	}
+
+	return clusters, nil
+}
+
+func (s3a *S3Archive) deleteObjects(keys []string) error {
+	for start := 0; start < len(keys); start += s3DeleteBatchSize {
+		end := util.Min(start+s3DeleteBatchSize, len(keys))
+		objects := make([]types.ObjectIdentifier, 0, end-start)
+		for _, k := range keys[start:end] {
+			objects = append(objects, types.ObjectIdentifier{Key: aws.String(k)})
+		}
+
+		out, err := s3a.client.DeleteObjects(context.Background(), &s3.DeleteObjectsInput{
+			Bucket: aws.String(s3a.bucket),
+			Delete: &types.Delete{Objects: objects, Quiet: aws.Bool(true)},
+		})
+		if err != nil {
+			return err
+		}
+		if len(out.Errors) > 0 {
+			return fmt.Errorf("delete %s: %s", aws.ToString(out.Errors[0].Key), aws.ToString(out.Errors[0].Message))
+		}
+	}
+
+	return nil
+}
+
+func (s3a *S3Archive) deletePrefix(prefix string) error {
+	keys := make([]string, 0)
+	if err := s3a.listObjects(prefix, func(obj types.Object) {
+		keys = append(keys, aws.ToString(obj.Key))
+	}); err != nil {
+		return err
+	}
+
+	return s3a.deleteObjects(keys)
+}
+
+// Splits a job object key into its job directory and file name. Keys that
+// do not belong to a job (e.g. cluster.json) are reported with ok == false.
+func splitS3JobKey(key string) (dir string, file string, startTime int64, ok bool) {
+	parts := strings.Split(key, "/")
+	if len(parts) != 5 {
+		return "", "", 0, false
+	}
+
+	startTime, err := strconv.ParseInt(parts[3], 10, 64)
+	if err != nil {
+		return "", "", 0, false
+	}
+
+	return path.Join(parts[:4]...), parts[4], startTime, true
+}
+
+func (s3a *S3Archive) loadJobMeta(key string) (*schema.JobMeta, error) {
+	b, err := s3a.getObject(key)
+	if err != nil {
+		log.Errorf("s3Backend loadJobMeta() > get object error: %v", err)
+		return &schema.JobMeta{}, err
+	}
+	if config.Keys.Validate {
+		if err := schema.Validate(schema.Meta, bytes.NewReader(b)); err != nil {
+			return &schema.JobMeta{}, fmt.Errorf("validate job meta: %v", err)
+		}
+	}
+
+	return DecodeJobMeta(bytes.NewReader(b))
+}
+
+// Returns the raw, uncompressed data.json content of the job directory dir.
+func (s3a *S3Archive) loadJobDataRaw(dir string) ([]byte, string, error) {
+	key := path.Join(dir, "data.json.gz")
+	b, err := s3a.getObject(key)
+	if err == nil {
+		r, err := gzip.NewReader(bytes.NewReader(b))
+		if err != nil {
+			log.Errorf("s3Backend loadJobData() > gzip error: %v", err)
+			return nil, key, err
+		}
+		defer r.Close()
+
+		b, err = io.ReadAll(r)
+		return b, key, err
+	}
+	if !isS3NotFound(err) {
+		log.Errorf("s3Backend loadJobData() > get object error: %v", err)
+		return nil, key, err
+	}
+
+	key = path.Join(dir, "data.json")
+	b, err = s3a.getObject(key)
+	if err != nil {
+		log.Errorf("s3Backend loadJobData() > get object error: %v", err)
+		return nil, key, err
+	}
+
+	return b, key, nil
+}
+
+func (s3a *S3Archive) loadJobData(dir string) (schema.JobData, error) {
+	b, key, err := s3a.loadJobDataRaw(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	if config.Keys.Validate {
+		if err := schema.Validate(schema.Data, bytes.NewReader(b)); err != nil {
+			return schema.JobData{}, fmt.Errorf("validate job data: %v", err)
+		}
+	}
+
+	return DecodeJobData(bytes.NewReader(b), s3a.cacheKey(key))
+}
+
+func (s3a *S3Archive) cacheKey(key string) string {
+	return fmt.Sprintf("s3://%s/%s", s3a.bucket, key)
+}
+
+func (s3a *S3Archive) Init(rawConfig json.RawMessage) (uint64, error) {
+
+	var config S3ArchiveConfig
+	if err := json.Unmarshal(rawConfig, &config); err != nil {
+		log.Warnf("Init() > Unmarshal error: %#v", err)
+		return 0, err
+	}
+	if config.Bucket == "" {
+		err := fmt.Errorf("Init() : empty config.Bucket")
+		log.Errorf("Init() > config.Bucket error: %v", err)
+		return 0, err
+	}
+	if config.Region == "" {
+		config.Region = "us-east-1"
+	}
+
+	s3a.bucket = config.Bucket
+	s3a.client = s3.New(s3.Options{
+		Region:       config.Region,
+		UsePathStyle: config.UsePathStyle,
+		Credentials: credentials.NewStaticCredentialsProvider(
+			config.AccessKey, config.SecretKey, ""),
+		BaseEndpoint: func() *string {
+			if config.Endpoint == "" {
+				return nil
+			}
+			return aws.String(config.Endpoint)
+		}(),
+		// Many S3-compatible object stores do not support the
+		// default checksum trailers of recent SDK versions.
+		RequestChecksumCalculation: aws.RequestChecksumCalculationWhenRequired,
+		ResponseChecksumValidation: aws.ResponseChecksumValidationWhenRequired,
+	})
+
+	b, err := s3a.getObject("version.txt")
+	if isS3NotFound(err) && s3a.isEmpty() {
+		// An empty bucket is initialized as archive of the current version.
+		b = []byte(fmt.Sprintf("%d\n", Version))
+		err = s3a.putObject("version.txt", b)
+	}
+	if err != nil {
+		log.Warnf("s3Backend Init() - %v", err)
+		return 0, err
+	}
+
+	version, err := strconv.ParseUint(strings.TrimSuffix(string(b), "\n"), 10, 64)
+	if err != nil {
+		log.Errorf("s3Backend Init()- %v", err)
+		return 0, err
+	}
+
+	s3a.clusters, err = s3a.listClusters()
+	if err != nil {
+		log.Errorf("Init() > listClusters() error: %v", err)
+		return 0, err
+	}
+
+	return version, nil
+}
+
+func (s3a *S3Archive) SetVersion(version uint64) error {
+	return s3a.putObject("version.txt", []byte(fmt.Sprintf("%d\n", version)))
+}
+
+func (s3a *S3Archive) Info() {
+	fmt.Printf("Job archive s3://%s\n", s3a.bucket)
+
+	clusters, err := s3a.listClusters()
+	if err != nil {
+		log.Fatalf("Reading clusters failed: %s", err.Error())
+	}
+
+	ci := make(map[string]*clusterInfo)
+
+	for _, cluster := range clusters {
+		ci[cluster] = &clusterInfo{dateFirst: time.Now().Unix()}
+
+		if err := s3a.listObjects(cluster+"/", func(obj types.Object) {
+			_, file, startTime, ok := splitS3JobKey(aws.ToString(obj.Key))
+			if !ok {
+				return
+			}
+
+			ci[cluster].diskSize += float64(aws.ToInt64(obj.Size)) * 1e-6
+			if file == "meta.json" {
+				ci[cluster].numJobs++
+				ci[cluster].dateFirst = util.Min(ci[cluster].dateFirst, startTime)
+				ci[cluster].dateLast = util.Max(ci[cluster].dateLast, startTime)
+			}
+		}); err != nil {
+			log.Fatalf("Reading jobs failed: %s", err.Error())
+		}
+	}
+
+	cit := clusterInfo{dateFirst: time.Now().Unix()}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.Debug)
+	fmt.Fprintln(w, "cluster\t#jobs\tfrom\tto\tdu (MB)")
+	for cluster, clusterInfo := range ci {
+		fmt.Fprintf(w, "%s\t%d\t%s\t%s\t%.2f\n", cluster,
+			clusterInfo.numJobs,
+			time.Unix(clusterInfo.dateFirst, 0),
+			time.Unix(clusterInfo.dateLast, 0),
+			clusterInfo.diskSize)
+
+		cit.numJobs += clusterInfo.numJobs
+		cit.dateFirst = util.Min(cit.dateFirst, clusterInfo.dateFirst)
+		cit.dateLast = util.Max(cit.dateLast, clusterInfo.dateLast)
+		cit.diskSize += clusterInfo.diskSize
+	}
+
+	fmt.Fprintf(w, "TOTAL\t%d\t%s\t%s\t%.2f\n",
+		cit.numJobs, time.Unix(cit.dateFirst, 0), time.Unix(cit.dateLast, 0), cit.diskSize)
+	w.Flush()
+}
+
+func (s3a *S3Archive) Exists(job *schema.Job) bool {
+	return s3a.objectExists(getS3Key(job, "meta.json"))
+}
+
+func (s3a *S3Archive) Clean(before int64, after int64) {
+
+	if after == 0 {
+		after = math.MaxInt64
+	}
+
+	clusters, err := s3a.listClusters()
+	if err != nil {
+		log.Fatalf("Reading clusters failed: %s", err.Error())
+	}
+
+	for _, cluster := range clusters {
+		keys := make([]string, 0)
+		if err := s3a.listObjects(cluster+"/", func(obj types.Object) {
+			key := aws.ToString(obj.Key)
+			_, _, startTime, ok := splitS3JobKey(key)
+			if !ok {
+				return
+			}
+
+			if startTime < before || startTime > after {
+				keys = append(keys, key)
+			}
+		}); err != nil {
+			log.Fatalf("Reading jobs failed: %s", err.Error())
+		}
+
+		if err := s3a.deleteObjects(keys); err != nil {
+			log.Errorf("JobArchive Clean() error: %v", err)
+		}
+	}
+}
+
+// Move copies all objects of the jobs below the key prefix location and
+// removes the originals afterwards. The prefix is relative to the bucket root.
+func (s3a *S3Archive) Move(jobs []*schema.Job, location string) {
+	for _, job := range jobs {
+		source := getS3Directory(job)
+		keys := make([]string, 0)
+
+		if err := s3a.listObjects(source+"/", func(obj types.Object) {
+			keys = append(keys, aws.ToString(obj.Key))
+		}); err != nil {
+			log.Errorf("JobArchive Move() error: %v", err)
+			continue
+		}
+
+		moved := make([]string, 0, len(keys))
+		for _, key := range keys {
+			target := strings.TrimPrefix(path.Join(location, key), "/")
+			if _, err := s3a.client.CopyObject(context.Background(), &s3.CopyObjectInput{
+				Bucket:     aws.String(s3a.bucket),
+				CopySource: aws.String(s3a.bucket + "/" + key),
+				Key:        aws.String(target),
+			}); err != nil {
+				log.Errorf("JobArchive Move() error: %v", err)
+				continue
+			}
+			moved = append(moved, key)
+		}
+
+		if err := s3a.deleteObjects(moved); err != nil {
+			log.Errorf("JobArchive Move() error: %v", err)
+		}
+	}
+}
+
+func (s3a *S3Archive) CleanUp(jobs []*schema.Job) {
+	start := time.Now()
+	for _, job := range jobs {
+		if err := s3a.deletePrefix(getS3Directory(job) + "/"); err != nil {
+			log.Errorf("JobArchive Cleanup() error: %v", err)
+		}
+	}
+
+	log.Infof("Retention Service - Remove %d files in %s", len(jobs), time.Since(start))
+}
+
+func (s3a *S3Archive) Compress(jobs []*schema.Job) {
+	var cnt int
+	start := time.Now()
+
+	for _, job := range jobs {
+		keyIn := getS3Key(job, "data.json")
+		b, err := s3a.getObject(keyIn)
+		if err != nil || len(b) <= 2000 {
+			continue
+		}
+
+		var buf bytes.Buffer
+		gzipWriter := gzip.NewWriter(&buf)
+		if _, err := gzipWriter.Write(b); err != nil {
+			log.Errorf("JobArchive Compress() error: %v", err)
+			continue
+		}
+		if err := gzipWriter.Close(); err != nil {
+			log.Errorf("JobArchive Compress() error: %v", err)
+			continue
+		}
+
+		if err := s3a.putObject(getS3Key(job, "data.json.gz"), buf.Bytes()); err != nil {
+			log.Errorf("JobArchive Compress() error: %v", err)
+			continue
+		}
+		if err := s3a.deleteObjects([]string{keyIn}); err != nil {
+			log.Errorf("JobArchive Compress() error: %v", err)
+		}
+		cnt++
+
+		if checksums, err := s3a.readChecksums(job); err == nil {
+			delete(checksums, "data.json")
+			checksums["data.json.gz"] = computeChecksum(buf.Bytes())
+			if err := s3a.putObject(getS3Key(job, checksumFile), checksums.encode()); err != nil {
+				log.Errorf("JobArchive Compress() error: %v", err)
+			}
+		}
+	}
+
+	log.Infof("Compression Service - %d files took %s", cnt, time.Since(start))
+}
+
+func (s3a *S3Archive) CompressLast(starttime int64) int64 {
+
+	key := "compress.txt"
+	b, err := s3a.getObject(key)
+	if err != nil {
+		log.Errorf("s3Backend Compress - %v", err)
+		s3a.putObject(key, []byte(fmt.Sprintf("%d", starttime)))
+		return starttime
+	}
+	last, err := strconv.ParseInt(strings.TrimSuffix(string(b), "\n"), 10, 64)
+	if err != nil {
+		log.Errorf("s3Backend Compress - %v", err)
+		return starttime
+	}
+
+	log.Infof("s3Backend Compress - start %d last %d", starttime, last)
+	s3a.putObject(key, []byte(fmt.Sprintf("%d", starttime)))
+	return last
+}
+
+func (s3a *S3Archive) readChecksums(job *schema.Job) (Checksums, error) {
+	b, err := s3a.getObject(getS3Key(job, checksumFile))
+	if isS3NotFound(err) {
+		return nil, ErrNoChecksums
+	} else if err != nil {
+		return nil, err
+	}
+
+	return decodeChecksums(b)
+}
+
+func (s3a *S3Archive) Verify(job *schema.Job) error {
+	checksums, err := s3a.readChecksums(job)
+	if err != nil {
+		return err
+	}
+
+	for file := range checksums {
+		b, err := s3a.getObject(getS3Key(job, file))
+		if err != nil {
+			return fmt.Errorf("%s: %w", file, err)
+		}
+		if err := checksums.verify(file, b); err != nil {
+			return err
+		}
+	}
+
+	return checksums.hasJobData()
+}
+
+func (s3a *S3Archive) LoadJobData(job *schema.Job) (schema.JobData, error) {
+	return s3a.loadJobData(getS3Directory(job))
+}
+
+func (s3a *S3Archive) LoadJobStats(job *schema.Job) (schema.ScopedJobStats, error) {
+	b, key, err := s3a.loadJobDataRaw(getS3Directory(job))
+	if err != nil {
+		return nil, err
+	}
+
+	if config.Keys.Validate {
+		if err := schema.Validate(schema.Data, bytes.NewReader(b)); err != nil {
+			return nil, fmt.Errorf("validate job data: %v", err)
+		}
+	}
+
+	return DecodeJobStats(bytes.NewReader(b), s3a.cacheKey(key))
+}
+
+func (s3a *S3Archive) LoadJobMeta(job *schema.Job) (*schema.JobMeta, error) {
+	return s3a.loadJobMeta(getS3Key(job, "meta.json"))
+}
+
+func (s3a *S3Archive) LoadClusterCfg(name string) (*schema.Cluster, error) {
+
+	b, err := s3a.getObject(path.Join(name, "cluster.json"))
+	if err != nil {
+		log.Errorf("LoadClusterCfg() > get object error: %v", err)
+		return &schema.Cluster{}, err
+	}
+	if config.Keys.Validate {
+		if err := schema.Validate(schema.ClusterCfg, bytes.NewReader(b)); err != nil {
+			log.Warnf("Validate cluster config: %v\n", err)
+			return &schema.Cluster{}, fmt.Errorf("validate cluster config: %v", err)
+		}
+	}
+
+	return DecodeCluster(bytes.NewReader(b))
+}
+
+func (s3a *S3Archive) StoreClusterCfg(name string, cfg *schema.Cluster) error {
+	var buf bytes.Buffer
+	if err := EncodeCluster(&buf, cfg); err != nil {
+		log.Error("Error while encoding cluster config to cluster.json object")
+		return err
+	}
+	if err := s3a.putObject(path.Join(name, "cluster.json"), buf.Bytes()); err != nil {
+		log.Error("Error while uploading cluster.json object")
+		return err
+	}
+
+	if !slices.Contains(s3a.clusters, name) {
+		s3a.clusters = append(s3a.clusters, name)
+	}
+
+	return nil
+}
+
+func (s3a *S3Archive) Iter(loadMetricData bool, filter *IterFilter) <-chan JobContainer {
+
+	ch := make(chan JobContainer)
+	go func() {
+		clusters, err := s3a.listClusters()
+		if err != nil {
+			log.Fatalf("Reading clusters failed: %s", err.Error())
+		}
+
+		for _, cluster := range clusters {
+			if !filter.matchCluster(cluster) {
+				continue
+			}
+
+			dirs := make([]string, 0)
+			if err := s3a.listObjects(cluster+"/", func(obj types.Object) {
+				dir, file, startTime, ok := splitS3JobKey(aws.ToString(obj.Key))
+				if ok && file == "meta.json" && filter.matchStartTime(startTime) {
+					dirs = append(dirs, dir)
+				}
+			}); err != nil {
+				log.Fatalf("Reading jobs failed: %s", err.Error())
+			}
+
+			for _, dir := range dirs {
+				job, err := s3a.loadJobMeta(path.Join(dir, "meta.json"))
+				if err != nil {
+					log.Errorf("in %s: %s", dir, err.Error())
+				}
+
+				if loadMetricData {
+					data, err := s3a.loadJobData(dir)
+					if err != nil {
+						log.Errorf("in %s: %s", dir, err.Error())
+					}
+					ch <- JobContainer{Meta: job, Data: &data}
+				} else {
+					ch <- JobContainer{Meta: job, Data: nil}
+				}
+			}
+		}
+		close(ch)
+	}()
+	return ch
+}
+
+func (s3a *S3Archive) StoreJobMeta(jobMeta *schema.JobMeta) error {
+
+	job := schema.Job{
+		BaseJob:       jobMeta.BaseJob,
+		StartTime:     time.Unix(jobMeta.StartTime, 0),
+		StartTimeUnix: jobMeta.StartTime,
+	}
+
+	var buf bytes.Buffer
+	if err := EncodeJobMeta(&buf, jobMeta); err != nil {
+		log.Error("Error while encoding job metadata to meta.json object")
+		return err
+	}
+	if err := s3a.putObject(getS3Key(&job, "meta.json"), buf.Bytes()); err != nil {
+		log.Error("Error while uploading meta.json object")
+		return err
+	}
+
+	// Jobs archived without checksums only get a checksum for meta.json
+	checksums, err := s3a.readChecksums(&job)
+	if err != nil {
+		checksums = make(Checksums)
+	}
+	checksums["meta.json"] = computeChecksum(buf.Bytes())
+
+	return s3a.putObject(getS3Key(&job, checksumFile), checksums.encode())
+}
+
+func (s3a *S3Archive) GetClusters() []string {
+	return s3a.clusters
+}
+
+func (s3a *S3Archive) ImportJob(
+	jobMeta *schema.JobMeta,
+	jobData *schema.JobData) error {
+
+	job := schema.Job{
+		BaseJob:       jobMeta.BaseJob,
+		StartTime:     time.Unix(jobMeta.StartTime, 0),
+		StartTimeUnix: jobMeta.StartTime,
+	}
+
+	checksums := make(Checksums)
+	var buf bytes.Buffer
+	if err := EncodeJobMeta(&buf, jobMeta); err != nil {
+		log.Error("Error while encoding job metadata to meta.json object")
+		return err
+	}
+	if err := s3a.putObject(getS3Key(&job, "meta.json"), buf.Bytes()); err != nil {
+		log.Error("Error while uploading meta.json object")
+		return err
+	}
+	checksums["meta.json"] = computeChecksum(buf.Bytes())
+
+	buf.Reset()
+	if err := EncodeJobData(&buf, jobData); err != nil {
+		log.Error("Error while encoding job metricdata to data.json object")
+		return err
+	}
+	if err := s3a.putObject(getS3Key(&job, "data.json"), buf.Bytes()); err != nil {
+		log.Error("Error while uploading data.json object")
+		return err
+	}
+	checksums["data.json"] = computeChecksum(buf.Bytes())
+
+	// A compressed version from an earlier import would shadow the new data
+	if s3a.objectExists(getS3Key(&job, "data.json.gz")) {
+		if err := s3a.deleteObjects([]string{getS3Key(&job, "data.json.gz")}); err != nil {
+			log.Error("Error while removing outdated data.json.gz object")
+			return err
+		}
+	}
+
+	if err := s3a.putObject(getS3Key(&job, checksumFile), checksums.encode()); err != nil {
+		log.Errorf("Error while uploading %s object", checksumFile)
+		return err
+	}
+
+	return nil
 }
@@ -0,0 +1,229 @@
+// Copyright (C) NHR@FAU, University Erlangen-Nuremberg.
+// All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+package archive
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ClusterCockpit/cc-backend/pkg/schema"
+	"github.com/johannesboyne/gofakes3"
+	"github.com/johannesboyne/gofakes3/backend/s3mem"
+)
+
+// Starts an in-process fake S3 server and uploads the test archive into the
+// bucket "job-archive".
+func setupS3(t *testing.T) *S3Archive {
+	backend := s3mem.New()
+	faker := gofakes3.New(backend)
+	ts := httptest.NewServer(faker.Server())
+	t.Cleanup(ts.Close)
+
+	if err := backend.CreateBucket("job-archive"); err != nil {
+		t.Fatal(err)
+	}
+
+	root := "testdata/archive"
+	err := filepath.Walk(root, func(p string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		b, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		key, _ := filepath.Rel(root, p)
+		_, err = backend.PutObject("job-archive", filepath.ToSlash(key),
+			map[string]string{}, bytes.NewReader(b), int64(len(b)))
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var s3a S3Archive
+	cfg := fmt.Sprintf(`{"kind": "s3", "endpoint": "%s", "bucket": "job-archive",
+	"accessKey": "test", "secretKey": "test", "usePathStyle": true}`, ts.URL)
+	version, err := s3a.Init(json.RawMessage(cfg))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version != Version {
+		t.Fatalf("unexpected version %d", version)
+	}
+
+	return &s3a
+}
+
+func TestS3InitMissingBucket(t *testing.T) {
+	var s3a S3Archive
+	_, err := s3a.Init(json.RawMessage("{\"kind\":\"s3\"}"))
+	if err == nil {
+		t.Fatal("expected error for config without bucket")
+	}
+}
+
+func TestS3Init(t *testing.T) {
+	s3a := setupS3(t)
+
+	if len(s3a.clusters) != 3 || s3a.clusters[1] != "emmy" {
+		t.Errorf("unexpected clusters %v", s3a.clusters)
+	}
+}
+
+func TestS3LoadJobMeta(t *testing.T) {
+	s3a := setupS3(t)
+
+	jobIn := schema.Job{BaseJob: schema.JobDefaults}
+	jobIn.StartTime = time.Unix(1608923076, 0)
+	jobIn.JobID = 1403244
+	jobIn.Cluster = "emmy"
+
+	job, err := s3a.LoadJobMeta(&jobIn)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if job.JobID != 1403244 {
+		t.Fail()
+	}
+	if int(job.NumNodes) != len(job.Resources) {
+		t.Fail()
+	}
+	if job.StartTime != 1608923076 {
+		t.Fail()
+	}
+}
+
+func TestS3LoadJobData(t *testing.T) {
+	s3a := setupS3(t)
+
+	jobIn := schema.Job{BaseJob: schema.JobDefaults}
+	jobIn.StartTime = time.Unix(1608923076, 0)
+	jobIn.JobID = 1403244
+	jobIn.Cluster = "emmy"
+
+	data, err := s3a.LoadJobData(&jobIn)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(data) == 0 {
+		t.Fatal("no metrics loaded")
+	}
+	for _, scopes := range data {
+		if _, exists := scopes[schema.MetricScopeNode]; !exists {
+			t.Fail()
+		}
+	}
+
+	stats, err := s3a.LoadJobStats(&jobIn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stats) == 0 {
+		t.Fatal("no statistics loaded")
+	}
+}
+
+func TestS3LoadCluster(t *testing.T) {
+	s3a := setupS3(t)
+
+	cfg, err := s3a.LoadClusterCfg("emmy")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.SubClusters[0].CoresPerSocket != 4 {
+		t.Fail()
+	}
+}
+
+func TestS3Iter(t *testing.T) {
+	s3a := setupS3(t)
+
+	cnt := 0
+	for job := range s3a.Iter(true, nil) {
+		if job.Meta.Cluster != "emmy" {
+			t.Fail()
+		}
+		if job.Data == nil || len(*job.Data) == 0 {
+			t.Errorf("no metric data for job %d", job.Meta.JobID)
+		}
+		cnt++
+	}
+
+	if cnt != 2 {
+		t.Errorf("expected 2 jobs, got %d", cnt)
+	}
+}
+
+func TestS3ImportCompressCleanUp(t *testing.T) {
+	s3a := setupS3(t)
+
+	jobIn := schema.Job{BaseJob: schema.JobDefaults}
+	jobIn.StartTime = time.Unix(1608923076, 0)
+	jobIn.JobID = 1403244
+	jobIn.Cluster = "emmy"
+
+	meta, err := s3a.LoadJobMeta(&jobIn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := s3a.LoadJobData(&jobIn)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	meta.JobID = 1403245
+	job := schema.Job{BaseJob: meta.BaseJob, StartTime: time.Unix(meta.StartTime, 0)}
+	if err := s3a.ImportJob(meta, &data); err != nil {
+		t.Fatal(err)
+	}
+	if !s3a.Exists(&job) || !s3a.objectExists(getS3Key(&job, "data.json")) {
+		t.Fatal("imported job does not exist")
+	}
+
+	s3a.Compress([]*schema.Job{&job})
+	if s3a.objectExists(getS3Key(&job, "data.json")) ||
+		!s3a.objectExists(getS3Key(&job, "data.json.gz")) {
+		t.Fatal("job data was not compressed")
+	}
+	if _, err := s3a.LoadJobData(&job); err != nil {
+		t.Fatal(err)
+	}
+
+	s3a.CleanUp([]*schema.Job{&job})
+	if s3a.Exists(&job) || !s3a.Exists(&jobIn) {
+		t.Fatal("unexpected state after cleanup")
+	}
+}
+
+func TestS3Clean(t *testing.T) {
+	s3a := setupS3(t)
+
+	s3a.Clean(1609000000, 0)
+
+	cnt := 0
+	for job := range s3a.Iter(false, nil) {
+		if job.Meta.StartTime < 1609000000 {
+			t.Errorf("job %d was not removed", job.Meta.JobID)
+		}
+		cnt++
+	}
+	if cnt != 1 {
+		t.Errorf("expected 1 job, got %d", cnt)
+	}
+
+	if _, err := s3a.LoadClusterCfg("emmy"); err != nil {
+		t.Fatal(err)
+	}
+}
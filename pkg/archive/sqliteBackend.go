@@ -0,0 +1,631 @@
+// Copyright (C) NHR@FAU, University Erlangen-Nuremberg.
+// All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+package archive
+
+import (
+	"bytes"
+	"compress/gzip"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ClusterCockpit/cc-backend/internal/config"
+	"github.com/ClusterCockpit/cc-backend/internal/util"
+	"github.com/ClusterCockpit/cc-backend/pkg/log"
+	"github.com/ClusterCockpit/cc-backend/pkg/schema"
+	"github.com/jmoiron/sqlx"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+type SqliteArchiveConfig struct {
+	DBPath string `json:"dbPath"`
+}
+
+type SqliteArchive struct {
+	db       *sqlx.DB
+	path     string
+	clusters []string
+}
+
+const sqliteArchiveSchema = `
+CREATE TABLE IF NOT EXISTS archive_info (
+	key   VARCHAR(255) PRIMARY KEY,
+	value TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS cluster (
+	name   VARCHAR(255) PRIMARY KEY,
+	config BLOB NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS job (
+	cluster         VARCHAR(255) NOT NULL,
+	job_id          BIGINT NOT NULL,
+	start_time      BIGINT NOT NULL,
+	meta            BLOB NOT NULL,
+	data            BLOB,
+	stats           BLOB,
+	data_compressed TINYINT NOT NULL DEFAULT 0,
+	meta_checksum   VARCHAR(64),
+	data_checksum   VARCHAR(64),
+	PRIMARY KEY (cluster, job_id, start_time)
+);
+
+CREATE INDEX IF NOT EXISTS job_start_time ON job (start_time);
+CREATE INDEX IF NOT EXISTS job_cluster_start_time ON job (cluster, start_time);
+`
+
+func openSqliteArchive(path string) (*sqlx.DB, error) {
+	db, err := sqlx.Open("sqlite3", path+"?_journal=WAL&_timeout=5000")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := db.Exec(sqliteArchiveSchema); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return db, nil
+}
+
+func compressBlob(b []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write(b); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func uncompressBlob(b []byte) ([]byte, error) {
+	r, err := gzip.NewReader(bytes.NewReader(b))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	return io.ReadAll(r)
+}
+
+func (sa *SqliteArchive) cacheKey(job *schema.Job) string {
+	return fmt.Sprintf("sqlite://%s/%s/%d/%d", sa.path, job.Cluster, job.JobID, job.StartTime.Unix())
+}
+
+func (sa *SqliteArchive) getInfo(key string) (string, error) {
+	var value string
+	err := sa.db.Get(&value, `SELECT value FROM archive_info WHERE key = ?`, key)
+	return value, err
+}
+
+func (sa *SqliteArchive) setInfo(key string, value string) error {
+	_, err := sa.db.Exec(`INSERT INTO archive_info (key, value) VALUES (?, ?)
+		ON CONFLICT (key) DO UPDATE SET value = excluded.value`, key, value)
+	return err
+}
+
+func (sa *SqliteArchive) loadClusters() error {
+	sa.clusters = make([]string, 0)
+	return sa.db.Select(&sa.clusters,
+		`SELECT name FROM cluster UNION SELECT DISTINCT cluster FROM job ORDER BY 1`)
+}
+
+func (sa *SqliteArchive) Init(rawConfig json.RawMessage) (uint64, error) {
+
+	var config SqliteArchiveConfig
+	if err := json.Unmarshal(rawConfig, &config); err != nil {
+		log.Warnf("Init() > Unmarshal error: %#v", err)
+		return 0, err
+	}
+	if config.DBPath == "" {
+		err := fmt.Errorf("Init() : empty config.DBPath")
+		log.Errorf("Init() > config.DBPath error: %v", err)
+		return 0, err
+	}
+	sa.path = config.DBPath
+
+	db, err := openSqliteArchive(sa.path)
+	if err != nil {
+		log.Errorf("sqliteBackend Init() - %v", err)
+		return 0, err
+	}
+	sa.db = db
+
+	// A freshly created archive database gets the current version.
+	value, err := sa.getInfo("version")
+	if errors.Is(err, sql.ErrNoRows) {
+		value = strconv.FormatUint(Version, 10)
+		if err = sa.setInfo("version", value); err != nil {
+			log.Errorf("sqliteBackend Init() - %v", err)
+			return 0, err
+		}
+	} else if err != nil {
+		log.Errorf("sqliteBackend Init() - %v", err)
+		return 0, err
+	}
+
+	version, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		log.Errorf("sqliteBackend Init()- %v", err)
+		return 0, err
+	}
+
+	if err := sa.loadClusters(); err != nil {
+		log.Errorf("Init() > loadClusters() error: %v", err)
+		return 0, err
+	}
+
+	return version, nil
+}
+
+func (sa *SqliteArchive) SetVersion(version uint64) error {
+	return sa.setInfo("version", strconv.FormatUint(version, 10))
+}
+
+func (sa *SqliteArchive) Info() {
+	fmt.Printf("Job archive %s\n", sa.path)
+
+	rows, err := sa.db.Query(`SELECT cluster, COUNT(*), MIN(start_time), MAX(start_time),
+		SUM(LENGTH(meta) + COALESCE(LENGTH(data), 0) + COALESCE(LENGTH(stats), 0))
+		FROM job GROUP BY cluster ORDER BY cluster`)
+	if err != nil {
+		log.Fatalf("Reading jobs failed: %s", err.Error())
+	}
+	defer rows.Close()
+
+	cit := clusterInfo{dateFirst: time.Now().Unix()}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.Debug)
+	fmt.Fprintln(w, "cluster\t#jobs\tfrom\tto\tdu (MB)")
+	for rows.Next() {
+		var cluster string
+		var ci clusterInfo
+		var size int64
+		if err := rows.Scan(&cluster, &ci.numJobs, &ci.dateFirst, &ci.dateLast, &size); err != nil {
+			log.Fatalf("Reading jobs failed: %s", err.Error())
+		}
+		ci.diskSize = float64(size) * 1e-6
+
+		fmt.Fprintf(w, "%s\t%d\t%s\t%s\t%.2f\n", cluster,
+			ci.numJobs,
+			time.Unix(ci.dateFirst, 0),
+			time.Unix(ci.dateLast, 0),
+			ci.diskSize)
+
+		cit.numJobs += ci.numJobs
+		cit.dateFirst = util.Min(cit.dateFirst, ci.dateFirst)
+		cit.dateLast = util.Max(cit.dateLast, ci.dateLast)
+		cit.diskSize += ci.diskSize
+	}
+
+	fmt.Fprintf(w, "TOTAL\t%d\t%s\t%s\t%.2f\n",
+		cit.numJobs, time.Unix(cit.dateFirst, 0), time.Unix(cit.dateLast, 0), cit.diskSize)
+	w.Flush()
+}
+
+func (sa *SqliteArchive) Exists(job *schema.Job) bool {
+	var cnt int
+	if err := sa.db.Get(&cnt, `SELECT COUNT(*) FROM job
+		WHERE cluster = ? AND job_id = ? AND start_time = ?`,
+		job.Cluster, job.JobID, job.StartTime.Unix()); err != nil {
+		log.Errorf("sqliteBackend Exists() - %v", err)
+		return false
+	}
+
+	return cnt > 0
+}
+
+func (sa *SqliteArchive) Clean(before int64, after int64) {
+
+	if after == 0 {
+		after = math.MaxInt64
+	}
+
+	res, err := sa.db.Exec(`DELETE FROM job WHERE start_time < ? OR start_time > ?`, before, after)
+	if err != nil {
+		log.Errorf("JobArchive Clean() error: %v", err)
+		return
+	}
+
+	cnt, _ := res.RowsAffected()
+	log.Infof("JobArchive Clean() - removed %d jobs", cnt)
+}
+
+// Move transfers the jobs into the sqlite archive database at path, which is
+// created if it does not exist yet.
+func (sa *SqliteArchive) Move(jobs []*schema.Job, path string) {
+	target, err := openSqliteArchive(path)
+	if err != nil {
+		log.Errorf("JobArchive Move() error: %v", err)
+		return
+	}
+	defer target.Close()
+
+	for _, job := range jobs {
+		var row struct {
+			Meta           []byte         `db:"meta"`
+			Data           []byte         `db:"data"`
+			Stats          []byte         `db:"stats"`
+			DataCompressed bool           `db:"data_compressed"`
+			MetaChecksum   sql.NullString `db:"meta_checksum"`
+			DataChecksum   sql.NullString `db:"data_checksum"`
+		}
+		if err := sa.db.Get(&row, `SELECT meta, data, stats, data_compressed, meta_checksum, data_checksum
+			FROM job WHERE cluster = ? AND job_id = ? AND start_time = ?`,
+			job.Cluster, job.JobID, job.StartTime.Unix()); err != nil {
+			log.Errorf("JobArchive Move() error: %v", err)
+			continue
+		}
+
+		if _, err := target.Exec(`INSERT OR REPLACE INTO job
+			(cluster, job_id, start_time, meta, data, stats, data_compressed, meta_checksum, data_checksum)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			job.Cluster, job.JobID, job.StartTime.Unix(),
+			row.Meta, row.Data, row.Stats, row.DataCompressed,
+			row.MetaChecksum, row.DataChecksum); err != nil {
+			log.Errorf("JobArchive Move() error: %v", err)
+			continue
+		}
+
+		if _, err := sa.db.Exec(`DELETE FROM job WHERE cluster = ? AND job_id = ? AND start_time = ?`,
+			job.Cluster, job.JobID, job.StartTime.Unix()); err != nil {
+			log.Errorf("JobArchive Move() error: %v", err)
+		}
+	}
+}
+
+func (sa *SqliteArchive) CleanUp(jobs []*schema.Job) {
+	start := time.Now()
+
+	tx, err := sa.db.Beginx()
+	if err != nil {
+		log.Errorf("JobArchive Cleanup() error: %v", err)
+		return
+	}
+	for _, job := range jobs {
+		if _, err := tx.Exec(`DELETE FROM job WHERE cluster = ? AND job_id = ? AND start_time = ?`,
+			job.Cluster, job.JobID, job.StartTime.Unix()); err != nil {
+			log.Errorf("JobArchive Cleanup() error: %v", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		log.Errorf("JobArchive Cleanup() error: %v", err)
+	}
+
+	log.Infof("Retention Service - Remove %d files in %s", len(jobs), time.Since(start))
+}
+
+func (sa *SqliteArchive) Compress(jobs []*schema.Job) {
+	var cnt int
+	start := time.Now()
+
+	for _, job := range jobs {
+		var data []byte
+		if err := sa.db.Get(&data, `SELECT data FROM job
+			WHERE cluster = ? AND job_id = ? AND start_time = ? AND data_compressed = 0`,
+			job.Cluster, job.JobID, job.StartTime.Unix()); err != nil {
+			continue
+		}
+		if len(data) <= 2000 {
+			continue
+		}
+
+		compressed, err := compressBlob(data)
+		if err != nil {
+			log.Errorf("JobArchive Compress() error: %v", err)
+			continue
+		}
+		if _, err := sa.db.Exec(`UPDATE job SET data = ?, data_compressed = 1,
+			data_checksum = CASE WHEN data_checksum IS NULL THEN NULL ELSE ? END
+			WHERE cluster = ? AND job_id = ? AND start_time = ?`,
+			compressed, computeChecksum(compressed),
+			job.Cluster, job.JobID, job.StartTime.Unix()); err != nil {
+			log.Errorf("JobArchive Compress() error: %v", err)
+			continue
+		}
+		cnt++
+	}
+
+	log.Infof("Compression Service - %d files took %s", cnt, time.Since(start))
+}
+
+func (sa *SqliteArchive) CompressLast(starttime int64) int64 {
+
+	value, err := sa.getInfo("compress_last")
+	if err != nil {
+		log.Errorf("sqliteBackend Compress - %v", err)
+		sa.setInfo("compress_last", strconv.FormatInt(starttime, 10))
+		return starttime
+	}
+	last, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		log.Errorf("sqliteBackend Compress - %v", err)
+		return starttime
+	}
+
+	log.Infof("sqliteBackend Compress - start %d last %d", starttime, last)
+	sa.setInfo("compress_last", strconv.FormatInt(starttime, 10))
+	return last
+}
+
+func (sa *SqliteArchive) Verify(job *schema.Job) error {
+	var row struct {
+		Meta         []byte         `db:"meta"`
+		Data         []byte         `db:"data"`
+		MetaChecksum sql.NullString `db:"meta_checksum"`
+		DataChecksum sql.NullString `db:"data_checksum"`
+	}
+	if err := sa.db.Get(&row, `SELECT meta, data, meta_checksum, data_checksum FROM job
+		WHERE cluster = ? AND job_id = ? AND start_time = ?`,
+		job.Cluster, job.JobID, job.StartTime.Unix()); err != nil {
+		return err
+	}
+
+	if !row.MetaChecksum.Valid {
+		return ErrNoChecksums
+	}
+	if computeChecksum(row.Meta) != row.MetaChecksum.String {
+		return fmt.Errorf("meta: checksum mismatch")
+	}
+	if !row.DataChecksum.Valid {
+		return fmt.Errorf("job data: %w", ErrNoChecksums)
+	}
+	if computeChecksum(row.Data) != row.DataChecksum.String {
+		return fmt.Errorf("data: checksum mismatch")
+	}
+
+	return nil
+}
+
+func (sa *SqliteArchive) loadJobDataRaw(job *schema.Job) ([]byte, error) {
+	var row struct {
+		Data           []byte `db:"data"`
+		DataCompressed bool   `db:"data_compressed"`
+	}
+	if err := sa.db.Get(&row, `SELECT data, data_compressed FROM job
+		WHERE cluster = ? AND job_id = ? AND start_time = ?`,
+		job.Cluster, job.JobID, job.StartTime.Unix()); err != nil {
+		log.Errorf("sqliteBackend LoadJobData()- %v", err)
+		return nil, err
+	}
+	if row.Data == nil {
+		return nil, fmt.Errorf("no metric data for job %d on %s", job.JobID, job.Cluster)
+	}
+
+	if row.DataCompressed {
+		return uncompressBlob(row.Data)
+	}
+
+	return row.Data, nil
+}
+
+func (sa *SqliteArchive) LoadJobData(job *schema.Job) (schema.JobData, error) {
+	b, err := sa.loadJobDataRaw(job)
+	if err != nil {
+		return nil, err
+	}
+
+	if config.Keys.Validate {
+		if err := schema.Validate(schema.Data, bytes.NewReader(b)); err != nil {
+			return schema.JobData{}, fmt.Errorf("validate job data: %v", err)
+		}
+	}
+
+	return DecodeJobData(bytes.NewReader(b), sa.cacheKey(job))
+}
+
+func (sa *SqliteArchive) LoadJobStats(job *schema.Job) (schema.ScopedJobStats, error) {
+	var stats []byte
+	if err := sa.db.Get(&stats, `SELECT stats FROM job
+		WHERE cluster = ? AND job_id = ? AND start_time = ?`,
+		job.Cluster, job.JobID, job.StartTime.Unix()); err != nil {
+		log.Errorf("sqliteBackend LoadJobStats()- %v", err)
+		return nil, err
+	}
+
+	// Jobs stored without precomputed statistics fall back to the metric data.
+	if stats == nil {
+		b, err := sa.loadJobDataRaw(job)
+		if err != nil {
+			return nil, err
+		}
+		return DecodeJobStats(bytes.NewReader(b), sa.cacheKey(job))
+	}
+
+	var scopedStats schema.ScopedJobStats
+	if err := json.Unmarshal(stats, &scopedStats); err != nil {
+		log.Warn("Error while decoding job stats json")
+		return nil, err
+	}
+
+	return scopedStats, nil
+}
+
+func (sa *SqliteArchive) LoadJobMeta(job *schema.Job) (*schema.JobMeta, error) {
+	var meta []byte
+	if err := sa.db.Get(&meta, `SELECT meta FROM job
+		WHERE cluster = ? AND job_id = ? AND start_time = ?`,
+		job.Cluster, job.JobID, job.StartTime.Unix()); err != nil {
+		log.Errorf("sqliteBackend LoadJobMeta()- %v", err)
+		return &schema.JobMeta{}, err
+	}
+
+	return decodeValidJobMeta(meta)
+}
+
+func decodeValidJobMeta(b []byte) (*schema.JobMeta, error) {
+	if config.Keys.Validate {
+		if err := schema.Validate(schema.Meta, bytes.NewReader(b)); err != nil {
+			return &schema.JobMeta{}, fmt.Errorf("validate job meta: %v", err)
+		}
+	}
+
+	return DecodeJobMeta(bytes.NewReader(b))
+}
+
+func (sa *SqliteArchive) LoadClusterCfg(name string) (*schema.Cluster, error) {
+
+	var b []byte
+	if err := sa.db.Get(&b, `SELECT config FROM cluster WHERE name = ?`, name); err != nil {
+		log.Errorf("LoadClusterCfg() > select error: %v", err)
+		return &schema.Cluster{}, err
+	}
+	if config.Keys.Validate {
+		if err := schema.Validate(schema.ClusterCfg, bytes.NewReader(b)); err != nil {
+			log.Warnf("Validate cluster config: %v\n", err)
+			return &schema.Cluster{}, fmt.Errorf("validate cluster config: %v", err)
+		}
+	}
+
+	return DecodeCluster(bytes.NewReader(b))
+}
+
+// StoreClusterCfg adds or replaces the cluster configuration of a cluster.
+func (sa *SqliteArchive) StoreClusterCfg(name string, cfg *schema.Cluster) error {
+	var buf bytes.Buffer
+	if err := EncodeCluster(&buf, cfg); err != nil {
+		return err
+	}
+
+	if _, err := sa.db.Exec(`INSERT INTO cluster (name, config) VALUES (?, ?)
+		ON CONFLICT (name) DO UPDATE SET config = excluded.config`, name, buf.Bytes()); err != nil {
+		log.Errorf("StoreClusterCfg() > insert error: %v", err)
+		return err
+	}
+
+	return sa.loadClusters()
+}
+
+func (sa *SqliteArchive) Iter(loadMetricData bool, filter *IterFilter) <-chan JobContainer {
+
+	ch := make(chan JobContainer)
+	go func() {
+		q := `SELECT cluster, job_id, start_time, meta FROM job WHERE 1 = 1`
+		args := make([]any, 0, 3)
+		if filter != nil {
+			if filter.Cluster != "" {
+				q += ` AND cluster = ?`
+				args = append(args, filter.Cluster)
+			}
+			if filter.StartTimeFrom != 0 {
+				q += ` AND start_time >= ?`
+				args = append(args, filter.StartTimeFrom)
+			}
+			if filter.StartTimeTo != 0 {
+				q += ` AND start_time <= ?`
+				args = append(args, filter.StartTimeTo)
+			}
+		}
+
+		rows, err := sa.db.Queryx(q+` ORDER BY cluster, start_time`, args...)
+		if err != nil {
+			log.Fatalf("Reading jobs failed: %s", err.Error())
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var cluster string
+			var jobID, startTime int64
+			var meta []byte
+			if err := rows.Scan(&cluster, &jobID, &startTime, &meta); err != nil {
+				log.Fatalf("Reading jobs failed: %s", err.Error())
+			}
+
+			jobMeta, err := decodeValidJobMeta(meta)
+			if err != nil {
+				log.Errorf("in %s/%d/%d: %s", cluster, jobID, startTime, err.Error())
+			}
+
+			if loadMetricData {
+				job := &schema.Job{StartTime: time.Unix(startTime, 0)}
+				job.Cluster = cluster
+				job.JobID = jobID
+
+				data, err := sa.LoadJobData(job)
+				if err != nil {
+					log.Errorf("in %s/%d/%d: %s", cluster, jobID, startTime, err.Error())
+				}
+				ch <- JobContainer{Meta: jobMeta, Data: &data}
+			} else {
+				ch <- JobContainer{Meta: jobMeta, Data: nil}
+			}
+		}
+		close(ch)
+	}()
+	return ch
+}
+
+func (sa *SqliteArchive) StoreJobMeta(jobMeta *schema.JobMeta) error {
+
+	var buf bytes.Buffer
+	if err := EncodeJobMeta(&buf, jobMeta); err != nil {
+		log.Error("Error while encoding job metadata")
+		return err
+	}
+
+	checksum := computeChecksum(buf.Bytes())
+	res, err := sa.db.Exec(`UPDATE job SET meta = ?, meta_checksum = ?
+		WHERE cluster = ? AND job_id = ? AND start_time = ?`,
+		buf.Bytes(), checksum, jobMeta.Cluster, jobMeta.JobID, jobMeta.StartTime)
+	if err != nil {
+		log.Error("Error while updating job metadata")
+		return err
+	}
+	if cnt, _ := res.RowsAffected(); cnt == 0 {
+		if _, err := sa.db.Exec(`INSERT INTO job (cluster, job_id, start_time, meta, meta_checksum)
+			VALUES (?, ?, ?, ?, ?)`,
+			jobMeta.Cluster, jobMeta.JobID, jobMeta.StartTime, buf.Bytes(), checksum); err != nil {
+			log.Error("Error while inserting job metadata")
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (sa *SqliteArchive) GetClusters() []string {
+	return sa.clusters
+}
+
+func (sa *SqliteArchive) ImportJob(
+	jobMeta *schema.JobMeta,
+	jobData *schema.JobData) error {
+
+	var metaBuf, dataBuf bytes.Buffer
+	if err := EncodeJobMeta(&metaBuf, jobMeta); err != nil {
+		log.Error("Error while encoding job metadata")
+		return err
+	}
+	if err := EncodeJobData(&dataBuf, jobData); err != nil {
+		log.Error("Error while encoding job metricdata")
+		return err
+	}
+	stats, err := json.Marshal(JobDataToStats(*jobData))
+	if err != nil {
+		log.Error("Error while encoding job stats")
+		return err
+	}
+
+	if _, err := sa.db.Exec(`INSERT OR REPLACE INTO job
+		(cluster, job_id, start_time, meta, data, stats, data_compressed, meta_checksum, data_checksum)
+		VALUES (?, ?, ?, ?, ?, ?, 0, ?, ?)`,
+		jobMeta.Cluster, jobMeta.JobID, jobMeta.StartTime,
+		metaBuf.Bytes(), dataBuf.Bytes(), stats,
+		computeChecksum(metaBuf.Bytes()), computeChecksum(dataBuf.Bytes())); err != nil {
+		log.Error("Error while inserting job into sqlite archive")
+		return err
+	}
+
+	return nil
+}
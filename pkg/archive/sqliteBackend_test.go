@@ -0,0 +1,229 @@
+// Copyright (C) NHR@FAU, University Erlangen-Nuremberg.
+// All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+package archive
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ClusterCockpit/cc-backend/pkg/schema"
+)
+
+// Creates a sqlite archive in a temporary directory and fills it with the
+// jobs and cluster configurations of the file based test archive.
+func setupSqlite(t *testing.T) *SqliteArchive {
+	var fsa FsArchive
+	if _, err := fsa.Init(json.RawMessage("{\"path\":\"testdata/archive\"}")); err != nil {
+		t.Fatal(err)
+	}
+
+	var sa SqliteArchive
+	dbPath := filepath.Join(t.TempDir(), "job-archive.db")
+	version, err := sa.Init(json.RawMessage(fmt.Sprintf("{\"kind\":\"sqlite\",\"dbPath\":\"%s\"}", dbPath)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { sa.db.Close() })
+	if version != Version {
+		t.Fatalf("unexpected version %d", version)
+	}
+
+	for _, cluster := range fsa.GetClusters() {
+		cfg, err := fsa.LoadClusterCfg(cluster)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := sa.StoreClusterCfg(cluster, cfg); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for job := range fsa.Iter(true, nil) {
+		if err := sa.ImportJob(job.Meta, job.Data); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return &sa
+}
+
+func TestSqliteInitEmptyPath(t *testing.T) {
+	var sa SqliteArchive
+	_, err := sa.Init(json.RawMessage("{\"kind\":\"sqlite\"}"))
+	if err == nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSqliteLoadJob(t *testing.T) {
+	sa := setupSqlite(t)
+
+	if len(sa.GetClusters()) != 3 || sa.GetClusters()[1] != "emmy" {
+		t.Errorf("unexpected clusters %v", sa.GetClusters())
+	}
+
+	jobIn := schema.Job{BaseJob: schema.JobDefaults}
+	jobIn.StartTime = time.Unix(1608923076, 0)
+	jobIn.JobID = 1403244
+	jobIn.Cluster = "emmy"
+
+	if !sa.Exists(&jobIn) {
+		t.Fatal("job does not exist")
+	}
+
+	job, err := sa.LoadJobMeta(&jobIn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if job.JobID != 1403244 || job.StartTime != 1608923076 {
+		t.Fail()
+	}
+
+	data, err := sa.LoadJobData(&jobIn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, scopes := range data {
+		if _, exists := scopes[schema.MetricScopeNode]; !exists {
+			t.Fail()
+		}
+	}
+
+	stats, err := sa.LoadJobStats(&jobIn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stats) != len(data) {
+		t.Errorf("expected stats for %d metrics, got %d", len(data), len(stats))
+	}
+
+	cfg, err := sa.LoadClusterCfg("emmy")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.SubClusters[0].CoresPerSocket != 4 {
+		t.Fail()
+	}
+}
+
+func TestSqliteStoreJobMeta(t *testing.T) {
+	sa := setupSqlite(t)
+
+	jobIn := schema.Job{BaseJob: schema.JobDefaults}
+	jobIn.StartTime = time.Unix(1608923076, 0)
+	jobIn.JobID = 1403244
+	jobIn.Cluster = "emmy"
+
+	jobMeta, err := sa.LoadJobMeta(&jobIn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	jobMeta.MetaData = map[string]string{"jobName": "renamed"}
+	if err := sa.StoreJobMeta(jobMeta); err != nil {
+		t.Fatal(err)
+	}
+
+	jobMeta, err = sa.LoadJobMeta(&jobIn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if jobMeta.MetaData["jobName"] != "renamed" {
+		t.Errorf("metadata was not updated: %v", jobMeta.MetaData)
+	}
+}
+
+func TestSqliteCompress(t *testing.T) {
+	sa := setupSqlite(t)
+
+	jobIn := schema.Job{BaseJob: schema.JobDefaults}
+	jobIn.StartTime = time.Unix(1608923076, 0)
+	jobIn.JobID = 1403244
+	jobIn.Cluster = "emmy"
+
+	sa.Compress([]*schema.Job{&jobIn})
+
+	var compressed bool
+	if err := sa.db.Get(&compressed, `SELECT data_compressed FROM job WHERE job_id = ?`, jobIn.JobID); err != nil {
+		t.Fatal(err)
+	}
+	if !compressed {
+		t.Fatal("job data was not compressed")
+	}
+
+	raw, err := sa.loadJobDataRaw(&jobIn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var data schema.JobData
+	if err := json.Unmarshal(raw, &data); err != nil {
+		t.Fatal(err)
+	}
+
+	if last := sa.CompressLast(100); last != 100 {
+		t.Errorf("expected 100, got %d", last)
+	}
+	if last := sa.CompressLast(200); last != 100 {
+		t.Errorf("expected 100, got %d", last)
+	}
+}
+
+func TestSqliteCleanAndIter(t *testing.T) {
+	sa := setupSqlite(t)
+
+	if cnt := countJobs(sa.Iter(false, &IterFilter{Cluster: "emmy", StartTimeTo: 1609000000})); cnt != 1 {
+		t.Errorf("expected 1 job before start time, got %d", cnt)
+	}
+
+	sa.Clean(1609000000, 0)
+
+	cnt := 0
+	for job := range sa.Iter(true, nil) {
+		if job.Meta.StartTime < 1609000000 {
+			t.Errorf("job %d was not removed", job.Meta.JobID)
+		}
+		if job.Data == nil || len(*job.Data) == 0 {
+			t.Errorf("no metric data for job %d", job.Meta.JobID)
+		}
+		cnt++
+	}
+	if cnt != 1 {
+		t.Errorf("expected 1 job, got %d", cnt)
+	}
+}
+
+func TestSqliteMoveAndCleanUp(t *testing.T) {
+	sa := setupSqlite(t)
+
+	jobs := make([]*schema.Job, 2)
+	jobs[0] = &schema.Job{StartTime: time.Unix(1608923076, 0)}
+	jobs[0].JobID = 1403244
+	jobs[0].Cluster = "emmy"
+	jobs[1] = &schema.Job{StartTime: time.Unix(1609300556, 0)}
+	jobs[1].JobID = 1404397
+	jobs[1].Cluster = "emmy"
+
+	target := filepath.Join(t.TempDir(), "moved.db")
+	sa.Move(jobs[:1], target)
+	if sa.Exists(jobs[0]) {
+		t.Error("moved job still exists")
+	}
+
+	var moved SqliteArchive
+	if _, err := moved.Init(json.RawMessage(fmt.Sprintf("{\"dbPath\":\"%s\"}", target))); err != nil {
+		t.Fatal(err)
+	}
+	defer moved.db.Close()
+	if !moved.Exists(jobs[0]) {
+		t.Error("job missing in target archive")
+	}
+
+	sa.CleanUp(jobs[1:])
+	if sa.Exists(jobs[1]) {
+		t.Error("job still exists")
+	}
+}
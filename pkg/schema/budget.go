@@ -0,0 +1,65 @@
+// Copyright (C) NHR@FAU, University Erlangen-Nuremberg.
+// All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+package schema
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ProjectBudget model
+// @Description Allocation of a project for a period, on one or all clusters.
+type ProjectBudget struct {
+	Project   string     `json:"project" db:"project" example:"abcd100"`
+	Cluster   string     `json:"cluster" db:"cluster" example:"fritz"` // Empty for all clusters
+	Unit      BudgetUnit `json:"unit" db:"unit" enums:"coreHours,accHours,energy" example:"coreHours"`
+	Amount    float64    `json:"amount" db:"amount" example:"1000000"`           // Allocated core-hours, accelerator-hours or kWh
+	StartTime int64      `json:"startTime" db:"start_time" example:"1704067200"` // Start of the period (unix epoch seconds)
+	EndTime   int64      `json:"endTime" db:"end_time" example:"1735689600"`     // End of the period (unix epoch seconds)
+	ID        int64      `json:"id" db:"id"`
+}
+
+// BudgetStatus model
+// @Description Consumption of a project budget, including running jobs.
+type BudgetStatus struct {
+	Budget    *ProjectBudget `json:"budget"`
+	Used      float64        `json:"used" example:"250000"`      // Consumed so far, in the unit of the budget
+	Remaining float64        `json:"remaining" example:"750000"` // Negative if the budget is overdrawn
+	BurnRate  float64        `json:"burnRate" example:"2500"`    // Average consumption per day since the start of the period
+	Projected float64        `json:"projected" example:"912500"` // Consumption at the end of the period at the current burn rate
+}
+
+type BudgetUnit string
+
+const (
+	BudgetUnitCoreHours BudgetUnit = "coreHours"
+	BudgetUnitAccHours  BudgetUnit = "accHours"
+	BudgetUnitEnergy    BudgetUnit = "energy"
+)
+
+func (e *BudgetUnit) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("SCHEMA/BUDGET > enums must be strings")
+	}
+
+	*e = BudgetUnit(str)
+	if !e.Valid() {
+		return errors.New("SCHEMA/BUDGET > invalid budget unit")
+	}
+
+	return nil
+}
+
+func (e BudgetUnit) MarshalGQL(w io.Writer) {
+	fmt.Fprintf(w, "\"%s\"", e)
+}
+
+func (e BudgetUnit) Valid() bool {
+	return e == BudgetUnitCoreHours ||
+		e == BudgetUnitAccHours ||
+		e == BudgetUnitEnergy
+}
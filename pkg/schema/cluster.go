@@ -5,6 +5,7 @@
 package schema
 
 import (
+	"encoding/json"
 	"fmt"
 	"strconv"
 )
@@ -29,6 +30,19 @@ type MetricValue struct {
 	Value float64 `json:"value"`
 }
 
+// UnmarshalJSON also accepts the plain numbers without unit of job archive
+// version 1.
+func (v *MetricValue) UnmarshalJSON(input []byte) error {
+	var f float64
+	if err := json.Unmarshal(input, &f); err == nil {
+		*v = MetricValue{Value: f}
+		return nil
+	}
+
+	type metricValue MetricValue
+	return json.Unmarshal(input, (*metricValue)(v))
+}
+
 type SubCluster struct {
 	Name            string         `json:"name"`
 	Nodes           string         `json:"nodes"`
@@ -63,6 +77,7 @@ type MetricConfig struct {
 	Name          string              `json:"name"`
 	Scope         MetricScope         `json:"scope"`
 	Aggregation   string              `json:"aggregation"`
+	Derived       string              `json:"derived,omitempty"`
 	Footprint     string              `json:"footprint,omitempty"`
 	SubClusters   []*SubClusterConfig `json:"subClusters,omitempty"`
 	Peak          float64             `json:"peak"`
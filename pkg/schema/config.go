@@ -79,6 +79,29 @@ type Retention struct {
 	Location  string `json:"location"`
 	Age       int    `json:"age"`
 	IncludeDB bool   `json:"includeDB"`
+	// Target timestep in seconds for policy downsample
+	Resolution int `json:"resolution"`
+	// Remove sub-node scopes of jobs older than this many days, only
+	// applicable for policy downsample. 0 keeps all scopes.
+	DropScopesAge int `json:"dropScopesAge"`
+	// Rules for individual jobs, evaluated in order before the policy above
+	Rules []RetentionRule `json:"rules"`
+}
+
+// A retention rule matches all jobs for which all its non-empty criteria
+// match. The first matching rule decides what happens to a job, options that
+// are not set are taken from the enclosing retention configuration.
+type RetentionRule struct {
+	Cluster   string `json:"cluster"`
+	Partition string `json:"partition"`
+	Project   string `json:"project"`
+	Tag       string `json:"tag"`
+	State     string `json:"jobState"`
+	// One of keep, delete, move or downsample
+	Policy    string `json:"policy"`
+	Location  string `json:"location"`
+	Age       int    `json:"age"`
+	IncludeDB *bool  `json:"includeDB"`
 }
 
 type ResampleConfig struct {
@@ -95,6 +118,108 @@ type CronFrequency struct {
 	FootprintWorker string `json:"footprint-worker"`
 }
 
+type SlurmConfig struct {
+	// Name of the cluster the jobs are added to
+	Cluster string `json:"cluster"`
+	// Name of the cluster in Slurm [Defaults to `cluster`]
+	SlurmCluster string `json:"slurm-cluster"`
+	// Files written by `sacct --json`, patterns are expanded using filepath.Glob.
+	// Files are read again once modified.
+	Files []string `json:"files"`
+	// URL of slurmrestd (for example: 'http://localhost:6820'), used if no files are configured.
+	// The token is read from the environment variable SLURM_JWT.
+	Url        string `json:"url"`
+	User       string `json:"user"`
+	ApiVersion string `json:"api-version"` // Defaults to 'v0.0.40'
+	// Parsed using time.ParseDuration [Defaults to '1m']
+	SyncInterval string `json:"sync-interval"`
+	// Jobs running within this duration before the first sync are imported.
+	// Parsed using time.ParseDuration [Defaults to '24h']
+	Lookback string `json:"lookback"`
+}
+
+type WebhookConfig struct {
+	// URL the events are posted to
+	Url string `json:"url"`
+	// Events sent to this URL (job.started, job.stopped, job.archived,
+	// job.archiving_failed, tag.added, job.alert). All events are sent if empty.
+	Events []string `json:"events"`
+	// Only send events of jobs matching these filters. Empty filters match all jobs.
+	Clusters []string   `json:"clusters"`
+	Projects []string   `json:"projects"`
+	States   []JobState `json:"states"`
+	// Secret used to sign the payload with HMAC-SHA256, sent in the X-CC-Signature header
+	Secret string `json:"secret"`
+}
+
+// A job rule attaches its tag to all jobs for which all its conditions
+// match. Rules are applied once a job is archived and periodically to running
+// jobs, tags are never removed again.
+type JobRuleConfig struct {
+	// Name of the rule, used in log messages
+	Name string      `json:"name"`
+	Tag  *JobRuleTag `json:"tag"`
+	// Only apply this rule to jobs on these clusters. Applies to all clusters if empty.
+	Clusters   []string            `json:"clusters"`
+	Conditions []*JobRuleCondition `json:"conditions"`
+}
+
+type JobRuleTag struct {
+	Type  string `json:"type"` // Defaults to 'jobClass'
+	Name  string `json:"name"`
+	Scope string `json:"scope"` // Defaults to 'global'
+}
+
+// A condition compares either a job property or a statistic of a metric to
+// `value`. If `threshold` is set, `value` is a fraction of that threshold of
+// the metric as configured for the subcluster of the job, for example value
+// 0.05 and threshold peak for "less than 5% of peak".
+type JobRuleCondition struct {
+	// One of duration (seconds), numNodes, numHwthreads, numAcc or energy
+	Property string `json:"property"`
+	Metric   string `json:"metric"`
+	// Statistic of the metric: avg, min or max [Defaults to 'avg']
+	Stat string `json:"stat"`
+	// One of <, <=, > or >=
+	Op        string  `json:"op"`
+	Value     float64 `json:"value"`
+	Threshold string  `json:"threshold"` // One of peak, normal, caution or alert
+}
+
+type SmtpConfig struct {
+	// Address of the mail server (for example: 'mail.example.org:587').
+	// STARTTLS is used if the server supports it.
+	Addr string `json:"addr"`
+	// Sender address of all emails
+	From string `json:"from"`
+	// Login for the mail server. The password is read from the environment variable SMTP_PASSWORD.
+	Username string `json:"username"`
+	// URL the web interface is reachable at, used for links in emails (for example: 'https://monitoring.example.org')
+	BaseUrl string `json:"base-url"`
+}
+
+// An alert fires once its conditions match the statistics of a running job
+// over the configured duration. Alerts are recorded in the database and only fire once per job.
+type AlertConfig struct {
+	// Name of the alert, used in notifications
+	Name string `json:"name"`
+	// Only check jobs on these clusters. Applies to all clusters if empty.
+	Clusters   []string            `json:"clusters"`
+	Conditions []*JobRuleCondition `json:"conditions"`
+	// job: conditions match the statistics of the whole job. node: conditions
+	// match the statistics of some, but not all nodes of the job. [Defaults to 'job']
+	Scope string `json:"scope"`
+	// Conditions are evaluated on the statistics of this last period of the job, once the job ran this long.
+	// Without, the statistics of the whole job are used. Parsed using time.ParseDuration [Defaults to '0s']
+	For string `json:"for"`
+	// Tag attached to the job [Defaults to type 'alert', the name of the alert and scope 'global']
+	Tag *JobRuleTag `json:"tag"`
+	// Send an email to the owner of the job
+	NotifyOwner bool `json:"notifyOwner"`
+	// Send an email to these addresses, for example to support staff
+	Emails []string `json:"emails"`
+}
+
 // Format of the configuration (file). See below for the defaults.
 type ProgramConfig struct {
 	// Address where the http (or https) server will listen on (for example: 'localhost:80').
@@ -171,6 +296,21 @@ type ProgramConfig struct {
 	// Frequency of cron job workers
 	CronFrequency *CronFrequency `json:"cron-frequency"`
 
+	// Import jobs from Slurm accounting data
+	Slurm []*SlurmConfig `json:"slurm"`
+
+	// Post job lifecycle events to these URLs
+	Webhooks []*WebhookConfig `json:"webhooks"`
+
+	// Tag jobs automatically based on their metrics
+	JobRules []*JobRuleConfig `json:"job-rules"`
+
+	// Alerts for running jobs, checked by the footprint worker
+	Alerts []*AlertConfig `json:"alerts"`
+
+	// Mail server for notifications
+	Smtp *SmtpConfig `json:"smtp"`
+
 	// Array of Clusters
 	Clusters []*ClusterConfig `json:"clusters"`
 }
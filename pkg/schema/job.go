@@ -5,10 +5,13 @@
 package schema
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"time"
+
+	ccunits "github.com/ClusterCockpit/cc-units"
 )
 
 // BaseJob is the common part of the job metadata structs
@@ -105,6 +108,39 @@ type Unit struct {
 	Prefix string `json:"prefix,omitempty"`
 }
 
+// UnmarshalJSON also accepts the plain unit strings of job archive version 1,
+// e.g. "GB/s".
+func (u *Unit) UnmarshalJSON(input []byte) error {
+	var s string
+	if err := json.Unmarshal(input, &s); err == nil {
+		*u = ConvertUnitString(s)
+		return nil
+	}
+
+	type unit Unit
+	return json.Unmarshal(input, (*unit)(u))
+}
+
+// ConvertUnitString splits a unit string like "GB/s" into its prefix and base.
+// Strings that are no known unit, e.g. "IPC" or "load", are kept as base.
+func ConvertUnitString(us string) Unit {
+	u := ccunits.NewUnit(us)
+	if !u.Valid() {
+		return Unit{Base: us}
+	}
+
+	p, m, d := u.GetPrefix(), u.GetMeasure(), u.GetUnitDenominator()
+	res := Unit{Prefix: p.Prefix(), Base: m.Short()}
+	if m == ccunits.Flops {
+		// The job archive uses F instead of Flops
+		res.Base = "F"
+	}
+	if d != ccunits.InvalidMeasure {
+		res.Base += "/" + d.Short()
+	}
+	return res
+}
+
 // JobStatistics model
 // @Description Specification for job metric statistics.
 type JobStatistics struct {
@@ -123,6 +159,17 @@ type Tag struct {
 	ID    int64  `json:"id" db:"id"`
 }
 
+// JobAlert model
+// @Description An alert which fired for a running job.
+type JobAlert struct {
+	Name      string `json:"name" db:"name" example:"idle-gpus"`
+	Message   string `json:"message" db:"message"`
+	ID        int64  `json:"id" db:"id"`
+	JobID     int64  `json:"jobId" db:"job_id" example:"123000"`             // The unique DB identifier of the job
+	Since     int64  `json:"since" db:"since" example:"1649723812"`          // Start of the period the conditions matched (unix epoch seconds)
+	CreatedAt int64  `json:"createdAt" db:"created_at" example:"1649725612"` // Time the alert fired (unix epoch seconds)
+}
+
 // Resource model
 // @Description A resource used by a job
 type Resource struct {
@@ -0,0 +1,31 @@
+// Copyright (C) NHR@FAU, University Erlangen-Nuremberg.
+// All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+package schema
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestConvertUnitString(t *testing.T) {
+	for in, want := range map[string]Unit{
+		"GB/s": {Prefix: "G", Base: "B/s"},
+		"GF/s": {Prefix: "G", Base: "F/s"},
+		"MHz":  {Prefix: "M", Base: "Hz"},
+		"W":    {Base: "W"},
+		"GB":   {Prefix: "G", Base: "B"},
+		"IPC":  {Base: "IPC"},
+		"load": {Base: "load"},
+		"":     {},
+	} {
+		if got := ConvertUnitString(in); got != want {
+			t.Errorf("%q: got %#v, want %#v", in, got, want)
+		}
+	}
+	var v MetricValue
+	if err := json.Unmarshal([]byte("14"), &v); err != nil || v.Value != 14 {
+		t.Error(v, err)
+	}
+}
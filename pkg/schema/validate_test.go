@@ -32,6 +32,29 @@ func TestValidateConfig(t *testing.T) {
 	}
 }
 
+func TestValidateConfigChain(t *testing.T) {
+	json := []byte(`{
+    "jwts": {
+        "max-age": "2m"
+    },
+	"clusters": [
+	{
+	   "name": "testcluster",
+	   "metricDataRepository": [
+		{"kind": "cc-metric-store", "url": "localhost:8082", "max-age": "336h"},
+		{"kind": "influxdb", "url": "localhost:8086", "until": "2024-07-01T00:00:00Z"}],
+	   "filterRanges": {
+		"numNodes": { "from": 1, "to": 64 },
+		"duration": { "from": 0, "to": 86400 },
+		"startTime": { "from": "2022-01-01T00:00:00Z", "to": null }
+	}}]
+}`)
+
+	if err := Validate(Config, bytes.NewReader(json)); err != nil {
+		t.Errorf("Error is not nil! %v", err)
+	}
+}
+
 func TestValidateJobMeta(t *testing.T) {
 
 }
@@ -0,0 +1,228 @@
+// Copyright (C) NHR@FAU, University Erlangen-Nuremberg.
+// All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+
+	"github.com/ClusterCockpit/cc-backend/internal/util"
+	"github.com/ClusterCockpit/cc-backend/pkg/archive"
+	"github.com/ClusterCockpit/cc-backend/pkg/log"
+)
+
+type convertStats struct {
+	source    int
+	converted int
+	skipped   int
+	failed    int
+	target    int
+}
+
+// Returns the archive configuration given either inline as JSON object or as
+// path to a file containing it.
+func loadArchiveConfig(in string) json.RawMessage {
+	if strings.HasPrefix(strings.TrimSpace(in), "{") {
+		return json.RawMessage(in)
+	}
+
+	b, err := os.ReadFile(in)
+	if err != nil {
+		log.Abortf("Archive Manager: Could not read archive config '%s'.\nError: %s\n", in, err.Error())
+	}
+
+	return json.RawMessage(b)
+}
+
+// A file archive must contain a version.txt before it can be opened. Create
+// an empty archive of the current version if the target does not exist yet.
+func prepareFileArchive(rawConfig json.RawMessage) error {
+	var cfg struct {
+		Kind string `json:"kind"`
+		Path string `json:"path"`
+	}
+	if err := json.Unmarshal(rawConfig, &cfg); err != nil {
+		return err
+	}
+	if cfg.Kind != "file" || cfg.Path == "" {
+		return nil
+	}
+
+	versionFile := filepath.Join(cfg.Path, "version.txt")
+	if util.CheckFileExists(versionFile) {
+		return nil
+	}
+	if err := os.MkdirAll(cfg.Path, 0777); err != nil {
+		return err
+	}
+
+	return os.WriteFile(versionFile, []byte(fmt.Sprintf("%d\n", archive.Version)), 0644)
+}
+
+func jobKey(cluster string, jobID int64, startTime int64) string {
+	return fmt.Sprintf("%s/%d/%d", cluster, jobID, startTime)
+}
+
+// Reads the keys of all jobs converted by a previous, possibly interrupted run.
+func loadProgress(filename string) (map[string]bool, error) {
+	done := make(map[string]bool)
+
+	f, err := os.Open(filename)
+	if os.IsNotExist(err) {
+		return done, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" {
+			done[line] = true
+		}
+	}
+
+	return done, scanner.Err()
+}
+
+// Copies all cluster configurations and jobs from src to dst. Jobs listed in
+// the progress file are skipped, every successfully converted job is
+// appended to it, which allows to resume an interrupted conversion.
+func convertArchive(
+	src, dst archive.ArchiveBackend,
+	numWorkers int,
+	progressFile string,
+) map[string]*convertStats {
+	stats := make(map[string]*convertStats)
+	for _, cluster := range src.GetClusters() {
+		cfg, err := src.LoadClusterCfg(cluster)
+		if err != nil {
+			log.Errorf("Convert: Loading cluster config for %s failed: %s", cluster, err.Error())
+			continue
+		}
+		if err := dst.StoreClusterCfg(cluster, cfg); err != nil {
+			log.Errorf("Convert: Storing cluster config for %s failed: %s", cluster, err.Error())
+		}
+	}
+
+	done, err := loadProgress(progressFile)
+	if err != nil {
+		log.Abortf("Archive Manager: Could not read progress file '%s'.\nError: %s\n", progressFile, err.Error())
+	}
+	if len(done) > 0 {
+		log.Infof("Convert: Resuming, %d jobs already converted", len(done))
+	}
+
+	progress, err := os.OpenFile(progressFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		log.Abortf("Archive Manager: Could not open progress file '%s'.\nError: %s\n", progressFile, err.Error())
+	}
+	defer progress.Close()
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	count := func(cluster string, fn func(s *convertStats)) {
+		mu.Lock()
+		defer mu.Unlock()
+		if _, ok := stats[cluster]; !ok {
+			stats[cluster] = &convertStats{}
+		}
+		fn(stats[cluster])
+	}
+
+	jobs := src.Iter(true, nil)
+	for range numWorkers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobs {
+				if job.Meta == nil || job.Meta.Cluster == "" {
+					count("", func(s *convertStats) { s.source++; s.failed++ })
+					continue
+				}
+
+				key := jobKey(job.Meta.Cluster, job.Meta.JobID, job.Meta.StartTime)
+				if done[key] {
+					count(job.Meta.Cluster, func(s *convertStats) { s.source++; s.skipped++ })
+					continue
+				}
+
+				if job.Data == nil || len(*job.Data) == 0 {
+					log.Errorf("Convert: No metric data for job %s", key)
+					count(job.Meta.Cluster, func(s *convertStats) { s.source++; s.failed++ })
+					continue
+				}
+
+				if err := dst.ImportJob(job.Meta, job.Data); err != nil {
+					log.Errorf("Convert: Importing job %s failed: %s", key, err.Error())
+					count(job.Meta.Cluster, func(s *convertStats) { s.source++; s.failed++ })
+					continue
+				}
+
+				count(job.Meta.Cluster, func(s *convertStats) {
+					s.source++
+					s.converted++
+					fmt.Fprintln(progress, key)
+				})
+			}
+		}()
+	}
+	wg.Wait()
+
+	for job := range dst.Iter(false, nil) {
+		if job.Meta != nil && job.Meta.Cluster != "" {
+			count(job.Meta.Cluster, func(s *convertStats) { s.target++ })
+		}
+	}
+
+	return stats
+}
+
+// Prints the result of a conversion and reports whether every source job
+// exists in the destination archive.
+func printConvertSummary(stats map[string]*convertStats) bool {
+	clusters := make([]string, 0, len(stats))
+	for cluster := range stats {
+		clusters = append(clusters, cluster)
+	}
+	sort.Strings(clusters)
+
+	ok := true
+	total := convertStats{}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.Debug)
+	fmt.Fprintln(w, "cluster\tsource\tconverted\tskipped\tfailed\tdestination\tstatus")
+	for _, cluster := range clusters {
+		s := stats[cluster]
+		status := "OK"
+		if s.failed > 0 || s.target < s.source {
+			status = "MISMATCH"
+			ok = false
+		}
+		name := cluster
+		if name == "" {
+			name = "(unreadable)"
+		}
+		fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%d\t%d\t%s\n",
+			name, s.source, s.converted, s.skipped, s.failed, s.target, status)
+
+		total.source += s.source
+		total.converted += s.converted
+		total.skipped += s.skipped
+		total.failed += s.failed
+		total.target += s.target
+	}
+	fmt.Fprintf(w, "TOTAL\t%d\t%d\t%d\t%d\t%d\t\n",
+		total.source, total.converted, total.skipped, total.failed, total.target)
+	w.Flush()
+
+	return ok
+}
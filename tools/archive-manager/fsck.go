@@ -0,0 +1,196 @@
+// Copyright (C) NHR@FAU, University Erlangen-Nuremberg.
+// All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ClusterCockpit/cc-backend/internal/importer"
+	"github.com/ClusterCockpit/cc-backend/internal/repository"
+	"github.com/ClusterCockpit/cc-backend/pkg/archive"
+	"github.com/ClusterCockpit/cc-backend/pkg/log"
+	"github.com/ClusterCockpit/cc-backend/pkg/schema"
+)
+
+const (
+	fsckCorrupt     = "corrupt"
+	fsckUnchecked   = "no checksums"
+	fsckMismatch    = "db mismatch"
+	fsckArchiveOnly = "archive only"
+	fsckDBOnly      = "db only"
+)
+
+type fsckIssue struct {
+	kind    string
+	cluster string
+	jobId   int64
+	start   int64
+	detail  string
+	action  string
+}
+
+type fsckResult struct {
+	jobs   int
+	issues []fsckIssue
+}
+
+// Compares the fields of an archived job that are also stored in the job
+// table. Returns a description of all differences.
+func compareJob(meta *schema.JobMeta, job *schema.Job) []string {
+	var diffs []string
+	check := func(field string, a, b any) {
+		if a != b {
+			diffs = append(diffs, fmt.Sprintf("%s: archive '%v' db '%v'", field, a, b))
+		}
+	}
+
+	check("user", meta.User, job.User)
+	check("project", meta.Project, job.Project)
+	check("subCluster", meta.SubCluster, job.SubCluster)
+	check("jobState", meta.State, job.State)
+	check("duration", meta.Duration, job.Duration)
+	check("numNodes", meta.NumNodes, job.NumNodes)
+	check("numHwthreads", meta.NumHWThreads, job.NumHWThreads)
+	check("numAcc", meta.NumAcc, job.NumAcc)
+	check("resources", len(meta.Resources), len(job.Resources))
+
+	return diffs
+}
+
+// Checks the integrity of all jobs in the archive and its consistency with
+// the job table. With repair set, archive-only jobs are imported into the
+// database and corrupt jobs as well as database rows without archived data
+// are flagged with MonitoringStatusArchivingFailed.
+func fsck(ar archive.ArchiveBackend, r *repository.JobRepository, repair bool) *fsckResult {
+	result := &fsckResult{}
+	archived := make(map[string]struct{})
+
+	for job := range ar.Iter(false, nil) {
+		if job.Meta == nil || job.Meta.Cluster == "" {
+			continue
+		}
+		result.jobs++
+		meta := job.Meta
+		archived[jobKey(meta.Cluster, meta.JobID, meta.StartTime)] = struct{}{}
+
+		j := &schema.Job{BaseJob: meta.BaseJob}
+		j.StartTime = time.Unix(meta.StartTime, 0)
+		j.StartTimeUnix = meta.StartTime
+
+		issue := fsckIssue{cluster: meta.Cluster, jobId: meta.JobID, start: meta.StartTime}
+		corrupt := false
+		if verr := ar.Verify(j); verr != nil {
+			if errors.Is(verr, archive.ErrNoChecksums) {
+				// Fall back to decoding the job data for jobs archived
+				// before checksums were recorded
+				if _, err := ar.LoadJobData(j); err != nil {
+					corrupt = true
+					issue.kind, issue.detail = fsckCorrupt, err.Error()
+				} else {
+					unchecked := issue
+					unchecked.kind, unchecked.detail = fsckUnchecked, verr.Error()
+					result.issues = append(result.issues, unchecked)
+				}
+			} else {
+				corrupt = true
+				issue.kind, issue.detail = fsckCorrupt, verr.Error()
+			}
+		}
+
+		dbJob, err := r.Find(&meta.JobID, &meta.Cluster, &meta.StartTime)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			log.Errorf("fsck: Querying job %d on %s failed: %s", meta.JobID, meta.Cluster, err.Error())
+			continue
+		}
+
+		if corrupt {
+			if repair && dbJob != nil {
+				issue.action = flagFailed(r, dbJob)
+			}
+			result.issues = append(result.issues, issue)
+			continue
+		}
+
+		if dbJob == nil {
+			issue.kind = fsckArchiveOnly
+			if repair {
+				if _, err := importer.ImportArchivedJob(meta); err != nil {
+					issue.action = fmt.Sprintf("import failed: %s", err.Error())
+				} else {
+					issue.action = "imported"
+				}
+			}
+			result.issues = append(result.issues, issue)
+			continue
+		}
+
+		if diffs := compareJob(meta, dbJob); len(diffs) > 0 {
+			for _, d := range diffs {
+				result.issues = append(result.issues, fsckIssue{
+					kind: fsckMismatch, cluster: meta.Cluster, jobId: meta.JobID,
+					start: meta.StartTime, detail: d,
+				})
+			}
+		}
+	}
+
+	jobs, err := r.FindJobsByMonitoringStatus(schema.MonitoringStatusArchivingSuccessful)
+	if err != nil {
+		log.Errorf("fsck: Querying archived jobs failed: %s", err.Error())
+		return result
+	}
+
+	for _, job := range jobs {
+		if _, ok := archived[jobKey(job.Cluster, job.JobID, job.StartTimeUnix)]; ok {
+			continue
+		}
+
+		issue := fsckIssue{
+			kind: fsckDBOnly, cluster: job.Cluster, jobId: job.JobID,
+			start: job.StartTimeUnix, detail: "no archived job data",
+		}
+		if repair {
+			issue.action = flagFailed(r, job)
+		}
+		result.issues = append(result.issues, issue)
+	}
+
+	return result
+}
+
+func flagFailed(r *repository.JobRepository, job *schema.Job) string {
+	if err := r.UpdateMonitoringStatus(job.ID, schema.MonitoringStatusArchivingFailed); err != nil {
+		return fmt.Sprintf("flagging failed: %s", err.Error())
+	}
+
+	return "flagged archiving failed"
+}
+
+// Prints all issues found and returns true if the archive is consistent
+func printFsckSummary(result *fsckResult) bool {
+	counts := make(map[string]int)
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.Debug)
+	fmt.Fprintln(w, "issue\tcluster\tjobId\tstartTime\tdetail\taction")
+	for _, i := range result.issues {
+		counts[i.kind]++
+		fmt.Fprintf(w, "%s\t%s\t%d\t%d\t%s\t%s\n",
+			i.kind, i.cluster, i.jobId, i.start, i.detail, i.action)
+	}
+	w.Flush()
+
+	fmt.Printf("\nChecked %d jobs\n", result.jobs)
+	w = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.Debug)
+	for _, kind := range []string{fsckCorrupt, fsckUnchecked, fsckMismatch, fsckArchiveOnly, fsckDBOnly} {
+		fmt.Fprintf(w, "%s\t%d\n", kind, counts[kind])
+	}
+	w.Flush()
+
+	return len(result.issues) == counts[fsckUnchecked]
+}
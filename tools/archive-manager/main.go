@@ -9,9 +9,11 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"runtime"
 	"time"
 
 	"github.com/ClusterCockpit/cc-backend/internal/config"
+	"github.com/ClusterCockpit/cc-backend/internal/repository"
 	"github.com/ClusterCockpit/cc-backend/pkg/archive"
 	"github.com/ClusterCockpit/cc-backend/pkg/log"
 )
@@ -32,7 +34,9 @@ func parseDate(in string) int64 {
 
 func main() {
 	var srcPath, flagConfigFile, flagLogLevel, flagRemoveCluster, flagRemoveAfter, flagRemoveBefore string
-	var flagLogDateTime, flagValidate bool
+	var flagSrcConfig, flagConvert, flagProgressFile, flagMigrateTo string
+	var flagLogDateTime, flagValidate, flagMigrate, flagDryRun, flagFsck, flagFsckRepair, flagRebuildIndex bool
+	var flagWorkers int
 
 	flag.StringVar(&srcPath, "s", "./var/job-archive", "Specify the source job archive path. Default is ./var/job-archive")
 	flag.BoolVar(&flagLogDateTime, "logdate", false, "Set this flag to add date and time to log messages")
@@ -42,26 +46,111 @@ func main() {
 	flag.StringVar(&flagRemoveBefore, "remove-before", "", "Remove all jobs with start time before date (Format: 2006-Jan-04)")
 	flag.StringVar(&flagRemoveAfter, "remove-after", "", "Remove all jobs with start time after date (Format: 2006-Jan-04)")
 	flag.BoolVar(&flagValidate, "validate", false, "Set this flag to validate a job archive against the json schema")
+	flag.StringVar(&flagSrcConfig, "src-config", "", "Specify the source archive config as JSON object or path to a JSON file. Overrides -s")
+	flag.StringVar(&flagConvert, "convert", "", "Convert the source archive into the destination archive given as JSON object or path to a JSON file")
+	flag.StringVar(&flagProgressFile, "progress", "./archive-convert.progress", "Specify the file recording converted jobs, used to resume an interrupted conversion")
+	flag.BoolVar(&flagMigrate, "migrate", false, "Upgrade the source archive to the current archive version")
+	flag.StringVar(&flagMigrateTo, "migrate-to", "", "Write the upgraded archive into the destination archive given as JSON object or path to a JSON file instead of upgrading in place")
+	flag.BoolVar(&flagDryRun, "dry-run", false, "Only report how many jobs each upgrade step would change")
+	flag.BoolVar(&flagFsck, "fsck", false, "Verify job checksums and check the archive for consistency with the job database")
+	flag.BoolVar(&flagFsckRepair, "fsck-repair", false, "Together with -fsck: import archive-only jobs and flag corrupt or missing jobs as archiving failed")
+	flag.BoolVar(&flagRebuildIndex, "rebuild-index", false, "Create or rebuild the job index of a file archive from scratch")
+	flag.IntVar(&flagWorkers, "workers", runtime.NumCPU(), "Number of parallel workers for archive conversion")
 	flag.Parse()
 
-	archiveCfg := fmt.Sprintf("{\"kind\": \"file\",\"path\": \"%s\"}", srcPath)
+	archiveCfg := json.RawMessage(fmt.Sprintf("{\"kind\": \"file\",\"path\": \"%s\"}", srcPath))
+	if flagSrcConfig != "" {
+		archiveCfg = loadArchiveConfig(flagSrcConfig)
+	}
 
 	log.Init(flagLogLevel, flagLogDateTime)
 	config.Init(flagConfigFile)
 
-	if err := archive.Init(json.RawMessage(archiveCfg), false); err != nil {
+	if flagMigrate {
+		// Files of older versions do not validate against the current schemas
+		config.Keys.Validate = false
+		src, version, err := archive.Open(archiveCfg)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		dst := src
+		if flagMigrateTo != "" && !flagDryRun {
+			dstCfg := loadArchiveConfig(flagMigrateTo)
+			if err := prepareFileArchive(dstCfg); err != nil {
+				log.Fatal(err)
+			}
+			if dst, err = archive.New(dstCfg); err != nil {
+				log.Fatal(err)
+			}
+		}
+
+		result, err := archive.MigrateArchive(src, version, dst, flagDryRun)
+		if result != nil {
+			printMigrationSummary(result, flagDryRun)
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+		os.Exit(0)
+	}
+
+	if flagConvert != "" {
+		dstCfg := loadArchiveConfig(flagConvert)
+		if err := prepareFileArchive(dstCfg); err != nil {
+			log.Fatal(err)
+		}
+
+		src, err := archive.New(archiveCfg)
+		if err != nil {
+			log.Fatal(err)
+		}
+		dst, err := archive.New(dstCfg)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		if !printConvertSummary(convertArchive(src, dst, max(flagWorkers, 1), flagProgressFile)) {
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+
+	if err := archive.Init(archiveCfg, false); err != nil {
 		log.Fatal(err)
 	}
 	ar := archive.GetHandle()
 
 	if flagValidate {
 		config.Keys.Validate = true
-		for job := range ar.Iter(true) {
+		for job := range ar.Iter(true, nil) {
 			log.Printf("Validate %s - %d\n", job.Meta.Cluster, job.Meta.JobID)
 		}
 		os.Exit(0)
 	}
 
+	if flagRebuildIndex {
+		fsa, ok := ar.(*archive.FsArchive)
+		if !ok {
+			log.Fatal("Archive Manager: Only file archives have a job index")
+		}
+		start := time.Now()
+		cnt, err := fsa.RebuildIndex()
+		if err != nil {
+			log.Fatalf("Archive Manager: Rebuilding job index failed: %s", err.Error())
+		}
+		fmt.Printf("Indexed %d jobs in %s\n", cnt, time.Since(start))
+		os.Exit(0)
+	}
+
+	if flagFsck {
+		repository.Connect(config.Keys.DBDriver, config.Keys.DB)
+		if !printFsckSummary(fsck(ar, repository.GetJobRepository(), flagFsckRepair)) {
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+
 	if flagRemoveBefore != "" || flagRemoveAfter != "" {
 		ar.Clean(parseDate(flagRemoveBefore), parseDate(flagRemoveAfter))
 		os.Exit(0)
@@ -0,0 +1,30 @@
+// Copyright (C) NHR@FAU, University Erlangen-Nuremberg.
+// All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+package main
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/ClusterCockpit/cc-backend/pkg/archive"
+)
+
+func printMigrationSummary(result *archive.MigrationResult, dryRun bool) {
+	if dryRun {
+		fmt.Printf("Dry run: upgrade from version %d to %d\n", result.From, archive.Version)
+	} else {
+		fmt.Printf("Upgrade from version %d to %d\n", result.From, archive.Version)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.Debug)
+	fmt.Fprintln(w, "step\tdescription\t#jobs changed\t#clusters changed")
+	for _, s := range result.Steps {
+		fmt.Fprintf(w, "%d -> %d\t%s\t%d\t%d\n",
+			s.From, s.From+1, s.Description, s.JobsChanged, s.ClustersChanged)
+	}
+	fmt.Fprintf(w, "TOTAL\t%d jobs\t%d failed\t\n", result.Jobs, result.Failed)
+	w.Flush()
+}